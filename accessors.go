@@ -0,0 +1,32 @@
+package cache
+
+// GetValue gets a copy of an item from the cache and tells if it was found
+//
+// It is Get for the callers that do not need the error: the item is not found
+// if it is missing, expired, or cannot be read from the disk.
+func (cache *Cache[T]) GetValue(key string) (item T, found bool) {
+	if value, err := cache.Get(key); err == nil {
+		return *value, true
+	}
+	return item, false
+}
+
+// MustGet gets an item from the cache or panics
+//
+// It is meant for initialization code and tests, where a missing item cannot be recovered from.
+func (cache *Cache[T]) MustGet(key string) T {
+	item, err := cache.Get(key)
+	if err != nil {
+		panic(err)
+	}
+	return *item
+}
+
+// MustSet sets an item in the cache or panics
+//
+// It is meant for initialization code and tests, where a failed write cannot be recovered from.
+func (cache *Cache[T]) MustSet(item T, key ...string) {
+	if err := cache.Set(item, key...); err != nil {
+		panic(err)
+	}
+}
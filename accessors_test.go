@@ -0,0 +1,28 @@
+package cache_test
+
+import (
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+func (suite *CacheSuite) TestCanGetValue() {
+	items := cache.New[string]("test")
+	_ = items.Set("value", "key")
+
+	value, found := items.GetValue("key")
+	suite.Assert().True(found)
+	suite.Assert().Equal("value", value)
+
+	value, found = items.GetValue("missing")
+	suite.Assert().False(found)
+	suite.Assert().Empty(value)
+}
+
+func (suite *CacheSuite) TestCanMustGetAndSet() {
+	items := cache.New[string]("test")
+	suite.Assert().NotPanics(func() { items.MustSet("value", "key") })
+	suite.Assert().Equal("value", items.MustGet("key"))
+
+	suite.Assert().PanicsWithError(errors.NotFound.With("key", "missing").Error(), func() { items.MustGet("missing") })
+	suite.Assert().Panics(func() { items.MustSet("value") }, "Setting an item without keys should panic")
+}
@@ -0,0 +1,235 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"runtime"
+)
+
+// arenaSpan is the place of an item in the arenas of a shard, see WithOffHeapStorage
+//
+// It holds no pointers, so the garbage collector does not follow the items of the records.
+type arenaSpan struct {
+	region uint32 // index of the region plus one, 0 means the item is not in an arena
+	offset uint32
+	length uint32
+	sealed bool // the span holds the item sealed by WithMemoryEncryption
+}
+
+// arenas are the memory regions, mapped outside of the Go heap, where a shard keeps its items
+//
+// Items are appended to the current region. A region is released when all its items are gone,
+// and the regions are compacted when more than half of their bytes are not used anymore.
+// The arenas are used under the mutex of their shard.
+type arenas struct {
+	size    int
+	regions *arenaRegions
+	current int
+	used    int
+}
+
+// arenaRegions are the regions of arenas, they are released when the arenas are collected
+type arenaRegions struct {
+	list [][]byte
+	live []int
+}
+
+// WithOffHeapStorage keeps the items serialized in memory regions mapped outside of the Go heap
+//
+// This is meant for caches holding tens of millions of items: the garbage collector does not scan the items
+// anymore on each cycle. Each shard maps regions of arenaSize bytes, up to 4 GB (2 GB on 32-bit platforms), as it needs them.
+// The items are marshaled in JSON, []byte items are kept as they are, and each read unmarshals a copy of the item.
+// Items that cannot be marshaled or that are larger than arenaSize stay in the Go heap.
+// The keys stay in the Go heap too, see WithCompactKeys.
+//
+// On platforms that cannot map memory, the regions are allocated in the Go heap, where they are not scanned either.
+//
+// An arenaSize of 0 or less keeps the items in the Go heap.
+func (cache *Cache[T]) WithOffHeapStorage(arenaSize int64) *Cache[T] {
+	cache.configure(func(config *settings) {
+		config.arenaSize = int(min(max(arenaSize, 0), math.MaxUint32, math.MaxInt))
+	})
+	cache.rebuild()
+	return cache
+}
+
+// newArenas creates arenas of regions of the given size
+func newArenas(size int) *arenas {
+	arenas := &arenas{size: size, regions: &arenaRegions{}, current: -1}
+	runtime.AddCleanup(arenas, func(regions *arenaRegions) { regions.release() }, arenas.regions)
+	return arenas
+}
+
+// fits tells if data can be appended to the current region
+func (arenas *arenas) fits(length int) bool {
+	return arenas.current >= 0 && arenas.used+length <= arenas.size
+}
+
+// wasted tells if more than half of the bytes of the regions are not used anymore
+func (arenas *arenas) wasted() bool {
+	var mapped, live int
+	for index, region := range arenas.regions.list {
+		if region != nil {
+			mapped += len(region)
+			live += arenas.regions.live[index]
+		}
+	}
+	return mapped > arenas.size && live*2 < mapped
+}
+
+// put copies data at the end of the current region, mapping a new region if needed
+func (arenas *arenas) put(data []byte) (span arenaSpan, err error) {
+	if !arenas.fits(len(data)) {
+		if err = arenas.grow(); err != nil {
+			return
+		}
+	}
+	copy(arenas.regions.list[arenas.current][arenas.used:], data)
+	span = arenaSpan{region: uint32(arenas.current + 1), offset: uint32(arenas.used), length: uint32(len(data))}
+	arenas.used += len(data)
+	arenas.regions.live[arenas.current] += len(data)
+	return
+}
+
+// grow maps a new current region, in the place of a released one if any
+func (arenas *arenas) grow() error {
+	region, err := mapArena(arenas.size)
+	if err != nil {
+		return err
+	}
+	previous := arenas.current
+	arenas.current, arenas.used = -1, 0
+	for index, current := range arenas.regions.list {
+		if current == nil {
+			arenas.regions.list[index], arenas.regions.live[index] = region, 0
+			arenas.current = index
+			break
+		}
+	}
+	if arenas.current < 0 {
+		arenas.regions.list = append(arenas.regions.list, region)
+		arenas.regions.live = append(arenas.regions.live, 0)
+		arenas.current = len(arenas.regions.list) - 1
+	}
+	if previous >= 0 && arenas.regions.live[previous] == 0 {
+		arenas.regions.unmap(previous)
+	}
+	return nil
+}
+
+// get gets the bytes of a span, they must be copied before the mutex of the shard is released
+func (arenas *arenas) get(span arenaSpan) []byte {
+	return arenas.regions.list[span.region-1][span.offset : span.offset+span.length]
+}
+
+// free tells the bytes of a span are not used anymore, the region is released when it has no more items
+func (arenas *arenas) free(span arenaSpan) {
+	if span.region == 0 {
+		return
+	}
+	index := int(span.region - 1)
+	arenas.regions.live[index] -= int(span.length)
+	if arenas.regions.live[index] == 0 && index != arenas.current {
+		arenas.regions.unmap(index)
+	}
+}
+
+// reset releases all the regions
+func (arenas *arenas) reset() {
+	arenas.regions.release()
+	arenas.current, arenas.used = -1, 0
+}
+
+// unmap releases a region
+func (regions *arenaRegions) unmap(index int) {
+	if regions.list[index] != nil {
+		unmapArena(regions.list[index])
+		regions.list[index], regions.live[index] = nil, 0
+	}
+}
+
+// release releases all the regions
+func (regions *arenaRegions) release() {
+	for index := range regions.list {
+		regions.unmap(index)
+	}
+	regions.list, regions.live = nil, nil
+}
+
+// offload moves the item of a record to the arenas of the shard
+//
+// The caller must hold the mutex.
+func (shard *shard[T]) offload(entry record[T]) record[T] {
+	data, sealed := entry.sealed, entry.sealed != nil
+	if !sealed {
+		var err error
+
+		if raw, ok := any(entry.Item).([]byte); ok {
+			data = raw
+		} else if data, err = json.Marshal(entry.Item); err != nil {
+			return entry
+		}
+	}
+	if len(data) == 0 || len(data) > shard.arenas.size {
+		return entry
+	}
+	if !shard.arenas.fits(len(data)) && shard.arenas.wasted() {
+		shard.compact()
+	}
+	span, err := shard.arenas.put(data)
+	if err != nil {
+		return entry
+	}
+	var zero T
+	span.sealed = sealed
+	entry.Item, entry.sealed, entry.span = zero, nil, span
+	return entry
+}
+
+// unload gets the item of a record back from the arenas of the shard
+//
+// The caller must hold the mutex, at least for reading.
+func (shard *shard[T]) unload(entry record[T]) (record[T], bool) {
+	if entry.span.region == 0 {
+		return entry, true
+	}
+	data, span := shard.arenas.get(entry.span), entry.span
+	entry.span = arenaSpan{}
+	if span.sealed {
+		entry.sealed = bytes.Clone(data)
+		return entry, true
+	}
+	if raw, ok := any(&entry.Item).(*[]byte); ok {
+		*raw = bytes.Clone(data)
+		return entry, true
+	}
+	return entry, json.Unmarshal(data, &entry.Item) == nil
+}
+
+// compact moves the items of the shard to new arenas, releasing the bytes that are not used anymore
+//
+// The caller must hold the mutex.
+func (shard *shard[T]) compact() {
+	fresh := newArenas(shard.arenas.size)
+	moved := make(map[string]arenaSpan, len(shard.items))
+	for key, entry := range shard.items {
+		if entry.span.region == 0 {
+			continue
+		}
+		span, err := fresh.put(shard.arenas.get(entry.span))
+		if err != nil {
+			fresh.reset()
+			return
+		}
+		span.sealed = entry.span.sealed
+		moved[key] = span
+	}
+	for key, span := range moved {
+		entry := shard.items[key]
+		entry.span = span
+		shard.items[key] = entry
+	}
+	shard.arenas.reset()
+	shard.arenas = fresh
+}
@@ -0,0 +1,71 @@
+package cache_test
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/gildas/go-cache"
+	"github.com/google/uuid"
+)
+
+func (suite *CacheSuite) TestCanCacheStuffOffHeap() {
+	users := cache.New[Credentials]("test").WithShards(2).WithOffHeapStorage(1024)
+	for i := 0; i < 200; i++ {
+		suite.Require().NoError(users.Set(Credentials{Username: fmt.Sprintf("user-%d", i)}, fmt.Sprintf("key-%d", i)))
+	}
+	for i := 0; i < 200; i += 2 {
+		suite.Require().NoError(users.Delete(fmt.Sprintf("key-%d", i)))
+	}
+	for i := 1; i < 200; i += 2 {
+		suite.Require().NoError(users.Set(Credentials{Username: fmt.Sprintf("updated-%d", i)}, fmt.Sprintf("key-%d", i)))
+	}
+	for i := 0; i < 200; i++ {
+		value, err := users.Get(fmt.Sprintf("key-%d", i))
+		if i%2 == 0 {
+			suite.Assert().Error(err, "key-%d should have been deleted", i)
+			continue
+		}
+		suite.Require().NoError(err, "Failed to get key-%d: %+v", i, err)
+		suite.Assert().Equal(fmt.Sprintf("updated-%d", i), value.Username)
+	}
+	suite.Assert().Equal(100, users.ReadSnapshot().Len())
+}
+
+func (suite *CacheSuite) TestCanCacheBytesAndSecretsOffHeap() {
+	payloads := cache.NewBytes("test").WithOffHeapStorage(64 * 1024).WithMemoryEncryption()
+	suite.Require().NoError(payloads.Set([]byte("payload"), "payload"))
+	suite.Require().NoError(payloads.SetWithExpiration([]byte("short"), time.Millisecond, "short"))
+	value, err := payloads.Get("payload")
+	suite.Require().NoError(err, "Failed to get item: %+v", err)
+	suite.Assert().Equal([]byte("payload"), *value)
+
+	(*value)[0] = 'X'
+	value, _ = payloads.Get("payload")
+	suite.Assert().Equal([]byte("payload"), *value, "Get should return a copy of the item")
+
+	time.Sleep(5 * time.Millisecond)
+	_, err = payloads.Get("short")
+	suite.Assert().Error(err, "The item should have expired")
+}
+
+// BenchmarkOffHeapStorage measures the duration of a garbage collection with a million items in the cache
+//
+// Run with: go test -run=^$ -bench=OffHeapStorage
+func BenchmarkOffHeapStorage(b *testing.B) {
+	for _, arenaSize := range []int64{0, 64 * 1024 * 1024} {
+		b.Run(fmt.Sprintf("arena=%d", arenaSize), func(b *testing.B) {
+			users := cache.New[User]("bench").WithShards(16).WithCompactKeys(true).WithOffHeapStorage(arenaSize)
+			for i := 0; i < 1000000; i++ {
+				_ = users.Set(User{ID: uuid.New(), Name: fmt.Sprintf("user-%d", i)}, fmt.Sprintf("key-%d", i))
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				runtime.GC()
+			}
+			b.StopTimer()
+			runtime.KeepAlive(users)
+		})
+	}
+}
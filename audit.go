@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/gildas/go-logger"
+)
+
+// AuditOperation is the operation of an AuditEvent
+type AuditOperation string
+
+const (
+	// AuditSet is recorded when an item is set
+	AuditSet AuditOperation = "set"
+	// AuditDelete is recorded when an item is deleted
+	AuditDelete AuditOperation = "delete"
+	// AuditClear is recorded when the cache is cleared
+	AuditClear AuditOperation = "clear"
+)
+
+// AuditEvent is a mutation of the cache recorded by an Auditor
+type AuditEvent struct {
+	Time      time.Time      `json:"time"`
+	Cache     string         `json:"cache"`
+	Operation AuditOperation `json:"operation"`
+	Key       string         `json:"key,omitempty"`
+	Actor     string         `json:"actor,omitempty"`
+	Size      int            `json:"size,omitempty"`
+}
+
+// Auditor records the mutations of a cache
+type Auditor interface {
+	Audit(event AuditEvent)
+}
+
+// AuditorFunc is a function that implements Auditor
+type AuditorFunc func(event AuditEvent)
+
+// Audit records the event
+//
+// implements Auditor
+func (fn AuditorFunc) Audit(event AuditEvent) {
+	fn(event)
+}
+
+// LoggerAuditor is an Auditor that writes the events through a logger
+type LoggerAuditor struct {
+	Logger *logger.Logger
+}
+
+// Audit records the event
+//
+// implements Auditor
+func (auditor LoggerAuditor) Audit(event AuditEvent) {
+	auditor.Logger.Record("audit", event).Infof("Cache %s: %s %s by %s", event.Cache, event.Operation, event.Key, event.Actor)
+}
+
+type actorKey struct{}
+
+// WithActor gets a context that tells the auditor who performs the operations
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext gets the actor stored in the context by WithActor
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok {
+		return actor
+	}
+	return ""
+}
+
+// WithAuditor records every Set, Delete, and Clear of the cache with the given Auditor
+//
+// The actor of the operations is taken from the context given to SetContext, DeleteContext,
+// or ClearContext (see WithActor). The size is the size of the item marshaled in JSON.
+//
+// A nil auditor stops the recording.
+func (cache *Cache[T]) WithAuditor(auditor Auditor) *Cache[T] {
+	return cache.configure(func(config *settings) {
+		config.auditor = auditor
+	})
+}
+
+// audit sends an event to the auditor of the cache, size is the size of the item, see auditSize
+func (config *settings) audit(ctx context.Context, name string, operation AuditOperation, key string, size int) {
+	config.auditor.Audit(AuditEvent{
+		Time:      time.Now().UTC(),
+		Cache:     name,
+		Operation: operation,
+		Key:       key,
+		Actor:     ActorFromContext(ctx),
+		Size:      size,
+	})
+}
+
+// auditSize gets the size of an item marshaled in JSON, 0 if it cannot be marshaled
+//
+// An item set under several keys is marshaled once for all their events.
+func auditSize(item any) int {
+	if data, err := json.Marshal(item); err == nil {
+		return len(data)
+	}
+	return 0
+}
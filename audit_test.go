@@ -0,0 +1,81 @@
+package cache_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+func (suite *CacheSuite) TestCanAuditMutations() {
+	var mutex sync.Mutex
+	events := []cache.AuditEvent{}
+	names := cache.New[string]("test").WithAuditor(cache.AuditorFunc(func(event cache.AuditEvent) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		events = append(events, event)
+	}))
+	ctx := cache.WithActor(context.Background(), "joe")
+
+	err := names.SetContext(ctx, "secret", "key1")
+	suite.Require().NoError(err, "Failed to set item: %+v", err)
+	err = names.DeleteContext(ctx, "key1")
+	suite.Require().NoError(err, "Failed to delete item: %+v", err)
+	err = names.ClearContext(ctx, nil)
+	suite.Require().NoError(err, "Failed to clear cache: %+v", err)
+	_ = names.Set("public", "key2")
+
+	suite.Require().Len(events, 4)
+	suite.Assert().Equal(cache.AuditSet, events[0].Operation)
+	suite.Assert().Equal("test", events[0].Cache)
+	suite.Assert().Equal("key1", events[0].Key)
+	suite.Assert().Equal("joe", events[0].Actor)
+	suite.Assert().Equal(len(`"secret"`), events[0].Size)
+	suite.Assert().False(events[0].Time.IsZero())
+	suite.Assert().Equal(cache.AuditDelete, events[1].Operation)
+	suite.Assert().Equal("key1", events[1].Key)
+	suite.Assert().Equal(cache.AuditClear, events[2].Operation)
+	suite.Assert().Equal("joe", events[2].Actor)
+	suite.Assert().Equal("key2", events[3].Key)
+	suite.Assert().Empty(events[3].Actor, "Set without a context should not have an actor")
+}
+
+// countedItem counts how many times it is marshaled
+type countedItem struct {
+	marshals *atomic.Int32
+}
+
+func (item countedItem) MarshalJSON() ([]byte, error) {
+	item.marshals.Add(1)
+	return []byte(`"counted"`), nil
+}
+
+func (suite *CacheSuite) TestShouldMarshalAuditedItemOncePerSet() {
+	var sizes []int
+	items := cache.New[countedItem]("test").WithAuditor(cache.AuditorFunc(func(event cache.AuditEvent) {
+		sizes = append(sizes, event.Size)
+	}))
+	item := countedItem{marshals: &atomic.Int32{}}
+
+	suite.Require().NoError(items.Set(item, "key1", "key2", "key3"))
+	suite.Assert().Equal([]int{len(`"counted"`), len(`"counted"`), len(`"counted"`)}, sizes)
+	suite.Assert().Equal(int32(1), item.marshals.Load(), "The item should be marshaled once for all its keys")
+}
+
+func (suite *CacheSuite) TestCanDeleteItem() {
+	names := cache.New[string]("test", cache.CacheOptionPersistent)
+	defer func() { _ = names.Clear() }()
+	_ = names.Set("secret", "key1")
+
+	err := names.Delete("key1")
+	suite.Require().NoError(err, "Failed to delete item: %+v", err)
+	_, err = names.Get("key1")
+	suite.Assert().ErrorIs(err, errors.NotFound)
+	_, err = cache.New[string]("test", cache.CacheOptionPersistent).Get("key1")
+	suite.Assert().ErrorIs(err, errors.NotFound, "Item should have been deleted from the disk")
+
+	err = names.Delete("unknown")
+	suite.Assert().NoError(err, "Deleting an unknown key should not fail")
+}
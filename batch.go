@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/gildas/go-errors"
+)
+
+// BatchResult tells what happened to each key of a batch operation
+//
+// Skipped keys are the keys GetMany did not find, because they were missing or expired.
+type BatchResult struct {
+	Succeeded []string
+	Failed    map[string]error
+	Skipped   []string
+}
+
+// FailedKeys gets the keys that failed, sorted, so they can be retried
+func (result BatchResult) FailedKeys() []string {
+	keys := make([]string, 0, len(result.Failed))
+	for key := range result.Failed {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// Err gets the errors of the failed keys in an errors.MultiError, or nil if no key failed
+func (result BatchResult) Err() error {
+	var failures errors.MultiError
+	for _, key := range result.FailedKeys() {
+		failures.Append(result.Failed[key])
+	}
+	return failures.AsError()
+}
+
+// add records the outcome of a key
+func (result *BatchResult) add(key string, err error) {
+	switch {
+	case err == nil:
+		result.Succeeded = append(result.Succeeded, key)
+	case errors.Is(err, errors.NotFound):
+		result.Skipped = append(result.Skipped, key)
+	default:
+		if result.Failed == nil {
+			result.Failed = map[string]error{}
+		}
+		result.Failed[key] = err
+	}
+}
+
+// SetMany sets each item under its key
+//
+// The items are set in the order of their keys. They are also stored under the keys they declare, see Set.
+func (cache *Cache[T]) SetMany(items map[string]T) (result BatchResult) {
+	for _, key := range slices.Sorted(maps.Keys(items)) {
+		result.add(key, cache.Set(items[key], key))
+	}
+	return
+}
+
+// GetMany gets the items of the given keys
+//
+// The keys that are missing or expired are skipped.
+func (cache *Cache[T]) GetMany(keys ...string) (items map[string]T, result BatchResult) {
+	items = make(map[string]T, len(keys))
+	for _, key := range keys {
+		item, err := cache.Get(key)
+		if err == nil {
+			items[key] = *item
+		}
+		result.add(key, err)
+	}
+	return
+}
+
+// DeleteMany removes the items of the given keys
+func (cache *Cache[T]) DeleteMany(keys ...string) (result BatchResult) {
+	for _, key := range keys {
+		result.add(key, cache.Delete(key))
+	}
+	return
+}
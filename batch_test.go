@@ -0,0 +1,49 @@
+package cache_test
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+func (suite *CacheSuite) TestCanSetManyWithPartialFailure() {
+	items := cache.New[string]("test", cache.CacheOptionPersistent).WithStoreTransform(func(data []byte) ([]byte, error) {
+		if bytes.Contains(data, []byte(`"Key":"bad`)) {
+			return nil, errors.NotImplemented.WithStack()
+		}
+		return data, nil
+	})
+	defer func() { _ = items.Clear() }()
+
+	result := items.SetMany(map[string]string{"good": "1", "bad-1": "2", "bad-2": "3"})
+	suite.Assert().Equal([]string{"good"}, result.Succeeded)
+	suite.Assert().Equal([]string{"bad-1", "bad-2"}, result.FailedKeys())
+	suite.Assert().ErrorIs(result.Failed["bad-1"], cache.ErrKeyNotPersisted)
+	suite.Assert().ErrorIs(result.Err(), errors.NotImplemented)
+}
+
+func (suite *CacheSuite) TestCanGetMany() {
+	items := cache.New[string]("test")
+	_ = items.Set("1", "one")
+	_ = items.Set("2", "two")
+	_ = items.SetWithExpiration("3", time.Nanosecond, "expired")
+	time.Sleep(time.Millisecond)
+
+	values, result := items.GetMany("one", "two", "expired", "missing")
+	suite.Assert().Equal(map[string]string{"one": "1", "two": "2"}, values)
+	suite.Assert().Equal([]string{"one", "two"}, result.Succeeded)
+	suite.Assert().Equal([]string{"expired", "missing"}, result.Skipped)
+	suite.Assert().Empty(result.Failed)
+	suite.Assert().NoError(result.Err())
+}
+
+func (suite *CacheSuite) TestCanDeleteMany() {
+	items := cache.New[string]("test")
+	_ = items.Set("1", "one")
+	result := items.DeleteMany("one", "missing")
+	suite.Assert().Equal([]string{"one", "missing"}, result.Succeeded)
+	_, found := items.GetValue("one")
+	suite.Assert().False(found)
+}
@@ -0,0 +1,368 @@
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gildas/go-errors"
+)
+
+// blobsFolder is the subfolder of the cache folder where the blobs are stored
+const blobsFolder = "blobs"
+
+// blobSegmentSize is the size of the segments encrypted blobs are sealed in
+const blobSegmentSize = 64 * 1024
+
+// blobMagic starts the blob files that are not encrypted, and the encrypted ones written before the key commitment
+var blobMagic = []byte("GCBL\x01")
+
+// committedBlobMagic starts the encrypted blob files, see writeBlob
+var committedBlobMagic = []byte("GCBL\x02")
+
+// SetReader streams the content of reader to the disk under the given key
+//
+// Blobs are independent of the items stored with Set and are never loaded in memory,
+// so very large payloads can be cached. When the cache is encrypted, the blob is
+// encrypted in segments as it is written. Blobs larger than the size given to
+// WithChunkSize are split in chunks.
+//
+// The blob expires after ttl, a ttl of 0 means the blob does not expire.
+// The cache must be persistent, otherwise ErrNotPersistent is returned.
+func (cache *Cache[T]) SetReader(key string, reader io.Reader, ttl time.Duration) (err error) {
+	var expiration uint64
+
+	if len(key) == 0 {
+		return errors.ArgumentMissing.With("key")
+	}
+	config := cache.settings()
+	if !config.persistent {
+		return ErrNotPersistent.With(cache.Name)
+	}
+	if config.folderError != nil {
+		return config.folderError
+	}
+	if ttl > 0 {
+		expiration = uint64(time.Now().Add(ttl).UnixNano())
+	}
+	folder := filepath.Join(config.folder, blobsFolder)
+	if err = os.MkdirAll(folder, 0700); err != nil {
+		return
+	}
+	if config.chunkSize > 0 {
+		return config.writeChunks(folder, config.filename(key), bufio.NewReader(reader), blobManifest{ChunkSize: config.chunkSize, Expiration: expiration})
+	}
+	if err = config.writeAtomic(filepath.Join(folder, config.filename(key)), func(writer io.Writer) error {
+		return config.writeBlob(writer, reader, expiration, config.blobData(config.filename(key), 0))
+	}); err == nil {
+		err = os.RemoveAll(chunksFolder(folder, config.filename(key)))
+	}
+	return
+}
+
+// GetReader gets a reader on the blob stored under the given key with SetReader
+//
+// The caller must close the reader. If the blob is not found, is incomplete, or has expired,
+// an errors.NotFound is returned. When the cache is encrypted, the reader fails
+// if the blob was modified or truncated.
+func (cache *Cache[T]) GetReader(key string) (io.ReadCloser, error) {
+	return cache.GetRange(key, 0, -1)
+}
+
+// GetRange gets a reader on length bytes of the blob stored under the given key, starting at offset
+//
+// When the blob was split in chunks, only the chunks of the range are read.
+// A negative length reads until the end of the blob.
+func (cache *Cache[T]) GetRange(key string, offset, length int64) (io.ReadCloser, error) {
+	config := cache.settings()
+	if !config.persistent {
+		return nil, ErrNotPersistent.With(cache.Name)
+	}
+	folder := filepath.Join(config.folder, blobsFolder)
+	reader, manifest, err := config.openBlob(filepath.Join(folder, config.filename(key)), config.blobData(config.filename(key), 0))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, errors.NotFound.With("key", key)
+	} else if err != nil {
+		return nil, err
+	}
+	if manifest != nil {
+		if !manifest.Complete {
+			return nil, errors.NotFound.With("key", key)
+		}
+		reader = &chunksReader{
+			config:  config,
+			filekey: config.filename(key),
+			folder:  chunksFolder(folder, config.filename(key)),
+			index:   int(offset / manifest.ChunkSize),
+			count:   manifest.Chunks,
+			skip:    offset % manifest.ChunkSize,
+		}
+	} else if err = skip(reader, offset); err != nil {
+		_ = reader.Close()
+		return nil, err
+	}
+	if length >= 0 {
+		return &limitedReader{Reader: io.LimitReader(reader, length), Closer: reader}, nil
+	}
+	return reader, nil
+}
+
+// writeBlob writes the header and the content of a blob, encrypted if needed
+//
+// Like the records, an encrypted blob is committed to the key with a random salt, see encryptCommitted,
+// and its segments are bound to additional, see blobData.
+func (config *settings) writeBlob(writer io.Writer, reader io.Reader, expiration uint64, additional []byte) (err error) {
+	if config.encryptionKey == nil {
+		if _, err = writer.Write(binary.BigEndian.AppendUint64(append([]byte{}, blobMagic...), expiration)); err == nil {
+			_, err = io.Copy(writer, reader)
+		}
+		return
+	}
+	var gcm cipher.AEAD
+	var nonce, commitment []byte
+
+	salt := make([]byte, saltSize)
+	if _, err = io.ReadFull(config.randomReader(), salt); err != nil {
+		return
+	}
+	if err = config.encryptionKey.use(func(key []byte) (err error) {
+		gcm, nonce, commitment, err = deriveEntry(key, salt)
+		return
+	}); err != nil {
+		return
+	}
+	header := binary.BigEndian.AppendUint64(append([]byte{}, committedBlobMagic...), expiration)
+	if _, err = writer.Write(append(append(header, salt...), commitment...)); err != nil {
+		return
+	}
+	return sealSegments(writer, reader, gcm, nonce[:gcm.NonceSize()-4], additional)
+}
+
+// blobData gets the data the segments of the chunk at index of the blob filekey are bound to
+//
+// A blob stored in a single file is bound like its first chunk, as it is written as one.
+func (config *settings) blobData(filekey string, index int) []byte {
+	return binary.BigEndian.AppendUint32(append(config.additionalData(filekey), "\x00blob"...), uint32(index))
+}
+
+// openSegments reads the header of the segments of an encrypted blob named name, after its expiration
+//
+// It gets the cipher, the nonce prefix, and the additional data of the segments, and the size of the header.
+// The blobs written before the key commitment are read only with WithLegacyDecryption, their segments are not bound.
+func (config *settings) openSegments(reader io.Reader, magic []byte, name string, additional []byte) (gcm cipher.AEAD, prefix, bound []byte, size int, err error) {
+	if bytes.Equal(magic, blobMagic) {
+		if !config.legacyDecryption {
+			return nil, nil, nil, 0, ErrLegacyEncryption.With(name)
+		}
+		if gcm, err = config.encryptionKey.gcm(); err != nil {
+			return
+		}
+		prefix = make([]byte, gcm.NonceSize()-4)
+		if _, err = io.ReadFull(reader, prefix); err != nil {
+			return nil, nil, nil, 0, errors.ArgumentInvalid.With("blob", name)
+		}
+		return gcm, prefix, nil, len(prefix), nil
+	}
+	var nonce, commitment []byte
+
+	header := make([]byte, saltSize+commitmentSize)
+	if _, err = io.ReadFull(reader, header); err != nil {
+		return nil, nil, nil, 0, errors.ArgumentInvalid.With("blob", name)
+	}
+	if err = config.encryptionKey.use(func(key []byte) (err error) {
+		gcm, nonce, commitment, err = deriveEntry(key, header[:saltSize])
+		return
+	}); err != nil {
+		return
+	}
+	if !hmac.Equal(header[saltSize:], commitment) {
+		return nil, nil, nil, 0, errKeyMismatch
+	}
+	return gcm, nonce[:gcm.NonceSize()-4], additional, len(header), nil
+}
+
+// isBlob tells if magic starts a blob file
+func isBlob(magic []byte) bool {
+	return bytes.Equal(magic, blobMagic) || bytes.Equal(magic, committedBlobMagic)
+}
+
+// openBlob opens the blob file with the given name, its segments are bound to additional if it is encrypted
+//
+// If the file is the manifest of a chunked blob, the manifest is returned instead of a reader.
+// Expired blobs are removed and os.ErrNotExist is returned.
+func (config *settings) openBlob(filename string, additional []byte) (reader io.ReadCloser, manifest *blobManifest, err error) {
+	var file *os.File
+
+	if file, err = os.Open(filename); err != nil {
+		return
+	}
+	magic := make([]byte, len(blobMagic))
+	if _, err = io.ReadFull(file, magic); err != nil {
+		_ = file.Close()
+		return nil, nil, errors.ArgumentInvalid.With("blob", filepath.Base(filename))
+	}
+	if bytes.Equal(magic, manifestMagic) {
+		defer file.Close()
+		if manifest, err = readManifest(file); err != nil {
+			return nil, nil, err
+		}
+		if manifest.Expiration > 0 && time.Now().UnixNano() > int64(manifest.Expiration) {
+			_ = os.RemoveAll(chunksFolder(filepath.Dir(filename), filepath.Base(filename)))
+			_ = os.Remove(filename)
+			return nil, nil, os.ErrNotExist
+		}
+		return nil, manifest, nil
+	}
+	header := make([]byte, 8)
+	if _, err = io.ReadFull(file, header); err != nil || !isBlob(magic) {
+		_ = file.Close()
+		return nil, nil, errors.ArgumentInvalid.With("blob", filepath.Base(filename))
+	}
+	if expiration := binary.BigEndian.Uint64(header); expiration > 0 && time.Now().UnixNano() > int64(expiration) {
+		_ = file.Close()
+		_ = os.Remove(filename)
+		return nil, nil, os.ErrNotExist
+	}
+	if config.encryptionKey == nil {
+		return file, nil, nil
+	}
+	gcm, prefix, bound, _, err := config.openSegments(file, magic, filepath.Base(filename), additional)
+	if err != nil {
+		_ = file.Close()
+		return nil, nil, err
+	}
+	return &segmentReader{file: file, source: bufio.NewReaderSize(file, blobSegmentSize+gcm.Overhead()), gcm: gcm, prefix: prefix, additional: bound}, nil, nil
+}
+
+// skip skips offset bytes of the reader, seeking when the reader is a file
+func skip(reader io.Reader, offset int64) (err error) {
+	if offset <= 0 {
+		return nil
+	}
+	if file, ok := reader.(*os.File); ok {
+		_, err = file.Seek(offset, io.SeekCurrent)
+		return
+	}
+	if _, err = io.CopyN(io.Discard, reader, offset); err == io.EOF {
+		return nil
+	}
+	return
+}
+
+// limitedReader is a ReadCloser that reads a limited number of bytes
+type limitedReader struct {
+	io.Reader
+	io.Closer
+}
+
+// newGCM creates an AES-GCM cipher with the given key
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// segmentNonce gets the nonce of the segment at the given index
+func segmentNonce(prefix []byte, index uint32) []byte {
+	return binary.BigEndian.AppendUint32(append([]byte{}, prefix...), index)
+}
+
+// segmentData gets the additional data of a segment, which tells if it is the last one
+//
+// so a blob that is truncated on a segment boundary does not decrypt.
+func segmentData(additional []byte, last bool) []byte {
+	flag := byte(0)
+	if last {
+		flag = 1
+	}
+	return append(additional[:len(additional):len(additional)], flag)
+}
+
+// sealSegments encrypts the content of reader in segments of blobSegmentSize and writes them
+func sealSegments(writer io.Writer, reader io.Reader, gcm cipher.AEAD, prefix, additional []byte) error {
+	source := bufio.NewReaderSize(reader, blobSegmentSize)
+	segment := make([]byte, blobSegmentSize)
+	sealed := make([]byte, 0, blobSegmentSize+gcm.Overhead())
+	for index := uint32(0); ; index++ {
+		last, size, err := readSegment(source, segment)
+		if err != nil {
+			return err
+		}
+		if _, err = writer.Write(gcm.Seal(sealed[:0], segmentNonce(prefix, index), segment[:size], segmentData(additional, last))); err != nil {
+			return err
+		}
+		if last {
+			return nil
+		}
+	}
+}
+
+// readSegment reads a segment from source and tells if it is the last one
+func readSegment(source *bufio.Reader, segment []byte) (last bool, size int, err error) {
+	size, err = io.ReadFull(source, segment)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return true, size, nil
+	}
+	if err != nil {
+		return
+	}
+	if _, err = source.Peek(1); err == io.EOF {
+		return true, size, nil
+	}
+	return false, size, err
+}
+
+// segmentReader decrypts a blob sealed by sealSegments
+type segmentReader struct {
+	file       *os.File
+	source     *bufio.Reader
+	gcm        cipher.AEAD
+	prefix     []byte
+	additional []byte
+	index      uint32
+	plain      []byte
+	sealed     []byte
+	done       bool
+}
+
+// Read reads the decrypted content of the blob
+//
+// implements io.Reader
+func (reader *segmentReader) Read(data []byte) (int, error) {
+	for len(reader.plain) == 0 {
+		if reader.done {
+			return 0, io.EOF
+		}
+		if reader.sealed == nil {
+			reader.sealed = make([]byte, blobSegmentSize+reader.gcm.Overhead())
+		}
+		last, size, err := readSegment(reader.source, reader.sealed)
+		if err != nil {
+			return 0, err
+		}
+		if reader.plain, err = reader.gcm.Open(reader.sealed[:0], segmentNonce(reader.prefix, reader.index), reader.sealed[:size], segmentData(reader.additional, last)); err != nil {
+			return 0, err
+		}
+		reader.index++
+		reader.done = last
+	}
+	size := copy(data, reader.plain)
+	reader.plain = reader.plain[size:]
+	return size, nil
+}
+
+// Close closes the blob file
+//
+// implements io.Closer
+func (reader *segmentReader) Close() error {
+	return reader.file.Close()
+}
@@ -0,0 +1,67 @@
+package cache_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"time"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+func (suite *CacheSuite) TestCanStreamBlobs() {
+	blobs := cache.New[string]("test", cache.CacheOptionPersistent)
+	defer func() { _ = blobs.Clear() }()
+	payload := make([]byte, 200*1024+17)
+	_, _ = rand.Read(payload)
+
+	err := blobs.SetReader("artifact", bytes.NewReader(payload), 0)
+	suite.Require().NoError(err, "Failed to set blob: %+v", err)
+	reader, err := blobs.GetReader("artifact")
+	suite.Require().NoError(err, "Failed to get blob: %+v", err)
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	suite.Require().NoError(err, "Failed to read blob: %+v", err)
+	suite.Assert().Equal(payload, data)
+
+	_, err = blobs.GetReader("unknown")
+	suite.Assert().ErrorIs(err, errors.NotFound)
+}
+
+func (suite *CacheSuite) TestCanStreamEncryptedBlobs() {
+	blobs := cache.New[string]("test").WithEncryptionKey([]byte("@v3ry#S3cr3tK3y!"))
+	defer func() { _ = blobs.Clear() }()
+	for _, size := range []int{0, 10, 64 * 1024, 200*1024 + 17} {
+		payload := make([]byte, size)
+		_, _ = rand.Read(payload)
+
+		err := blobs.SetReader("artifact", bytes.NewReader(payload), time.Hour)
+		suite.Require().NoError(err, "Failed to set blob of %d bytes: %+v", size, err)
+		reader, err := blobs.GetReader("artifact")
+		suite.Require().NoError(err, "Failed to get blob of %d bytes: %+v", size, err)
+		data, err := io.ReadAll(reader)
+		_ = reader.Close()
+		suite.Require().NoError(err, "Failed to read blob of %d bytes: %+v", size, err)
+		suite.Assert().Equal(payload, data, "Blob of %d bytes is not the same", size)
+	}
+}
+
+func (suite *CacheSuite) TestShouldNotGetExpiredBlobs() {
+	blobs := cache.New[string]("test", cache.CacheOptionPersistent)
+	defer func() { _ = blobs.Clear() }()
+
+	err := blobs.SetReader("artifact", bytes.NewReader([]byte("hello")), 10*time.Millisecond)
+	suite.Require().NoError(err, "Failed to set blob: %+v", err)
+	time.Sleep(20 * time.Millisecond)
+	_, err = blobs.GetReader("artifact")
+	suite.Assert().ErrorIs(err, errors.NotFound)
+}
+
+func (suite *CacheSuite) TestShouldNotStreamBlobsInMemory() {
+	blobs := cache.New[string]("test")
+	err := blobs.SetReader("artifact", bytes.NewReader([]byte("hello")), 0)
+	suite.Assert().ErrorIs(err, cache.ErrNotPersistent)
+	_, err = blobs.GetReader("artifact")
+	suite.Assert().ErrorIs(err, cache.ErrNotPersistent)
+}
@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// Blob is a binary item with its content metadata
+//
+// When a Blob is loaded from the disk, its data is checked against its checksum.
+type Blob struct {
+	Data        []byte `json:"data"`
+	ContentType string `json:"contentType,omitempty"`
+	Filename    string `json:"filename,omitempty"`
+	Checksum    string `json:"checksum,omitempty"`
+}
+
+// NewBlob creates a new Blob with the checksum of its data
+//
+// If contentType is empty, it is guessed from the filename extension or from the data.
+func NewBlob(data []byte, contentType, filename string) Blob {
+	if len(contentType) == 0 {
+		if contentType = mime.TypeByExtension(filepath.Ext(filename)); len(contentType) == 0 {
+			contentType = http.DetectContentType(data)
+		}
+	}
+	return Blob{
+		Data:        data,
+		ContentType: contentType,
+		Filename:    filename,
+		Checksum:    checksumOf(data),
+	}
+}
+
+// Verify checks the data of the Blob against its checksum
+//
+// A Blob without a checksum is always valid.
+func (blob Blob) Verify() error {
+	if len(blob.Checksum) == 0 {
+		return nil
+	}
+	if actual := checksumOf(blob.Data); !strings.EqualFold(actual, blob.Checksum) {
+		return ErrChecksumMismatch.With(blob.Checksum, actual)
+	}
+	return nil
+}
+
+// CacheSize gets the memory used by the Blob
+//
+// implements Sizer
+func (blob Blob) CacheSize() int64 {
+	return int64(len(blob.Data) + len(blob.ContentType) + len(blob.Filename) + len(blob.Checksum))
+}
+
+// UnmarshalJSON decodes JSON and verifies the checksum
+//
+// implements json.Unmarshaler
+func (blob *Blob) UnmarshalJSON(payload []byte) (err error) {
+	type surrogate Blob
+	var inner surrogate
+
+	if err = json.Unmarshal(payload, &inner); err != nil {
+		return err
+	}
+	if err = Blob(inner).Verify(); err != nil {
+		return err
+	}
+	*blob = Blob(inner)
+	return nil
+}
+
+// checksumOf gets the SHA-256 checksum of data
+func checksumOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
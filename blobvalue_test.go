@@ -0,0 +1,50 @@
+package cache_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+
+	"github.com/gildas/go-cache"
+)
+
+func (suite *CacheSuite) TestCanPersistBlob() {
+	blobs := cache.New[cache.Blob]("test", cache.CacheOptionPersistent)
+	defer func() { _ = blobs.Clear() }()
+	blob := cache.NewBlob([]byte("Hello World"), "", "hello.txt")
+	suite.Assert().Contains(blob.ContentType, "text/plain")
+	suite.Assert().Equal("sha256:a591a6d40bf420404a011733cfb7b190d62c65bf0bcda32b57b277d9ad9f146e", blob.Checksum)
+
+	err := blobs.Set(blob, "hello")
+	suite.Require().NoError(err, "Failed to set blob: %+v", err)
+	other := cache.New[cache.Blob]("test", cache.CacheOptionPersistent)
+	cached, err := other.Get("hello")
+	suite.Require().NoError(err, "Failed to get persisted blob: %+v", err)
+	suite.Assert().Equal(blob, *cached)
+}
+
+func (suite *CacheSuite) TestShouldFailWithCorruptedBlob() {
+	blobs := cache.New[cache.Blob]("test", cache.CacheOptionPersistent)
+	defer func() { _ = blobs.Clear() }()
+
+	err := blobs.Set(cache.NewBlob([]byte("Hello World"), "text/plain", "hello.txt"), "hello")
+	suite.Require().NoError(err, "Failed to set blob: %+v", err)
+	folder, _ := os.UserCacheDir()
+	filename := filepath.Join(folder, "test", uuid.NewSHA1(uuid.Nil, []byte("hello")).String())
+	data, err := os.ReadFile(filename)
+	suite.Require().NoError(err, "Failed to read persisted file: %+v", err)
+	// "SGVsbG8gV29ybGQ=" is "Hello World" in base64, "SGVsbG8gV29ybGU=" is "Hello Worle"
+	// The CRC of the file is updated, so only the checksum of the Blob can detect the change
+	payload := bytes.Replace(data[13:], []byte("SGVsbG8gV29ybGQ="), []byte("SGVsbG8gV29ybGU="), 1)
+	binary.BigEndian.PutUint32(data[9:13], crc32.ChecksumIEEE(payload))
+	err = os.WriteFile(filename, append(data[:13], payload...), 0600)
+	suite.Require().NoError(err, "Failed to corrupt persisted file: %+v", err)
+
+	other := cache.New[cache.Blob]("test", cache.CacheOptionPersistent)
+	_, err = other.Get("hello")
+	suite.Require().ErrorIs(err, cache.ErrChecksumMismatch)
+}
@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"hash/fnv"
+	"io/fs"
+	"math"
+	"path/filepath"
+	"sync"
+)
+
+// bloomFilter tells if a file might have been persisted
+//
+// A bloomFilter never gives false negatives for the files it knows about,
+// it is built from the content of the cache folder the first time it is used.
+type bloomFilter struct {
+	mutex  sync.RWMutex
+	bits   []uint64
+	hashes uint64
+	built  bool
+}
+
+// newBloomFilter creates a new bloomFilter sized for the expected number of files and false positive rate
+func newBloomFilter(expectedItems int, falsePositiveRate float64) *bloomFilter {
+	expectedItems = max(expectedItems, 1)
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		falsePositiveRate = 0.01
+	}
+	size := math.Ceil(-float64(expectedItems) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2))
+	hashes := math.Max(1, math.Round(size/float64(expectedItems)*math.Ln2))
+	return &bloomFilter{
+		bits:   make([]uint64, (uint64(size)+63)/64),
+		hashes: uint64(hashes),
+	}
+}
+
+// empty creates a new empty bloomFilter with the same size as this one
+func (filter *bloomFilter) empty() *bloomFilter {
+	return &bloomFilter{bits: make([]uint64, len(filter.bits)), hashes: filter.hashes}
+}
+
+// add adds a filekey to the filter
+func (filter *bloomFilter) add(filekey string) {
+	filter.mutex.Lock()
+	defer filter.mutex.Unlock()
+	filter.set(filekey)
+}
+
+// mayContain tells if the filekey might have been persisted in the given folder
+//
+// If it returns false, the file was definitely not persisted.
+func (filter *bloomFilter) mayContain(folder, filekey string) bool {
+	filter.mutex.RLock()
+	if !filter.built {
+		filter.mutex.RUnlock()
+		filter.build(folder)
+		filter.mutex.RLock()
+	}
+	defer filter.mutex.RUnlock()
+	size := uint64(len(filter.bits)) * 64
+	h1, h2 := bloomHashes(filekey)
+	for i := uint64(0); i < filter.hashes; i++ {
+		bit := (h1 + i*h2) % size
+		if filter.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// build adds all the files of the given folder and of its fan-out subfolders to the filter
+func (filter *bloomFilter) build(folder string) {
+	filter.mutex.Lock()
+	defer filter.mutex.Unlock()
+	if filter.built {
+		return
+	}
+	_ = filepath.WalkDir(folder, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if relative, _ := filepath.Rel(folder, path); relative != "." && !fanOutFolder(relative) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		filter.set(entry.Name())
+		return nil
+	})
+	filter.built = true
+}
+
+// reset empties the filter, it will be rebuilt from the disk the next time it is used
+func (filter *bloomFilter) reset() {
+	filter.mutex.Lock()
+	defer filter.mutex.Unlock()
+	clear(filter.bits)
+	filter.built = false
+}
+
+// set sets the bits of the filekey
+//
+// The caller must hold the write lock.
+func (filter *bloomFilter) set(filekey string) {
+	size := uint64(len(filter.bits)) * 64
+	h1, h2 := bloomHashes(filekey)
+	for i := uint64(0); i < filter.hashes; i++ {
+		bit := (h1 + i*h2) % size
+		filter.bits[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+// bloomHashes computes the two hashes used for double hashing
+func bloomHashes(filekey string) (uint64, uint64) {
+	hasher := fnv.New64a()
+	_, _ = hasher.Write([]byte(filekey))
+	h1 := hasher.Sum64()
+	return h1, (h1 >> 33) | 1
+}
+
+// WithBloomFilter tells the cache to remember which keys were persisted
+//
+// On a persistent cache, a Get for a key that is neither in memory nor on the disk
+// no longer reads the disk, which is useful when the cache folder is on a slow or network filesystem.
+//
+// The filter is built from the cache folder the first time it is needed and rebuilt after Clear.
+// Files written in the cache folder by other processes after the filter was built are not seen.
+func (cache *Cache[T]) WithBloomFilter(expectedItems int, falsePositiveRate float64) *Cache[T] {
+	return cache.configure(func(config *settings) {
+		config.bloom = newBloomFilter(expectedItems, falsePositiveRate)
+	})
+}
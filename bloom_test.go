@@ -0,0 +1,32 @@
+package cache_test
+
+import (
+	"github.com/google/uuid"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+func (suite *CacheSuite) TestCanCacheStuffWithBloomFilter() {
+	firstCache := cache.New[User]("test", cache.CacheOptionPersistent)
+	defer func() { _ = firstCache.Clear() }()
+	user := User{ID: uuid.New(), Name: "Joe"}
+	err := firstCache.Set(user)
+	suite.Require().NoError(err, "Failed to set cached user: %+v", err)
+
+	secondCache := cache.New[User]("test", cache.CacheOptionPersistent).WithBloomFilter(100, 0.01)
+	cached, err := secondCache.Get(user.GetID().String())
+	suite.Require().NoError(err, "Failed to get cached user: %+v", err)
+	suite.Assert().Equal(user, *cached, "User and Cached User are different")
+
+	_, err = secondCache.Get(uuid.NewString())
+	suite.Assert().ErrorIs(err, errors.NotFound)
+
+	other := User{ID: uuid.New(), Name: "Jane"}
+	err = secondCache.Set(other)
+	suite.Require().NoError(err, "Failed to set cached user: %+v", err)
+	thirdCache := cache.New[User]("test", cache.CacheOptionPersistent).WithBloomFilter(100, 0.01)
+	cached, err = thirdCache.Get(other.GetID().String())
+	suite.Require().NoError(err, "Failed to get cached user: %+v", err)
+	suite.Assert().Equal(other, *cached, "User and Cached User are different")
+}
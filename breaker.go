@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// circuitBreaker stops calling a failing dependency for a while
+//
+// After threshold consecutive failures, the circuit opens and calls fail fast for coolDown.
+// Then one call is allowed through: if it succeeds the circuit closes, otherwise it opens again.
+type circuitBreaker struct {
+	mutex     sync.Mutex
+	threshold int
+	coolDown  time.Duration
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// allow tells if a call can go through
+func (breaker *circuitBreaker) allow() bool {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+	if breaker.failures < breaker.threshold {
+		return true
+	}
+	if breaker.probing || time.Now().Before(breaker.openUntil) {
+		return false
+	}
+	breaker.probing = true
+	return true
+}
+
+// report records the outcome of a call
+func (breaker *circuitBreaker) report(err error) {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+	breaker.probing = false
+	if err == nil {
+		breaker.failures = 0
+		return
+	}
+	breaker.failures++
+	if breaker.failures >= breaker.threshold {
+		breaker.openUntil = time.Now().Add(breaker.coolDown)
+	}
+}
+
+// WithCircuitBreaker protects the computations of GetOrCompute with a circuit breaker
+//
+// After threshold consecutive failed computations, GetOrCompute fails fast with ErrCircuitOpen
+// for coolDown instead of piling more calls onto a failing dependency.
+// After coolDown, one computation is allowed: if it succeeds, computations resume normally.
+//
+// A threshold of 0 removes the circuit breaker.
+func (cache *Cache[T]) WithCircuitBreaker(threshold int, coolDown time.Duration) *Cache[T] {
+	return cache.configure(func(config *settings) {
+		if threshold <= 0 {
+			config.breaker = nil
+			return
+		}
+		config.breaker = &circuitBreaker{threshold: threshold, coolDown: coolDown}
+	})
+}
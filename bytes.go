@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"sync"
+)
+
+// BytesCache is a cache of opaque payloads, like serialized messages or rendered pages
+//
+// The items of a Cache[[]byte] are persisted as they are, after a small header,
+// instead of being marshaled to base64 in JSON. Large payloads can also be streamed
+// to and from the disk with SetReader and GetReader.
+type BytesCache = Cache[[]byte]
+
+// NewBytes creates a new BytesCache
+func NewBytes(name string, option ...CacheOption) *BytesCache {
+	return New[[]byte](name, option...)
+}
+
+// rawMagic starts the persisted records of []byte items
+var rawMagic = []byte("GCRW\x01")
+
+// rawBuffers are the buffers the records of []byte items are encoded in
+var rawBuffers = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
+// encodeRaw writes the given record to buffer, its metadata in JSON followed by its item as is
+func encodeRaw(buffer *bytes.Buffer, entry record[[]byte]) error {
+	header := entry
+	header.Item = nil
+	metadata, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+	buffer.Grow(len(rawMagic) + 4 + len(metadata) + len(entry.Item))
+	buffer.Write(rawMagic)
+	buffer.Write(binary.BigEndian.AppendUint32(nil, uint32(len(metadata))))
+	buffer.Write(metadata)
+	buffer.Write(entry.Item)
+	return nil
+}
+
+// decodeRaw reads a record written by encodeRaw
+//
+// The item of the record shares the memory of data.
+func decodeRaw(data []byte) (entry record[[]byte], err error) {
+	data = data[len(rawMagic):]
+	if len(data) < 4 || uint64(len(data)-4) < uint64(binary.BigEndian.Uint32(data)) {
+		return entry, errCorruptedFile
+	}
+	length := binary.BigEndian.Uint32(data)
+	if err = json.Unmarshal(data[4:4+length], &entry); err != nil {
+		return entry, err
+	}
+	entry.Item = data[4+length:]
+	return entry, nil
+}
+
+// isRaw tells if data was written by encodeRaw
+func isRaw(data []byte) bool {
+	return bytes.HasPrefix(data, rawMagic)
+}
+
+// restoreRaw decodes a record written by encodeRaw into value
+//
+// When value is a record of []byte that needs no migration, the record is decoded
+// directly and nil is returned. Otherwise the JSON of the record is returned, for
+// the migrations and the other readers.
+func (config *settings) restoreRaw(data []byte, value any) ([]byte, error) {
+	entry, err := decodeRaw(data)
+	if err != nil {
+		return nil, err
+	}
+	if target, ok := value.(*record[[]byte]); ok && (len(config.migrations) == 0 || entry.Version >= config.schemaVersion) {
+		*target = entry
+		return nil, nil
+	}
+	return json.Marshal(entry)
+}
+
+// maxPooledBuffer is the capacity above which a buffer is not kept in rawBuffers
+const maxPooledBuffer = 1024 * 1024
+
+// releaseBuffer zeroes a buffer and gives it back to rawBuffers
+//
+// The buffers hold the items in clear, they are zeroed so the pool does not keep plaintext around.
+func releaseBuffer(buffer *bytes.Buffer) {
+	buffer.Reset()
+	content := buffer.Bytes()
+	clear(content[:cap(content)])
+	if buffer.Cap() <= maxPooledBuffer {
+		rawBuffers.Put(buffer)
+	}
+}
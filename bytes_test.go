@@ -0,0 +1,63 @@
+package cache_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/gildas/go-cache"
+	"github.com/google/uuid"
+)
+
+func (suite *CacheSuite) TestCanPersistBytesAsIs() {
+	payloads := cache.NewBytes("test", cache.CacheOptionPersistent)
+	defer func() { _ = payloads.Clear() }()
+	payload := []byte("\x00\x01 opaque payload \xff")
+
+	err := payloads.Set(payload, "message")
+	suite.Require().NoError(err, "Failed to set payload: %+v", err)
+	folder, _ := os.UserCacheDir()
+	data, err := os.ReadFile(filepath.Join(folder, "test", uuid.NewSHA1(uuid.Nil, []byte("message")).String()))
+	suite.Require().NoError(err, "Failed to read persisted file: %+v", err)
+	suite.Assert().True(bytes.HasSuffix(data, payload), "The payload should be persisted as is")
+
+	reopened := cache.NewBytes("test", cache.CacheOptionPersistent)
+	value, err := reopened.Get("message")
+	suite.Require().NoError(err, "Failed to get payload: %+v", err)
+	suite.Assert().Equal(payload, *value)
+
+	var exported []byte
+	err = reopened.IteratePersisted(func(key string, reader io.Reader) (err error) {
+		exported, err = io.ReadAll(reader)
+		return
+	})
+	suite.Require().NoError(err, "Failed to iterate: %+v", err)
+	suite.Assert().JSONEq(`"AAEgb3BhcXVlIHBheWxvYWQg/w=="`, string(exported), "The JSON of the payload should be base64")
+}
+
+func (suite *CacheSuite) TestCanReadBytesPersistedInJSON() {
+	payloads := cache.NewBytes("test", cache.CacheOptionPersistent)
+	defer func() { _ = payloads.Clear() }()
+	folder, _ := os.UserCacheDir()
+	suite.Require().NoError(os.MkdirAll(filepath.Join(folder, "test"), 0700))
+	err := os.WriteFile(filepath.Join(folder, "test", uuid.NewSHA1(uuid.Nil, []byte("legacy")).String()), []byte(`{"Key":"legacy","Item":"SGVsbG8=","Expiration":0}`), 0600)
+	suite.Require().NoError(err, "Failed to write legacy file: %+v", err)
+
+	value, err := payloads.Get("legacy")
+	suite.Require().NoError(err, "Failed to get legacy payload: %+v", err)
+	suite.Assert().Equal([]byte("Hello"), *value)
+}
+
+func (suite *CacheSuite) TestCanPersistEncryptedBytes() {
+	payloads := cache.NewBytes("test").WithEncryptionKey([]byte("@v3ry#S3cr3tK3y!"))
+	defer func() { _ = payloads.Clear() }()
+	payload := bytes.Repeat([]byte("payload"), 1000)
+
+	err := payloads.Set(payload, "message")
+	suite.Require().NoError(err, "Failed to set payload: %+v", err)
+	reopened := cache.NewBytes("test").WithEncryptionKey([]byte("@v3ry#S3cr3tK3y!"))
+	value, err := reopened.Get("message")
+	suite.Require().NoError(err, "Failed to get payload: %+v", err)
+	suite.Assert().Equal(payload, *value)
+}
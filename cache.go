@@ -1,29 +1,121 @@
 package cache
 
 import (
+	"context"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
-	"encoding/json"
 	"io"
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/gildas/go-core"
 	"github.com/gildas/go-errors"
-	"github.com/google/uuid"
 )
 
 // Cache is a cache
+//
+// The configuration of a Cache (expiration, encryption key, ...) can be changed
+// at any time, even while other goroutines are using the cache.
+// Each operation works with the configuration that was current when it started.
 type Cache[T interface{}] struct {
-	Name          string
-	Items         sync.Map
-	Expiration    time.Duration
-	persistent    bool
-	folder        string
-	encryptionKey []byte
+	Name string
+	// Expiration is the default expiration of the items
+	//
+	// Deprecated: Use WithExpiration to change it. The field is kept up to date with the configuration,
+	// but setting it has no effect, as the configuration can change while the cache is in use.
+	Expiration time.Duration
+	// Items are the items in memory
+	//
+	// Deprecated: Use Get, Delete, ReadSnapshot, or Keys, see ItemsMap.
+	Items       ItemsMap[T]
+	items       atomic.Pointer[store[T]]
+	sets        sync.Map
+	dependents  sync.Map
+	tenants     sync.Map
+	flights     flightGroup[T]
+	stats       statistics
+	epoch       atomic.Uint64
+	immutables  atomic.Bool
+	immutable   sync.RWMutex // held by SetImmutable while it checks and stores its keys, and shared by the other Set calls
+	leases      leaseTable
+	waiters     waiters
+	snapshot    atomic.Pointer[Snapshot[T]]
+	config      atomic.Pointer[settings]
+	configMutex sync.Mutex
+	swapMutex   sync.RWMutex // held by rebuild while it replaces the store, see write
+}
+
+// settings contains the configuration of a Cache
+//
+// settings are never modified once stored in a Cache, they are replaced.
+type settings struct {
+	name               string
+	expiration         time.Duration
+	persistent         bool
+	folder             string
+	tenant             string
+	tenantKey          string
+	encryptionKey      *secret
+	legacyDecryption   bool
+	signingKey         *secret
+	capacity           int
+	newPolicy          func(capacity int) policy
+	shards             int
+	bloom              *bloomFilter
+	panicHandler       func(key string, recovered any, stack []byte)
+	breaker            *circuitBreaker
+	maxStale           time.Duration
+	tracer             *accessTracer
+	durability         Durability
+	auditor            Auditor
+	explicitKeysOnly   bool
+	validator          func(item any) error
+	storeTransform     func(data []byte) ([]byte, error)
+	compressor         *compressor
+	sealer             *sealer
+	sealerError        error
+	loadTransform      func(data []byte) ([]byte, error)
+	maxMemory          int64
+	chunkSize          int64
+	mmapThreshold      int64
+	loader             any
+	prefetcher         func(missedKey string) []string
+	coalescer          *coalescer
+	janitor            *janitor
+	expirationEngine   ExpirationEngine
+	expirationMode     ExpirationMode
+	onExpire           any
+	onChange           any
+	ioLimiter          ioLimiter
+	writeBehind        *writeBehind
+	errorHandler       func(operation string, key string, err error)
+	recorder           Recorder
+	topKeys            *topKeys
+	priorities         *priorities
+	random             io.Reader
+	compactKeys        bool
+	arenaSize          int
+	spiller            *spiller
+	folderError        error
+	keyIndex           *keyIndex
+	profilerLabels     bool
+	quarantine         bool
+	corrupted          *atomic.Uint64
+	touchOnGet         time.Duration
+	maxLifetime        time.Duration
+	rejectNilID        bool
+	keyNamespaces      bool
+	detectCollisions   bool
+	encryptedFilenames bool
+	filenameKey        *secret
+	fanOut             bool
+	migrations         map[int]func(data []byte) ([]byte, error)
+	schemaVersion      int
+	highWatermark      float64
+	lowWatermark       float64
 }
 
 type CacheOption int
@@ -36,59 +128,164 @@ const (
 )
 
 type record[T interface{}] struct {
+	Key        string `json:",omitempty"`
 	Item       T
 	Expiration uint64
+	Generation uint64      `json:",omitempty"`
+	Immutable  bool        `json:",omitempty"`
+	Cost       int64       `json:",omitempty"`
+	Version    int         `json:",omitempty"`
+	Tier       StorageTier `json:",omitempty"`
+	Created    uint64      `json:",omitempty"`
+	size       int64
+	sealed     []byte
+	span       arenaSpan
+}
+
+// metadata gets the metadata of the record kept in clear in the header of its file
+func (r record[T]) metadata() frameMetadata {
+	return frameMetadata{expiration: r.Expiration, generation: r.Generation, immutable: r.Immutable}
+}
+
+// expired tells if the record has expired
+func (r record[T]) expired() bool {
+	return r.Expiration > 0 && time.Now().UnixNano() > int64(r.Expiration)
+}
+
+// stale tells if the record has expired for less than maxStale
+func (r record[T]) stale(maxStale time.Duration) bool {
+	return r.Expiration > 0 && maxStale > 0 && time.Now().UnixNano() <= int64(r.Expiration)+maxStale.Nanoseconds()
 }
 
 // New creates a new Cache
+//
+// If a default expiration was registered for T with RegisterExpiration, the cache uses it.
 func New[T any](name string, option ...CacheOption) *Cache[T] {
 	cache := &Cache[T]{Name: name}
+	cache.Items = ItemsMap[T]{cache: cache}
+	config := &settings{name: name, corrupted: &cache.stats.corrupted}
+	config.expiration, _ = RegisteredExpiration[T]()
 	for _, opt := range option {
 		switch opt {
 		case CacheOptionPersistent:
-			cache.persistent = true
-			cache.folder, _ = os.UserCacheDir()
-			cache.folder = filepath.Join(cache.folder, cache.Name)
+			config.persistent = true
+			config.useCacheFolder(cache.Name)
 		}
 	}
+	cache.config.Store(config)
+	cache.Expiration = config.expiration
 	return cache
 }
 
 // WithExpiration sets the expiration time for the cache
+//
+// It is safe to call this method while the cache is in use.
 func (cache *Cache[T]) WithExpiration(expiration time.Duration) *Cache[T] {
-	cache.Expiration = expiration
-	return cache
+	return cache.configure(func(config *settings) {
+		config.expiration = expiration
+	})
 }
 
 // WithEncryptionKey sets the encryption key for the cache
+//
+// It is safe to call this method while the cache is in use.
 func (cache *Cache[T]) WithEncryptionKey(key []byte) *Cache[T] {
-	cache.encryptionKey = key
-	cache.persistent = true
-	cache.folder, _ = os.UserCacheDir()
-	cache.folder = filepath.Join(cache.folder, cache.Name)
+	return cache.configure(func(config *settings) {
+		config.encryptionKey = newSecret(key)
+		config.persistent = true
+		config.useCacheFolder(cache.Name)
+		config.encryptFilenames(cache.Name)
+	})
+}
+
+// configure applies the given changes to a copy of the current settings and stores it
+func (cache *Cache[T]) configure(change func(config *settings)) *Cache[T] {
+	cache.configMutex.Lock()
+	defer cache.configMutex.Unlock()
+	config := *cache.settings()
+	change(&config)
+	cache.config.Store(&config)
+	cache.Expiration = config.expiration
 	return cache
 }
 
+// settings gets the current settings of the cache
+//
+// The returned settings must not be modified
+func (cache *Cache[T]) settings() *settings {
+	if config := cache.config.Load(); config != nil {
+		return config
+	}
+	return &settings{}
+}
+
 // Set sets an item in the cache
+//
+// The item is stored under the given keys and the keys it declares (see Cacheable),
+// or its ID and name if it implements core.Identifiable, core.StringIdentifiable, or core.Named.
+//
+// If the item implements Expirable or Expiring, its expiration is used instead of the cache's expiration.
 func (cache *Cache[T]) Set(item T, key ...string) (err error) {
-	return cache.SetWithExpiration(item, cache.Expiration, key...)
+	return cache.SetWithExpiration(item, expirationOf(item, cache.settings().expiration), key...)
 }
 
 // SetWithExpiration sets an item in the cache with a custom expiration
 func (cache *Cache[T]) SetWithExpiration(item T, expiration time.Duration, key ...string) (err error) {
+	config := cache.settings()
+	return cache.put(context.Background(), config, item, expiration, config.keysOf(item, key)...)
+}
+
+// SetContext sets an item in the cache
+//
+// The context is given to the auditor, see WithAuditor.
+func (cache *Cache[T]) SetContext(ctx context.Context, item T, key ...string) (err error) {
+	config := cache.settings()
+	return cache.put(ctx, config, item, expirationOf(item, config.expiration), config.keysOf(item, key)...)
+}
+
+// put stores an item in the cache with the given settings under the given keys
+//
+// The keys must already contain the keys derived from the item, see settings.keysOf.
+//
+// The item is stored under all the keys even if some cannot be persisted,
+// the keys that failed are returned as ErrKeyNotPersisted in an errors.MultiError.
+func (cache *Cache[T]) put(ctx context.Context, config *settings, item T, expiration time.Duration, key ...string) (err error) {
+	return cache.putWith(ctx, config, setDefault, item, expiration, 0, TierDefault, nil, key...)
+}
+
+// putWith stores an item in the cache like put, as told by the given mode, see SetImmutable and ForceSet
+//
+// The cost of the item is used by the cost-aware eviction, see SetWithCost.
+// The tier tells where the item is kept, see SetWithTier.
+// The dependencies are only given to the OnChange callback, see putWithDependencies.
+func (cache *Cache[T]) putWith(ctx context.Context, config *settings, mode setMode, item T, expiration time.Duration, cost int64, tier StorageTier, dependencies []string, key ...string) (err error) {
 	var r record[T]
+	start := time.Now()
 
-	if identifiable, ok := any(item).(core.Identifiable); ok {
-		key = append(key, identifiable.GetID().String())
+	ctx, unlabel := config.label(ctx, cache.Name, "set")
+	defer unlabel()
+	if err = config.checkKeys(item, key); err != nil {
+		return
+	}
+	if err = config.validate(item); err != nil {
+		return
 	}
-	if identifiable, ok := any(item).(core.StringIdentifiable); ok {
-		key = append(key, identifiable.GetID())
+	if err = cache.checkCollisions(config, item, key); err != nil {
+		return
 	}
-	if named, ok := any(item).(core.Named); ok {
-		key = append(key, named.GetName())
+	switch mode {
+	case setImmutable:
+		cache.immutable.Lock()
+		defer cache.immutable.Unlock()
+		cache.immutables.Store(true)
+	case setDefault:
+		cache.immutable.RLock()
+		defer cache.immutable.RUnlock()
 	}
-	if len(key) == 0 {
-		return errors.ArgumentMissing.With("key")
+	if mode != setForced {
+		if err = cache.checkImmutables(config, key); err != nil {
+			return
+		}
 	}
 
 	if expiration == 0 {
@@ -96,80 +293,300 @@ func (cache *Cache[T]) SetWithExpiration(item T, expiration time.Duration, key .
 	} else {
 		r = record[T]{Item: item, Expiration: uint64(time.Now().Add(expiration).UnixNano())}
 	}
+	r.Generation = cache.generation(config)
+	if config.maxLifetime > 0 {
+		r.Created = uint64(time.Now().UnixNano())
+	}
+	r.Immutable = mode == setImmutable
+	r.Cost = cost
+	r.Tier = tier
+	r.Version = config.schemaVersion
+	var failures errors.MultiError
+	var size int
+	if config.auditor != nil {
+		size = auditSize(item)
+	}
 	for _, k := range key {
-		cache.Items.Store(k, r)
-		if cache.persistent {
-			var data []byte
-
-			if data, err = json.Marshal(r); err == nil {
-				if err = os.MkdirAll(cache.folder, 0700); err == nil {
-					k = uuid.NewSHA1(uuid.Nil, []byte(k)).String()
-					filename := filepath.Join(cache.folder, k)
-					if len(cache.encryptionKey) > 0 {
-						if data, err = cache.encrypt(data); err != nil {
-							return
-						}
-					}
-					err = os.WriteFile(filename, data, 0600)
-				}
-			}
+		r.Key = k
+		if err := cache.keep(config, k, r); err != nil {
+			failures.Append(err)
+			continue
+		}
+		if err := config.save(k, r); err != nil {
+			failures.Append(keyNotPersisted(k, err))
+		}
+		if config.tracer != nil {
+			config.tracer.trace(k, TraceSet, false, start)
+		}
+		if config.auditor != nil {
+			config.audit(ctx, cache.Name, AuditSet, k, size)
 		}
+		if config.onChange != nil {
+			cache.changed(ctx, config, Change[T]{
+				Operation:    AuditSet,
+				Key:          k,
+				Item:         item,
+				Expiration:   expirationTime(r.Expiration),
+				Tier:         tier,
+				Immutable:    r.Immutable,
+				Dependencies: dependencies,
+			})
+		}
+		cache.waiters.notify(k)
+	}
+	return failures.AsError()
+}
+
+// save persists the record of a key if the cache is persistent, now or in the background
+//
+// See WithWriteCoalescing and WithWriteBehind. The key is added to the key index, see WithKeyIndex.
+func (config *settings) save(key string, entry any) error {
+	if config.keyIndex != nil && config.persistent && tierOf(entry) != TierMemoryOnly {
+		config.keyIndex.add(key)
+	}
+	switch {
+	case !config.persistent:
+		return nil
+	case tierOf(entry) == TierMemoryOnly:
+		return config.forget(key)
+	case config.coalescer != nil:
+		config.coalescer.write(config, key, entry)
+		return nil
+	case config.writeBehind != nil:
+		return config.writeBehind.write(config, key, entry)
+	default:
+		return config.persist(config.filekey(key), entry)
 	}
-	return
 }
 
 // Get gets an item from the cache
 func (cache *Cache[T]) Get(key string) (*T, error) {
-	item, found := cache.Items.Load(key)
-	if !found {
-		if cache.persistent {
-			filekey := uuid.NewSHA1(uuid.Nil, []byte(key)).String()
-			filename := filepath.Join(cache.folder, filekey)
-			if data, err := os.ReadFile(filename); err == nil {
-				var record record[T]
-
-				if len(cache.encryptionKey) > 0 {
-					if data, err = cache.decrypt(data); err != nil {
-						return nil, err
-					}
-				}
-				if err = json.Unmarshal(data, &record); err == nil {
-					cache.Items.Store(key, record)
-					return &record.Item, nil
-				}
+	config := cache.settings()
+	start := time.Now()
+	_, unlabel := config.label(context.Background(), cache.Name, "get")
+	defer unlabel()
+	record, found, err := cache.lookup(config, key)
+	if err != nil && !errors.Is(err, ErrCorrupted) {
+		return nil, err
+	}
+	hit := found && !record.expired()
+	if config.tracer != nil {
+		defer config.tracer.trace(key, TraceGet, hit, start)
+	}
+	if !hit {
+		cache.stats.misses.Add(1)
+		cache.prefetch(config, key)
+		if found && !record.stale(config.maxStale) && config.expirationMode != EagerExpiration {
+			cache.remove(config, key)
+		}
+		return nil, notFound(key, err)
+	}
+	cache.stats.hits.Add(1)
+	if config.topKeys != nil {
+		config.topKeys.hit(key)
+	}
+	if config.priorities != nil {
+		config.priorities.hit(key)
+	}
+	cache.storage().touch(key)
+	cache.extend(config, key, record)
+	return &record.Item, nil
+}
+
+// lookup finds the record of a key in memory or on the disk, even if it is expired
+//
+// Records found on the disk are loaded in memory.
+// Records of a previous generation are removed and not found, see BumpGeneration.
+func (cache *Cache[T]) lookup(config *settings, key string) (entry record[T], found bool, err error) {
+	if entry, found, err = cache.find(config, key); found && entry.Generation != cache.generation(config) {
+		cache.remove(config, key)
+		return record[T]{}, false, nil
+	}
+	if found && entry.Immutable {
+		cache.immutables.Store(true)
+	}
+	return
+}
+
+// find finds the record of a key in memory or on the disk, whatever its generation
+func (cache *Cache[T]) find(config *settings, key string) (entry record[T], found bool, err error) {
+	if entry, found = cache.storage().load(key); found {
+		return entry, true, nil
+	}
+	if config.persistent {
+		if config.coalescer != nil {
+			if pending, found := config.coalescer.get(config, config.filekey(key)); found {
+				entry = pending.(record[T])
+				_ = cache.keep(config, key, entry)
+				return entry, true, nil
+			}
+		}
+		if config.writeBehind != nil {
+			if pending, found := config.writeBehind.get(config, config.filekey(key)); found {
+				entry = pending.(record[T])
+				_ = cache.keep(config, key, entry)
+				return entry, true, nil
 			}
 		}
-		return nil, errors.NotFound.With("key", key)
+		if err = config.restore(config.filekey(key), &entry); err == nil {
+			_ = cache.keep(config, key, entry)
+			return entry, true, nil
+		} else if !errors.Is(err, os.ErrNotExist) || errors.Is(err, ErrCorrupted) {
+			return entry, false, err
+		}
+	}
+	return entry, false, nil
+}
+
+// remove removes a key from memory and from the disk
+func (cache *Cache[T]) remove(config *settings, key string) {
+	cache.write(func(storage *store[T]) { storage.delete(key) })
+	if config.persistent {
+		_ = config.erase(config.filekey(key))
+	}
+}
+
+// Delete removes an item from the cache, in memory and on the disk
+//
+// Deleting a key that is not in the cache is not an error.
+func (cache *Cache[T]) Delete(key string) error {
+	return cache.DeleteContext(context.Background(), key)
+}
+
+// DeleteContext removes an item from the cache, in memory and on the disk
+//
+// The items that depend on it are removed too, see SetWithDependencies.
+//
+// The context is given to the auditor, see WithAuditor.
+func (cache *Cache[T]) DeleteContext(ctx context.Context, key string) error {
+	config := cache.settings()
+	ctx, unlabel := config.label(ctx, cache.Name, "delete")
+	defer unlabel()
+	return cache.delete(ctx, config, key, map[string]bool{})
+}
+
+// delete removes an item and the items that depend on it (see SetWithDependencies)
+func (cache *Cache[T]) delete(ctx context.Context, config *settings, key string, deleted map[string]bool) error {
+	if deleted[key] {
+		return nil
+	}
+	deleted[key] = true
+	cache.write(func(storage *store[T]) { storage.delete(key) })
+	if config.auditor != nil {
+		config.audit(ctx, cache.Name, AuditDelete, key, 0)
+	}
+	if config.persistent {
+		if err := config.erase(config.filekey(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	if config.onChange != nil {
+		cache.changed(ctx, config, Change[T]{Operation: AuditDelete, Key: key})
 	}
-	record := item.(record[T])
-	if record.Expiration > 0 && time.Now().UnixNano() > int64(record.Expiration) {
-		cache.Items.Delete(key)
-		if cache.persistent {
-			filename := filepath.Join(cache.folder, key)
-			_ = os.Remove(filename)
+	dependents, err := cache.takeDependents(config, key)
+	for _, dependent := range dependents {
+		if err := cache.delete(ctx, config, dependent, deleted); err != nil {
+			return err
 		}
-		return nil, errors.NotFound.With("key", key)
 	}
-	return &record.Item, nil
+	return err
 }
 
 // Clear clears the cache
 func (cache *Cache[T]) Clear() error {
-	cache.Items.Range(func(key, value interface{}) bool {
-		cache.Items.Delete(key)
+	return cache.ClearContext(context.Background(), nil)
+}
+
+// ClearContext clears the cache
+//
+// The context is given to the auditor, see WithAuditor.
+// When the context is cancelled, the files that were not removed yet are left on the disk
+// and the context error is returned. Calling ClearContext again removes them.
+//
+// If progress is not nil, it is called after each file is removed with the number of removed files and the total.
+func (cache *Cache[T]) ClearContext(ctx context.Context, progress func(done, total int)) error {
+	config := cache.settings()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if config.auditor != nil {
+		config.audit(ctx, cache.Name, AuditClear, "", 0)
+	}
+	cache.write(func(storage *store[T]) { storage.clear() })
+	cache.tenants.Range(func(id, view interface{}) bool {
+		_ = view.(*Cache[T]).ClearContext(ctx, nil)
+		return true
+	})
+	if config.onChange != nil {
+		cache.changed(ctx, config, Change[T]{Operation: AuditClear})
+	}
+	cache.sets.Range(func(key, value interface{}) bool {
+		cache.sets.Delete(key)
+		return true
+	})
+	cache.dependents.Range(func(key, value interface{}) bool {
+		cache.dependents.Delete(key)
 		return true
 	})
-	if cache.persistent {
-		return os.RemoveAll(cache.folder)
+	if config.bloom != nil {
+		defer config.bloom.reset()
+	}
+	if config.keyIndex != nil {
+		defer config.keyIndex.reset()
+	}
+	if config.persistent {
+		if config.coalescer != nil {
+			config.coalescer.cancelAll(config.folder)
+		}
+		if config.writeBehind != nil {
+			config.writeBehind.cancelAll(config.folder)
+		}
+		err := removeFolder(ctx, config.folder, progress)
+		if generation := cache.generation(config); generation > 0 {
+			// Keep the generation, so the items that were not removed keep their validity
+			if perr := config.persistGeneration(generation); err == nil {
+				err = perr
+			}
+		}
+		return err
 	}
 	return nil
 }
 
+// removeFolder removes a folder and its files, reporting the progress after each file
+func removeFolder(ctx context.Context, folder string, progress func(done, total int)) (err error) {
+	var files []string
+
+	err = filepath.WalkDir(folder, func(path string, entry os.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() {
+			files = append(files, path)
+		}
+		return err
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	for done, file := range files {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		if err = os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		if progress != nil {
+			progress(done+1, len(files))
+		}
+	}
+	return os.RemoveAll(folder)
+}
+
 // encrypt encrypts data using AES
-func (cache *Cache[T]) encrypt(data []byte) (encrypted []byte, err error) {
+func encrypt(key, data []byte) (encrypted []byte, err error) {
 	var block cipher.Block
 
-	if block, err = aes.NewCipher(cache.encryptionKey); err == nil {
+	if block, err = aes.NewCipher(key); err == nil {
 		var gcm cipher.AEAD
 
 		if gcm, err = cipher.NewGCM(block); err == nil {
@@ -183,10 +600,10 @@ func (cache *Cache[T]) encrypt(data []byte) (encrypted []byte, err error) {
 }
 
 // decrypt decrypts data using AES
-func (cache *Cache[T]) decrypt(data []byte) (decrypted []byte, err error) {
+func decrypt(key, data []byte) (decrypted []byte, err error) {
 	var block cipher.Block
 
-	if block, err = aes.NewCipher(cache.encryptionKey); err == nil {
+	if block, err = aes.NewCipher(key); err == nil {
 		var gcm cipher.AEAD
 
 		if gcm, err = cipher.NewGCM(block); err == nil {
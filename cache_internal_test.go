@@ -1,30 +1,25 @@
 package cache
 
 import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"strings"
 	"testing"
+	"time"
+	"unsafe"
 
-	"github.com/google/uuid"
+	"github.com/gildas/go-errors"
 	"github.com/stretchr/testify/require"
 )
 
-type User struct {
-	ID   uuid.UUID `json:"id"`
-	Name string    `json:"name"`
-}
-
-// GetID gets the ID of the User
-//
-// implements core.Identifiable
-func (user User) GetID() uuid.UUID {
-	return user.ID
-}
-
 func TestCanEncryptData(t *testing.T) {
 	encryptionKey := []byte("@v3ry#S3cr3tK3y!")
-	cache := New[User]("test")
-	cache.encryptionKey = encryptionKey
-
-	encrypted, err := cache.encrypt([]byte("Hello, World!"))
+	encrypted, err := encrypt(encryptionKey, []byte("Hello, World!"))
 	require.NoError(t, err, "Failed to encrypt the data")
 	require.Greater(t, len(encrypted), 0, "The encrypted data is empty")
 	require.NotEqual(t, "Hello, World!", string(encrypted), "The data is not encrypted")
@@ -32,29 +27,391 @@ func TestCanEncryptData(t *testing.T) {
 
 func TestCanDecryptData(t *testing.T) {
 	encryptionKey := []byte("@v3ry#S3cr3tK3y!")
-	cache := New[User]("test")
-	cache.encryptionKey = encryptionKey
-
-	encrypted, err := cache.encrypt([]byte("Hello, World!"))
+	encrypted, err := encrypt(encryptionKey, []byte("Hello, World!"))
 	require.NoError(t, err, "Failed to encrypt the data")
 	require.Greater(t, len(encrypted), 0, "The encrypted data is empty")
 
-	decrypted, err := cache.decrypt(encrypted)
+	decrypted, err := decrypt(encryptionKey, encrypted)
 	require.NoError(t, err, "Failed to decrypt the data")
 	require.Equal(t, "Hello, World!", string(decrypted), "The data is not decrypted")
 }
 
 func TestShouldFailWithWrongEncryptedData(t *testing.T) {
 	encryptionKey := []byte("@v3ry#S3cr3tK3y!")
-	cache := New[User]("test")
-	cache.encryptionKey = encryptionKey
-
-	encrypted, err := cache.encrypt([]byte("Hello, World!"))
+	encrypted, err := encrypt(encryptionKey, []byte("Hello, World!"))
 	require.NoError(t, err, "Failed to encrypt the data")
 	require.Greater(t, len(encrypted), 0, "The encrypted data is empty")
 
 	// Shorten the data (corrupt it)
 	encrypted = encrypted[:10]
-	_, err = cache.decrypt(encrypted)
+	_, err = decrypt(encryptionKey, encrypted)
 	require.Error(t, err, "Decryption should have failed")
 }
+
+func TestCommittedEncryptionFailsWithWrongKey(t *testing.T) {
+	encrypted, err := encryptCommitted(rand.Reader, []byte("@v3ry#S3cr3tK3y!"), []byte("Hello, World!"), []byte("test"))
+	require.NoError(t, err, "Failed to encrypt the data")
+	require.True(t, isCommitted(encrypted))
+
+	decrypted, err := decryptCommitted([]byte("@v3ry#S3cr3tK3y!"), encrypted, []byte("test"))
+	require.NoError(t, err, "Failed to decrypt the data")
+	require.Equal(t, "Hello, World!", string(decrypted))
+
+	_, err = decryptCommitted([]byte("@n0th3r#S3cr3tK!"), encrypted, []byte("test"))
+	require.ErrorIs(t, err, errKeyMismatch)
+
+	_, err = decryptCommitted([]byte("@v3ry#S3cr3tK3y!"), encrypted, []byte("other"))
+	require.Error(t, err, "Decryption should fail with other additional data")
+}
+
+func TestCanDecryptRecordsEncryptedBeforeKeyCommitment(t *testing.T) {
+	config := &settings{name: "test", encryptionKey: newSecret([]byte("@v3ry#S3cr3tK3y!"))}
+	encrypted, err := encrypt([]byte("@v3ry#S3cr3tK3y!"), []byte("Hello, World!"))
+	require.NoError(t, err, "Failed to encrypt the data")
+
+	_, err = config.decryptRecord("key", encrypted)
+	require.ErrorIs(t, err, ErrLegacyEncryption, "Records without key commitment should need WithLegacyDecryption")
+
+	config.legacyDecryption = true
+	decrypted, err := config.decryptRecord("key", encrypted)
+	require.NoError(t, err, "Failed to decrypt the data")
+	require.Equal(t, "Hello, World!", string(decrypted))
+}
+
+func TestBloomFilterHasNoFalseNegatives(t *testing.T) {
+	filter := newBloomFilter(1000, 0.01)
+	filter.built = true
+	for i := 0; i < 1000; i++ {
+		filter.add(filekey(fmt.Sprintf("key-%d", i)))
+	}
+	for i := 0; i < 1000; i++ {
+		require.True(t, filter.mayContain("", filekey(fmt.Sprintf("key-%d", i))), "Key %d should be in the filter", i)
+	}
+	falsePositives := 0
+	for i := 0; i < 1000; i++ {
+		if filter.mayContain("", filekey(fmt.Sprintf("other-%d", i))) {
+			falsePositives++
+		}
+	}
+	require.Less(t, falsePositives, 50, "Too many false positives")
+}
+
+func TestCanEstimateSize(t *testing.T) {
+	type node struct {
+		Name string
+		Next *node
+	}
+	require.Equal(t, int64(8), sizeOf(int64(1)))
+	require.Equal(t, int64(16+5), sizeOf("hello"), "A string should count its header and its bytes")
+	require.Equal(t, int64(24+3*8), sizeOf([]int64{1, 2, 3}))
+
+	first := &node{Name: "first"}
+	first.Next = &node{Name: "second", Next: first}
+	require.Equal(t, int64(8+24+5+24+6), sizeOf(first), "Cyclic values should be counted once")
+}
+
+func TestShouldFailWithTruncatedBlob(t *testing.T) {
+	gcm, err := newGCM([]byte("@v3ry#S3cr3tK3y!"))
+	require.NoError(t, err, "Failed to create the cipher")
+	prefix := make([]byte, gcm.NonceSize()-4)
+	var sealed bytes.Buffer
+	err = sealSegments(&sealed, bytes.NewReader(make([]byte, 2*blobSegmentSize)), gcm, prefix, nil)
+	require.NoError(t, err, "Failed to seal the blob")
+
+	// Drop the last segment
+	truncated := sealed.Bytes()[:blobSegmentSize+gcm.Overhead()]
+	reader := &segmentReader{source: bufio.NewReader(bytes.NewReader(truncated)), gcm: gcm, prefix: prefix}
+	_, err = io.ReadAll(reader)
+	require.Error(t, err, "Reading a truncated blob should fail")
+}
+
+func TestExpiryHeapPopsEarliestFirst(t *testing.T) {
+	var expirations expiryHeap
+	for i, at := range []int64{50, 10, 40, 30, 20, 60} {
+		expirations.set(fmt.Sprintf("key-%d", i), at)
+	}
+	expirations.set("key-5", 5)  // moves up
+	expirations.set("key-1", 45) // moves down
+	expirations.remove("key-2")
+	require.Len(t, expirations.within(30), 3)
+
+	var popped []string
+	for {
+		next, ok := expirations.pop(45)
+		if !ok {
+			break
+		}
+		popped = append(popped, next.key)
+	}
+	require.Equal(t, []string{"key-5", "key-4", "key-3", "key-1"}, popped)
+	require.Len(t, expirations.entries, 1)
+	require.Equal(t, 0, expirations.index["key-0"])
+}
+
+func TestTimingWheelExpiresElapsedSlots(t *testing.T) {
+	start := time.Unix(1000, 0)
+	wheel := newTimingWheel(time.Second, 8, start)
+	at := func(seconds float64) int64 {
+		return start.Add(time.Duration(seconds * float64(time.Second))).UnixNano()
+	}
+	wheel.set("past", at(-5))
+	wheel.set("soon", at(1.5))
+	wheel.set("later", at(3))
+	wheel.set("next-round", at(10))
+	wheel.set("cancelled", at(2))
+	wheel.remove("cancelled")
+	wheel.set("moved", at(2))
+	wheel.set("moved", at(20))
+
+	require.Len(t, wheel.within(at(3)), 3)
+	require.Equal(t, []expiry{{key: "past", at: at(-5)}}, wheel.expire(at(1.9)), "soon's slot has not elapsed yet")
+	require.ElementsMatch(t, []expiry{{key: "soon", at: at(1.5)}, {key: "later", at: at(3)}}, wheel.expire(at(3)))
+	require.Empty(t, wheel.expire(at(9)), "next-round shares a slot with later but is not expired")
+	require.ElementsMatch(t, []expiry{{key: "next-round", at: at(10)}, {key: "moved", at: at(20)}}, wheel.expire(at(30)))
+	require.Empty(t, wheel.index)
+}
+
+func TestIOLimiterBoundsConcurrency(t *testing.T) {
+	config := &settings{ioLimiter: make(ioLimiter, 2)}
+	first, second := config.acquireIO(), config.acquireIO()
+	acquired := make(chan struct{})
+	go func() {
+		defer config.acquireIO()()
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		require.Fail(t, "The third operation should wait for a slot")
+	case <-time.After(20 * time.Millisecond):
+	}
+	first()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		require.Fail(t, "The third operation should get the released slot")
+	}
+	second()
+}
+
+func TestTopKeysKeepsMostReadKeys(t *testing.T) {
+	top := &topKeys{size: 1, counters: map[string]uint64{}}
+	for i := 0; i < 100; i++ {
+		top.hit("hot")
+		top.hit(fmt.Sprintf("cold-%d", i))
+	}
+	require.Len(t, top.counters, topKeysFactor)
+	require.Equal(t, []KeyHits{{Key: "hot", Hits: 100}}, top.top())
+}
+
+func TestPoliciesIgnoreTouchesOfUnknownKeys(t *testing.T) {
+	for name, policy := range map[string]policy{"lru": newLRU(), "slru": newSLRU(1), "clock": newClock(), "greedydual": newGreedyDual()} {
+		policy.touch("ghost")
+		require.Equal(t, 0, policy.len(), "%s should not insert a key that is only read", name)
+		policy.add("key")
+		policy.touch("key")
+		require.Equal(t, 1, policy.len(), "%s should know the added key", name)
+	}
+}
+
+func TestMemoryEncryptionSealsItemsInShards(t *testing.T) {
+	secrets := New[string]("test").WithMemoryEncryption()
+	require.NoError(t, secrets.Set("p@ssw0rd", "password"))
+	for _, shard := range secrets.storage().shards {
+		for _, entry := range shard.items {
+			require.Empty(t, entry.Item, "The item should not be kept in clear")
+			require.NotContains(t, string(entry.sealed), "p@ssw0rd")
+		}
+	}
+	value, err := secrets.Get("password")
+	require.NoError(t, err, "Failed to get the item")
+	require.Equal(t, "p@ssw0rd", *value)
+}
+
+func TestSealerFailsWithoutRandom(t *testing.T) {
+	_, err := newSealer(bytes.NewReader(nil))
+	require.ErrorIs(t, err, ErrNoMemoryKey, "The sealer should not use a predictable key")
+	sealer, err := newSealer(bytes.NewReader(make([]byte, 32)))
+	require.NoError(t, err)
+	_, err = sealer.seal([]byte("p@ssw0rd"))
+	require.Error(t, err, "The sealer should not use a predictable nonce")
+}
+
+func TestCloseZeroesKeyMaterial(t *testing.T) {
+	secrets := New[string]("test-secrets").WithEncryptionKey([]byte("@v3ry#S3cr3tK3y!")).WithEncryptedFilenames().WithMemoryEncryption()
+	config := secrets.settings()
+	keys := [][]byte{config.encryptionKey.key, config.filenameKey.key, config.sealer.key.key}
+	require.NoError(t, secrets.Close())
+	for _, key := range keys {
+		require.Equal(t, make([]byte, len(key)), key, "The key should be zeroed")
+	}
+	_, err := config.encryptionKey.gcm()
+	require.ErrorIs(t, err, ErrKeyDestroyed)
+}
+
+func TestReleaseBufferZeroesContent(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	buffer.WriteString("p@ssw0rd")
+	content := buffer.Bytes()
+	releaseBuffer(buffer)
+	require.Equal(t, make([]byte, len(content)), content, "The buffer should be zeroed")
+}
+
+func TestCompactKeysShareOneCopyOfEachKey(t *testing.T) {
+	items := New[string]("test").WithCompactKeys(true).WithShards(1).WithCapacity(10)
+	line := "key-1 " + strings.Repeat("x", 1024)
+	require.NoError(t, items.Set("value", line[:5]))
+	first := items.storage().shards[0].items["key-1"].Key
+	require.NotSame(t, unsafe.StringData(line), unsafe.StringData(first), "The key should not keep the line alive")
+
+	require.NoError(t, items.Set("other value", strings.Clone("key-1")))
+	require.Same(t, unsafe.StringData(first), unsafe.StringData(items.storage().shards[0].items["key-1"].Key), "The key should be reused")
+}
+
+func TestOffHeapStorageKeepsItemsInArenas(t *testing.T) {
+	items := New[string]("test").WithShards(1).WithOffHeapStorage(64)
+	for i := range 20 {
+		require.NoError(t, items.Set(fmt.Sprintf("value-%02d", i), fmt.Sprintf("key-%02d", i)))
+	}
+	shard := items.storage().shards[0]
+	for _, entry := range shard.items {
+		require.Empty(t, entry.Item, "The item should not be kept in the Go heap")
+		require.NotZero(t, entry.span.region, "The item should be kept in an arena")
+	}
+	mapped := func() (count int) {
+		for _, region := range shard.arenas.regions.list {
+			if region != nil {
+				count++
+			}
+		}
+		return
+	}
+	require.Greater(t, mapped(), 1)
+	for i := range 20 {
+		require.NoError(t, items.Delete(fmt.Sprintf("key-%02d", i)))
+	}
+	require.LessOrEqual(t, mapped(), 1, "The regions without items should be released")
+
+	require.NoError(t, items.SetWithExpiration("expiring", time.Millisecond, "expiring"))
+	time.Sleep(2 * time.Millisecond)
+	expired := items.storage().expire(time.Now().UnixNano())
+	require.Len(t, expired, 1)
+	require.Equal(t, "expiring", expired[0].Item)
+}
+
+func TestSpillDropsTheShareAboveTheThreshold(t *testing.T) {
+	items := New[string]("test", CacheOptionPersistent).WithShards(1).WithSpillToDisk(1000, time.Hour)
+	defer items.Close()
+	for i := range 20 {
+		require.NoError(t, items.Set("value", fmt.Sprintf("key-%02d", i)))
+	}
+	_, _ = items.Get("key-00")
+	spiller := items.settings().spiller
+	spiller.measure = func() (uint64, uint64) { return 4000, 1 }
+	items.spill(spiller)
+	require.Len(t, items.storage().shards[0].items, 5)
+	require.Contains(t, items.storage().shards[0].items, "key-00", "The recently read key should stay in memory")
+
+	items.spill(spiller)
+	require.Len(t, items.storage().shards[0].items, 5, "Items should be dropped once per garbage collection")
+	require.Equal(t, uint64(15), items.Stats().Spilled)
+
+	spiller.measure = func() (uint64, uint64) { return 500, 2 }
+	items.spill(spiller)
+	require.Len(t, items.storage().shards[0].items, 5, "Items should not be dropped below the threshold")
+}
+
+func TestFrameKeepsMetadataInClear(t *testing.T) {
+	framed := frame([]byte("payload"), frameMetadata{expiration: 42, generation: 3, immutable: true})
+	metadata, known := unframeMetadata(framed)
+	require.True(t, known)
+	require.Equal(t, frameMetadata{expiration: 42, generation: 3, immutable: true}, metadata)
+	payload, err := unframe(framed)
+	require.NoError(t, err)
+	require.Equal(t, []byte("payload"), payload)
+
+	framed[15] ^= 0xFF
+	_, err = unframe(framed)
+	require.ErrorIs(t, err, errCorruptedFile, "The checksum should cover the metadata")
+
+	legacy := append(append([]byte("GCIT\x01"), 0, 0, 0, 7), binary.BigEndian.AppendUint32(nil, crc32.ChecksumIEEE([]byte("payload")))...)
+	legacy = append(legacy, "payload"...)
+	payload, err = unframe(legacy)
+	require.NoError(t, err, "Items persisted without metadata should still be read")
+	require.Equal(t, []byte("payload"), payload)
+	_, known = unframeMetadata(legacy)
+	require.False(t, known)
+
+	flagless := append([]byte("GCIT\x02"), 0, 0, 0, 7)
+	flagless = binary.BigEndian.AppendUint32(flagless, crc32.ChecksumIEEE(append(make([]byte, 16), "payload"...)))
+	flagless = append(append(flagless, make([]byte, 16)...), "payload"...)
+	payload, err = unframe(flagless)
+	require.NoError(t, err, "Items persisted without flags should still be read")
+	require.Equal(t, []byte("payload"), payload)
+	_, known = unframeMetadata(flagless)
+	require.False(t, known, "The flags of items persisted without flags are not known")
+}
+
+func TestGetAllForgetsTheEmptyMultiValueEntries(t *testing.T) {
+	tags := New[string]("test")
+	_, err := tags.GetAll("missing")
+	require.Error(t, err)
+	_, found := tags.sets.Load("missing")
+	require.False(t, found, "Getting a missing key should not create an entry")
+
+	require.NoError(t, tags.AddWithExpiration("go", "language", 10*time.Millisecond))
+	time.Sleep(20 * time.Millisecond)
+	_, err = tags.GetAll("go")
+	require.Error(t, err)
+	_, found = tags.sets.Load("go")
+	require.False(t, found, "An entry without items should be forgotten")
+
+	require.NoError(t, tags.Add("go", "language"))
+	items, err := tags.GetAll("go")
+	require.NoError(t, err)
+	require.Equal(t, []string{"language"}, items, "A forgotten entry should be created again")
+}
+
+func TestDeleteForgetsTheDependentsOfTheKey(t *testing.T) {
+	reports := New[string]("test")
+	require.NoError(t, reports.Set("sales", "sales"))
+	require.NoError(t, reports.Delete("sales"))
+	_, found := reports.dependents.Load("sales")
+	require.False(t, found, "Deleting a key without dependents should not create an entry")
+
+	require.NoError(t, reports.SetWithDependencies("margin", []string{"sales"}, "margin"))
+	require.NoError(t, reports.Delete("sales"))
+	_, found = reports.dependents.Load("sales")
+	require.False(t, found, "The entry should be removed once its dependents are taken")
+	require.NoError(t, reports.SetWithDependencies("margin", []string{"sales"}, "margin"))
+	require.NoError(t, reports.Delete("sales"))
+	_, err := reports.Get("margin")
+	require.ErrorIs(t, err, errors.NotFound, "The dependents added after the entry was removed should cascade")
+}
+
+func TestKeysPageSkipsPreviousGenerations(t *testing.T) {
+	items := New[int]("test")
+	require.NoError(t, items.Set(1, "current"))
+	generation, err := items.BumpGeneration()
+	require.NoError(t, err)
+	require.NoError(t, items.Set(2, "bumped"))
+	// A Set that started before BumpGeneration stores its record afterwards
+	require.NoError(t, items.keep(items.settings(), "stale", record[int]{Key: "stale", Item: 3, Generation: generation - 1}))
+
+	keys, next, err := items.KeysPage("", 10)
+	require.NoError(t, err)
+	require.Empty(t, next)
+	require.Equal(t, []string{"bumped"}, keys, "The keys of the previous generations should not be listed")
+}
+
+func TestTrimChangesTheSnapshot(t *testing.T) {
+	items := New[string]("test").WithShards(1).WithCapacity(100).WithWatermarks(0.9, 0.5)
+	for i := 0; i < 80; i++ {
+		require.NoError(t, items.Set("value", fmt.Sprintf("key-%d", i)))
+	}
+	require.Equal(t, 80, items.ReadSnapshot().Len())
+
+	shard := items.storage().shards[0]
+	shard.trimming.Store(true)
+	shard.trim(shard.eviction.Load())
+	require.Equal(t, 50, items.ReadSnapshot().Len(), "The snapshot should not keep the keys evicted in the background")
+}
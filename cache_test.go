@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -305,3 +306,29 @@ func (suite *CacheSuite) TestShouldFailWithInvalidEncyptionKey() {
 	suite.Require().Error(err, "Getting a cached user that should have expired did not fail")
 	suite.Require().Nil(cached, "Cached User is not nil")
 }
+
+func (suite *CacheSuite) TestCanReconfigureWhileInUse() {
+	cache := cache.New[User]("test")
+	defer func() { _ = cache.Clear() }()
+	user := User{ID: uuid.New(), Name: "Joe"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			cache.WithExpiration(time.Duration(i+1) * time.Minute)
+		}(i)
+		go func() {
+			defer wg.Done()
+			_ = cache.Set(user)
+			_, _ = cache.Get(user.GetID().String())
+		}()
+	}
+	wg.Wait()
+
+	cached, err := cache.Get(user.GetID().String())
+	suite.Require().NoError(err, "Failed to get cached user: %+v", err)
+	suite.Require().NotNil(cached, "Cached User is nil")
+	suite.Assert().Equal(user, *cached, "User and Cached User are different")
+}
@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Change is an item set, a key deleted, or a cache cleared, see OnChange
+//
+// Item is the zero value of T and Expiration is zero when a key is deleted or the cache is cleared,
+// Key is empty when the cache is cleared. Expiration is also zero when the item does not expire.
+// Tier tells where the item is kept, see SetWithTier, and Tenant is the tenant of the view the change was made in, see ForTenant.
+// Immutable tells the item was set with SetImmutable, and Dependencies are the dependencies given to SetWithDependencies.
+type Change[T any] struct {
+	Operation    AuditOperation
+	Key          string
+	Item         T
+	Expiration   time.Time
+	Tier         StorageTier
+	Tenant       string
+	Immutable    bool
+	Dependencies []string
+}
+
+// OnChange sets a callback called with each item set, once per key, each key deleted, and each clear
+//
+// The callback is called after the change is stored, with the context given to SetContext,
+// DeleteContext, or ClearContext. The dependents removed with a key are given as deletions too.
+// Clearing the cache gives an AuditClear, and one for each of its tenant views.
+// Purge gives a deletion for each key it removes from memory, the items it removes from the disk only
+// or from multi-value entries are not given. BumpGeneration gives no change.
+//
+// The callback is set on the tenant views already created too, see ForTenant. A nil callback stops the calls.
+func (cache *Cache[T]) OnChange(callback func(ctx context.Context, change Change[T])) *Cache[T] {
+	cache.configure(func(config *settings) {
+		if callback == nil {
+			config.onChange = nil
+		} else {
+			config.onChange = callback
+		}
+	})
+	cache.tenants.Range(func(id, view interface{}) bool {
+		view.(*Cache[T]).OnChange(callback)
+		return true
+	})
+	return cache
+}
+
+// SetWithExpirationContext sets an item in the cache with a custom expiration
+//
+// The context is given to the auditor and to the OnChange callback.
+func (cache *Cache[T]) SetWithExpirationContext(ctx context.Context, item T, expiration time.Duration, key ...string) (err error) {
+	config := cache.settings()
+	return cache.put(ctx, config, item, expiration, config.keysOf(item, key)...)
+}
+
+// SetImmutableWithExpirationContext sets an immutable item in the cache with a custom expiration, see SetImmutable
+//
+// The context is given to the auditor and to the OnChange callback.
+func (cache *Cache[T]) SetImmutableWithExpirationContext(ctx context.Context, item T, expiration time.Duration, key ...string) (err error) {
+	config := cache.settings()
+	return cache.putWith(ctx, config, setImmutable, item, expiration, 0, TierDefault, nil, config.keysOf(item, key)...)
+}
+
+// SetWithDependenciesAndExpirationContext sets an item that depends on other keys in the cache with a custom expiration,
+// see SetWithDependencies
+//
+// The context is given to the auditor and to the OnChange callback.
+func (cache *Cache[T]) SetWithDependenciesAndExpirationContext(ctx context.Context, item T, expiration time.Duration, dependencies []string, key ...string) (err error) {
+	config := cache.settings()
+	return cache.putWithDependencies(ctx, config, item, expiration, dependencies, config.keysOf(item, key)...)
+}
+
+// changed calls the OnChange callback of the cache, if any
+func (cache *Cache[T]) changed(ctx context.Context, config *settings, change Change[T]) {
+	if callback, ok := config.onChange.(func(context.Context, Change[T])); ok {
+		change.Tenant = config.tenant
+		callback(ctx, change)
+	}
+}
+
+// expirationTime gets the time of the expiration of a record, the zero time if it does not expire
+func expirationTime(expiration uint64) time.Time {
+	if expiration == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(expiration))
+}
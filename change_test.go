@@ -0,0 +1,32 @@
+package cache_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/gildas/go-cache"
+)
+
+func (suite *CacheSuite) TestCanWatchChanges() {
+	var changes []cache.Change[string]
+	var actors []string
+	greetings := cache.New[string]("test").OnChange(func(ctx context.Context, change cache.Change[string]) {
+		changes = append(changes, change)
+		actors = append(actors, cache.ActorFromContext(ctx))
+	})
+
+	suite.Require().NoError(greetings.SetWithExpirationContext(cache.WithActor(context.Background(), "joe"), "Hello", time.Hour, "hello", "hi"))
+	suite.Require().NoError(greetings.Delete("hi"))
+	suite.Require().Len(changes, 3)
+	suite.Assert().Equal(cache.AuditSet, changes[0].Operation)
+	suite.Assert().Equal("hello", changes[0].Key)
+	suite.Assert().Equal("Hello", changes[0].Item)
+	suite.Assert().WithinDuration(time.Now().Add(time.Hour), changes[0].Expiration, time.Second)
+	suite.Assert().Equal("hi", changes[1].Key)
+	suite.Assert().Equal(cache.Change[string]{Operation: cache.AuditDelete, Key: "hi"}, changes[2])
+	suite.Assert().Equal([]string{"joe", "joe", ""}, actors)
+
+	greetings.OnChange(nil)
+	suite.Require().NoError(greetings.Set("Bonjour", "bonjour"))
+	suite.Assert().Len(changes, 3, "The callback should not be called anymore")
+}
@@ -0,0 +1,210 @@
+package cache
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gildas/go-errors"
+)
+
+// manifestMagic starts the manifest of every chunked blob
+var manifestMagic = []byte("GCBM\x01")
+
+// blobManifest describes the chunks of a blob
+//
+// The manifest is written after each chunk, so an interrupted write can be resumed.
+type blobManifest struct {
+	ChunkSize  int64  `json:"chunkSize"`
+	Chunks     int    `json:"chunks"`
+	Size       int64  `json:"size"`
+	Complete   bool   `json:"complete"`
+	Expiration uint64 `json:"expiration,omitempty"`
+}
+
+// WithChunkSize splits the blobs larger than size bytes in chunks of size bytes
+//
+// Each chunk is written in its own file, so an interrupted SetReader can be
+// continued with ResumeReader, and GetRange reads only the chunks it needs.
+//
+// A size of 0 stores each blob in a single file.
+func (cache *Cache[T]) WithChunkSize(size int64) *Cache[T] {
+	return cache.configure(func(config *settings) {
+		config.chunkSize = max(size, 0)
+	})
+}
+
+// ResumeReader continues a SetReader of a chunked blob that was interrupted
+//
+// The reader must give the whole blob again, ResumeReader seeks it to the first byte
+// that was not persisted. If no chunk was persisted, the blob is written from the start
+// with the given ttl. If the blob is complete, nothing is written.
+func (cache *Cache[T]) ResumeReader(key string, reader io.ReadSeeker, ttl time.Duration) (err error) {
+	if len(key) == 0 {
+		return errors.ArgumentMissing.With("key")
+	}
+	config := cache.settings()
+	if !config.persistent {
+		return ErrNotPersistent.With(cache.Name)
+	}
+	folder := filepath.Join(config.folder, blobsFolder)
+	_, manifest, err := config.openBlob(filepath.Join(folder, config.filename(key)), config.blobData(config.filename(key), 0))
+	if err != nil || manifest == nil {
+		if _, err = reader.Seek(0, io.SeekStart); err != nil {
+			return
+		}
+		return cache.SetReader(key, reader, ttl)
+	}
+	if manifest.Complete {
+		return nil
+	}
+	if _, err = reader.Seek(manifest.Size, io.SeekStart); err != nil {
+		return
+	}
+	return config.writeChunks(folder, config.filename(key), bufio.NewReader(reader), *manifest)
+}
+
+// chunksFolder gets the folder where the chunks of the blob named filekey are stored
+func chunksFolder(folder, filekey string) string {
+	return filepath.Join(folder, filekey+".chunks")
+}
+
+// chunkFilename gets the name of the file of the chunk at the given index
+func chunkFilename(folder string, index int) string {
+	return filepath.Join(folder, fmt.Sprintf("%08d", index))
+}
+
+// writeChunks writes the content of source in chunks, starting after the chunks of the manifest
+//
+// A blob that fits in its first chunk is stored in a single file.
+func (config *settings) writeChunks(folder, filekey string, source *bufio.Reader, manifest blobManifest) (err error) {
+	filename := filepath.Join(folder, filekey)
+	chunks := chunksFolder(folder, filekey)
+	if manifest.Chunks == 0 {
+		if err = os.Remove(filename); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return
+		}
+		if err = os.RemoveAll(chunks); err != nil {
+			return
+		}
+	}
+	if err = os.MkdirAll(chunks, 0700); err != nil {
+		return
+	}
+	for index := manifest.Chunks; ; index++ {
+		chunk := &countingReader{reader: io.LimitReader(source, manifest.ChunkSize)}
+		if err = config.writeAtomic(chunkFilename(chunks, index), func(writer io.Writer) error {
+			return config.writeBlob(writer, chunk, manifest.Expiration, config.blobData(filekey, index))
+		}); err != nil {
+			return
+		}
+		_, err = source.Peek(1)
+		last := err == io.EOF
+		if err != nil && !last {
+			return
+		}
+		if index == 0 && last {
+			if err = os.Rename(chunkFilename(chunks, 0), filename); err == nil {
+				err = os.RemoveAll(chunks)
+			}
+			return
+		}
+		manifest.Chunks = index + 1
+		manifest.Size += chunk.count
+		manifest.Complete = last
+		if err = config.writeAtomic(filename, func(writer io.Writer) error {
+			if _, err := writer.Write(manifestMagic); err != nil {
+				return err
+			}
+			return json.NewEncoder(writer).Encode(manifest)
+		}); err != nil || last {
+			return
+		}
+	}
+}
+
+// readManifest reads the manifest of a chunked blob, after its magic
+func readManifest(reader io.Reader) (*blobManifest, error) {
+	var manifest blobManifest
+
+	if err := json.NewDecoder(reader).Decode(&manifest); err != nil {
+		return nil, errors.JSONUnmarshalError.Wrap(err)
+	}
+	if manifest.ChunkSize <= 0 {
+		return nil, errors.ArgumentInvalid.With("chunkSize", manifest.ChunkSize)
+	}
+	return &manifest, nil
+}
+
+// countingReader counts the bytes read from a reader
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+// Read reads from the reader and counts the bytes
+//
+// implements io.Reader
+func (reader *countingReader) Read(data []byte) (int, error) {
+	size, err := reader.reader.Read(data)
+	reader.count += int64(size)
+	return size, err
+}
+
+// chunksReader reads the chunks of a blob one after the other
+type chunksReader struct {
+	config  *settings
+	filekey string
+	folder  string
+	index   int
+	count   int
+	skip    int64
+	current io.ReadCloser
+}
+
+// Read reads the content of the chunks
+//
+// implements io.Reader
+func (reader *chunksReader) Read(data []byte) (int, error) {
+	for {
+		if reader.current == nil {
+			if reader.index >= reader.count {
+				return 0, io.EOF
+			}
+			current, _, err := reader.config.openBlob(chunkFilename(reader.folder, reader.index), reader.config.blobData(reader.filekey, reader.index))
+			if err != nil {
+				return 0, err
+			}
+			reader.current = current
+			if err = skip(current, reader.skip); err != nil {
+				return 0, err
+			}
+			reader.skip = 0
+		}
+		size, err := reader.current.Read(data)
+		if err == io.EOF {
+			_ = reader.current.Close()
+			reader.current = nil
+			reader.index++
+			if size > 0 {
+				return size, nil
+			}
+			continue
+		}
+		return size, err
+	}
+}
+
+// Close closes the current chunk
+//
+// implements io.Closer
+func (reader *chunksReader) Close() error {
+	if reader.current != nil {
+		return reader.current.Close()
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package cache_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+// failingReader fails after reading limit bytes
+type failingReader struct {
+	reader io.Reader
+	limit  int
+}
+
+func (reader *failingReader) Read(data []byte) (int, error) {
+	if reader.limit <= 0 {
+		return 0, errors.HTTPServiceUnavailable.WithStack()
+	}
+	if len(data) > reader.limit {
+		data = data[:reader.limit]
+	}
+	size, err := reader.reader.Read(data)
+	reader.limit -= size
+	return size, err
+}
+
+func (suite *CacheSuite) TestCanStoreBlobsInChunks() {
+	for _, encrypted := range []bool{false, true} {
+		blobs := cache.New[string]("test", cache.CacheOptionPersistent).WithChunkSize(1000)
+		if encrypted {
+			blobs.WithEncryptionKey([]byte("@v3ry#S3cr3tK3y!"))
+		}
+		payload := make([]byte, 3500)
+		_, _ = rand.Read(payload)
+
+		err := blobs.SetReader("artifact", bytes.NewReader(payload), 0)
+		suite.Require().NoError(err, "Failed to set blob: %+v", err)
+		reader, err := blobs.GetReader("artifact")
+		suite.Require().NoError(err, "Failed to get blob: %+v", err)
+		data, err := io.ReadAll(reader)
+		_ = reader.Close()
+		suite.Require().NoError(err, "Failed to read blob: %+v", err)
+		suite.Assert().Equal(payload, data)
+
+		// A range across 2 chunks
+		reader, err = blobs.GetRange("artifact", 1900, 300)
+		suite.Require().NoError(err, "Failed to get blob range: %+v", err)
+		data, err = io.ReadAll(reader)
+		_ = reader.Close()
+		suite.Require().NoError(err, "Failed to read blob range: %+v", err)
+		suite.Assert().Equal(payload[1900:2200], data)
+
+		// A small blob is not chunked
+		err = blobs.SetReader("small", bytes.NewReader(payload[:500]), 0)
+		suite.Require().NoError(err, "Failed to set blob: %+v", err)
+		reader, err = blobs.GetRange("small", 100, -1)
+		suite.Require().NoError(err, "Failed to get blob range: %+v", err)
+		data, err = io.ReadAll(reader)
+		_ = reader.Close()
+		suite.Require().NoError(err, "Failed to read blob range: %+v", err)
+		suite.Assert().Equal(payload[100:500], data)
+		_ = blobs.Clear()
+	}
+}
+
+func (suite *CacheSuite) TestCanResumeChunkedBlobs() {
+	blobs := cache.New[string]("test", cache.CacheOptionPersistent).WithChunkSize(1000)
+	defer func() { _ = blobs.Clear() }()
+	payload := make([]byte, 3500)
+	_, _ = rand.Read(payload)
+
+	err := blobs.SetReader("artifact", &failingReader{reader: bytes.NewReader(payload), limit: 2500}, 0)
+	suite.Require().Error(err, "SetReader should have failed")
+	_, err = blobs.GetReader("artifact")
+	suite.Assert().ErrorIs(err, errors.NotFound, "An incomplete blob should not be found")
+
+	err = blobs.ResumeReader("artifact", bytes.NewReader(payload), 0)
+	suite.Require().NoError(err, "Failed to resume blob: %+v", err)
+	reader, err := blobs.GetReader("artifact")
+	suite.Require().NoError(err, "Failed to get blob: %+v", err)
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	suite.Require().NoError(err, "Failed to read blob: %+v", err)
+	suite.Assert().Equal(payload, data)
+
+	folder, _ := os.UserCacheDir()
+	chunks, err := filepath.Glob(filepath.Join(folder, "test", "blobs", "*.chunks", "*"))
+	suite.Require().NoError(err)
+	suite.Assert().Len(chunks, 4)
+}
@@ -0,0 +1,50 @@
+package cache_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gildas/go-cache"
+)
+
+func (suite *CacheSuite) TestCanClearWithProgress() {
+	items := cache.New[string]("test", cache.CacheOptionPersistent)
+	for i := 0; i < 10; i++ {
+		_ = items.Set("value", fmt.Sprintf("key-%d", i))
+	}
+
+	var reports [][2]int
+	err := items.ClearContext(context.Background(), func(done, total int) {
+		reports = append(reports, [2]int{done, total})
+	})
+	suite.Require().NoError(err, "Failed to clear the cache: %+v", err)
+	suite.Require().Len(reports, 10)
+	suite.Assert().Equal([2]int{1, 10}, reports[0])
+	suite.Assert().Equal([2]int{10, 10}, reports[9])
+	_, err = cache.New[string]("test", cache.CacheOptionPersistent).Get("key-0")
+	suite.Assert().Error(err, "The cache should be empty")
+}
+
+func (suite *CacheSuite) TestCanCancelClear() {
+	items := cache.New[string]("test", cache.CacheOptionPersistent)
+	defer func() { _ = items.Clear() }()
+	for i := 0; i < 10; i++ {
+		_ = items.Set("value", fmt.Sprintf("key-%d", i))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err := items.ClearContext(ctx, func(done, total int) {
+		if done == 3 {
+			cancel()
+		}
+	})
+	suite.Require().ErrorIs(err, context.Canceled)
+	count := 0
+	reader := cache.New[string]("test", cache.CacheOptionPersistent)
+	for i := 0; i < 10; i++ {
+		if _, err := reader.Get(fmt.Sprintf("key-%d", i)); err == nil {
+			count++
+		}
+	}
+	suite.Assert().Equal(7, count, "The files that were not removed should still be on the disk")
+}
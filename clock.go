@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// clock is a CLOCK (second-chance) eviction policy
+//
+// The keys are in a ring with a referenced bit. Reading a key only sets its bit,
+// so reads do not move keys in a list. To find a victim, the hand goes around the ring,
+// clearing the bits it meets, and evicts the first key whose bit is clear.
+type clock struct {
+	mutex sync.RWMutex
+	slots map[string]*clockSlot
+	ring  []*clockSlot
+	hand  int
+}
+
+// clockSlot is a key of a clock policy
+type clockSlot struct {
+	key        string
+	index      int
+	referenced atomic.Bool
+}
+
+// newClock creates a new CLOCK eviction policy
+func newClock() *clock {
+	return &clock{slots: map[string]*clockSlot{}}
+}
+
+func (policy *clock) add(key string) {
+	policy.mutex.Lock()
+	defer policy.mutex.Unlock()
+	if slot, found := policy.slots[key]; found {
+		slot.referenced.Store(true)
+		return
+	}
+	slot := &clockSlot{key: key, index: len(policy.ring)}
+	policy.slots[key] = slot
+	policy.ring = append(policy.ring, slot)
+}
+
+func (policy *clock) touch(key string) {
+	policy.mutex.RLock()
+	defer policy.mutex.RUnlock()
+	if slot, found := policy.slots[key]; found {
+		slot.referenced.Store(true)
+	}
+}
+
+func (policy *clock) remove(key string) {
+	policy.mutex.Lock()
+	defer policy.mutex.Unlock()
+	if slot, found := policy.slots[key]; found {
+		policy.removeAt(slot.index)
+	}
+}
+
+func (policy *clock) victim() (string, bool) {
+	policy.mutex.Lock()
+	defer policy.mutex.Unlock()
+	for len(policy.ring) > 0 {
+		if policy.hand >= len(policy.ring) {
+			policy.hand = 0
+		}
+		slot := policy.ring[policy.hand]
+		if slot.referenced.CompareAndSwap(true, false) {
+			policy.hand++
+			continue
+		}
+		policy.removeAt(policy.hand)
+		return slot.key, true
+	}
+	return "", false
+}
+
+func (policy *clock) len() int {
+	policy.mutex.RLock()
+	defer policy.mutex.RUnlock()
+	return len(policy.ring)
+}
+
+// removeAt removes the slot at the given index of the ring, the last slot takes its place
+//
+// The caller must hold the mutex.
+func (policy *clock) removeAt(index int) {
+	slot := policy.ring[index]
+	last := len(policy.ring) - 1
+	policy.ring[index] = policy.ring[last]
+	policy.ring[index].index = index
+	policy.ring[last] = nil
+	policy.ring = policy.ring[:last]
+	delete(policy.slots, slot.key)
+}
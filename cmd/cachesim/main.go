@@ -0,0 +1,58 @@
+// cachesim replays an access trace recorded with cache.WithAccessTrace
+// against eviction policies and capacities, and reports their hit ratios.
+//
+// Usage:
+//
+//	cachesim -trace cache.trace -capacities 1000,10000,100000 -policies lru,clock,slru,slru:0.5
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gildas/go-cache"
+)
+
+func main() {
+	tracePath := flag.String("trace", "", "the trace file recorded with cache.WithAccessTrace")
+	capacitiesFlag := flag.String("capacities", "1000,10000,100000", "comma separated list of capacities")
+	policiesFlag := flag.String("policies", "lru,slru", "comma separated list of policies (lru, clock, slru, slru:<ratio>)")
+	flag.Parse()
+
+	if len(*tracePath) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	capacities := []int{}
+	for _, value := range strings.Split(*capacitiesFlag, ",") {
+		capacity, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || capacity <= 0 {
+			fmt.Fprintf(os.Stderr, "Invalid capacity: %s\n", value)
+			os.Exit(2)
+		}
+		capacities = append(capacities, capacity)
+	}
+
+	trace, err := os.Open(*tracePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open trace: %s\n", err)
+		os.Exit(1)
+	}
+	defer trace.Close()
+
+	results, err := cache.Simulate(trace, capacities, strings.Split(*policiesFlag, ",")...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to simulate: %s\n", err)
+		os.Exit(1)
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "POLICY\tCAPACITY\tHITS\tMISSES\tHIT RATIO")
+	for _, result := range results {
+		fmt.Fprintf(writer, "%s\t%d\t%d\t%d\t%.2f%%\n", result.Policy, result.Capacity, result.Hits, result.Misses, result.HitRatio*100)
+	}
+	_ = writer.Flush()
+}
@@ -0,0 +1,190 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// coalescer delays the persistence of the items so only the last write of a key within a window reaches the disk
+//
+// The writes in progress stay readable until they are on the disk, see Cache.Consistency.
+type coalescer struct {
+	mutex    sync.Mutex
+	window   time.Duration
+	pending  map[string]*pendingWrite
+	inflight map[string]*pendingWrite
+	dropped  *atomic.Uint64
+}
+
+// pendingWrite is a write waiting for the end of its window
+type pendingWrite struct {
+	config  *settings
+	key     string
+	filekey string
+	value   any
+	timer   *time.Timer
+	// cancelled is set when the key is deleted while its value is being written
+	cancelled bool
+}
+
+// WithWriteCoalescing delays the persistence of the items by window
+//
+// When the same key is set several times within the window, only its last value
+// is encrypted and written to the disk. The items in memory are always the last ones.
+// The writes that were replaced are counted in Stats.DroppedWrites.
+//
+// Other processes see the persisted items only at the end of the window,
+// call Flush to write the pending items immediately.
+//
+// A window of 0 writes the items immediately.
+func (cache *Cache[T]) WithWriteCoalescing(window time.Duration) *Cache[T] {
+	var previous *coalescer
+
+	cache.configure(func(config *settings) {
+		previous = config.coalescer
+		config.coalescer = nil
+		if window > 0 {
+			config.coalescer = &coalescer{window: window, pending: map[string]*pendingWrite{}, inflight: map[string]*pendingWrite{}, dropped: &cache.stats.droppedWrites}
+		}
+	})
+	if previous != nil {
+		_ = previous.flush()
+	}
+	return cache
+}
+
+// Flush writes the items waiting for the end of their coalescing window
+// and waits for the writes in the write-behind queue
+//
+// With WithPreloadPriority, the hit counts of the items are written too.
+func (cache *Cache[T]) Flush() error {
+	config := cache.settings()
+	if config.writeBehind != nil {
+		config.writeBehind.flush()
+	}
+	if config.coalescer != nil {
+		if err := config.coalescer.flush(); err != nil {
+			return err
+		}
+	}
+	if err := cache.persistPriorities(config); err != nil {
+		return err
+	}
+	return cache.persistKeyIndex(config)
+}
+
+// write schedules the persistence of the value of the given key
+func (coalescer *coalescer) write(config *settings, key string, value any) {
+	path := filepath.Join(config.folder, config.filekey(key))
+	coalescer.mutex.Lock()
+	defer coalescer.mutex.Unlock()
+	if pending, found := coalescer.pending[path]; found {
+		pending.config = config
+		pending.value = value
+		coalescer.dropped.Add(1)
+		return
+	}
+	coalescer.pending[path] = &pendingWrite{
+		config:  config,
+		key:     key,
+		filekey: config.filekey(key),
+		value:   value,
+		timer: time.AfterFunc(coalescer.window, func() {
+			if err := coalescer.persist(path); err != nil {
+				config.reportError(OperationCoalesce, key, err)
+			}
+		}),
+	}
+}
+
+// get gets the value waiting to be written in the file named filekey
+func (coalescer *coalescer) get(config *settings, filekey string) (any, bool) {
+	coalescer.mutex.Lock()
+	defer coalescer.mutex.Unlock()
+	path := filepath.Join(config.folder, filekey)
+	if pending, found := coalescer.pending[path]; found {
+		return pending.value, true
+	}
+	if pending, found := coalescer.inflight[path]; found {
+		return pending.value, true
+	}
+	return nil, false
+}
+
+// cancel cancels the write waiting for the file named filekey
+func (coalescer *coalescer) cancel(config *settings, filekey string) {
+	path := filepath.Join(config.folder, filekey)
+	coalescer.mutex.Lock()
+	defer coalescer.mutex.Unlock()
+	if pending, found := coalescer.pending[path]; found {
+		pending.timer.Stop()
+		delete(coalescer.pending, path)
+	}
+	if pending, found := coalescer.inflight[path]; found {
+		pending.cancelled = true
+		delete(coalescer.inflight, path)
+	}
+}
+
+// cancelAll cancels all the writes waiting in the given folder and its subfolders
+func (coalescer *coalescer) cancelAll(folder string) {
+	coalescer.mutex.Lock()
+	defer coalescer.mutex.Unlock()
+	for path, pending := range coalescer.pending {
+		if relative, err := filepath.Rel(folder, path); err == nil && filepath.IsLocal(relative) {
+			pending.timer.Stop()
+			delete(coalescer.pending, path)
+		}
+	}
+	for path, pending := range coalescer.inflight {
+		if relative, err := filepath.Rel(folder, path); err == nil && filepath.IsLocal(relative) {
+			pending.cancelled = true
+			delete(coalescer.inflight, path)
+		}
+	}
+}
+
+// persist writes the pending value of the given path
+func (coalescer *coalescer) persist(path string) error {
+	coalescer.mutex.Lock()
+	pending, found := coalescer.pending[path]
+	delete(coalescer.pending, path)
+	if found {
+		coalescer.inflight[path] = pending
+	}
+	coalescer.mutex.Unlock()
+	if !found {
+		return nil
+	}
+	err := pending.config.persist(pending.filekey, pending.value)
+	coalescer.mutex.Lock()
+	defer coalescer.mutex.Unlock()
+	if coalescer.inflight[path] == pending {
+		delete(coalescer.inflight, path)
+	}
+	if _, rescheduled := coalescer.pending[path]; pending.cancelled && !rescheduled && coalescer.inflight[path] == nil {
+		// The key was deleted while its value was being written, the value must not stay on the disk
+		_ = os.Remove(path)
+	}
+	return err
+}
+
+// flush writes all the pending values
+func (coalescer *coalescer) flush() (err error) {
+	coalescer.mutex.Lock()
+	paths := make([]string, 0, len(coalescer.pending))
+	for path, pending := range coalescer.pending {
+		pending.timer.Stop()
+		paths = append(paths, path)
+	}
+	coalescer.mutex.Unlock()
+	for _, path := range paths {
+		if persistErr := coalescer.persist(path); err == nil {
+			err = persistErr
+		}
+	}
+	return
+}
@@ -0,0 +1,44 @@
+package cache_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+func (suite *CacheSuite) TestCanCoalesceWrites() {
+	counters := cache.New[string]("test", cache.CacheOptionPersistent).WithWriteCoalescing(50 * time.Millisecond)
+	defer func() { _ = counters.Clear() }()
+	for i := 0; i < 10; i++ {
+		_ = counters.Set(fmt.Sprintf("value-%d", i), "counter")
+	}
+	value, err := counters.Get("counter")
+	suite.Require().NoError(err, "Failed to get value: %+v", err)
+	suite.Assert().Equal("value-9", *value, "The value in memory should be the last one")
+	suite.Assert().Equal(uint64(9), counters.Stats().DroppedWrites)
+
+	_, err = cache.New[string]("test", cache.CacheOptionPersistent).Get("counter")
+	suite.Assert().ErrorIs(err, errors.NotFound, "The value should not be persisted yet")
+	suite.Require().Eventually(func() bool {
+		value, err := cache.New[string]("test", cache.CacheOptionPersistent).Get("counter")
+		return err == nil && *value == "value-9"
+	}, time.Second, 10*time.Millisecond, "The last value should have been persisted")
+}
+
+func (suite *CacheSuite) TestCanFlushCoalescedWrites() {
+	counters := cache.New[string]("test", cache.CacheOptionPersistent).WithWriteCoalescing(time.Hour)
+	defer func() { _ = counters.Clear() }()
+	_ = counters.Set("value-1", "counter")
+	_ = counters.Set("value-2", "deleted")
+	_ = counters.Delete("deleted")
+
+	err := counters.Flush()
+	suite.Require().NoError(err, "Failed to flush: %+v", err)
+	value, err := cache.New[string]("test", cache.CacheOptionPersistent).Get("counter")
+	suite.Require().NoError(err, "Failed to get persisted value: %+v", err)
+	suite.Assert().Equal("value-1", *value)
+	_, err = cache.New[string]("test", cache.CacheOptionPersistent).Get("deleted")
+	suite.Assert().ErrorIs(err, errors.NotFound, "A deleted item should not be persisted")
+}
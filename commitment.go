@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/sha256"
+	"io"
+	"path/filepath"
+
+	"github.com/gildas/go-errors"
+)
+
+// committedMagic starts the records encrypted by encryptCommitted
+var committedMagic = []byte("GCE\x02")
+
+const (
+	saltSize       = 32
+	commitmentSize = 32
+)
+
+// errKeyMismatch is returned when a record was encrypted with another key
+var errKeyMismatch = errors.New("encryption key does not match")
+
+// encryptCommitted encrypts data with a key and a nonce derived from the key and a salt read from random
+//
+// The record is committed to the key: it starts with a digest derived from the key,
+// so decrypting it with another key fails instead of producing garbage.
+// The additional data is authenticated but not stored, see additionalData.
+func encryptCommitted(random io.Reader, key, data, additional []byte) ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(random, salt); err != nil {
+		return nil, err
+	}
+	gcm, nonce, commitment, err := deriveEntry(key, salt)
+	if err != nil {
+		return nil, err
+	}
+	encrypted := make([]byte, 0, len(committedMagic)+saltSize+commitmentSize+len(data)+gcm.Overhead())
+	encrypted = append(append(append(encrypted, committedMagic...), salt...), commitment...)
+	return gcm.Seal(encrypted, nonce, data, additional), nil
+}
+
+// decryptCommitted decrypts a record encrypted by encryptCommitted
+func decryptCommitted(key, data, additional []byte) ([]byte, error) {
+	if len(data) < len(committedMagic)+saltSize+commitmentSize {
+		return nil, errCorruptedFile
+	}
+	data = data[len(committedMagic):]
+	salt, stored, ciphertext := data[:saltSize], data[saltSize:saltSize+commitmentSize], data[saltSize+commitmentSize:]
+	gcm, nonce, commitment, err := deriveEntry(key, salt)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(stored, commitment) {
+		return nil, errKeyMismatch
+	}
+	return gcm.Open(nil, nonce, ciphertext, additional)
+}
+
+// deriveEntry derives the cipher, the nonce and the key commitment of a record from the key and its salt
+//
+// The key must be an AES key, like the keys of the records written before the key commitment.
+func deriveEntry(key, salt []byte) (gcm cipher.AEAD, nonce, commitment []byte, err error) {
+	var derived []byte
+	var block cipher.Block
+
+	if size := len(key); size != 16 && size != 24 && size != 32 {
+		err = aes.KeySizeError(size)
+		return
+	}
+	if derived, err = hkdf.Key(sha256.New, key, salt, "go-cache record", 32+12+commitmentSize); err != nil {
+		return
+	}
+	defer clear(derived[:32])
+	if block, err = aes.NewCipher(derived[:32]); err != nil {
+		return
+	}
+	if gcm, err = cipher.NewGCM(block); err != nil {
+		return
+	}
+	return gcm, derived[32:44], derived[44:], nil
+}
+
+// isCommitted tells if data was written by encryptCommitted
+func isCommitted(data []byte) bool {
+	return bytes.HasPrefix(data, committedMagic)
+}
+
+// WithLegacyDecryption reads the items and the blobs encrypted before the key commitment
+//
+// These files are neither committed to the key nor bound to their cache and key, so a file copied
+// from another cache or another key would be read. Without this option, reading them returns an ErrLegacyEncryption.
+// The items get the new format when they are written again, this option is meant for the migration only.
+func (cache *Cache[T]) WithLegacyDecryption(enabled bool) *Cache[T] {
+	return cache.configure(func(config *settings) {
+		config.legacyDecryption = enabled
+	})
+}
+
+// additionalData gets the data the records of filekey are bound to
+//
+// A record cannot be copied to another cache, to another tenant of the cache, or to another key.
+// The tenant is given by the name of its folder, see ForTenant, so its files can be read without its id.
+// The folder of filekey is ignored, so the records can be moved by WithDirectoryFanOut.
+func (config *settings) additionalData(filekey string) []byte {
+	data := "go-cache\x00" + config.name
+	if len(config.tenantKey) > 0 {
+		data += "\x00tenant\x00" + config.tenantKey
+	}
+	return []byte(data + "\x00" + filepath.Base(filekey))
+}
+
+// encryptRecord encrypts the record of filekey with the encryption key
+func (config *settings) encryptRecord(filekey string, data []byte) (encrypted []byte, err error) {
+	err = config.encryptionKey.use(func(key []byte) (err error) {
+		encrypted, err = encryptCommitted(config.randomReader(), key, data, config.additionalData(filekey))
+		return
+	})
+	return
+}
+
+// decryptRecord decrypts the record of filekey with the encryption key
+//
+// The records written before the key commitment are read only with WithLegacyDecryption, see decryptWithKey.
+func (config *settings) decryptRecord(filekey string, data []byte) (decrypted []byte, err error) {
+	if !isCommitted(data) && !config.legacyDecryption {
+		return nil, ErrLegacyEncryption.With(filekey)
+	}
+	err = config.encryptionKey.use(func(key []byte) (err error) {
+		decrypted, err = decryptWithKey(key, data, config.additionalData(filekey))
+		return
+	})
+	return
+}
@@ -0,0 +1,87 @@
+package cache_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/gildas/go-cache"
+	"github.com/google/uuid"
+)
+
+func (suite *CacheSuite) TestCannotReplayEncryptedItemsOnOtherKeysOrCaches() {
+	encryptionKey := []byte("@v3ry#S3cr3tK3y!")
+	users := cache.New[User]("test").WithEncryptionKey(encryptionKey)
+	others := cache.New[User]("test-replay").WithEncryptionKey(encryptionKey)
+	defer func() { _ = users.Clear(); _ = others.Clear() }()
+	suite.Require().NoError(users.Set(User{ID: uuid.New(), Name: "Admin"}, "admin"))
+	suite.Require().NoError(users.Set(User{ID: uuid.New(), Name: "Joe"}, "joe"))
+	suite.Require().NoError(others.Set(User{ID: uuid.New(), Name: "Joe"}, "joe"))
+
+	folder, _ := os.UserCacheDir()
+	admin, err := os.ReadFile(filepath.Join(folder, "test", uuid.NewSHA1(uuid.Nil, []byte("admin")).String()))
+	suite.Require().NoError(err, "Failed to read persisted file: %+v", err)
+	suite.Require().NoError(os.WriteFile(filepath.Join(folder, "test", uuid.NewSHA1(uuid.Nil, []byte("joe")).String()), admin, 0600))
+	suite.Require().NoError(os.WriteFile(filepath.Join(folder, "test-replay", uuid.NewSHA1(uuid.Nil, []byte("admin")).String()), admin, 0600))
+
+	_, err = cache.New[User]("test").WithEncryptionKey(encryptionKey).Get("joe")
+	suite.Assert().Error(err, "The item of another key should not decrypt")
+	_, err = cache.New[User]("test-replay").WithEncryptionKey(encryptionKey).Get("admin")
+	suite.Assert().Error(err, "The item of another cache should not decrypt")
+	value, err := cache.New[User]("test").WithEncryptionKey(encryptionKey).Get("admin")
+	suite.Require().NoError(err, "Failed to get item: %+v", err)
+	suite.Assert().Equal("Admin", value.Name)
+}
+
+func (suite *CacheSuite) TestCannotReplayEncryptedItemsOnOtherTenants() {
+	encryptionKey := []byte("@v3ry#S3cr3tK3y!")
+	users := cache.New[User]("test").WithEncryptionKey(encryptionKey)
+	defer func() { _ = users.Clear() }()
+	suite.Require().NoError(users.ForTenant("acme").Set(User{ID: uuid.New(), Name: "Admin"}, "admin"))
+	suite.Require().NoError(users.ForTenant("globex").Set(User{ID: uuid.New(), Name: "Joe"}, "joe"))
+
+	folder, _ := os.UserCacheDir()
+	tenant := func(id string) string {
+		return filepath.Join(folder, "test", "tenants", uuid.NewSHA1(uuid.Nil, []byte(id)).String())
+	}
+	filename := uuid.NewSHA1(uuid.Nil, []byte("admin")).String()
+	admin, err := os.ReadFile(filepath.Join(tenant("acme"), filename))
+	suite.Require().NoError(err, "Failed to read persisted file: %+v", err)
+	suite.Require().NoError(os.WriteFile(filepath.Join(tenant("globex"), filename), admin, 0600))
+
+	_, err = cache.New[User]("test").WithEncryptionKey(encryptionKey).ForTenant("globex").Get("admin")
+	suite.Assert().Error(err, "The item of another tenant should not decrypt")
+	value, err := cache.New[User]("test").WithEncryptionKey(encryptionKey).ForTenant("acme").Get("admin")
+	suite.Require().NoError(err, "Failed to get item: %+v", err)
+	suite.Assert().Equal("Admin", value.Name)
+}
+
+func (suite *CacheSuite) TestCannotReplayEncryptedBlobsOnOtherKeys() {
+	encryptionKey := []byte("@v3ry#S3cr3tK3y!")
+	blobs := cache.New[string]("test").WithEncryptionKey(encryptionKey)
+	defer func() { _ = blobs.Clear() }()
+	suite.Require().NoError(blobs.SetReader("admin", bytes.NewReader([]byte("admin secrets")), 0))
+	suite.Require().NoError(blobs.SetReader("joe", bytes.NewReader([]byte("joe secrets")), 0))
+
+	folder, _ := os.UserCacheDir()
+	admin, err := os.ReadFile(filepath.Join(folder, "test", "blobs", uuid.NewSHA1(uuid.Nil, []byte("admin")).String()))
+	suite.Require().NoError(err, "Failed to read persisted blob: %+v", err)
+	suite.Require().NoError(os.WriteFile(filepath.Join(folder, "test", "blobs", uuid.NewSHA1(uuid.Nil, []byte("joe")).String()), admin, 0600))
+
+	read := func(blobs *cache.Cache[string], key string) ([]byte, error) {
+		reader, err := blobs.GetReader(key)
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		return io.ReadAll(reader)
+	}
+	_, err = read(blobs, "joe")
+	suite.Assert().Error(err, "The blob of another key should not decrypt")
+	_, err = read(cache.New[string]("test").WithEncryptionKey([]byte("@n0th3r#S3cr3tK!")), "admin")
+	suite.Assert().Error(err, "The blob should not decrypt with another key")
+	data, err := read(blobs, "admin")
+	suite.Require().NoError(err, "Failed to read blob: %+v", err)
+	suite.Assert().Equal("admin secrets", string(data))
+}
@@ -0,0 +1,139 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"slices"
+
+	"github.com/gildas/go-errors"
+	"github.com/klauspost/compress/dict"
+	"github.com/klauspost/compress/zstd"
+)
+
+// zstdMagic starts every zstd frame
+var zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+// compressor compresses the persisted items with zstd, and an optional dictionary
+type compressor struct {
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+	err     error
+}
+
+// WithCompression compresses the persisted items with zstd and the given dictionary
+//
+// Small items, like short JSON payloads, barely compress on their own. A dictionary trained
+// on samples of the items (see TrainDictionary and Samples) gives them strong compression ratios.
+// A nil dictionary compresses each item on its own.
+//
+// The items are compressed after the transform given to WithStoreTransform, and before the encryption.
+// The items persisted without compression are still read. The items compressed with another dictionary
+// cannot be read anymore, they are treated as corrupted (see WithQuarantine).
+//
+// If the dictionary is invalid, the items cannot be persisted and Set returns ErrInvalidDictionary.
+func (cache *Cache[T]) WithCompression(dictionary []byte) *Cache[T] {
+	compressor := newCompressor(dictionary)
+	return cache.configure(func(config *settings) {
+		config.compressor = compressor
+	})
+}
+
+// Samples gets up to count items in memory, as they are persisted before compression
+//
+// The samples are meant to train a compression dictionary with TrainDictionary.
+// With WithRandom, the samples are the first items in the order of their keys.
+func (cache *Cache[T]) Samples(count int) (samples [][]byte) {
+	config := cache.settings()
+	sample := func(key string, entry record[T]) bool {
+		if len(samples) >= count {
+			return false
+		}
+		var data []byte
+		var err error
+
+		if raw, ok := any(entry).(record[[]byte]); ok {
+			var buffer bytes.Buffer
+			err = encodeRaw(&buffer, raw)
+			data = buffer.Bytes()
+		} else {
+			data, err = json.Marshal(entry)
+		}
+		if err == nil && config.storeTransform != nil {
+			data, err = config.storeTransform(data)
+		}
+		if err == nil {
+			samples = append(samples, data)
+		}
+		return true
+	}
+	if !config.deterministic() {
+		cache.storage().each(sample)
+		return
+	}
+	entries := map[string]record[T]{}
+	cache.storage().each(func(key string, entry record[T]) bool {
+		entries[key] = entry
+		return true
+	})
+	for _, key := range slices.Sorted(maps.Keys(entries)) {
+		if !sample(key, entries[key]) {
+			break
+		}
+	}
+	return
+}
+
+// TrainDictionary builds a zstd dictionary of at most size bytes from the given samples, see WithCompression
+//
+// The samples should look like the items of the cache, a few hundreds of them give a good dictionary.
+func TrainDictionary(samples [][]byte, size int) ([]byte, error) {
+	dictionary, err := dict.BuildZstdDict(samples, dict.Options{MaxDictSize: size, HashBytes: 6})
+	if err != nil {
+		return nil, ErrInvalidDictionary.Wrap(err)
+	}
+	return dictionary, nil
+}
+
+// newCompressor creates a new compressor with the given dictionary
+func newCompressor(dictionary []byte) *compressor {
+	var encoderOptions []zstd.EOption
+	var decoderOptions []zstd.DOption
+
+	if len(dictionary) > 0 {
+		encoderOptions = append(encoderOptions, zstd.WithEncoderDict(dictionary))
+		decoderOptions = append(decoderOptions, zstd.WithDecoderDicts(dictionary))
+	}
+	encoder, err := zstd.NewWriter(nil, encoderOptions...)
+	if err != nil {
+		return &compressor{err: ErrInvalidDictionary.Wrap(err)}
+	}
+	decoder, err := zstd.NewReader(nil, append(decoderOptions, zstd.WithDecoderConcurrency(0))...)
+	if err != nil {
+		return &compressor{err: ErrInvalidDictionary.Wrap(err)}
+	}
+	return &compressor{encoder: encoder, decoder: decoder}
+}
+
+// compress compresses data
+func (compressor *compressor) compress(data []byte) ([]byte, error) {
+	if compressor.err != nil {
+		return nil, compressor.err
+	}
+	return compressor.encoder.EncodeAll(data, nil), nil
+}
+
+// decompress decompresses data, the data that was not compressed is returned as is
+func (compressor *compressor) decompress(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, zstdMagic) {
+		return data, nil
+	}
+	if compressor.err != nil {
+		return nil, compressor.err
+	}
+	decompressed, err := compressor.decoder.DecodeAll(data, nil)
+	if err != nil {
+		return nil, errors.Join(errCorruptedFile, err)
+	}
+	return decompressed, nil
+}
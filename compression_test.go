@@ -0,0 +1,74 @@
+package cache_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+	"github.com/google/uuid"
+)
+
+type Event struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Source   string `json:"source"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+func newEvent(i int) Event {
+	return Event{
+		ID:       fmt.Sprintf("evt-%05d", i),
+		Type:     "com.acme.orders.created",
+		Source:   "https://orders.acme.com/api/v1",
+		Severity: []string{"info", "warning", "error"}[i%3],
+		Message:  fmt.Sprintf("Order %d was created by customer %d", i*7, i%13),
+	}
+}
+
+// persistedSize gets the size of the file of a key of the "test" cache
+func (suite *CacheSuite) persistedSize(key string) int64 {
+	folder, _ := os.UserCacheDir()
+	info, err := os.Stat(filepath.Join(folder, "test", uuid.NewSHA1(uuid.Nil, []byte(key)).String()))
+	suite.Require().NoError(err, "Failed to stat persisted file: %+v", err)
+	return info.Size()
+}
+
+func (suite *CacheSuite) TestCanCompressWithDictionary() {
+	events := cache.New[Event]("test", cache.CacheOptionPersistent)
+	defer func() { _ = events.Clear() }()
+	for i := range 500 {
+		suite.Require().NoError(events.Set(newEvent(i), fmt.Sprintf("event-%d", i)))
+	}
+	plain := suite.persistedSize("event-499")
+
+	samples := events.Samples(400)
+	suite.Require().Len(samples, 400)
+	dictionary, err := cache.TrainDictionary(samples, 4096)
+	suite.Require().NoError(err, "Failed to train dictionary: %+v", err)
+
+	compressed := cache.New[Event]("test", cache.CacheOptionPersistent).WithCompression(dictionary)
+	suite.Require().NoError(compressed.Set(newEvent(499), "event-1000"))
+	suite.Assert().Less(suite.persistedSize("event-1000")*2, plain, "The dictionary should at least halve the size of the item")
+
+	reopened := cache.New[Event]("test", cache.CacheOptionPersistent).WithCompression(dictionary)
+	event, err := reopened.Get("event-1000")
+	suite.Require().NoError(err, "Failed to get compressed item: %+v", err)
+	suite.Assert().Equal(newEvent(499), *event)
+	event, err = reopened.Get("event-1")
+	suite.Require().NoError(err, "Failed to get item persisted before the compression: %+v", err)
+	suite.Assert().Equal(newEvent(1), *event)
+}
+
+func (suite *CacheSuite) TestShouldFailWithInvalidDictionary() {
+	events := cache.New[Event]("test", cache.CacheOptionPersistent).WithCompression([]byte("not a dictionary"))
+	defer func() { _ = events.Clear() }()
+	err := events.Set(newEvent(1), "event-1")
+	suite.Assert().ErrorIs(err, cache.ErrInvalidDictionary)
+
+	_, err = cache.TrainDictionary(nil, 4096)
+	suite.Assert().ErrorIs(err, cache.ErrInvalidDictionary)
+	suite.Assert().False(errors.Is(err, errors.NotFound))
+}
@@ -0,0 +1,237 @@
+package cache
+
+import (
+	"context"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// ComputeOption configures GetOrCompute
+type ComputeOption func(options *computeOptions)
+
+type computeOptions struct {
+	timeout       time.Duration
+	expiration    time.Duration
+	hasExpiration bool
+	dependencies  []string
+}
+
+// ComputeTimeout sets the maximum duration of the computation
+//
+// When the computation takes longer, its context is cancelled and
+// all callers waiting for it get context.DeadlineExceeded.
+func ComputeTimeout(timeout time.Duration) ComputeOption {
+	return func(options *computeOptions) {
+		options.timeout = timeout
+	}
+}
+
+// ComputeExpiration sets the expiration of the computed item instead of the cache's expiration
+func ComputeExpiration(expiration time.Duration) ComputeOption {
+	return func(options *computeOptions) {
+		options.expiration = expiration
+		options.hasExpiration = true
+	}
+}
+
+// ComputeDependencies stores the computed item with dependencies, see SetWithDependencies
+//
+// The dependencies are recorded when the item is stored, whether the caller is still waiting or not.
+func ComputeDependencies(dependencies ...string) ComputeOption {
+	return func(options *computeOptions) {
+		options.dependencies = dependencies
+	}
+}
+
+// flight is a computation in progress
+type flight[T interface{}] struct {
+	done  chan struct{}
+	ctx   context.Context
+	value T
+	err   error
+}
+
+// flightGroup makes sure there is only one computation in progress per key
+type flightGroup[T interface{}] struct {
+	mutex   sync.Mutex
+	flights map[string]*flight[T]
+}
+
+// GetOrCompute gets an item from the cache or computes it if it is not there
+//
+// Only one computation runs per key at a time, other callers for the same key wait for its result.
+// The computation runs under its own context, derived from ctx without its cancellation,
+// so a caller that gives up does not cancel the computation for the others.
+// A caller whose ctx is done stops waiting and gets ctx's error.
+//
+// The computed item is stored under key with the cache's expiration, or the item's (see Expirable and Expiring),
+// unless ComputeExpiration is given. If the computed item cannot be stored, it is returned all the same
+// and the error is given to the error handler with OperationCompute, see WithErrorHandler.
+//
+// If the computation fails and WithServeStaleOnError was set, the expired item is returned instead.
+func (cache *Cache[T]) GetOrCompute(ctx context.Context, key string, compute func(context.Context) (T, error), options ...ComputeOption) (*T, error) {
+	config := cache.settings()
+	start := time.Now()
+	ctx, unlabel := config.label(ctx, cache.Name, "compute")
+	defer unlabel()
+	stale, found, _ := cache.lookup(config, key)
+	if config.tracer != nil {
+		config.tracer.trace(key, TraceGet, found && !stale.expired(), start)
+	}
+	if found && !stale.expired() {
+		cache.stats.hits.Add(1)
+		if config.topKeys != nil {
+			config.topKeys.hit(key)
+		}
+		cache.storage().touch(key)
+		cache.extend(config, key, stale)
+		return &stale.Item, nil
+	}
+	cache.stats.misses.Add(1)
+	cache.prefetch(config, key)
+	computeOptions := computeOptions{}
+	for _, option := range options {
+		option(&computeOptions)
+	}
+
+	value, err := cache.join(ctx, config, key, compute, computeOptions)
+	if err != nil && found && stale.stale(config.maxStale) {
+		cache.stats.staleHits.Add(1)
+		return &stale.Item, nil
+	}
+	return value, err
+}
+
+// join joins the computation in progress for the key or starts a new one, and waits for its result
+//
+// The circuit breaker is consulted only when a new computation starts, so callers can join a computation in progress,
+// like the probe of a half-open circuit.
+func (cache *Cache[T]) join(ctx context.Context, config *settings, key string, compute func(context.Context) (T, error), computeOptions computeOptions) (*T, error) {
+	current, err := cache.flights.join(key, func() (*flight[T], error) {
+		var computeCtx context.Context
+		var cancel context.CancelFunc
+		if config.breaker != nil && !config.breaker.allow() {
+			return nil, ErrCircuitOpen.With(key)
+		}
+		if computeOptions.timeout > 0 {
+			computeCtx, cancel = context.WithTimeout(context.WithoutCancel(ctx), computeOptions.timeout)
+		} else {
+			computeCtx, cancel = context.WithCancel(context.WithoutCancel(ctx))
+		}
+		current := &flight[T]{done: make(chan struct{}), ctx: computeCtx}
+		go func() {
+			defer cancel()
+			defer cache.flights.leave(key, current)
+			defer close(current.done)
+			current.value, current.err = cache.compute(computeCtx, key, compute)
+			if current.err == nil && computeCtx.Err() != nil {
+				current.err = computeCtx.Err()
+			}
+			if config.breaker != nil {
+				config.breaker.report(current.err)
+			}
+			if current.err == nil {
+				expiration := expirationOf(current.value, config.expiration)
+				if computeOptions.hasExpiration {
+					expiration = computeOptions.expiration
+				}
+				keys := config.keysOf(current.value, []string{key})
+				config.reportError(OperationCompute, key, cache.putWithDependencies(context.Background(), config, current.value, expiration, computeOptions.dependencies, keys...))
+			}
+		}()
+		return current, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case <-current.done:
+		return current.result()
+	case <-current.ctx.Done():
+		select {
+		case <-current.done:
+			return current.result()
+		default:
+			// The computation took too long, the next caller will start a new one
+			cache.flights.leave(key, current)
+			return nil, current.ctx.Err()
+		}
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// WithServeStaleOnError tells GetOrCompute to return the expired item when its computation fails
+//
+// The expired item is returned only if it expired less than maxStale ago,
+// expired items are kept until then. Such reads are counted in Stats as StaleHits.
+//
+// A maxStale of 0 disables this behavior.
+func (cache *Cache[T]) WithServeStaleOnError(maxStale time.Duration) *Cache[T] {
+	return cache.configure(func(config *settings) {
+		config.maxStale = maxStale
+	})
+}
+
+// compute runs the computation, turning a panic into an ErrComputePanic error
+func (cache *Cache[T]) compute(ctx context.Context, key string, compute func(context.Context) (T, error)) (value T, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = ErrComputePanic.With(key, recovered)
+			if handler := cache.settings().panicHandler; handler != nil {
+				handler(key, recovered, debug.Stack())
+			}
+		}
+	}()
+	return compute(ctx)
+}
+
+// WithPanicHandler sets the function called when a computation panics
+//
+// The handler gets the key being computed, the value given to panic and the stack trace of the panic.
+// Callers waiting for the computation get an ErrComputePanic error.
+func (cache *Cache[T]) WithPanicHandler(handler func(key string, recovered any, stack []byte)) *Cache[T] {
+	return cache.configure(func(config *settings) {
+		config.panicHandler = handler
+	})
+}
+
+// result gets the result of a flight that is over
+func (current *flight[T]) result() (*T, error) {
+	if current.err != nil {
+		return nil, current.err
+	}
+	value := current.value
+	return &value, nil
+}
+
+// join gets the flight in progress for the key or starts a new one
+//
+// If start fails, no flight is started and its error is returned.
+func (group *flightGroup[T]) join(key string, start func() (*flight[T], error)) (*flight[T], error) {
+	group.mutex.Lock()
+	defer group.mutex.Unlock()
+	if current, found := group.flights[key]; found {
+		return current, nil
+	}
+	if group.flights == nil {
+		group.flights = map[string]*flight[T]{}
+	}
+	current, err := start()
+	if err != nil {
+		return nil, err
+	}
+	group.flights[key] = current
+	return current, nil
+}
+
+// leave removes the flight of the key once it is over
+func (group *flightGroup[T]) leave(key string, current *flight[T]) {
+	group.mutex.Lock()
+	defer group.mutex.Unlock()
+	if group.flights[key] == current {
+		delete(group.flights, key)
+	}
+}
@@ -0,0 +1,245 @@
+package cache_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+func (suite *CacheSuite) TestCanGetOrCompute() {
+	users := cache.New[User]("test")
+	defer func() { _ = users.Clear() }()
+	user := User{ID: uuid.New(), Name: "Joe"}
+	var calls atomic.Int32
+	compute := func(ctx context.Context) (User, error) {
+		calls.Add(1)
+		time.Sleep(100 * time.Millisecond)
+		return user, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cached, err := users.GetOrCompute(context.Background(), "joe", compute)
+			suite.Assert().NoError(err, "Failed to compute user: %+v", err)
+			if suite.Assert().NotNil(cached) {
+				suite.Assert().Equal(user, *cached, "User and Cached User are different")
+			}
+		}()
+	}
+	wg.Wait()
+	suite.Assert().Equal(int32(1), calls.Load(), "The computation should have run once")
+
+	cached, err := users.Get("joe")
+	suite.Require().NoError(err, "Failed to get cached user: %+v", err)
+	suite.Assert().Equal(user, *cached, "User and Cached User are different")
+}
+
+func (suite *CacheSuite) TestCanGetOrComputeWithExpiration() {
+	users := cache.New[User]("test")
+	defer func() { _ = users.Clear() }()
+	user := User{ID: uuid.New(), Name: "Joe"}
+	_, err := users.GetOrCompute(context.Background(), "joe", func(ctx context.Context) (User, error) {
+		return user, nil
+	}, cache.ComputeExpiration(100*time.Millisecond))
+	suite.Require().NoError(err, "Failed to compute user: %+v", err)
+
+	time.Sleep(200 * time.Millisecond)
+	_, err = users.Get("joe")
+	suite.Assert().Error(err, "The computed user should have expired")
+}
+
+func (suite *CacheSuite) TestShouldReturnComputedItemThatCannotBeStored() {
+	var reported atomic.Int32
+	reports := cache.New[string]("test").WithValidator(func(report string) error {
+		return errors.New("rejected")
+	}).WithErrorHandler(func(operation string, key string, err error) {
+		if operation == cache.OperationCompute && key == "report" {
+			reported.Add(1)
+		}
+	})
+	value, err := reports.GetOrCompute(context.Background(), "report", func(ctx context.Context) (string, error) {
+		return "computed", nil
+	})
+	suite.Require().NoError(err, "A computed item that cannot be stored should still be returned: %+v", err)
+	suite.Assert().Equal("computed", *value)
+	suite.Assert().Equal(int32(1), reported.Load(), "The cache error should have been reported")
+}
+
+func (suite *CacheSuite) TestShouldReleaseWaitersWhenComputeTimesOut() {
+	users := cache.New[User]("test")
+	defer func() { _ = users.Clear() }()
+	release := make(chan struct{})
+	defer close(release)
+
+	start := time.Now()
+	_, err := users.GetOrCompute(context.Background(), "joe", func(ctx context.Context) (User, error) {
+		<-release // this computation ignores its context
+		return User{}, nil
+	}, cache.ComputeTimeout(100*time.Millisecond))
+	suite.Require().Error(err, "The computation should have timed out")
+	suite.Assert().ErrorIs(err, context.DeadlineExceeded)
+	suite.Assert().Less(time.Since(start), time.Second)
+
+	user := User{ID: uuid.New(), Name: "Joe"}
+	cached, err := users.GetOrCompute(context.Background(), "joe", func(ctx context.Context) (User, error) {
+		return user, nil
+	})
+	suite.Require().NoError(err, "A new computation should have started: %+v", err)
+	suite.Assert().Equal(user, *cached)
+}
+
+func (suite *CacheSuite) TestShouldStopWaitingWhenContextIsDone() {
+	users := cache.New[User]("test")
+	defer func() { _ = users.Clear() }()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := users.GetOrCompute(ctx, "joe", func(ctx context.Context) (User, error) {
+		time.Sleep(200 * time.Millisecond)
+		return User{ID: uuid.New(), Name: "Joe"}, nil
+	})
+	suite.Assert().ErrorIs(err, context.DeadlineExceeded)
+
+	time.Sleep(300 * time.Millisecond)
+	_, err = users.Get("joe")
+	suite.Assert().NoError(err, "The computation should have completed for the other callers")
+}
+
+func (suite *CacheSuite) TestShouldRecoverWhenComputePanics() {
+	var handled atomic.Value
+	users := cache.New[User]("test").WithPanicHandler(func(key string, recovered any, stack []byte) {
+		handled.Store(key)
+		suite.Assert().Equal("boom", recovered)
+		suite.Assert().NotEmpty(stack)
+	})
+	defer func() { _ = users.Clear() }()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := users.GetOrCompute(context.Background(), "joe", func(ctx context.Context) (User, error) {
+				time.Sleep(50 * time.Millisecond)
+				panic("boom")
+			})
+			suite.Assert().ErrorIs(err, cache.ErrComputePanic)
+			var detailedError *errors.Error
+			if suite.Assert().True(errors.As(err, &detailedError), "Error should be a detailed error") {
+				suite.Assert().Equal("joe", detailedError.What)
+				suite.Assert().Equal("boom", detailedError.Value)
+			}
+		}()
+	}
+	wg.Wait()
+	suite.Assert().Equal("joe", handled.Load(), "The panic handler should have been called")
+}
+
+func (suite *CacheSuite) TestCanOpenCircuitOnFailures() {
+	users := cache.New[User]("test").WithCircuitBreaker(2, 200*time.Millisecond)
+	defer func() { _ = users.Clear() }()
+	var calls atomic.Int32
+	failing := func(ctx context.Context) (User, error) {
+		calls.Add(1)
+		return User{}, errors.HTTPServiceUnavailable.WithStack()
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := users.GetOrCompute(context.Background(), "joe", failing)
+		suite.Assert().ErrorIs(err, errors.HTTPServiceUnavailable)
+	}
+	_, err := users.GetOrCompute(context.Background(), "joe", failing)
+	suite.Assert().ErrorIs(err, cache.ErrCircuitOpen, "The circuit should be open")
+	suite.Assert().Equal(int32(2), calls.Load(), "The computation should not have been called while the circuit is open")
+
+	time.Sleep(300 * time.Millisecond)
+	user := User{ID: uuid.New(), Name: "Joe"}
+	cached, err := users.GetOrCompute(context.Background(), "joe", func(ctx context.Context) (User, error) {
+		return user, nil
+	})
+	suite.Require().NoError(err, "The circuit should be half-open: %+v", err)
+	suite.Assert().Equal(user, *cached)
+
+	_, err = users.GetOrCompute(context.Background(), "jane", failing)
+	suite.Assert().ErrorIs(err, errors.HTTPServiceUnavailable, "The circuit should be closed")
+}
+
+func (suite *CacheSuite) TestCanJoinHalfOpenCircuitProbe() {
+	users := cache.New[User]("test").WithCircuitBreaker(1, 50*time.Millisecond)
+	defer func() { _ = users.Clear() }()
+	_, err := users.GetOrCompute(context.Background(), "joe", func(ctx context.Context) (User, error) {
+		return User{}, errors.HTTPServiceUnavailable.WithStack()
+	})
+	suite.Require().ErrorIs(err, errors.HTTPServiceUnavailable)
+	time.Sleep(100 * time.Millisecond)
+
+	user := User{ID: uuid.New(), Name: "Joe"}
+	started := make(chan struct{})
+	release := make(chan struct{})
+	probe := make(chan error, 1)
+	go func() {
+		_, err := users.GetOrCompute(context.Background(), "joe", func(ctx context.Context) (User, error) {
+			close(started)
+			<-release
+			return user, nil
+		})
+		probe <- err
+	}()
+	<-started
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+	}()
+	joined, err := users.GetOrCompute(context.Background(), "joe", func(ctx context.Context) (User, error) {
+		return User{}, errors.New("the computation in progress should be joined")
+	})
+	suite.Require().NoError(err, "The probe of the half-open circuit should be joined: %+v", err)
+	suite.Assert().Equal(user, *joined)
+	suite.Require().NoError(<-probe)
+}
+
+func (suite *CacheSuite) TestCanServeStaleOnError() {
+	users := cache.New[User]("test").WithExpiration(100 * time.Millisecond).WithServeStaleOnError(time.Second)
+	defer func() { _ = users.Clear() }()
+	user := User{ID: uuid.New(), Name: "Joe"}
+	_, err := users.GetOrCompute(context.Background(), "joe", func(ctx context.Context) (User, error) {
+		return user, nil
+	})
+	suite.Require().NoError(err, "Failed to compute user: %+v", err)
+
+	time.Sleep(200 * time.Millisecond)
+	_, err = users.Get("joe")
+	suite.Assert().ErrorIs(err, errors.NotFound, "The user should have expired")
+
+	cached, err := users.GetOrCompute(context.Background(), "joe", func(ctx context.Context) (User, error) {
+		return User{}, errors.HTTPServiceUnavailable.WithStack()
+	})
+	suite.Require().NoError(err, "The stale user should have been served: %+v", err)
+	suite.Assert().Equal(user, *cached)
+
+	stats := users.Stats()
+	suite.Assert().Equal(uint64(1), stats.StaleHits)
+	suite.Assert().Equal(uint64(3), stats.Misses)
+}
+
+func (suite *CacheSuite) TestShouldNotServeTooStaleItems() {
+	users := cache.New[User]("test").WithExpiration(100 * time.Millisecond).WithServeStaleOnError(100 * time.Millisecond)
+	defer func() { _ = users.Clear() }()
+	_ = users.Set(User{ID: uuid.New(), Name: "Joe"}, "joe")
+
+	time.Sleep(300 * time.Millisecond)
+	_, err := users.GetOrCompute(context.Background(), "joe", func(ctx context.Context) (User, error) {
+		return User{}, errors.HTTPServiceUnavailable.WithStack()
+	})
+	suite.Assert().ErrorIs(err, errors.HTTPServiceUnavailable)
+	suite.Assert().Equal(uint64(0), users.Stats().StaleHits)
+}
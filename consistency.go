@@ -0,0 +1,66 @@
+package cache
+
+// Consistency tells what the readers of a cache see after a Set, see Cache.Consistency
+//
+// Whatever the configuration, a Get that follows a Set on the same Cache sees the new item:
+// the items being persisted in the background are read from memory until they are on the disk.
+type Consistency int
+
+const (
+	// MemoryConsistency is the consistency of the caches that are not persistent
+	//
+	// Only the Cache that set an item sees it.
+	MemoryConsistency Consistency = iota
+	// WriteThroughConsistency is the consistency of the persistent caches that write the items when they are set
+	//
+	// Set returns once the item is on the disk, other caches on the same folder see it from then on.
+	WriteThroughConsistency
+	// ReadYourWritesConsistency is the consistency of the caches with WithWriteBehind or WithWriteCoalescing
+	//
+	// Set returns before the item is on the disk, other caches on the same folder see it
+	// once it is written, or after Flush.
+	ReadYourWritesConsistency
+	// LossyConsistency is the consistency of the caches with WithWriteBehind and a policy that drops writes
+	//
+	// Like ReadYourWritesConsistency, but a dropped item is never written: once it is evicted from
+	// memory, it is not found. The previous item of its key is removed from the disk so it is never read instead.
+	LossyConsistency
+)
+
+// String gets the name of the consistency
+//
+// implements fmt.Stringer
+func (consistency Consistency) String() string {
+	switch consistency {
+	case WriteThroughConsistency:
+		return "write-through"
+	case ReadYourWritesConsistency:
+		return "read-your-writes"
+	case LossyConsistency:
+		return "lossy"
+	default:
+		return "memory"
+	}
+}
+
+// Consistency gets what the readers of the cache see after a Set with its current configuration
+//
+// Replication to other instances, like the gossip package does, is always asynchronous:
+// other instances see the items later, if they see them at all.
+func (cache *Cache[T]) Consistency() Consistency {
+	return cache.settings().consistency()
+}
+
+// consistency gets the consistency of the cache with these settings
+func (config *settings) consistency() Consistency {
+	switch {
+	case !config.persistent:
+		return MemoryConsistency
+	case config.writeBehind != nil && (config.writeBehind.policy == BackpressureDropOldest || config.writeBehind.policy == BackpressureDropNewest):
+		return LossyConsistency
+	case config.writeBehind != nil || config.coalescer != nil:
+		return ReadYourWritesConsistency
+	default:
+		return WriteThroughConsistency
+	}
+}
@@ -0,0 +1,70 @@
+package cache_test
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+func (suite *CacheSuite) TestCanGetConsistency() {
+	suite.Assert().Equal(cache.MemoryConsistency, cache.New[string]("test").Consistency())
+	suite.Assert().Equal(cache.WriteThroughConsistency, cache.New[string]("test", cache.CacheOptionPersistent).Consistency())
+	suite.Assert().Equal(cache.ReadYourWritesConsistency, cache.New[string]("test", cache.CacheOptionPersistent).WithWriteCoalescing(time.Second).Consistency())
+	behind := cache.New[string]("test", cache.CacheOptionPersistent).WithWriteBehind(10, cache.BackpressureBlock)
+	defer behind.Close()
+	suite.Assert().Equal(cache.ReadYourWritesConsistency, behind.Consistency())
+	lossy := cache.New[string]("test", cache.CacheOptionPersistent).WithWriteBehind(10, cache.BackpressureDropOldest)
+	defer lossy.Close()
+	suite.Assert().Equal(cache.LossyConsistency, lossy.Consistency())
+	suite.Assert().Equal("lossy", lossy.Consistency().String())
+}
+
+// newGatedEvictingWriteBehind creates a cache that keeps one item in memory and whose write-behind goroutine is stuck writing "first" until gate is closed
+func (suite *CacheSuite) newGatedEvictingWriteBehind(policy cache.BackpressurePolicy) (items *cache.Cache[string], gate chan struct{}) {
+	gate = make(chan struct{})
+	items = cache.New[string]("test", cache.CacheOptionPersistent).WithCapacity(1).WithStoreTransform(func(data []byte) ([]byte, error) {
+		if bytes.Contains(data, []byte(`"first"`)) {
+			<-gate
+		}
+		return data, nil
+	}).WithWriteBehind(1, policy)
+	return
+}
+
+func (suite *CacheSuite) TestShouldReadWriteInProgress() {
+	items, gate := suite.newGatedEvictingWriteBehind(cache.BackpressureBlock)
+	defer func() { _ = items.Clear() }()
+	defer items.Close()
+	defer close(gate)
+
+	_ = items.Set("first", "first")
+	suite.Require().Eventually(func() bool { return items.Stats().WriteQueueDepth == 0 }, time.Second, time.Millisecond, "The goroutine should be writing first")
+	_ = items.Set("second", "second") // evicts first from memory
+	value, err := items.Get("first")
+	suite.Require().NoError(err, "The item being written should be found: %+v", err)
+	suite.Assert().Equal("first", *value)
+}
+
+func (suite *CacheSuite) TestShouldNotReadOlderItemWhenWriteIsDropped() {
+	items, gate := suite.newGatedEvictingWriteBehind(cache.BackpressureDropNewest)
+	defer func() { _ = items.Clear() }()
+	defer items.Close()
+
+	_ = items.Set("old", "key")
+	_ = items.Flush()
+	_ = items.Set("first", "first")
+	suite.Require().Eventually(func() bool { return items.Stats().WriteQueueDepth == 0 }, time.Second, time.Millisecond, "The goroutine should be writing first")
+	_ = items.Set("second", "second")
+	_ = items.Set("new", "key") // dropped, the queue is full
+	suite.Require().Equal(uint64(1), items.Stats().OverflowedWrites)
+	value, err := items.Get("key")
+	suite.Require().NoError(err, "Failed to get item: %+v", err)
+	suite.Assert().Equal("new", *value)
+
+	_ = items.Set("other", "other") // evicts key from memory
+	_, err = items.Get("key")
+	suite.Assert().ErrorIs(err, errors.NotFound, "The older item should not be read instead of the dropped one")
+	close(gate)
+}
@@ -0,0 +1,146 @@
+package cache
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+)
+
+// SetWithCost sets an item in the cache with the cost of computing it again
+//
+// With WithCostAwareEviction, the items that are cheap to compute again are evicted
+// before the expensive ones. The cost is in any unit, as long as it is the same for all the items.
+// The items set without a cost have a cost of 1.
+func (cache *Cache[T]) SetWithCost(item T, cost int64, key ...string) error {
+	config := cache.settings()
+	return cache.putWith(context.Background(), config, setDefault, item, expirationOf(item, config.expiration), cost, TierDefault, nil, config.keysOf(item, key)...)
+}
+
+// WithCostAwareEviction sets the maximum number of keys the cache keeps in memory
+// and evicts them by cost, see SetWithCost
+//
+// The policy is GreedyDual-Size: the keys with the lowest cost per byte are evicted first,
+// and the keys that are read recently are kept longer, so expensive items that are not read
+// anymore are evicted eventually. The size of the items is used only with WithMaxMemory.
+//
+// A capacity of 0 means the number of keys is not bounded, the policy is still used with WithMaxMemory.
+func (cache *Cache[T]) WithCostAwareEviction(capacity int) *Cache[T] {
+	cache.configure(func(config *settings) {
+		config.capacity = max(capacity, 0)
+		config.newPolicy = func(int) policy { return newGreedyDual() }
+	})
+	cache.rebuild()
+	return cache
+}
+
+// costPolicy is a policy that is told the cost and the size of the records that are stored
+type costPolicy interface {
+	addCost(key string, cost, size int64)
+}
+
+// greedyDual is a GreedyDual-Size eviction policy
+//
+// The priority of a key is the inflation plus its cost per byte, the key with the lowest priority is evicted
+// and the inflation becomes its priority. So the priority of the keys that are not read goes down over time.
+type greedyDual struct {
+	mutex     sync.Mutex
+	entries   map[string]*costEntry
+	queue     costQueue
+	inflation float64
+}
+
+// costEntry is a key of a greedyDual policy
+type costEntry struct {
+	key      string
+	cost     float64
+	priority float64
+	index    int
+}
+
+// newGreedyDual creates a new GreedyDual-Size eviction policy
+func newGreedyDual() *greedyDual {
+	return &greedyDual{entries: map[string]*costEntry{}}
+}
+
+func (policy *greedyDual) add(key string) {
+	policy.addCost(key, 0, 0)
+}
+
+func (policy *greedyDual) addCost(key string, cost, size int64) {
+	policy.mutex.Lock()
+	defer policy.mutex.Unlock()
+	entry, found := policy.entries[key]
+	if !found {
+		entry = &costEntry{key: key}
+		policy.entries[key] = entry
+	}
+	entry.cost = float64(max(cost, 1)) / float64(max(size, 1))
+	entry.priority = policy.inflation + entry.cost
+	if found {
+		heap.Fix(&policy.queue, entry.index)
+	} else {
+		heap.Push(&policy.queue, entry)
+	}
+}
+
+func (policy *greedyDual) touch(key string) {
+	policy.mutex.Lock()
+	defer policy.mutex.Unlock()
+	if entry, found := policy.entries[key]; found {
+		entry.priority = policy.inflation + entry.cost
+		heap.Fix(&policy.queue, entry.index)
+	}
+}
+
+func (policy *greedyDual) remove(key string) {
+	policy.mutex.Lock()
+	defer policy.mutex.Unlock()
+	if entry, found := policy.entries[key]; found {
+		heap.Remove(&policy.queue, entry.index)
+		delete(policy.entries, key)
+	}
+}
+
+func (policy *greedyDual) victim() (string, bool) {
+	policy.mutex.Lock()
+	defer policy.mutex.Unlock()
+	if len(policy.queue) == 0 {
+		return "", false
+	}
+	entry := heap.Pop(&policy.queue).(*costEntry)
+	delete(policy.entries, entry.key)
+	policy.inflation = entry.priority
+	return entry.key, true
+}
+
+func (policy *greedyDual) len() int {
+	policy.mutex.Lock()
+	defer policy.mutex.Unlock()
+	return len(policy.queue)
+}
+
+// costQueue is a min-heap of costEntry by priority, implements heap.Interface
+type costQueue []*costEntry
+
+func (queue costQueue) Len() int           { return len(queue) }
+func (queue costQueue) Less(i, j int) bool { return queue[i].priority < queue[j].priority }
+
+func (queue costQueue) Swap(i, j int) {
+	queue[i], queue[j] = queue[j], queue[i]
+	queue[i].index = i
+	queue[j].index = j
+}
+
+func (queue *costQueue) Push(value any) {
+	entry := value.(*costEntry)
+	entry.index = len(*queue)
+	*queue = append(*queue, entry)
+}
+
+func (queue *costQueue) Pop() any {
+	old := *queue
+	entry := old[len(old)-1]
+	old[len(old)-1] = nil
+	*queue = old[:len(old)-1]
+	return entry
+}
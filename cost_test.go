@@ -0,0 +1,38 @@
+package cache_test
+
+import (
+	"fmt"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+func (suite *CacheSuite) TestCanEvictByCost() {
+	reports := cache.New[string]("test").WithShards(1).WithCostAwareEviction(2)
+	defer func() { _ = reports.Clear() }()
+	_ = reports.SetWithCost("expensive", 100, "expensive")
+	_ = reports.SetWithCost("cheap", 1, "cheap")
+	_, _ = reports.Get("cheap")
+	_ = reports.SetWithCost("average", 10, "average")
+
+	_, err := reports.Get("cheap")
+	suite.Assert().ErrorIs(err, errors.NotFound, "The cheapest report should have been evicted even if it was read last")
+	for _, key := range []string{"expensive", "average"} {
+		_, err = reports.Get(key)
+		suite.Assert().NoError(err, "Failed to get report %s: %+v", key, err)
+	}
+}
+
+func (suite *CacheSuite) TestShouldEventuallyEvictExpensiveItemsThatAreNotRead() {
+	reports := cache.New[string]("test").WithShards(1).WithCostAwareEviction(2)
+	defer func() { _ = reports.Clear() }()
+	_ = reports.SetWithCost("expensive", 10, "expensive")
+	for i := 0; i < 10; i++ {
+		_ = reports.SetWithCost("report", 3, fmt.Sprintf("report-%d", i))
+	}
+
+	_, err := reports.Get("expensive")
+	suite.Assert().ErrorIs(err, errors.NotFound, "The expensive report that is not read should have been evicted")
+	_, err = reports.Get("report-9")
+	suite.Assert().NoError(err, "Failed to get report: %+v", err)
+}
@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DecodeRecord decodes the content of a file persisted by a cache without encryption, compression, or transforms
+//
+// It returns the key, the item, and the expiration of the record, the expiration is zero if the item never expires.
+// Corrupted or malicious data gives an error, never a panic, so DecodeRecord can check the files
+// of a persistence folder that is fed by other tools before a cache reads them.
+func DecodeRecord[T any](data []byte) (key string, item T, expiration time.Time, err error) {
+	var entry record[T]
+
+	if data, err = unframe(data); err != nil {
+		return
+	}
+	if isRaw(data) {
+		if data, err = (&settings{}).restoreRaw(data, &entry); err != nil {
+			return
+		}
+	}
+	if data != nil {
+		if err = json.Unmarshal(data, &entry); err != nil {
+			return
+		}
+	}
+	return entry.Key, entry.Item, expirationTime(entry.Expiration), nil
+}
+
+// decryptWithKey decrypts a record encrypted with the given key and bound to the given additional data
+//
+// The records written before the key commitment are decrypted without the additional data.
+func decryptWithKey(key, data, additional []byte) ([]byte, error) {
+	if isCommitted(data) {
+		return decryptCommitted(key, data, additional)
+	}
+	return decrypt(key, data)
+}
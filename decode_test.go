@@ -0,0 +1,27 @@
+package cache_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/gildas/go-cache"
+	"github.com/google/uuid"
+)
+
+func (suite *CacheSuite) TestCanDecodePersistedRecords() {
+	items := cache.New[string]("test", cache.CacheOptionPersistent).WithExpiration(0)
+	defer func() { _ = items.Clear() }()
+	suite.Require().NoError(items.Set("value", "key"))
+	folder, _ := os.UserCacheDir()
+	data, err := os.ReadFile(filepath.Join(folder, "test", uuid.NewSHA1(uuid.Nil, []byte("key")).String()))
+	suite.Require().NoError(err, "Failed to read persisted file: %+v", err)
+
+	key, item, expiration, err := cache.DecodeRecord[string](data)
+	suite.Require().NoError(err, "Failed to decode the record: %+v", err)
+	suite.Assert().Equal("key", key)
+	suite.Assert().Equal("value", item)
+	suite.Assert().True(expiration.IsZero())
+
+	_, _, _, err = cache.DecodeRecord[string](data[:len(data)-2])
+	suite.Assert().Error(err, "Truncated records should not decode")
+}
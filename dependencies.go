@@ -0,0 +1,172 @@
+package cache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gildas/go-errors"
+)
+
+// dependentsFolder is the subfolder of the cache folder where the dependents of the keys are persisted
+const dependentsFolder = "dependents"
+
+// dependents contains the keys that depend on a key
+type dependents struct {
+	mutex   sync.Mutex
+	loaded  bool
+	removed bool // the entry was taken, see takeDependents
+	keys    map[string]struct{}
+}
+
+// SetWithDependencies sets an item in the cache that depends on other keys
+//
+// When one of the dependencies is deleted, the item is deleted too, and so on
+// for the items that depend on it. This is useful to cache objects derived from other cached objects.
+//
+// Only Delete cascades: when a dependency expires, is evicted or purged (see Purge), the items that depend on it
+// stay in the cache until they expire or are deleted themselves.
+func (cache *Cache[T]) SetWithDependencies(item T, dependencies []string, key ...string) (err error) {
+	config := cache.settings()
+	return cache.putWithDependencies(context.Background(), config, item, expirationOf(item, config.expiration), dependencies, config.keysOf(item, key)...)
+}
+
+// putWithDependencies stores an item like put and records that its keys depend on the dependencies
+//
+// The dependents of the dependencies stay locked while the item is stored,
+// so a concurrent Delete of a dependency cannot miss the item.
+func (cache *Cache[T]) putWithDependencies(ctx context.Context, config *settings, item T, expiration time.Duration, dependencies []string, key ...string) (err error) {
+	dependencies = slices.Compact(slices.Sorted(slices.Values(dependencies))) // locked in order, so concurrent calls do not deadlock
+	entries := make([]*dependents, 0, len(dependencies))
+	defer func() {
+		for _, entry := range entries {
+			entry.mutex.Unlock()
+		}
+	}()
+	for _, dependency := range dependencies {
+		entry := cache.lockDependentsOf(dependency)
+		entries = append(entries, entry)
+		if err = entry.load(config, dependency); err != nil {
+			return
+		}
+	}
+	if err = cache.putWith(ctx, config, setDefault, item, expiration, 0, TierDefault, dependencies, key...); err != nil {
+		return
+	}
+	for i, entry := range entries {
+		if err = entry.add(config, dependencies[i], key); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// add records that the given keys depend on the key of the entry
+//
+// The caller must hold the entry's mutex and have loaded it.
+func (entry *dependents) add(config *settings, dependency string, keys []string) error {
+	for _, key := range keys {
+		entry.keys[key] = struct{}{}
+	}
+	if config.persistent {
+		return config.dependents().persist(config.filekey(dependency), entry.list())
+	}
+	return nil
+}
+
+// takeDependents gets the keys that depend on the given key and forgets them
+//
+// The entry of the key is removed, so the keys that have no dependents do not accumulate entries.
+func (cache *Cache[T]) takeDependents(config *settings, key string) ([]string, error) {
+	if _, found := cache.dependents.Load(key); !found && !config.dependents().persisted(config.filekey(key)) {
+		return nil, nil
+	}
+	entry := cache.lockDependentsOf(key)
+	defer entry.mutex.Unlock()
+	if err := entry.load(config, key); err != nil {
+		return nil, err
+	}
+	keys := entry.list()
+	if config.persistent && len(keys) > 0 {
+		if err := config.dependents().erase(config.filekey(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return keys, err
+		}
+	}
+	// The file is erased first, so the next entry does not load the keys again
+	entry.removed = true
+	cache.dependents.CompareAndDelete(key, entry)
+	return keys, nil
+}
+
+// lockDependentsOf gets the dependents of the given key with their mutex held, creating them if needed
+//
+// The entries that were taken while waiting for their mutex are skipped, see takeDependents.
+func (cache *Cache[T]) lockDependentsOf(key string) *dependents {
+	for {
+		value, _ := cache.dependents.LoadOrStore(key, &dependents{keys: map[string]struct{}{}})
+		entry := value.(*dependents)
+		entry.mutex.Lock()
+		if !entry.removed {
+			return entry
+		}
+		entry.mutex.Unlock()
+	}
+}
+
+// persisted tells if the file named filekey exists in the folder of a persistent cache
+func (config *settings) persisted(filekey string) bool {
+	if !config.persistent {
+		return false
+	}
+	if _, err := os.Stat(filepath.Join(config.folder, filekey)); err == nil {
+		return true
+	}
+	_, err := os.Stat(filepath.Join(config.folder, filepath.Base(filekey))) // not moved to its fan-out subfolder yet
+	return err == nil
+}
+
+// load loads the dependents from the disk the first time they are used
+//
+// The caller must hold the entry's mutex.
+func (entry *dependents) load(config *settings, key string) error {
+	if entry.loaded {
+		return nil
+	}
+	if config.persistent {
+		var keys []string
+
+		if err := config.dependents().restore(config.filekey(key), &keys); err == nil {
+			for _, key := range keys {
+				entry.keys[key] = struct{}{}
+			}
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	entry.loaded = true
+	return nil
+}
+
+// list gets the dependent keys, sorted
+//
+// The caller must hold the entry's mutex.
+func (entry *dependents) list() []string {
+	keys := make([]string, 0, len(entry.keys))
+	for key := range entry.keys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// dependents gets the settings to persist the dependents of the keys
+func (config *settings) dependents() *settings {
+	dependents := *config
+	dependents.folder = filepath.Join(config.folder, dependentsFolder)
+	dependents.bloom = nil
+	return &dependents
+}
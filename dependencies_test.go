@@ -0,0 +1,39 @@
+package cache_test
+
+import (
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+func (suite *CacheSuite) TestCanCascadeDeletionToDependents() {
+	reports := cache.New[string]("test")
+	_ = reports.Set("sales", "sales")
+	_ = reports.Set("costs", "costs")
+	_ = reports.SetWithDependencies("margin", []string{"sales", "costs"}, "margin")
+	_ = reports.SetWithDependencies("summary", []string{"margin"}, "summary")
+	_ = reports.SetWithDependencies("forecast", []string{"costs"}, "forecast")
+
+	err := reports.Delete("sales")
+	suite.Require().NoError(err, "Failed to delete: %+v", err)
+	for _, key := range []string{"sales", "margin", "summary"} {
+		_, err = reports.Get(key)
+		suite.Assert().ErrorIs(err, errors.NotFound, "%s should have been deleted", key)
+	}
+	for _, key := range []string{"costs", "forecast"} {
+		_, err = reports.Get(key)
+		suite.Assert().NoError(err, "%s should not have been deleted", key)
+	}
+}
+
+func (suite *CacheSuite) TestCanCascadePersistedDependents() {
+	reports := cache.New[string]("test", cache.CacheOptionPersistent)
+	defer func() { _ = reports.Clear() }()
+	_ = reports.Set("sales", "sales")
+	_ = reports.SetWithDependencies("margin", []string{"sales"}, "margin")
+
+	// Another process deletes the dependency
+	err := cache.New[string]("test", cache.CacheOptionPersistent).Delete("sales")
+	suite.Require().NoError(err, "Failed to delete: %+v", err)
+	_, err = cache.New[string]("test", cache.CacheOptionPersistent).Get("margin")
+	suite.Assert().ErrorIs(err, errors.NotFound, "margin should have been deleted from the disk")
+}
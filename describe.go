@@ -0,0 +1,164 @@
+package cache
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Description is a summary of the configuration of a cache, see Describe
+//
+// The secrets are redacted: the keys are reported only by the cipher they are used with.
+type Description struct {
+	Name             string        `json:"name"`
+	Persistent       bool          `json:"persistent"`
+	Folder           string        `json:"folder,omitempty"`
+	FolderError      string        `json:"folderError,omitempty"`
+	Codec            string        `json:"codec"`
+	Compression      string        `json:"compression,omitempty"`
+	Transforms       bool          `json:"transforms,omitempty"`
+	Cipher           string        `json:"cipher,omitempty"`
+	Signature        string        `json:"signature,omitempty"`
+	MemoryCipher     string        `json:"memoryCipher,omitempty"`
+	Policy           string        `json:"policy,omitempty"`
+	Capacity         int           `json:"capacity,omitempty"`
+	MaxMemory        int64         `json:"maxMemory,omitempty"`
+	Shards           int           `json:"shards"`
+	ArenaSize        int           `json:"arenaSize,omitempty"`
+	SpillThreshold   uint64        `json:"spillThreshold,omitempty"`
+	Expiration       time.Duration `json:"expiration"`
+	MaxStale         time.Duration `json:"maxStale,omitempty"`
+	Durability       string        `json:"durability"`
+	Consistency      string        `json:"consistency"`
+	ExpirationMode   string        `json:"expirationMode"`
+	ExpirationEngine string        `json:"expirationEngine"`
+	SchemaVersion    int           `json:"schemaVersion,omitempty"`
+}
+
+// Describe gets a summary of the configuration of the cache, meant to be logged when the application starts
+//
+// It shows the mistakes that are silent otherwise, like a cache that is not persistent,
+// a cache that has no writable folder (see CacheFolder), or a cache that is not encrypted.
+func (cache *Cache[T]) Describe() Description {
+	config := cache.settings()
+	storage := cache.storage()
+	description := Description{
+		Name:             cache.Name,
+		Persistent:       config.persistent,
+		Codec:            "json",
+		Transforms:       config.storeTransform != nil || config.loadTransform != nil,
+		Capacity:         config.capacity,
+		MaxMemory:        config.maxMemory,
+		Shards:           len(storage.shards),
+		ArenaSize:        config.arenaSize,
+		Expiration:       config.expiration,
+		MaxStale:         config.maxStale,
+		Durability:       config.durability.String(),
+		Consistency:      config.consistency().String(),
+		ExpirationMode:   config.expirationMode.String(),
+		ExpirationEngine: config.expirationEngine.String(),
+		SchemaVersion:    config.schemaVersion,
+	}
+	if config.persistent {
+		description.Folder = config.folder
+		if config.folderError != nil {
+			description.FolderError = config.folderError.Error()
+		}
+	}
+	if _, ok := any(new(T)).(*[]byte); ok {
+		description.Codec = "raw"
+	}
+	if config.compressor != nil {
+		description.Compression = "zstd"
+	}
+	if config.encryptionKey != nil {
+		description.Cipher = describeCipher(config.encryptionKey, "AES-%d-GCM, HKDF-SHA256 key commitment")
+	}
+	if config.signingKey != nil {
+		description.Signature = "HMAC-SHA256"
+	}
+	if config.sealer != nil {
+		description.MemoryCipher = describeCipher(config.sealer.key, "AES-%d-GCM")
+	}
+	if config.spiller != nil {
+		description.SpillThreshold = config.spiller.threshold
+	}
+	if len(storage.shards) > 0 {
+		if eviction := storage.shards[0].eviction.Load(); eviction != nil {
+			description.Policy = policyName(eviction.policy)
+		}
+	}
+	return description
+}
+
+// String gets the description on a single line, e.g. for a log
+func (description Description) String() string {
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, "cache %s:", description.Name)
+	if description.Persistent {
+		fmt.Fprintf(&builder, " persistent in %s", description.Folder)
+		if len(description.FolderError) > 0 {
+			fmt.Fprintf(&builder, " (%s)", description.FolderError)
+		}
+	} else {
+		builder.WriteString(" in memory only")
+	}
+	fmt.Fprintf(&builder, ", codec %s", description.Codec)
+	if len(description.Compression) > 0 {
+		fmt.Fprintf(&builder, ", compression %s", description.Compression)
+	}
+	if len(description.Cipher) > 0 {
+		fmt.Fprintf(&builder, ", cipher %s", description.Cipher)
+	} else if description.Persistent {
+		builder.WriteString(", not encrypted")
+	}
+	if len(description.Signature) > 0 {
+		fmt.Fprintf(&builder, ", signature %s", description.Signature)
+	}
+	if len(description.MemoryCipher) > 0 {
+		fmt.Fprintf(&builder, ", memory cipher %s", description.MemoryCipher)
+	}
+	if len(description.Policy) > 0 {
+		fmt.Fprintf(&builder, ", policy %s", description.Policy)
+	}
+	if description.Capacity > 0 {
+		fmt.Fprintf(&builder, ", capacity %d", description.Capacity)
+	}
+	if description.MaxMemory > 0 {
+		fmt.Fprintf(&builder, ", max memory %d", description.MaxMemory)
+	}
+	if description.SpillThreshold > 0 {
+		fmt.Fprintf(&builder, ", spill above %d", description.SpillThreshold)
+	}
+	fmt.Fprintf(&builder, ", %d shards, expiration %s, durability %s", description.Shards, description.Expiration, description.Durability)
+	return builder.String()
+}
+
+// describeCipher gets the name of the cipher used with the given key, format gets the size of the key in bits
+func describeCipher(key *secret, format string) (name string) {
+	err := key.use(func(key []byte) error {
+		name = fmt.Sprintf(format, len(key)*8)
+		return nil
+	})
+	if err != nil {
+		return "destroyed key"
+	}
+	return
+}
+
+// policyName gets the name of an eviction policy
+func policyName(policy policy) string {
+	switch policy.(type) {
+	case *lru:
+		return "lru"
+	case *slru:
+		return "slru"
+	case *clock:
+		return "clock"
+	case *greedyDual:
+		return "greedy-dual"
+	default:
+		return fmt.Sprintf("%T", policy)
+	}
+}
@@ -0,0 +1,43 @@
+package cache_test
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gildas/go-cache"
+)
+
+func (suite *CacheSuite) TestCanDescribeCache() {
+	users := cache.New[User]("test", cache.CacheOptionPersistent).
+		WithEncryptionKey([]byte("0123456789abcdef0123456789abcdef")).
+		WithSigningKey([]byte("signing key")).
+		WithSegmentedLRU(100, 0.8).
+		WithShards(4).
+		WithExpiration(time.Minute)
+	description := users.Describe()
+	suite.Assert().Equal("test", description.Name)
+	suite.Assert().True(description.Persistent)
+	suite.Assert().NotEmpty(description.Folder)
+	suite.Assert().Empty(description.FolderError)
+	suite.Assert().Equal("json", description.Codec)
+	suite.Assert().Equal("AES-256-GCM, HKDF-SHA256 key commitment", description.Cipher)
+	suite.Assert().Equal("HMAC-SHA256", description.Signature)
+	suite.Assert().Equal("slru", description.Policy)
+	suite.Assert().Equal(100, description.Capacity)
+	suite.Assert().Equal(4, description.Shards)
+	suite.Assert().Equal(time.Minute, description.Expiration)
+	suite.Assert().Contains(description.String(), "cipher AES-256-GCM")
+
+	payload, err := json.Marshal(description)
+	suite.Require().NoError(err, "Failed to marshal the description: %+v", err)
+	suite.Assert().NotContains(string(payload), "0123456789abcdef", "The key should not be in the description")
+}
+
+func (suite *CacheSuite) TestCanDescribeMemoryOnlyCache() {
+	description := cache.NewBytes("test").Describe()
+	suite.Assert().False(description.Persistent)
+	suite.Assert().Empty(description.Folder)
+	suite.Assert().Equal("raw", description.Codec)
+	suite.Assert().Empty(description.Policy)
+	suite.Assert().Contains(description.String(), "in memory only")
+}
@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// dryRunSamples is the maximum number of keys given in a DryRun
+const dryRunSamples = 20
+
+// DryRun tells what a destructive operation would remove, see ClearDryRun and PurgeDryRun
+type DryRun struct {
+	// Items is the number of items that would be removed, in memory or on the disk
+	Items int `json:"items"`
+	// Files is the number of files that would be removed or rewritten
+	Files int `json:"files"`
+	// Bytes is the size of these files
+	Bytes int64 `json:"bytes"`
+	// Keys is a sample of the keys that would be removed
+	Keys  []string `json:"keys"`
+	files map[string]bool
+}
+
+// newDryRun creates an empty DryRun
+func newDryRun() *DryRun {
+	return &DryRun{Keys: []string{}, files: map[string]bool{}}
+}
+
+// add counts an item with its key, the key is sampled if it is not empty
+func (report *DryRun) add(key string) {
+	report.Items++
+	if len(key) > 0 && len(report.Keys) < dryRunSamples {
+		report.Keys = append(report.Keys, key)
+	}
+}
+
+// addFile counts the file at path once, if it exists
+func (report *DryRun) addFile(path string) {
+	if report.files[path] {
+		return
+	}
+	report.files[path] = true
+	if info, err := os.Stat(path); err == nil {
+		report.Files++
+		report.Bytes += info.Size()
+	}
+}
+
+// seen tells if the file at path was already counted
+func (report *DryRun) seen(path string) bool {
+	return report.files[path]
+}
+
+// ClearDryRun reports what ClearContext would remove, without removing anything
+//
+// The items in memory are counted with their keys. The persisted files are all counted, and those that hold
+// an item that is not in memory are counted as one item each: their keys are not read.
+func (cache *Cache[T]) ClearDryRun(ctx context.Context) (report DryRun, err error) {
+	dryRun := newDryRun()
+	err = cache.clearDryRun(ctx, dryRun)
+	return *dryRun, err
+}
+
+// clearDryRun adds what ClearContext would remove to the report
+func (cache *Cache[T]) clearDryRun(ctx context.Context, report *DryRun) (err error) {
+	config := cache.settings()
+	if err = ctx.Err(); err != nil {
+		return
+	}
+	cache.tenants.Range(func(id, view interface{}) bool {
+		err = view.(*Cache[T]).clearDryRun(ctx, report)
+		return err == nil
+	})
+	if err != nil {
+		return
+	}
+	cache.storage().each(func(key string, entry record[T]) bool {
+		report.add(key)
+		if config.persistent {
+			report.addFile(filepath.Join(config.folder, config.filekey(key)))
+		}
+		return true
+	})
+	cache.sets.Range(func(key, value interface{}) bool {
+		entry := value.(*set[T])
+		entry.mutex.Lock()
+		defer entry.mutex.Unlock()
+		for range entry.records {
+			report.add(key.(string))
+		}
+		if config.persistent {
+			report.addFile(filepath.Join(config.folder, setFilekey(key.(string))))
+		}
+		return true
+	})
+	if !config.persistent {
+		return
+	}
+	internal := internalFiles(config)
+	err = filepath.WalkDir(config.folder, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		if report.seen(path) {
+			return nil
+		}
+		report.addFile(path)
+		relative, _ := filepath.Rel(config.folder, path)
+		if folder := strings.Split(relative, string(filepath.Separator))[0]; folder == blobsFolder || folder == dependentsFolder || folder == leasesFolder || folder == quarantineFolder {
+			return nil
+		}
+		if !internal[entry.Name()] && !strings.HasPrefix(entry.Name(), ".tmp-") {
+			report.add("")
+		}
+		return nil
+	})
+	if os.IsNotExist(err) {
+		err = nil
+	}
+	return
+}
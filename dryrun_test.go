@@ -0,0 +1,66 @@
+package cache_test
+
+import (
+	"context"
+
+	"github.com/gildas/go-cache"
+	"github.com/google/uuid"
+)
+
+func (suite *CacheSuite) TestCanDryRunPurge() {
+	users := cache.New[User]("test", cache.CacheOptionPersistent)
+	defer func() { _ = users.Clear() }()
+	joe := User{ID: uuid.New(), Name: "Joe"}
+	jane := User{ID: uuid.New(), Name: "Jane"}
+	_ = users.Set(joe)
+	_ = users.Set(jane)
+	_ = users.Add("friends", joe)
+	_ = users.Add("friends", jane)
+	_ = users.ForTenant("acme").Set(joe, "me")
+
+	report, err := users.PurgeDryRun(func(key string, item User) bool { return item.ID == joe.ID })
+	suite.Require().NoError(err, "Failed to dry run the purge: %+v", err)
+	suite.Assert().Equal(6, report.Items, "Joe's ID and Name keys, the friend, and the 3 keys of the tenant item should be reported")
+	suite.Assert().Equal(6, report.Files)
+	suite.Assert().Positive(report.Bytes)
+	suite.Assert().Contains(report.Keys, "Joe")
+	suite.Assert().Contains(report.Keys, "me")
+
+	value, err := users.Get("Joe")
+	suite.Require().NoError(err, "The dry run should not remove anything: %+v", err)
+	suite.Assert().Equal(joe.ID, value.ID)
+
+	reloaded := cache.New[User]("test", cache.CacheOptionPersistent)
+	report, err = reloaded.PurgeDryRun(func(key string, item User) bool { return item.ID == joe.ID })
+	suite.Require().NoError(err, "Failed to dry run the purge: %+v", err)
+	suite.Assert().Equal(6, report.Items, "The persisted items should be reported too")
+
+	count, err := users.Purge(func(key string, item User) bool { return item.ID == joe.ID })
+	suite.Require().NoError(err, "Failed to purge: %+v", err)
+	suite.Assert().Equal(report.Items, count)
+}
+
+func (suite *CacheSuite) TestCanDryRunClear() {
+	names := cache.New[string]("test", cache.CacheOptionPersistent)
+	defer func() { _ = names.Clear() }()
+	for _, key := range []string{"key1", "key2", "key3"} {
+		suite.Require().NoError(names.Set("value", key))
+	}
+	_ = names.Add("set", "value1")
+
+	report, err := names.ClearDryRun(context.Background())
+	suite.Require().NoError(err, "Failed to dry run the clear: %+v", err)
+	suite.Assert().Equal(4, report.Items)
+	suite.Assert().Equal(4, report.Files)
+	suite.Assert().ElementsMatch([]string{"key1", "key2", "key3", "set"}, report.Keys)
+
+	reloaded := cache.New[string]("test", cache.CacheOptionPersistent)
+	report, err = reloaded.ClearDryRun(context.Background())
+	suite.Require().NoError(err, "Failed to dry run the clear: %+v", err)
+	suite.Assert().Equal(4, report.Items, "The persisted items should be counted")
+	suite.Assert().Empty(report.Keys, "The keys of the persisted items are not read")
+
+	value, err := names.Get("key1")
+	suite.Require().NoError(err, "The dry run should not remove anything: %+v", err)
+	suite.Assert().Equal("value", *value)
+}
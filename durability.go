@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// Durability tells how persisted writes reach the disk
+type Durability int
+
+const (
+	// Lazy writes the files and lets the operating system flush them to the disk when it wants
+	//
+	// This is the fastest, but recent writes can be lost or truncated if the machine crashes.
+	Lazy Durability = iota
+	// Strict writes each file in a temporary file, flushes it to the disk, renames it, and flushes its folder
+	//
+	// When a write returns, the data survives a crash, and a file is never seen partially written.
+	Strict
+)
+
+// String gets the name of the Durability
+//
+// implements fmt.Stringer
+func (durability Durability) String() string {
+	switch durability {
+	case Strict:
+		return "strict"
+	default:
+		return "lazy"
+	}
+}
+
+// WithDurability sets how persisted writes reach the disk
+//
+// The default is Lazy.
+func (cache *Cache[T]) WithDurability(durability Durability) *Cache[T] {
+	return cache.configure(func(config *settings) {
+		config.durability = durability
+	})
+}
+
+// writeFile writes data in the file as required by the durability of the cache
+func (config *settings) writeFile(filename string, data []byte) (err error) {
+	if config.durability != Strict {
+		defer config.acquireIO()()
+		return os.WriteFile(filename, data, 0600)
+	}
+	return config.writeAtomic(filename, func(writer io.Writer) error {
+		_, err := writer.Write(data)
+		return err
+	})
+}
+
+// writeAtomic writes a temporary file with the given function and renames it to filename
+//
+// The file is never seen partially written. With the Strict durability, it is also flushed to the disk.
+func (config *settings) writeAtomic(filename string, write func(writer io.Writer) error) (err error) {
+	var file *os.File
+
+	defer config.acquireIO()()
+	folder := filepath.Dir(filename)
+	if file, err = os.CreateTemp(folder, ".tmp-*"); err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(file.Name())
+		}
+	}()
+	if err = write(file); err == nil && config.durability == Strict {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return
+	}
+	if err = os.Chmod(file.Name(), 0600); err != nil {
+		return
+	}
+	if err = os.Rename(file.Name(), filename); err != nil {
+		return
+	}
+	if config.durability == Strict {
+		return syncFolder(folder)
+	}
+	return nil
+}
+
+// syncFolder flushes the entries of a folder to the disk
+//
+// Folders cannot be flushed on Windows, where renames are durable once they return.
+func syncFolder(folder string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	directory, err := os.Open(folder)
+	if err != nil {
+		return err
+	}
+	defer directory.Close()
+	return directory.Sync()
+}
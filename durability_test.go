@@ -0,0 +1,32 @@
+package cache_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/gildas/go-cache"
+)
+
+func (suite *CacheSuite) TestCanCacheStuffWithStrictDurability() {
+	firstCache := cache.New[User]("test", cache.CacheOptionPersistent).WithDurability(cache.Strict)
+	defer func() { _ = firstCache.Clear() }()
+	user := User{ID: uuid.New(), Name: "Joe"}
+	err := firstCache.Set(user)
+	suite.Require().NoError(err, "Failed to set cached user: %+v", err)
+
+	secondCache := cache.New[User]("test", cache.CacheOptionPersistent)
+	cached, err := secondCache.Get(user.GetID().String())
+	suite.Require().NoError(err, "Failed to get cached user: %+v", err)
+	suite.Assert().Equal(user, *cached, "User and Cached User are different")
+
+	folder, _ := os.UserCacheDir()
+	entries, err := os.ReadDir(filepath.Join(folder, "test"))
+	suite.Require().NoError(err, "Failed to read the cache folder: %+v", err)
+	for _, entry := range entries {
+		suite.Assert().False(strings.HasPrefix(entry.Name(), ".tmp-"), "Temporary file %s was left behind", entry.Name())
+	}
+	suite.Assert().Equal("strict", cache.Strict.String())
+}
@@ -0,0 +1,46 @@
+package cache
+
+const (
+	// OperationExpire is the operation of the errors of the janitor when it removes expired items
+	OperationExpire = "expire"
+	// OperationWriteBehind is the operation of the errors of the write-behind goroutine
+	OperationWriteBehind = "write-behind"
+	// OperationCoalesce is the operation of the errors of the writes at the end of their coalescing window
+	OperationCoalesce = "coalesce"
+	// OperationRestore is the operation of the errors of the corrupted files found when reading the persisted items
+	OperationRestore = "restore"
+	// OperationPrefetch is the operation of the errors of the loader when it prefetches keys
+	OperationPrefetch = "prefetch"
+	// OperationWarm is the operation of the errors of the loader when it warms the cache, see Warm
+	OperationWarm = "warm"
+	// OperationFetch is the operation of the errors of a Fetcher when it caches a fetched resource
+	OperationFetch = "fetch"
+	// OperationCompute is the operation of the errors of GetOrCompute when it stores a computed item
+	OperationCompute = "compute"
+	// OperationMemoize is the operation of the errors of the memoized functions when they store a result, see Memoize
+	OperationMemoize = "memoize"
+)
+
+// WithErrorHandler sets the function called with the errors of the background operations
+//
+// The background operations are the janitor (OperationExpire), the write-behind goroutine (OperationWriteBehind),
+// the coalesced writes (OperationCoalesce), the prefetches (OperationPrefetch), the loads of Warm (OperationWarm),
+// the resources a Fetcher could not cache (OperationFetch, with the URL as the key), the computed items
+// GetOrCompute could not store (OperationCompute), the results memoized functions could not store (OperationMemoize),
+// and the recovery of the corrupted files (OperationRestore,
+// with the file name as the key).
+// Without a handler, these errors are ignored.
+//
+// The handler is called from the goroutine of the operation, it should not block.
+func (cache *Cache[T]) WithErrorHandler(handler func(operation string, key string, err error)) *Cache[T] {
+	return cache.configure(func(config *settings) {
+		config.errorHandler = handler
+	})
+}
+
+// reportError gives an error of a background operation to the error handler, if any
+func (config *settings) reportError(operation string, key string, err error) {
+	if err != nil && config.errorHandler != nil {
+		config.errorHandler(operation, key, err)
+	}
+}
@@ -0,0 +1,65 @@
+package cache_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+type asyncError struct {
+	operation string
+	key       string
+	err       error
+}
+
+func (suite *CacheSuite) TestCanHandleWriteBehindErrors() {
+	failures := make(chan asyncError, 1)
+	items := cache.New[string]("test", cache.CacheOptionPersistent).WithStoreTransform(func(data []byte) ([]byte, error) {
+		return nil, errors.NotImplemented.WithStack()
+	}).WithErrorHandler(func(operation, key string, err error) {
+		failures <- asyncError{operation, key, err}
+	}).WithWriteBehind(10, cache.BackpressureBlock)
+	defer func() { _ = items.Clear() }()
+	defer items.Close()
+
+	err := items.Set("value", "key")
+	suite.Require().NoError(err, "The write should fail in the background")
+	select {
+	case failure := <-failures:
+		suite.Assert().Equal(cache.OperationWriteBehind, failure.operation)
+		suite.Assert().Equal("key", failure.key)
+		suite.Assert().ErrorIs(failure.err, errors.NotImplemented)
+	case <-time.After(time.Second):
+		suite.Fail("The error handler should have been called")
+	}
+}
+
+func (suite *CacheSuite) TestCanHandlePrefetchErrors() {
+	failures := make(chan asyncError, 1)
+	items := cache.New[string]("test").WithLoader(func(ctx context.Context, key string) (string, error) {
+		if key == "related" {
+			return "", errors.HTTPServiceUnavailable.WithStack()
+		}
+		return "value", nil
+	}).WithPrefetcher(func(missedKey string) []string {
+		if missedKey == "key" {
+			return []string{"related"}
+		}
+		return nil
+	}).WithErrorHandler(func(operation, key string, err error) {
+		failures <- asyncError{operation, key, err}
+	})
+
+	_, err := items.Load(context.Background(), "key")
+	suite.Require().NoError(err, "Failed to load: %+v", err)
+	select {
+	case failure := <-failures:
+		suite.Assert().Equal(cache.OperationPrefetch, failure.operation)
+		suite.Assert().Equal("related", failure.key)
+		suite.Assert().ErrorIs(failure.err, errors.HTTPServiceUnavailable)
+	case <-time.After(time.Second):
+		suite.Fail("The error handler should have been called")
+	}
+}
@@ -0,0 +1,129 @@
+package cache
+
+import (
+	"net/http"
+
+	"github.com/gildas/go-errors"
+)
+
+// ErrComputePanic is returned to all callers waiting for a computation that panicked
+//
+// Its What is the key being computed and its Value is the value given to panic.
+var ErrComputePanic = errors.NewSentinel(http.StatusInternalServerError, "error.cache.compute.panic", "Computation of %s panicked: %v")
+
+// ErrCircuitOpen is returned by GetOrCompute when the circuit breaker is open
+//
+// Its What is the key that could not be computed.
+var ErrCircuitOpen = errors.NewSentinel(http.StatusServiceUnavailable, "error.cache.circuit.open", "Circuit is open, cannot compute %s")
+
+// ErrInvalidItem is returned by Set and Add when the validator rejects an item
+//
+// Its Cause is the error returned by the validator.
+var ErrInvalidItem = errors.NewSentinel(http.StatusBadRequest, "error.cache.item.invalid", "Item is invalid")
+
+// ErrNotPersistent is returned by the operations that need a persistent cache
+//
+// Its What is the name of the cache.
+var ErrNotPersistent = errors.NewSentinel(http.StatusBadRequest, "error.cache.persistent.missing", "Cache %s is not persistent")
+
+// ErrIdempotencyPending is returned by IdempotencyStore.Do when the request is still being processed
+//
+// Its What is the idempotency key.
+var ErrIdempotencyPending = errors.NewSentinel(http.StatusConflict, "error.cache.idempotency.pending", "Request %s is still in progress")
+
+// ErrIdempotencyFailed is returned by IdempotencyStore.Do when the request had failed
+//
+// Its What is the idempotency key and its Value is the message of the original error.
+var ErrIdempotencyFailed = errors.NewSentinel(http.StatusUnprocessableEntity, "error.cache.idempotency.failed", "Request %s failed: %v")
+
+// ErrChecksumMismatch is returned when the data of a Blob does not match its checksum
+//
+// Its What is the expected checksum and its Value is the actual checksum.
+var ErrChecksumMismatch = errors.NewSentinel(http.StatusUnprocessableEntity, "error.cache.checksum.mismatch", "Checksum %s does not match %v")
+
+// ErrKeyNotPersisted is returned by Set when the item could not be persisted under a key
+//
+// Its What is the key and its Cause is the persistence error. When several keys fail, they are returned in an errors.MultiError.
+var ErrKeyNotPersisted = errors.NewSentinel(http.StatusInternalServerError, "error.cache.key.persist", "Failed to persist key %s")
+
+// keyNotPersisted gets the ErrKeyNotPersisted of a key caused by err
+func keyNotPersisted(key string, err error) error {
+	failure := ErrKeyNotPersisted.With(key).(errors.Error)
+	return failure.Wrap(err)
+}
+
+// ErrEmptyKey is returned by Set and Add when a key is empty
+//
+// It wraps an errors.ArgumentMissing.
+var ErrEmptyKey = errors.NewSentinel(http.StatusBadRequest, "error.cache.key.empty", "Key is empty")
+
+// ErrNilID is returned by Set when the ID of an item is the nil UUID, see WithNilIDRejection
+var ErrNilID = errors.NewSentinel(http.StatusBadRequest, "error.cache.id.nil", "Item has a nil ID")
+
+// ErrKeyCollision is returned by Set when a derived key is held by an item that derived it from another source, see WithKeyCollisionDetection
+//
+// Its What is the key and its Value is the source of the cached item ("id" or "name").
+var ErrKeyCollision = errors.NewSentinel(http.StatusConflict, "error.cache.key.collision", "Key %s is already the %v of another item")
+
+// ErrImmutable is returned by Set when a key holds an item set with SetImmutable
+//
+// Its What is the key.
+var ErrImmutable = errors.NewSentinel(http.StatusConflict, "error.cache.key.immutable", "Key %s holds an immutable item")
+
+// ErrLeaseHeld is returned by AcquireLease when another caller holds the lease of a key
+//
+// Its What is the key.
+var ErrLeaseHeld = errors.NewSentinel(http.StatusConflict, "error.cache.lease.held", "Lease of %s is held by another caller")
+
+// ErrMigrationFailed is returned when a persisted item cannot be migrated to the current schema version, see WithMigration
+//
+// Its Value is the version the migration failed from, its Cause is the error of the migration if any.
+var ErrMigrationFailed = errors.NewSentinel(http.StatusInternalServerError, "error.cache.migration.failed", "Failed to migrate item from %s %v")
+
+// ErrCorrupted is returned when a persisted item is truncated, was partially written, or does not match its checksum
+//
+// Its What is the name of the file and its Cause tells what is wrong. The corrupted item is treated as missing,
+// Get returns an errors.NotFound that wraps the ErrCorrupted.
+var ErrCorrupted = errors.NewSentinel(http.StatusInternalServerError, "error.cache.corrupted", "Persisted item %s is corrupted")
+
+// ErrInvalidDictionary is returned when a compression dictionary cannot be trained or used, see WithCompression
+//
+// Its Cause is the error of the compression library.
+var ErrInvalidDictionary = errors.NewSentinel(http.StatusBadRequest, "error.cache.dictionary.invalid", "Invalid compression dictionary")
+
+// ErrKeyDestroyed is returned when the encryption key is needed after the cache was closed, see Close
+var ErrKeyDestroyed = errors.NewSentinel(http.StatusGone, "error.cache.key.destroyed", "Encryption key was destroyed")
+
+// ErrNoMemoryKey is returned by Set when the key of the memory encryption could not be read, see WithMemoryEncryption
+//
+// Its Cause is the error of the random reader.
+var ErrNoMemoryKey = errors.NewSentinel(http.StatusInternalServerError, "error.cache.memorykey.missing", "Memory encryption key could not be read")
+
+// ErrTampered is returned when a persisted item does not match its signature, see WithSigningKey
+//
+// Its What is the name of the file.
+var ErrTampered = errors.NewSentinel(http.StatusUnprocessableEntity, "error.cache.tampered", "Persisted item %s was tampered with")
+
+// ErrNoCacheFolder is returned when a persistent cache has no writable folder, see CacheFolder
+//
+// Its What is the list of the folders that were tried.
+var ErrNoCacheFolder = errors.NewSentinel(http.StatusInsufficientStorage, "error.cache.folder.missing", "No writable cache folder in %s")
+
+// ErrLegacyEncryption is returned when an item or a blob was encrypted before the key commitment, see WithLegacyDecryption
+//
+// Its What is the name of the file.
+var ErrLegacyEncryption = errors.NewSentinel(http.StatusUnprocessableEntity, "error.cache.encryption.legacy", "Persisted file %s was encrypted before the key commitment")
+
+// notFound gets the errors.NotFound of a key, caused by err if it is not nil
+func notFound(key string, err error) error {
+	missing := errors.NotFound.With("key", key).(errors.Error)
+	if err != nil {
+		return missing.Wrap(err)
+	}
+	return missing
+}
+
+// emptyKey gets the error of an empty key
+func emptyKey() error {
+	return ErrEmptyKey.Wrap(errors.ArgumentMissing.With("key"))
+}
@@ -0,0 +1,254 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// policy decides which entry to evict when a cache reaches its capacity
+type policy interface {
+	// add tells the policy a key was stored
+	add(key string)
+	// touch tells the policy a key was read, unknown keys are ignored as only add inserts keys
+	touch(key string)
+	// remove tells the policy a key was removed
+	remove(key string)
+	// victim removes from the policy the key to evict next
+	victim() (key string, ok bool)
+	// len gets the number of keys known to the policy
+	len() int
+}
+
+// lru is a Least Recently Used eviction policy
+type lru struct {
+	mutex    sync.Mutex
+	elements map[string]*list.Element
+	entries  *list.List
+}
+
+// newLRU creates a new LRU eviction policy
+func newLRU() *lru {
+	return &lru{elements: map[string]*list.Element{}, entries: list.New()}
+}
+
+func (policy *lru) add(key string) {
+	policy.mutex.Lock()
+	defer policy.mutex.Unlock()
+	if element, found := policy.elements[key]; found {
+		policy.entries.MoveToFront(element)
+		return
+	}
+	policy.elements[key] = policy.entries.PushFront(key)
+}
+
+func (policy *lru) touch(key string) {
+	policy.mutex.Lock()
+	defer policy.mutex.Unlock()
+	if element, found := policy.elements[key]; found {
+		policy.entries.MoveToFront(element)
+	}
+}
+
+func (policy *lru) remove(key string) {
+	policy.mutex.Lock()
+	defer policy.mutex.Unlock()
+	if element, found := policy.elements[key]; found {
+		policy.entries.Remove(element)
+		delete(policy.elements, key)
+	}
+}
+
+func (policy *lru) victim() (string, bool) {
+	policy.mutex.Lock()
+	defer policy.mutex.Unlock()
+	if element := policy.entries.Back(); element != nil {
+		key := policy.entries.Remove(element).(string)
+		delete(policy.elements, key)
+		return key, true
+	}
+	return "", false
+}
+
+func (policy *lru) len() int {
+	policy.mutex.Lock()
+	defer policy.mutex.Unlock()
+	return policy.entries.Len()
+}
+
+// slru is a Segmented Least Recently Used eviction policy
+//
+// New keys go to the probation segment, keys that are read again are promoted
+// to the protected segment. Victims are taken from the probation segment first,
+// so a scan of one-off keys cannot evict the keys that are frequently read.
+type slru struct {
+	mutex         sync.Mutex
+	elements      map[string]*list.Element
+	protected     map[string]bool
+	probation     *list.List
+	protectedList *list.List
+	protectedSize int
+}
+
+// newSLRU creates a new Segmented LRU eviction policy
+//
+// protectedSize is the maximum number of keys in the protected segment.
+func newSLRU(protectedSize int) *slru {
+	return &slru{
+		elements:      map[string]*list.Element{},
+		protected:     map[string]bool{},
+		probation:     list.New(),
+		protectedList: list.New(),
+		protectedSize: protectedSize,
+	}
+}
+
+func (policy *slru) add(key string) {
+	policy.mutex.Lock()
+	defer policy.mutex.Unlock()
+	if _, found := policy.elements[key]; found {
+		policy.promote(key)
+		return
+	}
+	policy.elements[key] = policy.probation.PushFront(key)
+}
+
+func (policy *slru) touch(key string) {
+	policy.mutex.Lock()
+	defer policy.mutex.Unlock()
+	if _, found := policy.elements[key]; found {
+		policy.promote(key)
+	}
+}
+
+// promote moves the key at the front of the protected segment
+//
+// If the protected segment is full, its least recently used key is demoted to the probation segment.
+// The caller must hold the mutex.
+func (policy *slru) promote(key string) {
+	element := policy.elements[key]
+	if policy.protected[key] {
+		policy.protectedList.MoveToFront(element)
+		return
+	}
+	if policy.protectedSize <= 0 {
+		policy.probation.MoveToFront(element)
+		return
+	}
+	policy.probation.Remove(element)
+	policy.elements[key] = policy.protectedList.PushFront(key)
+	policy.protected[key] = true
+	if policy.protectedList.Len() > policy.protectedSize {
+		demoted := policy.protectedList.Remove(policy.protectedList.Back()).(string)
+		delete(policy.protected, demoted)
+		policy.elements[demoted] = policy.probation.PushFront(demoted)
+	}
+}
+
+func (policy *slru) remove(key string) {
+	policy.mutex.Lock()
+	defer policy.mutex.Unlock()
+	if element, found := policy.elements[key]; found {
+		if policy.protected[key] {
+			policy.protectedList.Remove(element)
+			delete(policy.protected, key)
+		} else {
+			policy.probation.Remove(element)
+		}
+		delete(policy.elements, key)
+	}
+}
+
+func (policy *slru) victim() (string, bool) {
+	policy.mutex.Lock()
+	defer policy.mutex.Unlock()
+	segment := policy.probation
+	if segment.Len() == 0 {
+		segment = policy.protectedList
+	}
+	if element := segment.Back(); element != nil {
+		key := segment.Remove(element).(string)
+		delete(policy.elements, key)
+		delete(policy.protected, key)
+		return key, true
+	}
+	return "", false
+}
+
+func (policy *slru) len() int {
+	policy.mutex.Lock()
+	defer policy.mutex.Unlock()
+	return len(policy.elements)
+}
+
+// WithCapacity sets the maximum number of keys the cache keeps in memory
+//
+// When the capacity is reached, the Least Recently Used keys are evicted.
+// Evicted keys of a persistent cache are still on the disk and are reloaded on the next Get.
+//
+// A capacity of 0 means the cache is unbounded.
+func (cache *Cache[T]) WithCapacity(capacity int) *Cache[T] {
+	return cache.withPolicy(capacity, func(capacity int) policy {
+		return newLRU()
+	})
+}
+
+// WithSegmentedLRU sets the maximum number of keys the cache keeps in memory
+// and evicts them with a Segmented LRU policy
+//
+// Keys that are read at least twice move to a protected segment, which holds
+// protectedRatio of the capacity (e.g. 0.8). Keys are evicted from the probation
+// segment first, so a scan of keys that are read only once does not evict the
+// keys that are used frequently.
+func (cache *Cache[T]) WithSegmentedLRU(capacity int, protectedRatio float64) *Cache[T] {
+	protectedRatio = min(max(protectedRatio, 0), 1)
+	return cache.withPolicy(capacity, func(capacity int) policy {
+		return newSLRU(int(float64(capacity) * protectedRatio))
+	})
+}
+
+// WithClockEviction sets the maximum number of keys the cache keeps in memory
+// and evicts them with a CLOCK (second-chance) policy
+//
+// CLOCK approximates LRU: reading a key only marks it as referenced, it does not move the key in a list.
+// This costs less than LRU for caches that are read much more often than they are written.
+func (cache *Cache[T]) WithClockEviction(capacity int) *Cache[T] {
+	return cache.withPolicy(capacity, func(capacity int) policy {
+		return newClock()
+	})
+}
+
+// WithWatermarks evicts the keys in batches, in the background, between a high and a low watermark
+//
+// When a shard goes above high (e.g. 0.95) of its capacity or memory budget, keys are evicted
+// until it is below low (e.g. 0.8), off the hot path of Set.
+// The capacity and the memory budget are still enforced by Set if the eviction cannot keep up.
+//
+// The watermarks are fractions with 0 < low < high <= 1, other values turn the watermarks off.
+func (cache *Cache[T]) WithWatermarks(high, low float64) *Cache[T] {
+	cache.configure(func(config *settings) {
+		if low <= 0 || low >= high || high > 1 {
+			high, low = 0, 0
+		}
+		config.highWatermark = high
+		config.lowWatermark = low
+	})
+	cache.rebuild()
+	return cache
+}
+
+// withPolicy sets the capacity and the eviction policy of the cache
+//
+// The keys currently in memory are given to the new policy and evicted if needed.
+func (cache *Cache[T]) withPolicy(capacity int, newPolicy func(capacity int) policy) *Cache[T] {
+	cache.configure(func(config *settings) {
+		if capacity <= 0 {
+			config.capacity = 0
+			config.newPolicy = nil
+			return
+		}
+		config.capacity = capacity
+		config.newPolicy = newPolicy
+	})
+	cache.rebuild()
+	return cache
+}
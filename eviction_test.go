@@ -0,0 +1,102 @@
+package cache_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+func (suite *CacheSuite) TestCanEvictLeastRecentlyUsed() {
+	cache := cache.New[Session]("test").WithCapacity(3)
+	defer func() { _ = cache.Clear() }()
+	sessions := []Session{{ID: uuid.New()}, {ID: uuid.New()}, {ID: uuid.New()}, {ID: uuid.New()}}
+	for _, session := range sessions[:3] {
+		_ = cache.Set(session)
+	}
+	_, err := cache.Get(sessions[0].GetID().String())
+	suite.Require().NoError(err, "Failed to get cached session: %+v", err)
+
+	_ = cache.Set(sessions[3])
+	_, err = cache.Get(sessions[1].GetID().String())
+	suite.Assert().ErrorIs(err, errors.NotFound, "The least recently used session should have been evicted")
+	for _, session := range []Session{sessions[0], sessions[2], sessions[3]} {
+		_, err = cache.Get(session.GetID().String())
+		suite.Assert().NoError(err, "Failed to get cached session: %+v", err)
+	}
+}
+
+func (suite *CacheSuite) TestCanEvictWithSegmentedLRU() {
+	cache := cache.New[Session]("test").WithSegmentedLRU(4, 0.5)
+	defer func() { _ = cache.Clear() }()
+	hot := []Session{{ID: uuid.New()}, {ID: uuid.New()}}
+	for _, session := range hot {
+		_ = cache.Set(session)
+		_, _ = cache.Get(session.GetID().String())
+	}
+
+	// A scan of sessions that are used only once should not evict the hot ones
+	for i := 0; i < 10; i++ {
+		_ = cache.Set(Session{ID: uuid.New()}, fmt.Sprintf("scan-%d", i))
+	}
+	for _, session := range hot {
+		_, err := cache.Get(session.GetID().String())
+		suite.Assert().NoError(err, "Hot session should not have been evicted: %+v", err)
+	}
+}
+
+func (suite *CacheSuite) TestCanEvictWithClock() {
+	cache := cache.New[Session]("test").WithShards(1).WithClockEviction(3)
+	defer func() { _ = cache.Clear() }()
+	sessions := []Session{{ID: uuid.New()}, {ID: uuid.New()}, {ID: uuid.New()}, {ID: uuid.New()}}
+	for _, session := range sessions[:3] {
+		_ = cache.Set(session)
+	}
+	_, err := cache.Get(sessions[0].GetID().String())
+	suite.Require().NoError(err, "Failed to get cached session: %+v", err)
+
+	_ = cache.Set(sessions[3])
+	_, err = cache.Get(sessions[1].GetID().String())
+	suite.Assert().ErrorIs(err, errors.NotFound, "The first session that was not read should have been evicted")
+	for _, session := range []Session{sessions[0], sessions[2], sessions[3]} {
+		_, err = cache.Get(session.GetID().String())
+		suite.Assert().NoError(err, "Failed to get cached session: %+v", err)
+	}
+}
+
+func (suite *CacheSuite) TestCanReloadEvictedItemsFromDisk() {
+	cache := cache.New[Session]("test", cache.CacheOptionPersistent).WithCapacity(1)
+	first := Session{ID: uuid.New()}
+	second := Session{ID: uuid.New()}
+	_ = cache.Set(first)
+	_ = cache.Set(second)
+
+	cached, err := cache.Get(first.GetID().String())
+	suite.Require().NoError(err, "Failed to get cached session: %+v", err)
+	suite.Assert().Equal(first, *cached)
+}
+
+func (suite *CacheSuite) TestCanEvictBetweenWatermarks() {
+	items := cache.New[string]("test").WithShards(1).WithCapacity(100).WithWatermarks(0.9, 0.5)
+	defer func() { _ = items.Clear() }()
+	count := func() (count int) {
+		for i := 0; i < 100; i++ {
+			if _, err := items.Get(fmt.Sprintf("key-%d", i)); err == nil {
+				count++
+			}
+		}
+		return
+	}
+	for i := 0; i < 90; i++ {
+		_ = items.Set("value", fmt.Sprintf("key-%d", i))
+	}
+	suite.Assert().Equal(90, count(), "No key should be evicted below the high watermark")
+
+	_ = items.Set("value", "key-90")
+	suite.Assert().Eventually(func() bool { return count() == 50 }, time.Second, 10*time.Millisecond, "The keys should be evicted down to the low watermark")
+	_, err := items.Get("key-90")
+	suite.Assert().NoError(err, "The most recently used key should not be evicted: %+v", err)
+}
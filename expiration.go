@@ -0,0 +1,35 @@
+package cache
+
+import "time"
+
+// Expirable is implemented by items that know how long they should be cached
+//
+// Set uses CacheExpiration instead of the cache's expiration.
+type Expirable interface {
+	CacheExpiration() time.Duration
+}
+
+// Expiring is implemented by items that know when they expire, like OAuth tokens
+//
+// Set uses ExpiresAt instead of the cache's expiration.
+type Expiring interface {
+	ExpiresAt() time.Time
+}
+
+// expirationOf gets the expiration of the given item, or the given default expiration
+//
+// An item that has already expired gets the shortest expiration,
+// as an expiration of 0 would keep it forever.
+func expirationOf(item any, expiration time.Duration) time.Duration {
+	if expirable, ok := item.(Expirable); ok {
+		if duration := expirable.CacheExpiration(); duration > 0 {
+			return duration
+		}
+	}
+	if expiring, ok := item.(Expiring); ok {
+		if expiresAt := expiring.ExpiresAt(); !expiresAt.IsZero() {
+			return max(time.Until(expiresAt), time.Nanosecond)
+		}
+	}
+	return expiration
+}
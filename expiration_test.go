@@ -0,0 +1,64 @@
+package cache_test
+
+import (
+	"time"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+type Token struct {
+	Value   string    `json:"value"`
+	Expires time.Time `json:"expires"`
+}
+
+func (token Token) ExpiresAt() time.Time {
+	return token.Expires
+}
+
+type Nonce struct {
+	Value string `json:"value"`
+}
+
+func (nonce Nonce) CacheExpiration() time.Duration {
+	return 50 * time.Millisecond
+}
+
+func (suite *CacheSuite) TestCanExpireAtItemExpiration() {
+	tokens := cache.New[Token]("test").WithExpiration(time.Hour)
+	_ = tokens.Set(Token{Value: "short", Expires: time.Now().Add(50 * time.Millisecond)}, "short")
+	_ = tokens.Set(Token{Value: "long"}, "long")
+	_ = tokens.Set(Token{Value: "expired", Expires: time.Now().Add(-time.Minute)}, "expired")
+
+	_, err := tokens.Get("expired")
+	suite.Assert().ErrorIs(err, errors.NotFound, "An item that has already expired should not be returned")
+	_, err = tokens.Get("short")
+	suite.Require().NoError(err, "Failed to get token: %+v", err)
+	time.Sleep(100 * time.Millisecond)
+	_, err = tokens.Get("short")
+	suite.Assert().ErrorIs(err, errors.NotFound, "The token should have expired at its own expiration")
+	_, err = tokens.Get("long")
+	suite.Assert().NoError(err, "A token without expiration should use the cache's expiration")
+}
+
+func (suite *CacheSuite) TestCanExpireWithItemDuration() {
+	nonces := cache.New[Nonce]("test").WithExpiration(time.Hour)
+	_ = nonces.Set(Nonce{Value: "1"}, "nonce")
+	_ = nonces.SetWithExpiration(Nonce{Value: "2"}, time.Hour, "explicit")
+
+	_, err := nonces.Get("nonce")
+	suite.Require().NoError(err, "Failed to get nonce: %+v", err)
+	time.Sleep(100 * time.Millisecond)
+	_, err = nonces.Get("nonce")
+	suite.Assert().ErrorIs(err, errors.NotFound, "The nonce should have expired with its own duration")
+	_, err = nonces.Get("explicit")
+	suite.Assert().NoError(err, "SetWithExpiration should not use the item's duration")
+}
+
+func (suite *CacheSuite) TestDeprecatedExpirationFieldFollowsConfiguration() {
+	tokens := cache.New[Token]("test")
+	suite.Assert().Equal(time.Duration(0), tokens.Expiration) //nolint:staticcheck // the deprecated field is still supported
+	tokens.WithExpiration(time.Hour)
+	suite.Assert().Equal(time.Hour, tokens.Expiration)                   //nolint:staticcheck // the deprecated field is still supported
+	suite.Assert().Equal(time.Hour, tokens.ForTenant("acme").Expiration) //nolint:staticcheck // the deprecated field is still supported
+}
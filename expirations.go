@@ -0,0 +1,65 @@
+package cache
+
+import "time"
+
+// ExpirationEngine tells how a cache keeps track of the expirations of its items
+type ExpirationEngine int
+
+const (
+	// MinHeap keeps the expirations in a min-heap, scheduling and cancelling an expiration is O(log n)
+	//
+	// This is the default engine, items are expired at their exact expiration.
+	MinHeap ExpirationEngine = iota
+	// TimingWheel keeps the expirations in a timing wheel, scheduling and cancelling an expiration is O(1)
+	//
+	// Items are expired up to timingWheelResolution after their expiration.
+	// This suits workloads with a very high churn of short expirations.
+	TimingWheel
+)
+
+// expirationQueue keeps the expirations of the keys of a shard
+//
+// An expirationQueue is not safe for concurrent use, the shard mutex protects it.
+type expirationQueue interface {
+	// set sets the expiration of a key, at 0 removes it
+	set(key string, at int64)
+	// remove removes the expiration of a key
+	remove(key string)
+	// expire removes and returns the expirations at or before deadline
+	expire(deadline int64) []expiry
+	// within gets the expirations at or before deadline, not sorted
+	within(deadline int64) []expiry
+	// clear removes all the expirations
+	clear()
+}
+
+// String gets the name of the engine
+//
+// implements fmt.Stringer
+func (engine ExpirationEngine) String() string {
+	switch engine {
+	case TimingWheel:
+		return "timing-wheel"
+	default:
+		return "min-heap"
+	}
+}
+
+// WithExpirationEngine sets how the cache keeps track of the expirations of its items
+func (cache *Cache[T]) WithExpirationEngine(engine ExpirationEngine) *Cache[T] {
+	cache.configure(func(config *settings) {
+		config.expirationEngine = engine
+	})
+	cache.rebuild()
+	return cache
+}
+
+// newExpirationQueue creates the expirationQueue of the given engine
+func newExpirationQueue(engine ExpirationEngine) expirationQueue {
+	switch engine {
+	case TimingWheel:
+		return newTimingWheel(timingWheelResolution, timingWheelSlots, time.Now())
+	default:
+		return &expiryHeap{}
+	}
+}
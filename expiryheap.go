@@ -0,0 +1,133 @@
+package cache
+
+// expiry is the expiration of a key, in nanoseconds since the epoch
+type expiry struct {
+	key string
+	at  int64
+}
+
+// expiryHeap is a min-heap of the expirations of the keys of a shard
+//
+// The earliest expiration is at the top, so the expired keys are found in O(log n) each
+// instead of scanning all the keys. Keys that do not expire are not in the heap.
+type expiryHeap struct {
+	entries []expiry
+	index   map[string]int
+}
+
+// set sets the expiration of a key, at 0 removes it
+func (expirations *expiryHeap) set(key string, at int64) {
+	if at == 0 {
+		expirations.remove(key)
+		return
+	}
+	if expirations.index == nil {
+		expirations.index = map[string]int{}
+	}
+	if i, found := expirations.index[key]; found {
+		previous := expirations.entries[i].at
+		expirations.entries[i].at = at
+		if at < previous {
+			expirations.up(i)
+		} else {
+			expirations.down(i)
+		}
+		return
+	}
+	expirations.entries = append(expirations.entries, expiry{key: key, at: at})
+	expirations.index[key] = len(expirations.entries) - 1
+	expirations.up(len(expirations.entries) - 1)
+}
+
+// remove removes the expiration of a key
+func (expirations *expiryHeap) remove(key string) {
+	i, found := expirations.index[key]
+	if !found {
+		return
+	}
+	last := len(expirations.entries) - 1
+	expirations.swap(i, last)
+	expirations.entries = expirations.entries[:last]
+	delete(expirations.index, key)
+	if i < last {
+		expirations.down(i)
+		expirations.up(i)
+	}
+}
+
+// pop removes and returns the earliest expiration if it is at or before deadline
+func (expirations *expiryHeap) pop(deadline int64) (expiry, bool) {
+	if len(expirations.entries) == 0 || expirations.entries[0].at > deadline {
+		return expiry{}, false
+	}
+	top := expirations.entries[0]
+	expirations.remove(top.key)
+	return top, true
+}
+
+// expire removes and returns the expirations at or before deadline
+func (expirations *expiryHeap) expire(deadline int64) (expired []expiry) {
+	for {
+		next, ok := expirations.pop(deadline)
+		if !ok {
+			return
+		}
+		expired = append(expired, next)
+	}
+}
+
+// within gets the expirations at or before deadline
+//
+// Only the part of the heap before deadline is visited. The expirations are not sorted.
+func (expirations *expiryHeap) within(deadline int64) (found []expiry) {
+	var visit func(i int)
+	visit = func(i int) {
+		if i >= len(expirations.entries) || expirations.entries[i].at > deadline {
+			return
+		}
+		found = append(found, expirations.entries[i])
+		visit(2*i + 1)
+		visit(2*i + 2)
+	}
+	visit(0)
+	return
+}
+
+// clear removes all the expirations
+func (expirations *expiryHeap) clear() {
+	expirations.entries = nil
+	expirations.index = nil
+}
+
+func (expirations *expiryHeap) swap(i, j int) {
+	expirations.entries[i], expirations.entries[j] = expirations.entries[j], expirations.entries[i]
+	expirations.index[expirations.entries[i].key] = i
+	expirations.index[expirations.entries[j].key] = j
+}
+
+func (expirations *expiryHeap) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if expirations.entries[parent].at <= expirations.entries[i].at {
+			return
+		}
+		expirations.swap(i, parent)
+		i = parent
+	}
+}
+
+func (expirations *expiryHeap) down(i int) {
+	for {
+		smallest := i
+		for child := 2*i + 1; child <= 2*i+2; child++ {
+			if child < len(expirations.entries) && expirations.entries[child].at < expirations.entries[smallest].at {
+				smallest = child
+			}
+		}
+		if smallest == i {
+			return
+		}
+		expirations.swap(i, smallest)
+		i = smallest
+	}
+}
@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// fanOutWidth is the length of the names of the subfolders of a fan-out layout
+const fanOutWidth = 2
+
+// filekey gets the path of the file that persists the given key, relative to the cache folder
+//
+// With WithDirectoryFanOut, the file is in two levels of subfolders named after
+// the first characters of its name (aa/bb/aabbcc...), otherwise it is in the cache folder.
+func (config *settings) filekey(key string) string {
+	name := config.filename(key)
+	if !config.fanOut {
+		return name
+	}
+	return fanOut(name)
+}
+
+// fanOut gets the path of the file with the given name in a fan-out layout
+func fanOut(name string) string {
+	if len(name) < 2*fanOutWidth {
+		return name
+	}
+	return filepath.Join(name[:fanOutWidth], name[fanOutWidth:2*fanOutWidth], name)
+}
+
+// WithDirectoryFanOut spreads the persisted items in two levels of subfolders
+//
+// Many file systems get slow with tens of thousands of files in a single folder.
+// With the fan-out, the file of an item is in aa/bb/ where aa and bb are the first characters of its name.
+//
+// The items persisted in the cache folder before are moved in their subfolder when they are read.
+func (cache *Cache[T]) WithDirectoryFanOut() *Cache[T] {
+	return cache.configure(func(config *settings) {
+		config.fanOut = true
+	})
+}
+
+// unflatten moves the file of a fan-out layout that was persisted in the cache folder to its subfolder
+//
+// It tells if the file was moved.
+func (config *settings) unflatten(filekey string) bool {
+	name := filepath.Base(filekey)
+	if name == filekey {
+		return false
+	}
+	target := filepath.Join(config.folder, filekey)
+	if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+		return false
+	}
+	return os.Rename(filepath.Join(config.folder, name), target) == nil
+}
+
+// walkPersisted calls fn with the path, relative to the cache folder, of each persisted file
+//
+// The files in the cache folder and in the subfolders of a fan-out layout are walked,
+// the other subfolders (blobs, tenants, quarantine...) are not.
+func (config *settings) walkPersisted(fn func(filekey string) error) error {
+	err := filepath.WalkDir(config.folder, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relative, _ := filepath.Rel(config.folder, path)
+		if entry.IsDir() {
+			if relative == "." || fanOutFolder(relative) {
+				return nil
+			}
+			return filepath.SkipDir
+		}
+		if strings.HasPrefix(entry.Name(), ".tmp-") {
+			return nil
+		}
+		return fn(relative)
+	})
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// internalFiles gets the names of the files that persist the state of the cache instead of items
+//
+// They are the generation (see BumpGeneration), the priorities (see WithPreloadPriority), and the key index (see WithKeyIndex).
+func internalFiles(config *settings) map[string]bool {
+	files := map[string]bool{}
+	for _, key := range []string{generationKey, prioritiesKey, keysKey} {
+		files[filepath.Base(config.filekey(key))] = true
+	}
+	return files
+}
+
+// fanOutFolder tells if the given relative path is a subfolder of a fan-out layout
+func fanOutFolder(relative string) bool {
+	parts := strings.Split(relative, string(filepath.Separator))
+	if len(parts) > 2 {
+		return false
+	}
+	for _, part := range parts {
+		if len(part) != fanOutWidth {
+			return false
+		}
+		for _, c := range part {
+			if !strings.ContainsRune("0123456789abcdef", c) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,60 @@
+package cache_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+func (suite *CacheSuite) TestCanFanOutPersistedItems() {
+	items := cache.New[string]("test", cache.CacheOptionPersistent).WithDirectoryFanOut()
+	defer func() { _ = items.Clear() }()
+	err := items.Set("value", "key")
+	suite.Require().NoError(err, "Failed to set item: %+v", err)
+
+	folder, _ := os.UserCacheDir()
+	name := uuid.NewSHA1(uuid.Nil, []byte("key")).String()
+	_, err = os.Stat(filepath.Join(folder, "test", name[:2], name[2:4], name))
+	suite.Assert().NoError(err, "The item should be persisted in its subfolder")
+	_, err = os.Stat(filepath.Join(folder, "test", name))
+	suite.Assert().ErrorIs(err, os.ErrNotExist, "The item should not be persisted in the cache folder")
+
+	reopened := cache.New[string]("test", cache.CacheOptionPersistent).WithDirectoryFanOut()
+	suite.Require().NoError(reopened.Preload(context.Background(), 0, nil))
+	keys := []string{}
+	err = reopened.IteratePersisted(func(key string, reader io.Reader) error {
+		keys = append(keys, key)
+		return nil
+	})
+	suite.Require().NoError(err, "Failed to iterate: %+v", err)
+	suite.Assert().Equal([]string{"key"}, keys)
+}
+
+func (suite *CacheSuite) TestCanMigrateToFanOut() {
+	flat := cache.New[string]("test", cache.CacheOptionPersistent)
+	defer func() { _ = flat.Clear() }()
+	suite.Require().NoError(flat.Set("value", "key"))
+	suite.Require().NoError(flat.Set("other", "other"))
+
+	folder, _ := os.UserCacheDir()
+	name := uuid.NewSHA1(uuid.Nil, []byte("key")).String()
+	fanned := cache.New[string]("test", cache.CacheOptionPersistent).WithDirectoryFanOut()
+	value, err := fanned.Get("key")
+	suite.Require().NoError(err, "Failed to get item persisted in the cache folder: %+v", err)
+	suite.Assert().Equal("value", *value)
+	_, err = os.Stat(filepath.Join(folder, "test", name[:2], name[2:4], name))
+	suite.Assert().NoError(err, "The item should have been moved to its subfolder")
+	_, err = os.Stat(filepath.Join(folder, "test", name))
+	suite.Assert().ErrorIs(err, os.ErrNotExist, "The item should not be in the cache folder anymore")
+
+	suite.Require().NoError(fanned.Delete("other"))
+	reopened := cache.New[string]("test", cache.CacheOptionPersistent).WithDirectoryFanOut()
+	_, err = reopened.Get("other")
+	suite.Assert().ErrorIs(err, errors.NotFound, "Deleting an item that was not migrated should remove it from the cache folder")
+}
@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gildas/go-errors"
+)
+
+// Resource is a resource fetched from a URL by a Fetcher
+type Resource struct {
+	URL          string    `json:"url"`
+	Body         []byte    `json:"body"`
+	ContentType  string    `json:"contentType,omitempty"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"lastModified,omitempty"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+}
+
+// Fetcher fetches resources from URLs and caches them
+//
+// When a cached resource is older than MaxAge, the Fetcher revalidates it
+// with a conditional request (If-None-Match, If-Modified-Since).
+// If the server answers 304 Not Modified, or cannot be reached, the cached body is used.
+type Fetcher struct {
+	Cache  *Cache[Resource]
+	Client *http.Client
+	MaxAge time.Duration
+}
+
+// NewFetcher creates a new Fetcher that caches resources for maxAge before revalidating them
+func NewFetcher(name string, maxAge time.Duration, option ...CacheOption) *Fetcher {
+	return &Fetcher{
+		Cache:  New[Resource](name, option...).WithExpiration(0),
+		Client: http.DefaultClient,
+		MaxAge: maxAge,
+	}
+}
+
+// FetchURL fetches the body of the resource at the given URL
+//
+// The cached body is returned if it is still fresh, or if it could be revalidated.
+func (fetcher *Fetcher) FetchURL(ctx context.Context, url string) ([]byte, error) {
+	resource, err := fetcher.Fetch(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return resource.Body, nil
+}
+
+// Fetch fetches the resource at the given URL
+//
+// The cached resource is returned if it is still fresh, or if it could be revalidated.
+// A resource that was fetched but could not be cached is still returned,
+// the error is given to the error handler of the cache with OperationFetch, see WithErrorHandler.
+func (fetcher *Fetcher) Fetch(ctx context.Context, url string) (*Resource, error) {
+	cached, _ := fetcher.Cache.Get(url)
+	if cached != nil && time.Now().Before(cached.ExpiresAt) {
+		return cached, nil
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.InvalidURL.With(url)
+	}
+	if cached != nil {
+		if len(cached.ETag) > 0 {
+			request.Header.Set("If-None-Match", cached.ETag)
+		}
+		if len(cached.LastModified) > 0 {
+			request.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	client := fetcher.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		if cached != nil {
+			return cached, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+	defer response.Body.Close()
+
+	switch {
+	case response.StatusCode == http.StatusNotModified && cached != nil:
+		revalidated := *cached
+		revalidated.ExpiresAt = time.Now().Add(fetcher.MaxAge)
+		if etag := response.Header.Get("ETag"); len(etag) > 0 {
+			revalidated.ETag = etag
+		}
+		fetcher.store(revalidated, url)
+		return &revalidated, nil
+	case response.StatusCode >= 200 && response.StatusCode < 300:
+		body, err := io.ReadAll(response.Body)
+		if err != nil {
+			if cached != nil {
+				return cached, nil
+			}
+			return nil, errors.WithStack(err)
+		}
+		resource := Resource{
+			URL:          url,
+			Body:         body,
+			ContentType:  response.Header.Get("Content-Type"),
+			ETag:         response.Header.Get("ETag"),
+			LastModified: response.Header.Get("Last-Modified"),
+			ExpiresAt:    time.Now().Add(fetcher.MaxAge),
+		}
+		fetcher.store(resource, url)
+		return &resource, nil
+	case response.StatusCode >= 500 && cached != nil:
+		return cached, nil
+	default:
+		return nil, errors.FromHTTPStatusCode(response.StatusCode)
+	}
+}
+
+// store caches a fetched resource, the error is reported to the error handler of the cache
+func (fetcher *Fetcher) store(resource Resource, url string) {
+	fetcher.Cache.settings().reportError(OperationFetch, url, fetcher.Cache.Set(resource, url))
+}
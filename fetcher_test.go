@@ -0,0 +1,90 @@
+package cache_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+func (suite *CacheSuite) TestCanFetchURL() {
+	var requests, revalidations atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			revalidations.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("Hello, World!"))
+	}))
+	defer server.Close()
+
+	fetcher := cache.NewFetcher("test", 250*time.Millisecond)
+	defer func() { _ = fetcher.Cache.Clear() }()
+
+	body, err := fetcher.FetchURL(context.Background(), server.URL)
+	suite.Require().NoError(err, "Failed to fetch URL: %+v", err)
+	suite.Assert().Equal("Hello, World!", string(body))
+
+	body, err = fetcher.FetchURL(context.Background(), server.URL)
+	suite.Require().NoError(err, "Failed to fetch URL: %+v", err)
+	suite.Assert().Equal("Hello, World!", string(body))
+	suite.Assert().Equal(int32(1), requests.Load(), "The second fetch should have been served from the cache")
+
+	time.Sleep(500 * time.Millisecond)
+	body, err = fetcher.FetchURL(context.Background(), server.URL)
+	suite.Require().NoError(err, "Failed to fetch URL: %+v", err)
+	suite.Assert().Equal("Hello, World!", string(body))
+	suite.Assert().Equal(int32(1), revalidations.Load(), "The expired resource should have been revalidated")
+}
+
+func (suite *CacheSuite) TestCanFetchURLWhenServerIsDown() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("Hello, World!"))
+	}))
+
+	fetcher := cache.NewFetcher("test", 10*time.Millisecond)
+	defer func() { _ = fetcher.Cache.Clear() }()
+
+	_, err := fetcher.FetchURL(context.Background(), server.URL)
+	suite.Require().NoError(err, "Failed to fetch URL: %+v", err)
+	server.Close()
+
+	time.Sleep(50 * time.Millisecond)
+	body, err := fetcher.FetchURL(context.Background(), server.URL)
+	suite.Require().NoError(err, "The cached body should have been used: %+v", err)
+	suite.Assert().Equal("Hello, World!", string(body))
+
+	_, err = fetcher.FetchURL(context.Background(), server.URL+"/unknown")
+	suite.Assert().Error(err, "Fetching an unknown resource while the server is down should fail")
+}
+
+func (suite *CacheSuite) TestShouldFetchURLWhenResourceCannotBeCached() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("Hello, World!"))
+	}))
+	defer server.Close()
+
+	var reported atomic.Int32
+	fetcher := cache.NewFetcher("test", time.Minute)
+	fetcher.Cache.WithValidator(func(resource cache.Resource) error {
+		return errors.New("rejected")
+	}).WithErrorHandler(func(operation string, key string, err error) {
+		if operation == cache.OperationFetch && key == server.URL {
+			reported.Add(1)
+		}
+	})
+	defer func() { _ = fetcher.Cache.Clear() }()
+
+	body, err := fetcher.FetchURL(context.Background(), server.URL)
+	suite.Require().NoError(err, "A resource that cannot be cached should still be fetched: %+v", err)
+	suite.Assert().Equal("Hello, World!", string(body))
+	suite.Assert().Equal(int32(1), reported.Load(), "The cache error should have been reported")
+}
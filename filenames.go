@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+)
+
+// filename gets the name of the file that persists the given key
+//
+// With WithEncryptedFilenames, the name is an HMAC of the key under the encryption key.
+// Once the cache is closed, the HMAC key is destroyed and the name does not match any encrypted file.
+func (config *settings) filename(key string) (name string) {
+	if config.filenameKey == nil {
+		return filekey(key)
+	}
+	err := config.filenameKey.use(func(secret []byte) error {
+		mac := hmac.New(sha256.New, secret)
+		_, _ = mac.Write([]byte(key))
+		name = hex.EncodeToString(mac.Sum(nil))
+		return nil
+	})
+	if err != nil {
+		return filekey(key)
+	}
+	return name
+}
+
+// encryptFilenames derives the key of the filenames from the encryption key and renames the folder of the cache
+//
+// It does nothing until both WithEncryptedFilenames and WithEncryptionKey are called.
+func (config *settings) encryptFilenames(name string) {
+	if !config.encryptedFilenames || config.encryptionKey == nil {
+		return
+	}
+	filenameKey, err := config.encryptionKey.derive("go-cache filenames")
+	if err != nil {
+		return
+	}
+	config.filenameKey = filenameKey
+	if filepath.Base(config.folder) == name {
+		config.folder = filepath.Join(filepath.Dir(config.folder), config.filename(name))
+	}
+}
+
+// WithEncryptedFilenames names the persisted files and the cache folder with an HMAC under the encryption key
+//
+// The persistence folder does not reveal the cache names or the keys anymore, and the files cannot be forged without the key.
+// It requires WithEncryptionKey, the items persisted without it are not found anymore.
+func (cache *Cache[T]) WithEncryptedFilenames() *Cache[T] {
+	return cache.configure(func(config *settings) {
+		config.encryptedFilenames = true
+		config.encryptFilenames(cache.Name)
+	})
+}
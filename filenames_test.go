@@ -0,0 +1,37 @@
+package cache_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+func (suite *CacheSuite) TestCanEncryptFilenames() {
+	encryptionKey := []byte("@v3ry#S3cr3tK3y!")
+	firstCache := cache.New[User]("test-filenames").WithEncryptionKey(encryptionKey).WithEncryptedFilenames()
+	defer func() { _ = firstCache.Clear() }()
+	user := User{ID: uuid.New(), Name: "Joe"}
+	err := firstCache.Set(user)
+	suite.Require().NoError(err, "Failed to set cached user: %+v", err)
+
+	folder, _ := os.UserCacheDir()
+	_, err = os.Stat(filepath.Join(folder, "test-filenames"))
+	suite.Assert().ErrorIs(err, os.ErrNotExist, "The cache folder should not be named after the cache")
+
+	secondCache := cache.New[User]("test-filenames").WithEncryptedFilenames().WithEncryptionKey(encryptionKey)
+	cached, err := secondCache.Get(user.GetID().String())
+	suite.Require().NoError(err, "Failed to get cached user: %+v", err)
+	suite.Assert().Equal(user, *cached, "User and Cached User are different")
+
+	thirdCache := cache.New[User]("test-filenames").WithEncryptionKey(encryptionKey)
+	_, err = thirdCache.Get(user.GetID().String())
+	suite.Assert().ErrorIs(err, errors.NotFound, "The item should not be found under its plain filename")
+
+	fourthCache := cache.New[User]("test-filenames").WithEncryptionKey([]byte("@n0th3r#S3cr3tK3y")).WithEncryptedFilenames()
+	_, err = fourthCache.Get(user.GetID().String())
+	suite.Assert().ErrorIs(err, errors.NotFound, "The item should not be found with another encryption key")
+}
@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gildas/go-errors"
+)
+
+// FolderEnvironment is the environment variable that overrides the folder of the persistent caches
+const FolderEnvironment = "GO_CACHE_DIR"
+
+// CacheFolder gets the folder where the persistent caches create their own folder
+//
+// It is the first writable folder of:
+//   - the folder given in the GO_CACHE_DIR environment variable,
+//   - the user cache folder of the platform, see os.UserCacheDir:
+//     $XDG_CACHE_HOME or ~/.cache on Linux, ~/Library/Caches on macOS, %LocalAppData% on Windows,
+//   - a go-cache folder in the temporary folder of the platform, see os.TempDir.
+//     As the temporary folder can be shared, this folder is used only if it is not a symbolic link,
+//     and on Unix only if it is owned by the current user and not accessible by the others (mode 0700).
+//
+// The folders are created if needed. When none is writable, like in a scratch container
+// without HOME and without /tmp, ErrNoCacheFolder is returned.
+func CacheFolder() (folder string, err error) {
+	var candidates []string
+
+	if folder = os.Getenv(FolderEnvironment); len(folder) > 0 {
+		candidates = append(candidates, folder)
+	}
+	if folder, err = os.UserCacheDir(); err == nil {
+		candidates = append(candidates, folder)
+	}
+	for _, folder = range candidates {
+		if writable(folder) {
+			return folder, nil
+		}
+	}
+	temp := filepath.Join(os.TempDir(), tempFolder())
+	if private(temp) && writable(temp) {
+		return temp, nil
+	}
+	return "", ErrNoCacheFolder.With(fmt.Sprint(append(candidates, temp)))
+}
+
+// tempFolder gets the name of the folder in the temporary folder, per user when the platform has user ids
+func tempFolder() string {
+	if uid := os.Getuid(); uid >= 0 {
+		return fmt.Sprintf("go-cache-%d", uid)
+	}
+	return "go-cache"
+}
+
+// writable tells if files can be created in the given folder, creating it if needed
+func writable(folder string) bool {
+	if !filepath.IsAbs(folder) || os.MkdirAll(folder, 0700) != nil {
+		return false
+	}
+	probe, err := os.CreateTemp(folder, ".probe-*")
+	if err != nil {
+		return false
+	}
+	_ = probe.Close()
+	_ = os.Remove(probe.Name())
+	return true
+}
+
+// private tells if the given folder belongs only to the current user, creating it if needed
+//
+// The folder must not be a symbolic link, see ownedPrivately for the other checks of the platform.
+func private(folder string) bool {
+	if !filepath.IsAbs(folder) {
+		return false
+	}
+	if err := os.Mkdir(folder, 0700); err != nil && !errors.Is(err, os.ErrExist) {
+		return false
+	}
+	info, err := os.Lstat(folder)
+	if err != nil || !info.IsDir() {
+		return false
+	}
+	return ownedPrivately(info)
+}
+
+// useCacheFolder persists the cache in its own folder in CacheFolder
+//
+// When there is no cache folder, the error is kept and returned when items are persisted or restored.
+func (config *settings) useCacheFolder(name string) {
+	var folder string
+
+	folder, config.folderError = CacheFolder()
+	config.folder = filepath.Join(folder, name)
+}
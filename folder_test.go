@@ -0,0 +1,76 @@
+package cache_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+func (suite *CacheSuite) TestCanOverrideCacheFolder() {
+	folder := suite.T().TempDir()
+	suite.T().Setenv(cache.FolderEnvironment, folder)
+	names := cache.New[string]("test", cache.CacheOptionPersistent)
+	suite.Require().NoError(names.Set("value", "key"))
+	entries, err := os.ReadDir(filepath.Join(folder, "test"))
+	suite.Require().NoError(err, "Failed to read the cache folder: %+v", err)
+	suite.Assert().NotEmpty(entries)
+}
+
+func (suite *CacheSuite) TestCacheFolderFallsBackToTempFolder() {
+	if runtime.GOOS == "windows" {
+		suite.T().Skip("The user cache folder does not depend on HOME on Windows")
+	}
+	temp := suite.T().TempDir()
+	suite.T().Setenv(cache.FolderEnvironment, "")
+	suite.T().Setenv("HOME", "")
+	suite.T().Setenv("XDG_CACHE_HOME", "")
+	suite.T().Setenv("TMPDIR", temp)
+	folder, err := cache.CacheFolder()
+	suite.Require().NoError(err, "Failed to get the cache folder: %+v", err)
+	suite.Assert().Equal(filepath.Join(temp, fmt.Sprintf("go-cache-%d", os.Getuid())), folder)
+}
+
+func (suite *CacheSuite) TestCacheFolderShouldNotFallBackToSharedTempFolder() {
+	if runtime.GOOS == "windows" {
+		suite.T().Skip("The user cache folder does not depend on HOME on Windows")
+	}
+	temp := suite.T().TempDir()
+	suite.T().Setenv(cache.FolderEnvironment, "")
+	suite.T().Setenv("HOME", "")
+	suite.T().Setenv("XDG_CACHE_HOME", "")
+	suite.T().Setenv("TMPDIR", temp)
+	fallback := filepath.Join(temp, fmt.Sprintf("go-cache-%d", os.Getuid()))
+
+	suite.Require().NoError(os.Symlink(suite.T().TempDir(), fallback))
+	_, err := cache.CacheFolder()
+	suite.Assert().ErrorIs(err, cache.ErrNoCacheFolder, "A symbolic link should not be used")
+
+	suite.Require().NoError(os.Remove(fallback))
+	suite.Require().NoError(os.Mkdir(fallback, 0700))
+	suite.Require().NoError(os.Chmod(fallback, 0777))
+	_, err = cache.CacheFolder()
+	suite.Assert().ErrorIs(err, cache.ErrNoCacheFolder, "A folder accessible by the others should not be used")
+}
+
+func (suite *CacheSuite) TestCannotPersistWithoutCacheFolder() {
+	if runtime.GOOS == "windows" {
+		suite.T().Skip("The user cache folder does not depend on HOME on Windows")
+	}
+	file := filepath.Join(suite.T().TempDir(), "file")
+	suite.Require().NoError(os.WriteFile(file, nil, 0600))
+	suite.T().Setenv(cache.FolderEnvironment, filepath.Join(file, "cache"))
+	suite.T().Setenv("HOME", "")
+	suite.T().Setenv("XDG_CACHE_HOME", "")
+	suite.T().Setenv("TMPDIR", file)
+	_, err := cache.CacheFolder()
+	suite.Require().ErrorIs(err, cache.ErrNoCacheFolder)
+
+	names := cache.New[string]("test", cache.CacheOptionPersistent)
+	err = names.Set("value", "key")
+	suite.Require().Error(err, "Set should fail without a cache folder")
+	suite.Assert().True(errors.Is(err, cache.ErrNoCacheFolder), "Error should be ErrNoCacheFolder, got %+v", err)
+}
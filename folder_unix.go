@@ -0,0 +1,14 @@
+//go:build unix
+
+package cache
+
+import (
+	"os"
+	"syscall"
+)
+
+// ownedPrivately tells if a folder is owned by the current user and not accessible by the others
+func ownedPrivately(info os.FileInfo) bool {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	return ok && int(stat.Uid) == os.Getuid() && info.Mode().Perm() == 0700
+}
@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"time"
+)
+
+// CachedFile is a file of an FS kept in the cache
+type CachedFile struct {
+	Name        string      `json:"name"`
+	Data        []byte      `json:"data"`
+	ContentType string      `json:"contentType,omitempty"`
+	ModTime     time.Time   `json:"modTime"`
+	Mode        fs.FileMode `json:"mode"`
+}
+
+// FS is a fs.FS that keeps the files of a slow origin (e.g. a remote object store) in a cache
+//
+// Only regular files are cached, directories are always read from the origin.
+// Use http.FS to serve the files with http.FileServer.
+type FS struct {
+	Origin fs.FS
+	Cache  *Cache[CachedFile]
+}
+
+// NewFS creates a new FS that caches the files of origin for ttl
+func NewFS(name string, origin fs.FS, ttl time.Duration, option ...CacheOption) *FS {
+	return &FS{
+		Origin: origin,
+		Cache:  New[CachedFile](name, option...).WithExpiration(ttl).WithExplicitKeysOnly(),
+	}
+}
+
+// Open opens the named file, from the cache if it is there
+//
+// implements fs.FS
+func (filesystem *FS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if cached, err := filesystem.Cache.Get(name); err == nil {
+		return &cachedFileReader{CachedFile: cached, Reader: bytes.NewReader(cached.Data)}, nil
+	}
+	file, err := filesystem.Origin.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		return file, err
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: err}
+	}
+	cached := &CachedFile{
+		Name:        path.Base(name),
+		Data:        data,
+		ContentType: contentType(name, data),
+		ModTime:     info.ModTime(),
+		Mode:        info.Mode(),
+	}
+	_ = filesystem.Cache.Set(*cached, name)
+	return &cachedFileReader{CachedFile: cached, Reader: bytes.NewReader(cached.Data)}, nil
+}
+
+// contentType gets the content type of a file from its extension, or from its content
+func contentType(name string, data []byte) string {
+	if contentType := mime.TypeByExtension(path.Ext(name)); len(contentType) > 0 {
+		return contentType
+	}
+	return http.DetectContentType(data)
+}
+
+// cachedFileReader reads a CachedFile
+//
+// It implements io.Seeker, so http.FileServer can serve ranges.
+type cachedFileReader struct {
+	*CachedFile
+	*bytes.Reader
+}
+
+// Stat gets the information of the file
+//
+// implements fs.File
+func (file *cachedFileReader) Stat() (fs.FileInfo, error) {
+	return cachedFileInfo{file.CachedFile}, nil
+}
+
+// Close closes the file
+//
+// implements fs.File
+func (file *cachedFileReader) Close() error {
+	return nil
+}
+
+// cachedFileInfo is the fs.FileInfo of a CachedFile
+//
+// Sys returns the CachedFile, to get its content type.
+type cachedFileInfo struct {
+	file *CachedFile
+}
+
+func (info cachedFileInfo) Name() string       { return info.file.Name }
+func (info cachedFileInfo) Size() int64        { return int64(len(info.file.Data)) }
+func (info cachedFileInfo) Mode() fs.FileMode  { return info.file.Mode }
+func (info cachedFileInfo) ModTime() time.Time { return info.file.ModTime }
+func (info cachedFileInfo) IsDir() bool        { return false }
+func (info cachedFileInfo) Sys() any           { return info.file }
@@ -0,0 +1,76 @@
+package cache_test
+
+import (
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing/fstest"
+	"time"
+
+	"github.com/gildas/go-cache"
+)
+
+type countingFS struct {
+	fstest.MapFS
+	opens int
+}
+
+func (origin *countingFS) Open(name string) (fs.File, error) {
+	origin.opens++
+	return origin.MapFS.Open(name)
+}
+
+func (suite *CacheSuite) TestCanCacheFileSystem() {
+	modTime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	origin := &countingFS{MapFS: fstest.MapFS{
+		"css/site.css": &fstest.MapFile{Data: []byte("body {}"), ModTime: modTime},
+		"data":         &fstest.MapFile{Data: []byte("<html><body></body></html>"), ModTime: modTime},
+	}}
+	files := cache.NewFS("test", origin, time.Minute)
+
+	for range 2 {
+		file, err := files.Open("css/site.css")
+		suite.Require().NoError(err, "Failed to open file: %+v", err)
+		data, err := io.ReadAll(file)
+		suite.Require().NoError(err, "Failed to read file: %+v", err)
+		suite.Assert().Equal("body {}", string(data))
+		info, err := file.Stat()
+		suite.Require().NoError(err, "Failed to stat file: %+v", err)
+		suite.Assert().Equal("site.css", info.Name())
+		suite.Assert().Equal(int64(7), info.Size())
+		suite.Assert().True(modTime.Equal(info.ModTime()))
+		suite.Require().IsType(&cache.CachedFile{}, info.Sys())
+		suite.Assert().Contains(info.Sys().(*cache.CachedFile).ContentType, "text/css")
+		suite.Require().NoError(file.Close())
+	}
+	suite.Assert().Equal(1, origin.opens, "The file should be read from the origin once")
+
+	file, err := files.Open("data")
+	suite.Require().NoError(err, "Failed to open file: %+v", err)
+	info, _ := file.Stat()
+	suite.Assert().Contains(info.Sys().(*cache.CachedFile).ContentType, "text/html", "The content type should be detected")
+
+	_, err = files.Open("missing.txt")
+	suite.Require().ErrorIs(err, fs.ErrNotExist)
+
+	dir, err := files.Open("css")
+	suite.Require().NoError(err, "Failed to open directory: %+v", err)
+	info, _ = dir.Stat()
+	suite.Assert().True(info.IsDir())
+}
+
+func (suite *CacheSuite) TestCanServeCachedFileSystem() {
+	origin := fstest.MapFS{"index.txt": &fstest.MapFile{Data: []byte("Hello World"), ModTime: time.Now()}}
+	server := httptest.NewServer(http.FileServer(http.FS(cache.NewFS("test", origin, time.Minute))))
+	defer server.Close()
+
+	request, _ := http.NewRequest(http.MethodGet, server.URL+"/index.txt", nil)
+	request.Header.Set("Range", "bytes=6-")
+	response, err := http.DefaultClient.Do(request)
+	suite.Require().NoError(err, "Failed to get file: %+v", err)
+	defer response.Body.Close()
+	body, _ := io.ReadAll(response.Body)
+	suite.Assert().Equal(http.StatusPartialContent, response.StatusCode)
+	suite.Assert().Equal("World", string(body))
+}
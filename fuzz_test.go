@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+)
+
+func FuzzDecodeRecord(f *testing.F) {
+	item, _ := json.Marshal(record[string]{Key: "key", Item: "value", Expiration: 42})
+	f.Add(frame(item, frameMetadata{}))
+	f.Add(item)
+	var buffer bytes.Buffer
+	_ = encodeRaw(&buffer, record[[]byte]{Key: "key", Item: []byte("value")})
+	f.Add(frame(buffer.Bytes(), frameMetadata{expiration: 1}))
+	f.Add(buffer.Bytes())
+	f.Add([]byte("GCIT\x01\x00\x00\x00\x00"))
+	f.Add([]byte("GCRW\x01\xff\xff\xff\xff"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		_, _, _, _ = DecodeRecord[string](data)
+		_, _, _, _ = DecodeRecord[[]byte](data)
+		_, _, _, _ = DecodeRecord[map[string]any](data)
+	})
+}
+
+func FuzzDecryptWithKey(f *testing.F) {
+	key := []byte("@v3ry#S3cr3tK3y!")
+	committed, _ := encryptCommitted(rand.Reader, key, []byte("Hello, World!"), []byte("test"))
+	legacy, _ := encrypt(key, []byte("Hello, World!"))
+	f.Add(key, committed, []byte("test"))
+	f.Add(key, legacy, []byte(nil))
+	f.Add(key, []byte("GCE\x02"), []byte(nil))
+	f.Add([]byte("short"), committed, []byte("test"))
+
+	f.Fuzz(func(t *testing.T, key, data, additional []byte) {
+		_, _ = decryptWithKey(key, data, additional)
+	})
+}
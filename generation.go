@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"os"
+
+	"github.com/gildas/go-errors"
+)
+
+// generationKey is the key of the generation of a persistent cache
+//
+// It starts with a NUL so it does not collide with the keys of the items.
+const generationKey = "\x00generation"
+
+// Generation gets the current generation of the cache
+//
+// The items set in a previous generation are not found anymore, see BumpGeneration.
+func (cache *Cache[T]) Generation() uint64 {
+	return cache.generation(cache.settings())
+}
+
+// BumpGeneration starts a new generation of the cache and returns it
+//
+// All the items of the previous generations are invalidated at once, the items of the multi-value entries
+// and of the tenant views too: they are treated as misses, and removed from the disk when they are looked up.
+// Unlike Clear, the persisted files are not walked, so it takes the same time for huge persistent caches.
+//
+// The generation of a persistent cache is persisted with its items, the tenants that are not loaded get a new generation on the disk.
+func (cache *Cache[T]) BumpGeneration() (generation uint64, err error) {
+	config := cache.settings()
+	_ = cache.generation(config)
+	generation = cache.epoch.Add(1) - 1
+	cache.write(func(storage *store[T]) { storage.clear() })
+	cache.sets.Range(func(key, value interface{}) bool {
+		cache.sets.Delete(key)
+		return true
+	})
+	if config.persistent {
+		err = config.persistGeneration(generation)
+	}
+	cache.tenants.Range(func(id, view interface{}) bool {
+		if _, verr := view.(*Cache[T]).BumpGeneration(); err == nil {
+			err = verr
+		}
+		return true
+	})
+	if config.persistent {
+		for _, name := range cache.unloadedTenants(config) {
+			if terr := config.tenantSettings(name).bumpPersistedGeneration(); err == nil {
+				err = terr
+			}
+		}
+	}
+	return
+}
+
+// bumpPersistedGeneration starts a new generation in the folder of the settings, for a cache that is not loaded
+func (config *settings) bumpPersistedGeneration() error {
+	var persisted persistedGeneration
+
+	if err := config.restore(config.filekey(generationKey), &persisted); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return config.persistGeneration(persisted.Generation + 1)
+}
+
+// persistedGeneration is the content of the file that persists the generation of a cache
+type persistedGeneration struct {
+	Generation uint64
+}
+
+// persistGeneration writes the generation of a persistent cache
+func (config *settings) persistGeneration(generation uint64) error {
+	return config.persist(config.filekey(generationKey), persistedGeneration{Generation: generation})
+}
+
+// generation gets the current generation, loading it from the disk the first time
+//
+// The epoch holds the generation plus one, zero means it has not been loaded yet.
+func (cache *Cache[T]) generation(config *settings) uint64 {
+	if epoch := cache.epoch.Load(); epoch > 0 {
+		return epoch - 1
+	}
+	var persisted persistedGeneration
+
+	if config.persistent {
+		quiet := *config // loading the generation is not an operation of the cache
+		quiet.recorder = nil
+		_ = quiet.restore(config.filekey(generationKey), &persisted)
+	}
+	cache.epoch.CompareAndSwap(0, persisted.Generation+1)
+	return cache.epoch.Load() - 1
+}
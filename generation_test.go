@@ -0,0 +1,92 @@
+package cache_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+func (suite *CacheSuite) TestCanBumpGeneration() {
+	users := cache.New[User]("test")
+	joe := User{ID: uuid.New(), Name: "Joe"}
+	suite.Require().NoError(users.Set(joe))
+	suite.Assert().Equal(uint64(0), users.Generation())
+
+	generation, err := users.BumpGeneration()
+	suite.Require().NoError(err, "Failed to bump the generation: %+v", err)
+	suite.Assert().Equal(uint64(1), generation)
+	suite.Assert().Equal(uint64(1), users.Generation())
+	_, err = users.Get(joe.ID.String())
+	suite.Assert().ErrorIs(err, errors.NotFound, "The items of the previous generation should be misses")
+
+	suite.Require().NoError(users.Set(joe))
+	_, err = users.Get(joe.ID.String())
+	suite.Assert().NoError(err, "The items of the current generation should be found: %+v", err)
+}
+
+func (suite *CacheSuite) TestCanBumpGenerationOfPersistentCache() {
+	users := cache.New[User]("test-generation", cache.CacheOptionPersistent)
+	folder, _ := os.UserCacheDir()
+	defer func() { _ = os.RemoveAll(filepath.Join(folder, "test-generation")) }()
+	joe := User{ID: uuid.New(), Name: "Joe"}
+	suite.Require().NoError(users.Set(joe))
+	_, err := users.BumpGeneration()
+	suite.Require().NoError(err, "Failed to bump the generation: %+v", err)
+	ann := User{ID: uuid.New(), Name: "Ann"}
+	suite.Require().NoError(users.Set(ann))
+
+	reopened := cache.New[User]("test-generation", cache.CacheOptionPersistent)
+	suite.Assert().Equal(uint64(1), reopened.Generation(), "The generation should be persisted")
+	_, err = reopened.Get(joe.ID.String())
+	suite.Assert().ErrorIs(err, errors.NotFound, "The items of the previous generation should be misses")
+	_, err = os.Stat(filepath.Join(folder, "test-generation", uuid.NewSHA1(uuid.Nil, []byte(joe.ID.String())).String()))
+	suite.Assert().ErrorIs(err, os.ErrNotExist, "The items of the previous generation should be removed lazily")
+	cached, err := reopened.Get(ann.ID.String())
+	suite.Require().NoError(err, "Failed to get user: %+v", err)
+	suite.Assert().Equal(ann, *cached)
+
+	suite.Require().NoError(users.Clear())
+	reopened = cache.New[User]("test-generation", cache.CacheOptionPersistent)
+	suite.Assert().Equal(uint64(1), reopened.Generation(), "Clear should keep the generation")
+}
+
+func (suite *CacheSuite) TestCanBumpGenerationOfSets() {
+	users := cache.New[User]("test-generation-sets", cache.CacheOptionPersistent)
+	folder, _ := os.UserCacheDir()
+	defer func() { _ = os.RemoveAll(filepath.Join(folder, "test-generation-sets")) }()
+	suite.Require().NoError(users.Add("admins", User{ID: uuid.New(), Name: "Joe"}))
+	_, err := users.BumpGeneration()
+	suite.Require().NoError(err, "Failed to bump the generation: %+v", err)
+	_, err = users.GetAll("admins")
+	suite.Assert().ErrorIs(err, errors.NotFound, "The records of the previous generation should be misses")
+
+	ann := User{ID: uuid.New(), Name: "Ann"}
+	suite.Require().NoError(users.Add("admins", ann))
+	reopened := cache.New[User]("test-generation-sets", cache.CacheOptionPersistent)
+	admins, err := reopened.GetAll("admins")
+	suite.Require().NoError(err, "Failed to get the admins: %+v", err)
+	suite.Assert().Equal([]User{ann}, admins, "Only the records of the current generation should be kept")
+}
+
+func (suite *CacheSuite) TestCanBumpGenerationOfTenants() {
+	users := cache.New[User]("test-generation-tenants", cache.CacheOptionPersistent)
+	folder, _ := os.UserCacheDir()
+	defer func() { _ = os.RemoveAll(filepath.Join(folder, "test-generation-tenants")) }()
+	joe := User{ID: uuid.New(), Name: "Joe"}
+	suite.Require().NoError(users.ForTenant("acme").Set(joe, "joe"))
+	suite.Require().NoError(users.ForTenant("globex").Set(joe, "joe"))
+
+	reopened := cache.New[User]("test-generation-tenants", cache.CacheOptionPersistent)
+	_, err := reopened.ForTenant("acme").Get("joe")
+	suite.Require().NoError(err, "Failed to get user: %+v", err)
+	_, err = reopened.BumpGeneration()
+	suite.Require().NoError(err, "Failed to bump the generation: %+v", err)
+	_, err = reopened.ForTenant("acme").Get("joe")
+	suite.Assert().ErrorIs(err, errors.NotFound, "The items of the loaded tenants should be misses")
+	_, err = cache.New[User]("test-generation-tenants", cache.CacheOptionPersistent).ForTenant("globex").Get("joe")
+	suite.Assert().ErrorIs(err, errors.NotFound, "The items of the tenants that are not loaded should be misses")
+}
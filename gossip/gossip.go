@@ -0,0 +1,238 @@
+// Package gossip replicates the changes of a Cache between the instances of a small cluster
+//
+// The instances discover each other with hashicorp/memberlist, and send the items set, the keys
+// deleted, and the clears of their cache to the other members. The items kept in memory only, see cache.SetVolatile,
+// are not replicated. The changes are sent as JSON, so an encrypted cache is replicated only
+// within a cluster encrypted with memberlist's SecretKey or Keyring. The replication is best-effort: the caches are
+// eventually consistent, changes made while a member is unreachable are not sent to it later,
+// and concurrent changes of the same key may end differently on different members.
+// Purge replicates only the keys it removes from memory, and BumpGeneration is not replicated, see cache.OnChange.
+// The items set with cache.SetImmutable or cache.SetWithDependencies are set the same way on the other members.
+//
+//	users := cache.New[User]("users")
+//	replica, err := gossip.Join(users, memberlist.DefaultLANConfig(), "10.0.0.1:7946")
+//	defer replica.Leave(time.Second)
+//	err = users.Set(user, user.ID) // replicated to the other members
+package gossip
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+	"github.com/hashicorp/memberlist"
+)
+
+// Actor is the actor of the changes applied from other members, see cache.WithActor
+const Actor = "gossip"
+
+// queueSize is the number of changes waiting to be sent before new changes are dropped
+const queueSize = 1024
+
+// ErrNotEncrypted is returned by Join when the cache is encrypted and the cluster is not
+//
+// Its What is the name of the cache.
+var ErrNotEncrypted = errors.NewSentinel(http.StatusBadRequest, "error.gossip.encryption.missing", "Cache %s is encrypted but the cluster is not")
+
+// Replica replicates the changes of a Cache with the other members of a cluster
+type Replica[T any] struct {
+	Cache   *cache.Cache[T]
+	members *memberlist.Memberlist
+	queue   chan []byte
+	done    chan struct{}
+	leave   sync.Once
+}
+
+// message is a change sent to the other members
+type message[T any] struct {
+	Cache        string               `json:"cache"`
+	Operation    cache.AuditOperation `json:"op"`
+	Key          string               `json:"key,omitempty"`
+	Tenant       string               `json:"tenant,omitempty"`
+	Item         T                    `json:"item,omitzero"`
+	Expiration   time.Time            `json:"expiration,omitzero"`
+	Immutable    bool                 `json:"immutable,omitempty"`
+	Dependencies []string             `json:"dependencies,omitempty"`
+}
+
+// remoteKey marks the contexts of the changes applied from other members, so they are not sent back
+type remoteKey struct{}
+
+// Join starts a member of the cluster with the given memberlist configuration and joins the given peers
+//
+// The member replicates the changes of the cache, whose OnChange callback it takes over.
+// Without peers, the member starts a new cluster that other members can join.
+// All the members of a cluster must replicate caches with the same name.
+//
+// When the cache is encrypted on the disk or in memory, the memberlist configuration must have a SecretKey
+// or a Keyring so the items are encrypted on the network too, otherwise ErrNotEncrypted is returned.
+// The cache must then be encrypted before it joins the cluster.
+func Join[T any](cache *cache.Cache[T], config *memberlist.Config, peers ...string) (replica *Replica[T], err error) {
+	if description := cache.Describe(); (len(description.Cipher) > 0 || len(description.MemoryCipher) > 0) && config.Keyring == nil && len(config.SecretKey) == 0 {
+		return nil, ErrNotEncrypted.With(cache.Name)
+	}
+	replica = &Replica[T]{
+		Cache: cache,
+		queue: make(chan []byte, queueSize),
+		done:  make(chan struct{}),
+	}
+	config.Delegate = delegate[T]{replica}
+	if replica.members, err = memberlist.Create(config); err != nil {
+		return nil, err
+	}
+	if len(peers) > 0 {
+		if _, err = replica.members.Join(peers); err != nil {
+			_ = replica.members.Shutdown()
+			return nil, err
+		}
+	}
+	go replica.send()
+	cache.OnChange(replica.changed)
+	return replica, nil
+}
+
+// Address gets the address other members can join this member at
+func (replica *Replica[T]) Address() string {
+	return replica.members.LocalNode().Address()
+}
+
+// Members gets the names of the members of the cluster, this member included
+func (replica *Replica[T]) Members() []string {
+	members := replica.members.Members()
+	names := make([]string, 0, len(members))
+	for _, member := range members {
+		names = append(names, member.Name)
+	}
+	return names
+}
+
+// Leave stops the replication and leaves the cluster, waiting at most timeout for the other members to know
+//
+// Calling Leave again does nothing.
+func (replica *Replica[T]) Leave(timeout time.Duration) (err error) {
+	replica.leave.Do(func() {
+		replica.Cache.OnChange(nil)
+		close(replica.done)
+		err = replica.members.Leave(timeout)
+		if shutdownErr := replica.members.Shutdown(); err == nil {
+			err = shutdownErr
+		}
+	})
+	return
+}
+
+// changed queues a change of the cache to be sent to the other members
+//
+// The items kept in memory only are not sent. When the queue is full, the change is dropped.
+func (replica *Replica[T]) changed(ctx context.Context, change cache.Change[T]) {
+	if remote, _ := ctx.Value(remoteKey{}).(bool); remote || change.Tier == cache.TierMemoryOnly {
+		return
+	}
+	payload, err := json.Marshal(message[T]{
+		Cache:        replica.Cache.Name,
+		Operation:    change.Operation,
+		Key:          change.Key,
+		Tenant:       change.Tenant,
+		Item:         change.Item,
+		Expiration:   change.Expiration,
+		Immutable:    change.Immutable,
+		Dependencies: change.Dependencies,
+	})
+	if err != nil {
+		return
+	}
+	select {
+	case replica.queue <- payload:
+	default:
+	}
+}
+
+// send sends the queued changes to the other members until the replica leaves the cluster
+func (replica *Replica[T]) send() {
+	for {
+		select {
+		case <-replica.done:
+			return
+		case payload := <-replica.queue:
+			local := replica.members.LocalNode()
+			for _, member := range replica.members.Members() {
+				if member.Name != local.Name {
+					_ = replica.members.SendReliable(member, payload)
+				}
+			}
+		}
+	}
+}
+
+// apply applies a change received from another member
+func (replica *Replica[T]) apply(payload []byte) {
+	var change message[T]
+
+	if err := json.Unmarshal(payload, &change); err != nil || change.Cache != replica.Cache.Name {
+		return
+	}
+	if len(change.Key) == 0 && change.Operation != cache.AuditClear {
+		return
+	}
+	target := replica.Cache
+	if len(change.Tenant) > 0 {
+		target = target.ForTenant(change.Tenant)
+	}
+	ctx := cache.WithActor(context.WithValue(context.Background(), remoteKey{}, true), Actor)
+	switch change.Operation {
+	case cache.AuditSet:
+		var expiration time.Duration
+		if !change.Expiration.IsZero() {
+			if expiration = time.Until(change.Expiration); expiration <= 0 {
+				return
+			}
+		}
+		switch {
+		case change.Immutable:
+			_ = target.SetImmutableWithExpirationContext(ctx, change.Item, expiration, change.Key)
+		case len(change.Dependencies) > 0:
+			_ = target.SetWithDependenciesAndExpirationContext(ctx, change.Item, expiration, change.Dependencies, change.Key)
+		default:
+			_ = target.SetWithExpirationContext(ctx, change.Item, expiration, change.Key)
+		}
+	case cache.AuditDelete:
+		_ = target.DeleteContext(ctx, change.Key)
+	case cache.AuditClear:
+		_ = target.ClearContext(ctx, nil)
+	}
+}
+
+// delegate receives the messages of the other members
+//
+// implements memberlist.Delegate
+type delegate[T any] struct {
+	replica *Replica[T]
+}
+
+// NodeMeta gets the metadata of the member, there is none
+func (delegate delegate[T]) NodeMeta(limit int) []byte {
+	return nil
+}
+
+// NotifyMsg applies a change sent by another member
+func (delegate delegate[T]) NotifyMsg(payload []byte) {
+	delegate.replica.apply(payload)
+}
+
+// GetBroadcasts gets the messages to gossip, the changes are sent directly instead
+func (delegate delegate[T]) GetBroadcasts(overhead, limit int) [][]byte {
+	return nil
+}
+
+// LocalState gets the state sent to the members that join, the caches are not synchronized
+func (delegate delegate[T]) LocalState(join bool) []byte {
+	return nil
+}
+
+// MergeRemoteState merges the state of a member that joins, the caches are not synchronized
+func (delegate delegate[T]) MergeRemoteState(payload []byte, join bool) {
+}
@@ -0,0 +1,162 @@
+package gossip_test
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/memberlist"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-cache/gossip"
+)
+
+type GossipSuite struct {
+	suite.Suite
+}
+
+func TestGossipSuite(t *testing.T) {
+	suite.Run(t, new(GossipSuite))
+}
+
+func (suite *GossipSuite) join(name string, peers ...string) *gossip.Replica[string] {
+	replica, err := gossip.Join(cache.New[string]("test-gossip"), localConfig(name), peers...)
+	suite.Require().NoError(err, "Failed to join the cluster: %+v", err)
+	return replica
+}
+
+// localConfig gets the memberlist configuration of a member listening on the loopback
+func localConfig(name string) *memberlist.Config {
+	config := memberlist.DefaultLocalConfig()
+	config.Name = name
+	config.BindAddr = "127.0.0.1"
+	config.BindPort = 0
+	config.LogOutput = io.Discard
+	return config
+}
+
+func (suite *GossipSuite) TestCanReplicateChanges() {
+	first := suite.join("first")
+	defer func() { _ = first.Leave(time.Second) }()
+	second := suite.join("second", first.Address())
+	defer func() { _ = second.Leave(time.Second) }()
+	suite.Require().Eventually(func() bool { return len(first.Members()) == 2 }, 5*time.Second, 10*time.Millisecond, "The members should know each other")
+
+	err := first.Cache.SetWithExpiration("Hello", time.Hour, "greeting")
+	suite.Require().NoError(err, "Failed to set item: %+v", err)
+	suite.Require().Eventually(func() bool {
+		value, found := second.Cache.GetValue("greeting")
+		return found && value == "Hello"
+	}, 5*time.Second, 10*time.Millisecond, "The item should be replicated")
+
+	err = second.Cache.Delete("greeting")
+	suite.Require().NoError(err, "Failed to delete item: %+v", err)
+	suite.Assert().Eventually(func() bool {
+		_, found := first.Cache.GetValue("greeting")
+		return !found
+	}, 5*time.Second, 10*time.Millisecond, "The deletion should be replicated")
+}
+
+func (suite *GossipSuite) TestCanReplicateClears() {
+	first := suite.join("first")
+	defer func() { _ = first.Leave(time.Second) }()
+	second := suite.join("second", first.Address())
+	defer func() { _ = second.Leave(time.Second) }()
+	suite.Require().Eventually(func() bool { return len(first.Members()) == 2 }, 5*time.Second, 10*time.Millisecond, "The members should know each other")
+
+	suite.Require().NoError(first.Cache.Set("Hello", "greeting"))
+	suite.Require().Eventually(func() bool {
+		_, found := second.Cache.GetValue("greeting")
+		return found
+	}, 5*time.Second, 10*time.Millisecond, "The item should be replicated")
+
+	suite.Require().NoError(first.Cache.Clear())
+	suite.Assert().Eventually(func() bool {
+		_, found := second.Cache.GetValue("greeting")
+		return !found
+	}, 5*time.Second, 10*time.Millisecond, "The clear should be replicated")
+}
+
+func (suite *GossipSuite) TestShouldNotReplicateVolatileItems() {
+	first := suite.join("first")
+	defer func() { _ = first.Leave(time.Second) }()
+	second := suite.join("second", first.Address())
+	defer func() { _ = second.Leave(time.Second) }()
+	suite.Require().Eventually(func() bool { return len(first.Members()) == 2 }, 5*time.Second, 10*time.Millisecond, "The members should know each other")
+
+	suite.Require().NoError(first.Cache.SetVolatile("secret", "volatile"))
+	suite.Require().NoError(first.Cache.Set("Hello", "greeting"))
+	suite.Require().Eventually(func() bool {
+		_, found := second.Cache.GetValue("greeting")
+		return found
+	}, 5*time.Second, 10*time.Millisecond, "The item should be replicated")
+	_, found := second.Cache.GetValue("volatile")
+	suite.Assert().False(found, "The volatile item should not be replicated")
+}
+
+func (suite *GossipSuite) TestShouldNotJoinUnencryptedClusterWithEncryptedCache() {
+	users := cache.New[string]("test-gossip").WithMemoryEncryption()
+	_, err := gossip.Join(users, localConfig("first"))
+	suite.Require().ErrorIs(err, gossip.ErrNotEncrypted)
+
+	config := localConfig("first")
+	config.SecretKey = []byte("@v3ry#S3cr3tK3y!")
+	replica, err := gossip.Join(users, config)
+	suite.Require().NoError(err, "Failed to join the encrypted cluster: %+v", err)
+	_ = replica.Leave(time.Second)
+}
+
+func (suite *GossipSuite) TestCanReplicateTenantViewsCreatedBeforeJoin() {
+	users := cache.New[string]("test-gossip")
+	acme := users.ForTenant("acme")
+	first, err := gossip.Join(users, localConfig("first"))
+	suite.Require().NoError(err, "Failed to join the cluster: %+v", err)
+	defer func() { _ = first.Leave(time.Second) }()
+	second := suite.join("second", first.Address())
+	defer func() { _ = second.Leave(time.Second) }()
+	suite.Require().Eventually(func() bool { return len(first.Members()) == 2 }, 5*time.Second, 10*time.Millisecond, "The members should know each other")
+
+	suite.Require().NoError(acme.Set("Hello", "greeting"))
+	suite.Require().Eventually(func() bool {
+		_, found := second.Cache.ForTenant("acme").GetValue("greeting")
+		return found
+	}, 5*time.Second, 10*time.Millisecond, "The item of the tenant should be replicated")
+
+	purged, err := users.Purge(func(key string, item string) bool { return key == "greeting" })
+	suite.Require().NoError(err, "Failed to purge: %+v", err)
+	suite.Require().Equal(1, purged)
+	suite.Assert().Eventually(func() bool {
+		_, found := second.Cache.ForTenant("acme").GetValue("greeting")
+		return !found
+	}, 5*time.Second, 10*time.Millisecond, "The purge should be replicated")
+}
+
+func (suite *GossipSuite) TestCanLeaveTwice() {
+	replica := suite.join("first")
+	suite.Require().NoError(replica.Leave(time.Second))
+	suite.Assert().NotPanics(func() { _ = replica.Leave(time.Second) }, "Leaving again should do nothing")
+}
+
+func (suite *GossipSuite) TestCanReplicateImmutableItemsAndDependencies() {
+	first := suite.join("first")
+	defer func() { _ = first.Leave(time.Second) }()
+	second := suite.join("second", first.Address())
+	defer func() { _ = second.Leave(time.Second) }()
+	suite.Require().Eventually(func() bool { return len(first.Members()) == 2 }, 5*time.Second, 10*time.Millisecond, "The members should know each other")
+
+	suite.Require().NoError(first.Cache.SetImmutable("production", "environment"))
+	suite.Require().NoError(first.Cache.Set("sales", "report"))
+	suite.Require().NoError(first.Cache.SetWithDependencies("summary", []string{"report"}, "summary"))
+	suite.Require().Eventually(func() bool {
+		_, immutable := second.Cache.GetValue("environment")
+		_, summary := second.Cache.GetValue("summary")
+		return immutable && summary
+	}, 5*time.Second, 10*time.Millisecond, "The items should be replicated")
+
+	err := second.Cache.Set("staging", "environment")
+	suite.Assert().ErrorIs(err, cache.ErrImmutable, "The replicated item should be immutable")
+	suite.Require().NoError(second.Cache.Delete("report"))
+	_, found := second.Cache.GetValue("summary")
+	suite.Assert().False(found, "The replicated dependencies should cascade")
+}
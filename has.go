@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gildas/go-errors"
+)
+
+// Has tells if the given key holds an item that has not expired, in memory or on the disk
+//
+// Unlike Get, Has does not decrypt or unmarshal the item: the expiration of a persisted item
+// is read from the header of its file. The header is not covered by WithSigningKey.
+// Only the items persisted with an older header are read in full.
+//
+// Has is not a read of the item: the statistics, the eviction policy, and the expiration of the key do not change.
+func (cache *Cache[T]) Has(key string) bool {
+	config := cache.settings()
+	metadata, found := cache.metadata(config, key)
+	return found && cache.current(config, metadata)
+}
+
+// metadata gets the metadata of the record of a key in memory or on the disk, without reading its item
+func (cache *Cache[T]) metadata(config *settings, key string) (frameMetadata, bool) {
+	if metadata, found := cache.storage().peek(key); found {
+		return metadata, true
+	}
+	if !config.persistent || len(key) == 0 {
+		return frameMetadata{}, false
+	}
+	filekey := config.filekey(key)
+	if config.coalescer != nil {
+		if pending, found := config.coalescer.get(config, filekey); found {
+			return metadataOf(pending), true
+		}
+	}
+	if config.writeBehind != nil {
+		if pending, found := config.writeBehind.get(config, filekey); found {
+			return metadataOf(pending), true
+		}
+	}
+	metadata, known, err := config.peek(filekey)
+	if err != nil {
+		return frameMetadata{}, false
+	}
+	if !known {
+		var entry record[T]
+
+		if err = config.restore(filekey, &entry); err != nil {
+			return frameMetadata{}, false
+		}
+		metadata = entry.metadata()
+	}
+	return metadata, true
+}
+
+// current tells if an item with the given metadata is of the current generation and has not expired
+func (cache *Cache[T]) current(config *settings, metadata frameMetadata) bool {
+	return metadata.live(cache.generation(config), time.Now().UnixNano())
+}
+
+// peek reads the metadata from the header of the file named filekey
+//
+// known is false when the file was persisted without metadata in its header.
+func (config *settings) peek(filekey string) (metadata frameMetadata, known bool, err error) {
+	var file *os.File
+	var read int
+
+	if config.bloom != nil && !config.bloom.mayContain(config.folder, filepath.Base(filekey)) {
+		return metadata, false, os.ErrNotExist
+	}
+	release := config.acquireIO()
+	defer release()
+	file, err = os.Open(filepath.Join(config.folder, filekey))
+	if errors.Is(err, os.ErrNotExist) && config.fanOut && config.unflatten(filekey) {
+		file, err = os.Open(filepath.Join(config.folder, filekey))
+	}
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	header := make([]byte, frameHeaderSize)
+	if read, err = io.ReadFull(file, header); err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return
+	}
+	metadata, known = unframeMetadata(header[:read])
+	return metadata, known, nil
+}
@@ -0,0 +1,33 @@
+package cache_test
+
+import (
+	"time"
+
+	"github.com/gildas/go-cache"
+)
+
+func (suite *CacheSuite) TestCanCheckIfCacheHasKey() {
+	names := cache.New[string]("test")
+	suite.Require().NoError(names.Set("value", "key"))
+	suite.Require().NoError(names.SetWithExpiration("value", time.Millisecond, "short"))
+	suite.Assert().True(names.Has("key"))
+	suite.Assert().False(names.Has("unknown"))
+	time.Sleep(5 * time.Millisecond)
+	suite.Assert().False(names.Has("short"), "An expired item should not be found")
+}
+
+func (suite *CacheSuite) TestCanCheckIfPersistentCacheHasKeyWithoutDecrypting() {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	writer := cache.New[Credentials]("test", cache.CacheOptionPersistent).WithEncryptionKey(key)
+	suite.Require().NoError(writer.Set(Credentials{Username: "joe"}, "joe"))
+	suite.Require().NoError(writer.SetWithExpiration(Credentials{Username: "jane"}, time.Millisecond, "jane"))
+	time.Sleep(5 * time.Millisecond)
+
+	reader := cache.New[Credentials]("test", cache.CacheOptionPersistent).WithEncryptionKey([]byte("fedcba9876543210fedcba9876543210"))
+	suite.Assert().True(reader.Has("joe"), "The item should be found without being decrypted")
+	suite.Assert().False(reader.Has("jane"), "An expired item should not be found")
+	suite.Assert().False(reader.Has("unknown"))
+	suite.Assert().Zero(reader.Stats().Hits+reader.Stats().Misses, "Has should not count as a read")
+	_, err := reader.Get("joe")
+	suite.Assert().Error(err, "The item cannot be decrypted with another key")
+}
@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gildas/go-errors"
+)
+
+// IdempotencyState is the state of a request in an IdempotencyStore
+type IdempotencyState string
+
+const (
+	// IdempotencyPending means the request is being processed
+	IdempotencyPending IdempotencyState = "pending"
+	// IdempotencySucceeded means the request was processed and its result is recorded
+	IdempotencySucceeded IdempotencyState = "succeeded"
+	// IdempotencyFailed means the request failed and its error is recorded
+	IdempotencyFailed IdempotencyState = "failed"
+)
+
+// IdempotencyRecord is the record of a request in an IdempotencyStore
+type IdempotencyRecord[T any] struct {
+	Key         string           `json:"key"`
+	State       IdempotencyState `json:"state"`
+	Result      T                `json:"result,omitempty"`
+	Error       string           `json:"error,omitempty"`
+	StartedAt   time.Time        `json:"startedAt"`
+	CompletedAt time.Time        `json:"completedAt,omitempty"`
+}
+
+// IdempotencyStore records the requests processed by an API server by their idempotency key
+//
+// A request that is retried with the same idempotency key gets the recorded result
+// instead of being processed again.
+//
+// Pending requests are recorded for PendingTTL, so a request whose processing
+// crashed can be retried. Completed requests are recorded for CompletedTTL.
+//
+// Only one request per key is started at a time in a process. Processes that share
+// a persistent cache folder may start the same request concurrently.
+type IdempotencyStore[T any] struct {
+	Cache        *Cache[IdempotencyRecord[T]]
+	PendingTTL   time.Duration
+	CompletedTTL time.Duration
+	mutex        sync.Mutex
+}
+
+// NewIdempotencyStore creates a new IdempotencyStore
+func NewIdempotencyStore[T any](name string, pendingTTL, completedTTL time.Duration, option ...CacheOption) *IdempotencyStore[T] {
+	return &IdempotencyStore[T]{
+		Cache:        New[IdempotencyRecord[T]](name, option...).WithExplicitKeysOnly(),
+		PendingTTL:   pendingTTL,
+		CompletedTTL: completedTTL,
+	}
+}
+
+// Begin records that the request with the given idempotency key is being processed
+//
+// If the request is already recorded, its record is returned and started is false.
+// Otherwise, a pending record is returned and started is true: the caller must
+// process the request and call Succeed or Fail.
+func (store *IdempotencyStore[T]) Begin(key string) (record *IdempotencyRecord[T], started bool, err error) {
+	if len(key) == 0 {
+		return nil, false, errors.ArgumentMissing.With("key")
+	}
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	if record, err = store.Cache.Get(key); err == nil {
+		return record, false, nil
+	} else if !errors.Is(err, errors.NotFound) {
+		return nil, false, err
+	}
+	record = &IdempotencyRecord[T]{Key: key, State: IdempotencyPending, StartedAt: time.Now().UTC()}
+	if err = store.Cache.SetWithExpiration(*record, store.PendingTTL, key); err != nil {
+		return nil, false, err
+	}
+	return record, true, nil
+}
+
+// Succeed records the result of the request with the given idempotency key
+func (store *IdempotencyStore[T]) Succeed(key string, result T) error {
+	return store.complete(key, IdempotencyRecord[T]{State: IdempotencySucceeded, Result: result})
+}
+
+// Fail records the error of the request with the given idempotency key
+func (store *IdempotencyStore[T]) Fail(key string, failure error) error {
+	return store.complete(key, IdempotencyRecord[T]{State: IdempotencyFailed, Error: failure.Error()})
+}
+
+// Get gets the record of the request with the given idempotency key
+func (store *IdempotencyStore[T]) Get(key string) (*IdempotencyRecord[T], error) {
+	return store.Cache.Get(key)
+}
+
+// Do processes the request with the given idempotency key with fn, unless it was already processed
+//
+// If the request succeeded before, its result is returned.
+// If it failed before, an ErrIdempotencyFailed with the original error message is returned.
+// If it is still being processed, an ErrIdempotencyPending is returned.
+func (store *IdempotencyStore[T]) Do(ctx context.Context, key string, fn func(context.Context) (T, error)) (result T, err error) {
+	var record *IdempotencyRecord[T]
+	var started bool
+
+	if record, started, err = store.Begin(key); err != nil {
+		return
+	}
+	if !started {
+		switch record.State {
+		case IdempotencySucceeded:
+			return record.Result, nil
+		case IdempotencyFailed:
+			return result, ErrIdempotencyFailed.With(key, record.Error)
+		default:
+			return result, ErrIdempotencyPending.With(key)
+		}
+	}
+	if result, err = fn(ctx); err != nil {
+		_ = store.Fail(key, err)
+		return
+	}
+	return result, store.Succeed(key, result)
+}
+
+// complete records the completed request with the given idempotency key
+func (store *IdempotencyStore[T]) complete(key string, record IdempotencyRecord[T]) error {
+	if len(key) == 0 {
+		return errors.ArgumentMissing.With("key")
+	}
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	record.Key = key
+	record.CompletedAt = time.Now().UTC()
+	record.StartedAt = record.CompletedAt
+	if pending, err := store.Cache.Get(key); err == nil {
+		record.StartedAt = pending.StartedAt
+	}
+	return store.Cache.SetWithExpiration(record, store.CompletedTTL, key)
+}
@@ -0,0 +1,66 @@
+package cache_test
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+func (suite *CacheSuite) TestCanProcessRequestOnce() {
+	var calls atomic.Int32
+	store := cache.NewIdempotencyStore[string]("test", time.Minute, time.Hour)
+	charge := func(ctx context.Context) (string, error) {
+		calls.Add(1)
+		return "charge-42", nil
+	}
+
+	result, err := store.Do(context.Background(), "request-1", charge)
+	suite.Require().NoError(err, "Failed to process request: %+v", err)
+	suite.Assert().Equal("charge-42", result)
+	result, err = store.Do(context.Background(), "request-1", charge)
+	suite.Require().NoError(err, "Failed to process request: %+v", err)
+	suite.Assert().Equal("charge-42", result)
+	suite.Assert().Equal(int32(1), calls.Load(), "The request should be processed only once")
+
+	record, err := store.Get("request-1")
+	suite.Require().NoError(err, "Failed to get record: %+v", err)
+	suite.Assert().Equal(cache.IdempotencySucceeded, record.State)
+	suite.Assert().False(record.CompletedAt.Before(record.StartedAt))
+}
+
+func (suite *CacheSuite) TestCanRecordFailedRequest() {
+	store := cache.NewIdempotencyStore[string]("test", time.Minute, time.Hour)
+	_, err := store.Do(context.Background(), "request-1", func(ctx context.Context) (string, error) {
+		return "", errors.HTTPBadRequest.WithStack()
+	})
+	suite.Require().ErrorIs(err, errors.HTTPBadRequest)
+
+	_, err = store.Do(context.Background(), "request-1", func(ctx context.Context) (string, error) {
+		return "ok", nil
+	})
+	suite.Assert().ErrorIs(err, cache.ErrIdempotencyFailed, "The recorded failure should be returned")
+}
+
+func (suite *CacheSuite) TestShouldNotProcessPendingRequest() {
+	store := cache.NewIdempotencyStore[string]("test", 50*time.Millisecond, time.Hour)
+	record, started, err := store.Begin("request-1")
+	suite.Require().NoError(err, "Failed to begin request: %+v", err)
+	suite.Require().True(started)
+	suite.Assert().Equal(cache.IdempotencyPending, record.State)
+
+	_, err = store.Do(context.Background(), "request-1", func(ctx context.Context) (string, error) {
+		return "ok", nil
+	})
+	suite.Assert().ErrorIs(err, cache.ErrIdempotencyPending)
+
+	// The pending request crashed, it can be retried once its record expired
+	time.Sleep(100 * time.Millisecond)
+	result, err := store.Do(context.Background(), "request-1", func(ctx context.Context) (string, error) {
+		return "ok", nil
+	})
+	suite.Require().NoError(err, "Failed to process request: %+v", err)
+	suite.Assert().Equal("ok", result)
+}
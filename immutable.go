@@ -0,0 +1,54 @@
+package cache
+
+import "context"
+
+// setMode tells how putWith stores an item
+type setMode int
+
+const (
+	// setDefault stores the item unless a key holds an immutable item
+	setDefault setMode = iota
+	// setImmutable stores the item as immutable unless a key holds an immutable item
+	setImmutable
+	// setForced stores the item even if a key holds an immutable item
+	setForced
+)
+
+// SetImmutable sets an item in the cache that cannot be overwritten
+//
+// The next Set on any of its keys fails with an ErrImmutable, until the item expires,
+// is deleted, or is overwritten with ForceSet.
+//
+// The item is stored under the same keys as with Set. The check and the store of SetImmutable exclude the other
+// Set calls of the cache, but not those of other processes sharing its folder.
+func (cache *Cache[T]) SetImmutable(item T, key ...string) error {
+	config := cache.settings()
+	return cache.putWith(context.Background(), config, setImmutable, item, expirationOf(item, config.expiration), 0, TierDefault, nil, config.keysOf(item, key)...)
+}
+
+// ForceSet sets an item in the cache even if it overwrites an immutable item
+//
+// The item is not immutable, see SetImmutable.
+func (cache *Cache[T]) ForceSet(item T, key ...string) error {
+	config := cache.settings()
+	return cache.putWith(context.Background(), config, setForced, item, expirationOf(item, config.expiration), 0, TierDefault, nil, config.keysOf(item, key)...)
+}
+
+// checkImmutables checks that none of the keys holds an immutable item
+//
+// The keys of a persistent cache are always checked, in memory or in the header of their file,
+// so the immutable items persisted by other caches or before a restart are found.
+// The keys of other caches are checked only once an immutable item was set or read by this cache,
+// so the caches without immutable items do not look up the keys they store.
+func (cache *Cache[T]) checkImmutables(config *settings, key []string) error {
+	if !config.persistent && !cache.immutables.Load() {
+		return nil
+	}
+	for _, k := range key {
+		if metadata, found := cache.metadata(config, k); found && metadata.immutable && cache.current(config, metadata) {
+			cache.immutables.Store(true)
+			return ErrImmutable.With(k)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package cache_test
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gildas/go-cache"
+)
+
+func (suite *CacheSuite) TestCanSetImmutableItems() {
+	settings := cache.New[string]("test")
+	err := settings.SetImmutable("production", "environment")
+	suite.Require().NoError(err, "Failed to set immutable item: %+v", err)
+
+	err = settings.Set("staging", "environment")
+	suite.Assert().ErrorIs(err, cache.ErrImmutable, "Setting an immutable key should fail")
+	err = settings.SetImmutable("staging", "environment")
+	suite.Assert().ErrorIs(err, cache.ErrImmutable, "Setting an immutable key again should fail")
+	value, err := settings.Get("environment")
+	suite.Require().NoError(err, "Failed to get item: %+v", err)
+	suite.Assert().Equal("production", *value, "The immutable item should not be overwritten")
+
+	err = settings.ForceSet("staging", "environment")
+	suite.Require().NoError(err, "ForceSet should overwrite immutable items: %+v", err)
+	err = settings.Set("development", "environment")
+	suite.Assert().NoError(err, "The forced item should not be immutable: %+v", err)
+
+	err = settings.SetImmutable("production", "environment")
+	suite.Require().NoError(err, "Failed to set immutable item: %+v", err)
+	suite.Require().NoError(settings.Delete("environment"))
+	err = settings.Set("staging", "environment")
+	suite.Assert().NoError(err, "A deleted immutable item should not block Set: %+v", err)
+}
+
+func (suite *CacheSuite) TestShouldKeepImmutableItemsOnDisk() {
+	settings := cache.New[string]("test", cache.CacheOptionPersistent)
+	defer func() { _ = settings.Clear() }()
+	err := settings.SetImmutable("production", "environment")
+	suite.Require().NoError(err, "Failed to set immutable item: %+v", err)
+
+	reopened := cache.New[string]("test", cache.CacheOptionPersistent)
+	value, err := reopened.Get("environment")
+	suite.Require().NoError(err, "Failed to get item: %+v", err)
+	suite.Assert().Equal("production", *value)
+	err = reopened.Set("staging", "environment")
+	suite.Assert().ErrorIs(err, cache.ErrImmutable, "The item should still be immutable once read from the disk")
+}
+
+func (suite *CacheSuite) TestShouldKeepImmutableItemsOnDiskWithoutReadingThem() {
+	settings := cache.New[string]("test", cache.CacheOptionPersistent)
+	defer func() { _ = settings.Clear() }()
+	err := settings.SetImmutable("production", "environment")
+	suite.Require().NoError(err, "Failed to set immutable item: %+v", err)
+
+	reopened := cache.New[string]("test", cache.CacheOptionPersistent)
+	err = reopened.Set("staging", "environment")
+	suite.Assert().ErrorIs(err, cache.ErrImmutable, "The item should still be immutable after a restart")
+}
+
+func (suite *CacheSuite) TestCanSetImmutableItemsConcurrently() {
+	settings := cache.New[string]("test")
+	var succeeded atomic.Int32
+	var group sync.WaitGroup
+	for i := range 8 {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			if settings.SetImmutable(fmt.Sprintf("value-%d", i), "environment") == nil {
+				succeeded.Add(1)
+			}
+		}()
+	}
+	group.Wait()
+	suite.Assert().Equal(int32(1), succeeded.Load(), "Only one immutable item should be set")
+}
@@ -0,0 +1,129 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Cacher is the interface of the operations of a Cache, so they can be decorated
+type Cacher[T any] interface {
+	Get(key string) (*T, error)
+	Set(item T, key ...string) error
+	SetWithExpiration(item T, expiration time.Duration, key ...string) error
+	GetOrCompute(ctx context.Context, key string, compute func(context.Context) (T, error), options ...ComputeOption) (*T, error)
+	Delete(key string) error
+	Clear() error
+}
+
+const (
+	// PhaseTotal is the phase of the whole operation
+	PhaseTotal = "total"
+	// PhaseSerialize is the phase that marshals, unmarshals, and transforms the persisted items
+	PhaseSerialize = "serialize"
+	// PhaseEncrypt is the phase that encrypts and decrypts the persisted items
+	PhaseEncrypt = "encrypt"
+	// PhaseDisk is the phase that reads and writes the files
+	PhaseDisk = "disk"
+)
+
+// Recorder records the durations of the operations of a cache and of their phases
+//
+// The operations are "get", "set", "compute", "delete", and "clear" with the PhaseTotal phase,
+// and "persist" and "restore" with the PhaseSerialize, PhaseEncrypt, and PhaseDisk phases.
+// err is the error of the operation or phase, if any.
+type Recorder interface {
+	Record(operation string, phase string, duration time.Duration, err error)
+}
+
+// RecorderFunc is a function that implements Recorder
+type RecorderFunc func(operation string, phase string, duration time.Duration, err error)
+
+// Record records the duration of an operation phase
+//
+// implements Recorder
+func (fn RecorderFunc) Record(operation string, phase string, duration time.Duration, err error) {
+	fn(operation, phase, duration, err)
+}
+
+// instrumented is a Cacher that records the duration of each operation
+type instrumented[T any] struct {
+	cacher   Cacher[T]
+	recorder Recorder
+}
+
+// Instrumented decorates a Cacher so the duration of each operation is given to the recorder
+//
+// The cacher is used through the Cacher interface only, it is not changed.
+// To record the phases of the persistence of a *Cache too, give it the recorder with WithRecorder.
+func Instrumented[T any](cacher Cacher[T], recorder Recorder) Cacher[T] {
+	return &instrumented[T]{cacher: cacher, recorder: recorder}
+}
+
+// WithRecorder records the duration of the phases of the persistence of the items, see Recorder
+//
+// A nil recorder stops recording.
+func (cache *Cache[T]) WithRecorder(recorder Recorder) *Cache[T] {
+	return cache.configure(func(config *settings) {
+		config.recorder = recorder
+	})
+}
+
+// Get gets an item from the cache
+//
+// implements Cacher
+func (decorator *instrumented[T]) Get(key string) (item *T, err error) {
+	defer decorator.record("get", time.Now(), &err)
+	return decorator.cacher.Get(key)
+}
+
+// Set sets an item in the cache
+//
+// implements Cacher
+func (decorator *instrumented[T]) Set(item T, key ...string) (err error) {
+	defer decorator.record("set", time.Now(), &err)
+	return decorator.cacher.Set(item, key...)
+}
+
+// SetWithExpiration sets an item in the cache with a custom expiration
+//
+// implements Cacher
+func (decorator *instrumented[T]) SetWithExpiration(item T, expiration time.Duration, key ...string) (err error) {
+	defer decorator.record("set", time.Now(), &err)
+	return decorator.cacher.SetWithExpiration(item, expiration, key...)
+}
+
+// GetOrCompute gets an item from the cache or computes it
+//
+// implements Cacher
+func (decorator *instrumented[T]) GetOrCompute(ctx context.Context, key string, compute func(context.Context) (T, error), options ...ComputeOption) (item *T, err error) {
+	defer decorator.record("compute", time.Now(), &err)
+	return decorator.cacher.GetOrCompute(ctx, key, compute, options...)
+}
+
+// Delete removes an item from the cache
+//
+// implements Cacher
+func (decorator *instrumented[T]) Delete(key string) (err error) {
+	defer decorator.record("delete", time.Now(), &err)
+	return decorator.cacher.Delete(key)
+}
+
+// Clear clears the cache
+//
+// implements Cacher
+func (decorator *instrumented[T]) Clear() (err error) {
+	defer decorator.record("clear", time.Now(), &err)
+	return decorator.cacher.Clear()
+}
+
+// record records the duration of an operation that started at start
+func (decorator *instrumented[T]) record(operation string, start time.Time, err *error) {
+	decorator.recorder.Record(operation, PhaseTotal, time.Since(start), *err)
+}
+
+// recordPhase records the duration of a persistence phase that started at start, if the cache is instrumented
+func (config *settings) recordPhase(operation string, phase string, start time.Time, err error) {
+	if config.recorder != nil {
+		config.recorder.Record(operation, phase, time.Since(start), err)
+	}
+}
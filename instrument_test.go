@@ -0,0 +1,64 @@
+package cache_test
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+type recordedPhase struct {
+	operation string
+	phase     string
+	failed    bool
+}
+
+func (suite *CacheSuite) TestCanInstrumentOperations() {
+	var mutex sync.Mutex
+	var recorded []recordedPhase
+	recorder := cache.RecorderFunc(func(operation, phase string, duration time.Duration, err error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		recorded = append(recorded, recordedPhase{operation, phase, err != nil})
+	})
+	items := cache.Instrumented[string](cache.New[string]("test", cache.CacheOptionPersistent).WithEncryptionKey([]byte("@v3ry#S3cr3tK3y!")).WithRecorder(recorder), recorder)
+	defer func() { _ = items.Clear() }()
+
+	err := items.Set("value", "key")
+	suite.Require().NoError(err, "Failed to set item: %+v", err)
+	suite.Assert().Equal([]recordedPhase{
+		{"persist", cache.PhaseSerialize, false},
+		{"persist", cache.PhaseEncrypt, false},
+		{"persist", cache.PhaseDisk, false},
+		{"set", cache.PhaseTotal, false},
+	}, recorded)
+
+	recorded = nil
+	_, err = items.Get("missing")
+	suite.Require().ErrorIs(err, errors.NotFound)
+	suite.Assert().Equal([]recordedPhase{{"restore", cache.PhaseDisk, true}, {"get", cache.PhaseTotal, true}}, recorded)
+
+	recorded = nil
+	_, err = items.GetOrCompute(context.Background(), "key", func(context.Context) (string, error) { return "computed", nil })
+	suite.Require().NoError(err, "Failed to compute item: %+v", err)
+	suite.Assert().Equal([]recordedPhase{{"compute", cache.PhaseTotal, false}}, recorded)
+}
+
+func (suite *CacheSuite) TestShouldNotConfigureInstrumentedCache() {
+	var recorded []recordedPhase
+	recorder := cache.RecorderFunc(func(operation, phase string, duration time.Duration, err error) {
+		recorded = append(recorded, recordedPhase{operation, phase, err != nil})
+	})
+	inner := cache.New[string]("test", cache.CacheOptionPersistent)
+	defer func() { _ = inner.Clear() }()
+	items := cache.Instrumented[string](inner, recorder)
+
+	suite.Require().NoError(items.Set("value", "key"))
+	suite.Assert().Equal([]recordedPhase{{"set", cache.PhaseTotal, false}}, recorded)
+
+	recorded = nil
+	suite.Require().NoError(inner.Set("value", "key"))
+	suite.Assert().Empty(recorded, "The wrapped cache should not record its operations")
+}
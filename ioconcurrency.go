@@ -0,0 +1,29 @@
+package cache
+
+// ioLimiter is a semaphore that bounds the simultaneous disk operations of a cache
+type ioLimiter chan struct{}
+
+// WithIOConcurrency bounds the number of simultaneous disk operations
+//
+// Operations over the limit wait for a slot, so a burst of misses does not open thousands
+// of files at once or saturate a network filesystem. A limit of 0 removes the bound.
+//
+// Reading a persisted item, writing it, and removing it each take a slot for their duration.
+// The readers returned by GetReader, GetRange, and GetReaderAt do not hold a slot.
+func (cache *Cache[T]) WithIOConcurrency(limit int) *Cache[T] {
+	return cache.configure(func(config *settings) {
+		config.ioLimiter = nil
+		if limit > 0 {
+			config.ioLimiter = make(ioLimiter, limit)
+		}
+	})
+}
+
+// acquireIO waits for a slot of the I/O limiter and returns the function that releases it
+func (config *settings) acquireIO() (release func()) {
+	if config.ioLimiter == nil {
+		return func() {}
+	}
+	config.ioLimiter <- struct{}{}
+	return func() { <-config.ioLimiter }
+}
@@ -0,0 +1,36 @@
+package cache_test
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gildas/go-cache"
+)
+
+func (suite *CacheSuite) TestCanBoundIOConcurrency() {
+	items := cache.New[string]("test", cache.CacheOptionPersistent).WithIOConcurrency(2).WithDurability(cache.Strict)
+	defer func() { _ = items.Clear() }()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_ = items.Set(fmt.Sprintf("value-%d", i), fmt.Sprintf("key-%d", i))
+		}(i)
+	}
+	wg.Wait()
+
+	reader := cache.New[string]("test", cache.CacheOptionPersistent).WithIOConcurrency(2)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			value, err := reader.Get(fmt.Sprintf("key-%d", i))
+			if suite.Assert().NoError(err, "Failed to get key-%d", i) {
+				suite.Assert().Equal(fmt.Sprintf("value-%d", i), *value)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,40 @@
+package cache
+
+// ItemsMap is a view of the items of a cache in memory, with the methods of the sync.Map that Cache.Items was
+//
+// The values are the items, not their records. Expired items are skipped.
+//
+// Deprecated: Use Get, Delete, ReadSnapshot, or Keys. The items are kept in shards, see WithShards.
+type ItemsMap[T any] struct {
+	cache *Cache[T]
+}
+
+// Load gets the item of a key in memory
+func (items ItemsMap[T]) Load(key any) (value any, ok bool) {
+	name, isString := key.(string)
+	if items.cache == nil || !isString {
+		return nil, false
+	}
+	entry, found := items.cache.storage().load(name)
+	if !found || entry.expired() {
+		return nil, false
+	}
+	return entry.Item, true
+}
+
+// Range calls fn with each key and item in memory until fn returns false
+func (items ItemsMap[T]) Range(fn func(key, value any) bool) {
+	if items.cache == nil {
+		return
+	}
+	items.cache.storage().each(func(key string, entry record[T]) bool {
+		return entry.expired() || fn(key, entry.Item)
+	})
+}
+
+// Delete removes the item of a key, in memory and on the disk
+func (items ItemsMap[T]) Delete(key any) {
+	if name, isString := key.(string); items.cache != nil && isString {
+		_ = items.cache.Delete(name)
+	}
+}
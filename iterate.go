@@ -0,0 +1,30 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// IteratePersisted calls fn with the key and the JSON of each item persisted on the disk
+//
+// The files are read one at a time, so caches larger than the memory can be exported, migrated, or audited.
+// The items are decrypted and transformed as they are when read by Get, expired items are skipped.
+// The reader is valid only during the call to fn.
+//
+// The iteration stops at the first error returned by fn, which is returned.
+func (cache *Cache[T]) IteratePersisted(fn func(key string, reader io.Reader) error) error {
+	config := cache.settings()
+	if !config.persistent {
+		return ErrNotPersistent.With(cache.Name)
+	}
+	generation := cache.generation(config)
+	return config.walkPersisted(func(filekey string) error {
+		var entry record[json.RawMessage]
+
+		if err := config.restore(filekey, &entry); err != nil || len(entry.Key) == 0 || entry.expired() || entry.Generation != generation {
+			return nil
+		}
+		return fn(entry.Key, bytes.NewReader(entry.Item))
+	})
+}
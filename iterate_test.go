@@ -0,0 +1,42 @@
+package cache_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+func (suite *CacheSuite) TestCanIteratePersistedItems() {
+	items := cache.New[string]("test", cache.CacheOptionPersistent).WithEncryptionKey([]byte("@v3ry#S3cr3tK3y!"))
+	defer func() { _ = items.Clear() }()
+	for i := 0; i < 5; i++ {
+		_ = items.Set(fmt.Sprintf("value-%d", i), fmt.Sprintf("key-%d", i))
+	}
+	_ = items.SetWithExpiration("expired", time.Nanosecond, "expired")
+	_ = items.Add("set", "not an item")
+
+	found := map[string]string{}
+	err := items.IteratePersisted(func(key string, reader io.Reader) error {
+		var value string
+		if err := json.NewDecoder(reader).Decode(&value); err != nil {
+			return err
+		}
+		found[key] = value
+		return nil
+	})
+	suite.Require().NoError(err, "Failed to iterate: %+v", err)
+	suite.Assert().Len(found, 5)
+	suite.Assert().Equal("value-3", found["key-3"])
+
+	count := 0
+	err = items.IteratePersisted(func(key string, reader io.Reader) error {
+		count++
+		return errors.NotImplemented.WithStack()
+	})
+	suite.Assert().ErrorIs(err, errors.NotImplemented)
+	suite.Assert().Equal(1, count, "The iteration should stop at the first error")
+}
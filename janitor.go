@@ -0,0 +1,219 @@
+package cache
+
+import (
+	"cmp"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gildas/go-errors"
+)
+
+// ExpirationMode tells when the expired items of a cache are removed
+type ExpirationMode int
+
+const (
+	// LazyExpiration removes the expired items when they are read
+	//
+	// This is the default mode. Reads do not pay more than a lookup, but expired
+	// items that are not read again stay in memory and on the disk.
+	LazyExpiration ExpirationMode = iota
+	// EagerExpiration removes the expired items with a janitor only, and calls the OnExpire callbacks
+	//
+	// Reads of expired items do not remove them, so they never wait for a file to be removed,
+	// but expired items stay in memory until the next janitor run.
+	EagerExpiration
+	// HybridExpiration removes the expired items when they are read and with a janitor
+	//
+	// Memory is reclaimed the soonest, at the cost of both. The OnExpire callbacks
+	// are called only for the items removed by the janitor.
+	HybridExpiration
+)
+
+// defaultJanitorInterval is the interval of the janitor started by WithExpirationMode
+const defaultJanitorInterval = time.Minute
+
+// String gets the name of the mode
+//
+// implements fmt.Stringer
+func (mode ExpirationMode) String() string {
+	switch mode {
+	case EagerExpiration:
+		return "eager"
+	case HybridExpiration:
+		return "hybrid"
+	default:
+		return "lazy"
+	}
+}
+
+// WithExpirationMode sets when the expired items are removed
+//
+// EagerExpiration and HybridExpiration start a janitor that runs every minute,
+// unless one was started with WithJanitor. LazyExpiration stops the janitor.
+func (cache *Cache[T]) WithExpirationMode(mode ExpirationMode) *Cache[T] {
+	var previous *janitor
+
+	cache.configure(func(config *settings) {
+		config.expirationMode = mode
+		if mode == LazyExpiration {
+			previous = config.janitor
+			config.janitor = nil
+		} else if config.janitor == nil {
+			config.janitor = newJanitor(defaultJanitorInterval, cache.expire)
+		}
+	})
+	if previous != nil {
+		previous.close()
+	}
+	return cache
+}
+
+// ExpirationMode gets when the expired items are removed
+func (cache *Cache[T]) ExpirationMode() ExpirationMode {
+	return cache.settings().expirationMode
+}
+
+// OnExpire sets a callback called with each expired item removed by the janitor
+//
+// The callback is called by the janitor goroutine, after the item was removed.
+func (cache *Cache[T]) OnExpire(callback func(key string, item T)) *Cache[T] {
+	return cache.configure(func(config *settings) {
+		config.onExpire = callback
+	})
+}
+
+// janitor removes the expired items of a cache at regular intervals
+type janitor struct {
+	interval time.Duration
+	stop     chan struct{}
+	done     chan struct{}
+	once     sync.Once
+}
+
+// WithJanitor removes the expired items every interval
+//
+// Without a janitor, expired items are removed when they are read.
+// Starting a janitor in LazyExpiration mode switches to HybridExpiration, stopping it switches back to LazyExpiration.
+//
+// The janitor removes the expired items in memory and their files. Items that
+// were persisted but never loaded are removed when they are read.
+//
+// An interval of 0 stops the janitor. Call Close to stop the janitor when the cache is not needed anymore.
+func (cache *Cache[T]) WithJanitor(interval time.Duration) *Cache[T] {
+	var previous *janitor
+
+	cache.configure(func(config *settings) {
+		previous = config.janitor
+		config.janitor = nil
+		if interval > 0 {
+			config.janitor = newJanitor(interval, cache.expire)
+			if config.expirationMode == LazyExpiration {
+				config.expirationMode = HybridExpiration
+			}
+		} else {
+			config.expirationMode = LazyExpiration
+		}
+	})
+	if previous != nil {
+		previous.close()
+	}
+	return cache
+}
+
+// Close stops the janitor, the memory checks, and the write-behind goroutine, and writes the pending items
+//
+// The items set after Close are written synchronously.
+//
+// Close then zeroes the key material of the cache: the encryption key, the key of the filenames,
+// and the key of WithMemoryEncryption. Once closed, the operations that need a key fail with ErrKeyDestroyed
+// and the items kept encrypted in memory cannot be read anymore, until WithEncryptionKey is called again.
+func (cache *Cache[T]) Close() (err error) {
+	config := cache.settings()
+	config.stopJanitors()
+	cache.tenants.Range(func(_, view any) bool {
+		view.(*Cache[T]).settings().stopJanitors()
+		return true
+	})
+	if config.writeBehind != nil {
+		config.writeBehind.close()
+	}
+	err = cache.Flush()
+	config.destroySecrets()
+	return
+}
+
+// stopJanitors stops the janitor and the memory checks of the settings
+func (config *settings) stopJanitors() {
+	if config.janitor != nil {
+		config.janitor.close()
+	}
+	if config.spiller != nil {
+		config.spiller.janitor.close()
+	}
+}
+
+// ExpiringWithin gets the keys of the items in memory that expire within the given duration
+//
+// The keys are sorted by expiration, the ones that expire first come first.
+// The keys that already expired are not returned.
+func (cache *Cache[T]) ExpiringWithin(window time.Duration) []string {
+	now := time.Now().UnixNano()
+	expirations := cache.storage().expiring(now + window.Nanoseconds())
+	expirations = slices.DeleteFunc(expirations, func(expiration expiry) bool { return expiration.at < now })
+	slices.SortFunc(expirations, func(a, b expiry) int { return cmp.Compare(a.at, b.at) })
+	keys := make([]string, 0, len(expirations))
+	for _, expiration := range expirations {
+		keys = append(keys, expiration.key)
+	}
+	return keys
+}
+
+// expire removes the expired items from memory and their files
+//
+// Items are kept while they can be served stale, see WithServeStaleOnError.
+func (cache *Cache[T]) expire() {
+	config := cache.settings()
+	var expired []record[T]
+	cache.write(func(storage *store[T]) {
+		expired = storage.expire(time.Now().UnixNano() - config.maxStale.Nanoseconds())
+	})
+	onExpire, _ := config.onExpire.(func(key string, item T))
+	for _, entry := range expired {
+		if config.persistent {
+			if err := config.erase(config.filekey(entry.Key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				config.reportError(OperationExpire, entry.Key, err)
+			}
+		}
+		if onExpire != nil {
+			onExpire(entry.Key, entry.Item)
+		}
+	}
+	cache.stats.expired.Add(uint64(len(expired)))
+}
+
+// newJanitor starts a janitor that calls expire every interval
+func newJanitor(interval time.Duration, expire func()) *janitor {
+	janitor := &janitor{interval: interval, stop: make(chan struct{}), done: make(chan struct{})}
+	go func() {
+		defer close(janitor.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				expire()
+			case <-janitor.stop:
+				return
+			}
+		}
+	}()
+	return janitor
+}
+
+// close stops the janitor and waits for it to finish
+func (janitor *janitor) close() {
+	janitor.once.Do(func() { close(janitor.stop) })
+	<-janitor.done
+}
@@ -0,0 +1,95 @@
+package cache_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gildas/go-cache"
+)
+
+func (suite *CacheSuite) TestCanRemoveExpiredItemsWithJanitor() {
+	items := cache.New[string]("test", cache.CacheOptionPersistent).WithJanitor(10 * time.Millisecond)
+	defer func() { _ = items.Clear() }()
+	defer items.Close()
+
+	_ = items.SetWithExpiration("short", 20*time.Millisecond, "short")
+	_ = items.SetWithExpiration("long", time.Hour, "long")
+	_ = items.Set("forever", "forever")
+	suite.Assert().Eventually(func() bool { return items.Stats().Expired == 1 }, time.Second, 10*time.Millisecond)
+	suite.Assert().Equal(uint64(0), items.Stats().Misses, "The expired item should be removed without being read")
+
+	other := cache.New[string]("test", cache.CacheOptionPersistent)
+	_, err := other.Get("short")
+	suite.Assert().Error(err, "The file of the expired item should be removed")
+	_, err = other.Get("long")
+	suite.Assert().NoError(err, "The item should not be expired")
+}
+
+func (suite *CacheSuite) TestCanGetKeysExpiringWithin() {
+	items := cache.New[string]("test")
+	_ = items.SetWithExpiration("item", 3*time.Minute, "three")
+	_ = items.SetWithExpiration("item", time.Minute, "one")
+	_ = items.SetWithExpiration("item", 2*time.Minute, "two")
+	_ = items.SetWithExpiration("item", time.Hour, "sixty")
+	_ = items.SetWithExpiration("item", time.Nanosecond, "expired")
+	_ = items.Set("item", "forever")
+	time.Sleep(time.Millisecond)
+
+	suite.Assert().Equal([]string{"one", "two", "three"}, items.ExpiringWithin(5*time.Minute))
+	suite.Assert().Empty(items.ExpiringWithin(time.Second))
+}
+
+func (suite *CacheSuite) TestCanRemoveExpiredItemsWithTimingWheel() {
+	items := cache.New[string]("test").WithExpirationEngine(cache.TimingWheel).WithJanitor(10 * time.Millisecond)
+	defer items.Close()
+
+	_ = items.SetWithExpiration("short", 20*time.Millisecond, "short")
+	_ = items.SetWithExpiration("long", time.Hour, "long")
+	suite.Assert().Equal([]string{"short"}, items.ExpiringWithin(time.Minute))
+	suite.Assert().Eventually(func() bool { return items.Stats().Expired == 1 }, time.Second, 10*time.Millisecond)
+	_, err := items.Get("long")
+	suite.Assert().NoError(err, "The item should not be expired")
+}
+
+func (suite *CacheSuite) TestCanChooseExpirationMode() {
+	items := cache.New[string]("test")
+	suite.Assert().Equal(cache.LazyExpiration, items.ExpirationMode())
+	items.WithJanitor(time.Hour)
+	suite.Assert().Equal(cache.HybridExpiration, items.ExpirationMode(), "A janitor should switch to hybrid")
+	items.WithJanitor(0)
+	suite.Assert().Equal(cache.LazyExpiration, items.ExpirationMode(), "Stopping the janitor should switch to lazy")
+
+	expired := make(chan string, 1)
+	items.WithExpirationMode(cache.EagerExpiration).WithJanitor(10 * time.Millisecond).OnExpire(func(key string, item string) {
+		expired <- key + "=" + item
+	})
+	defer items.Close()
+	suite.Assert().Equal(cache.EagerExpiration, items.ExpirationMode())
+	suite.Assert().Equal("eager", items.ExpirationMode().String())
+
+	_ = items.SetWithExpiration("value", 20*time.Millisecond, "key")
+	select {
+	case event := <-expired:
+		suite.Assert().Equal("key=value", event)
+	case <-time.After(time.Second):
+		suite.Fail("The janitor should have expired the item")
+	}
+	suite.Assert().Equal(uint64(1), items.Stats().Expired)
+}
+
+func (suite *CacheSuite) TestShouldNotRemoveExpiredItemsOnReadWhenEager() {
+	items := cache.New[string]("test", cache.CacheOptionPersistent).WithExpirationMode(cache.EagerExpiration)
+	defer func() { _ = items.Clear() }()
+	defer items.Close()
+
+	_ = items.SetWithExpiration("value", time.Nanosecond, "key")
+	time.Sleep(time.Millisecond)
+	_, err := items.Get("key")
+	suite.Require().Error(err, "The item should be expired")
+	folder, _ := os.UserCacheDir()
+	_, err = os.Stat(filepath.Join(folder, "test", uuid.NewSHA1(uuid.Nil, []byte("key")).String()))
+	suite.Assert().NoError(err, "The file of the expired item should be left to the janitor")
+}
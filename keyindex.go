@@ -0,0 +1,179 @@
+package cache
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// keysKey is the key of the index of the keys of a persistent cache, see WithKeyIndex
+//
+// It starts with a NUL so it does not collide with the keys of the items.
+const keysKey = "\x00keys"
+
+// keyIndex is the keys of the persisted items of a cache, see WithKeyIndex
+type keyIndex struct {
+	mutex  sync.Mutex
+	keys   map[string]bool
+	loaded bool
+}
+
+// persistedKeys is the content of the file that persists the key index of a cache
+type persistedKeys struct {
+	Keys []string
+}
+
+// WithKeyIndex keeps an index of the keys of the persisted items, so Keys also gets the keys of the items that are not in memory
+//
+// The files of the items are named after a hash of their keys, the index maps the files back to their keys.
+// It is persisted with the items, encrypted like them, by Flush and Close.
+// The first time the index is needed, the items persisted after the index, e.g. before a crash, or before
+// the index was turned on, are read to add their keys.
+func (cache *Cache[T]) WithKeyIndex() *Cache[T] {
+	return cache.configure(func(config *settings) {
+		if config.keyIndex == nil {
+			config.keyIndex = newKeyIndex()
+		}
+	})
+}
+
+// newKeyIndex creates an empty keyIndex
+func newKeyIndex() *keyIndex {
+	return &keyIndex{keys: map[string]bool{}}
+}
+
+// Keys gets the sorted keys of the items that have not expired
+//
+// Without WithKeyIndex, only the keys of the items in memory are returned.
+// With it, the keys of the persisted items are returned too, and the keys
+// of the items that were deleted or have expired are removed from the index.
+func (cache *Cache[T]) Keys() []string {
+	config := cache.settings()
+	generation, now := cache.generation(config), time.Now().UnixNano()
+	found := map[string]bool{}
+	cache.storage().eachMetadata(func(key string, metadata frameMetadata) {
+		if metadata.live(generation, now) {
+			found[key] = true
+		}
+	})
+	if config.keyIndex != nil && config.persistent {
+		var gone []string
+
+		for _, key := range config.keyIndex.list(config) {
+			if found[key] {
+				continue
+			} else if cache.Has(key) {
+				found[key] = true
+			} else {
+				gone = append(gone, key)
+			}
+		}
+		config.keyIndex.remove(gone...)
+	}
+	keys := make([]string, 0, len(found))
+	for key := range found {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// add adds a key to the index
+func (index *keyIndex) add(key string) {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+	index.keys[key] = true
+}
+
+// remove removes keys from the index
+func (index *keyIndex) remove(keys ...string) {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+	for _, key := range keys {
+		delete(index.keys, key)
+	}
+}
+
+// reset empties the index, after the persisted items were removed
+func (index *keyIndex) reset() {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+	index.keys = map[string]bool{}
+	index.loaded = true
+}
+
+// list gets the keys of the index, loading the persisted index first if needed
+func (index *keyIndex) list(config *settings) []string {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+	index.load(config)
+	keys := make([]string, 0, len(index.keys))
+	for key := range index.keys {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// load reads the persisted index and the keys of the items persisted after it
+//
+// The caller must hold the mutex.
+func (index *keyIndex) load(config *settings) {
+	var persisted persistedKeys
+	var since time.Time
+
+	if index.loaded {
+		return
+	}
+	index.loaded = true
+	quiet := *config // loading the index is not an operation of the cache
+	quiet.recorder = nil
+	if info, err := os.Stat(filepath.Join(config.folder, config.filekey(keysKey))); err == nil {
+		since = info.ModTime()
+	}
+	if quiet.restore(config.filekey(keysKey), &persisted) != nil {
+		since = time.Time{}
+	}
+	for _, key := range persisted.Keys {
+		index.keys[key] = true
+	}
+	internal := internalFiles(config)
+	_ = config.walkPersisted(func(filekey string) error {
+		if internal[filepath.Base(filekey)] {
+			return nil
+		}
+		if info, err := os.Stat(filepath.Join(config.folder, filekey)); err != nil || info.ModTime().Before(since) {
+			return nil
+		}
+		if key := quiet.persistedKey(filekey); len(key) > 0 {
+			index.keys[key] = true
+		}
+		return nil
+	})
+}
+
+// persistedKey gets the key of the item persisted in the file named filekey, if any
+func (config *settings) persistedKey(filekey string) string {
+	var data json.RawMessage
+	var entry struct{ Key string }
+
+	if config.restore(filekey, &data) != nil || len(data) == 0 || data[0] != '{' {
+		return ""
+	}
+	if json.Unmarshal(data, &entry) != nil {
+		return ""
+	}
+	return entry.Key
+}
+
+// persistKeyIndex writes the key index of the cache, see WithKeyIndex
+func (cache *Cache[T]) persistKeyIndex(config *settings) error {
+	if config.keyIndex == nil || !config.persistent {
+		return nil
+	}
+	persisted := persistedKeys{Keys: config.keyIndex.list(config)}
+	slices.Sort(persisted.Keys)
+	return config.persist(config.filekey(keysKey), persisted)
+}
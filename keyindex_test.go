@@ -0,0 +1,72 @@
+package cache_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/gildas/go-cache"
+)
+
+func (suite *CacheSuite) TestCanGetKeysInMemory() {
+	names := cache.New[string]("test")
+	suite.Require().NoError(names.Set("value", "key2"))
+	suite.Require().NoError(names.Set("value", "key1"))
+	suite.Require().NoError(names.SetWithExpiration("value", time.Millisecond, "short"))
+	time.Sleep(5 * time.Millisecond)
+	suite.Assert().Equal([]string{"key1", "key2"}, names.Keys())
+}
+
+func (suite *CacheSuite) TestCanGetPersistedKeysWithKeyIndex() {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	writer := cache.New[string]("test", cache.CacheOptionPersistent).WithEncryptionKey(key).WithKeyIndex()
+	defer func() { _ = cache.New[string]("test", cache.CacheOptionPersistent).Clear() }()
+	for _, name := range []string{"key1", "key2", "key3"} {
+		suite.Require().NoError(writer.Set("value", name))
+	}
+	suite.Require().NoError(writer.Delete("key2"))
+	suite.Require().NoError(writer.Flush())
+	suite.Require().NoError(writer.Set("value", "late"), "This key is set after the index was persisted")
+
+	reader := cache.New[string]("test", cache.CacheOptionPersistent).WithEncryptionKey(key).WithKeyIndex()
+	suite.Assert().Equal([]string{"key1", "key3", "late"}, reader.Keys())
+	suite.Assert().Equal(0, reader.Len(), "The items should not be loaded in memory")
+
+	suite.Require().NoError(reader.Delete("key1"))
+	suite.Assert().Equal([]string{"key3", "late"}, reader.Keys())
+	suite.Assert().Equal([]string{}, cache.New[string]("test", cache.CacheOptionPersistent).WithEncryptionKey(key).Keys(), "Without the index, only the keys in memory are returned")
+}
+
+func (suite *CacheSuite) TestCanRebuildKeyIndex() {
+	writer := cache.New[string]("test", cache.CacheOptionPersistent)
+	defer func() { _ = writer.Clear() }()
+	suite.Require().NoError(writer.Set("value", "key1"))
+	suite.Require().NoError(writer.Set("value", "key2"))
+
+	reader := cache.New[string]("test", cache.CacheOptionPersistent).WithKeyIndex()
+	suite.Assert().Equal([]string{"key1", "key2"}, reader.Keys(), "The index should be built from the persisted items")
+}
+
+func (suite *CacheSuite) TestKeyIndexIsNotCountedAsItem() {
+	items := cache.New[string]("test", cache.CacheOptionPersistent).WithKeyIndex().WithPreloadPriority()
+	defer func() { _ = items.Clear() }()
+	suite.Require().NoError(items.Set("value", "key1"))
+	suite.Require().NoError(items.Set("value", "key2"))
+	suite.Require().NoError(items.Flush())
+
+	reader := cache.New[string]("test", cache.CacheOptionPersistent)
+	count, err := reader.LenWithPersisted()
+	suite.Require().NoError(err, "Failed to count the items: %+v", err)
+	suite.Assert().Equal(2, count)
+
+	report, err := reader.ClearDryRun(context.Background())
+	suite.Require().NoError(err, "Failed to dry run the clear: %+v", err)
+	suite.Assert().Equal(2, report.Items, "The key index and the priorities should not be counted as items")
+
+	var seen []string
+	_, err = reader.Purge(func(key string, item string) bool {
+		seen = append(seen, key)
+		return false
+	})
+	suite.Require().NoError(err, "Failed to purge: %+v", err)
+	suite.Assert().ElementsMatch([]string{"key1", "key2"}, seen, "The internal files should not be given to the predicate")
+}
@@ -0,0 +1,183 @@
+package cache
+
+import (
+	"github.com/gildas/go-core"
+	"github.com/gildas/go-errors"
+	"github.com/google/uuid"
+)
+
+// Cacheable is implemented by items that declare the keys they are cached under
+//
+// When an item is Cacheable, Set does not derive keys from
+// core.Identifiable, core.StringIdentifiable, or core.Named.
+type Cacheable interface {
+	CacheKeys() []string
+}
+
+// keysOf gets the keys to store the given item under, the given keys first
+//
+// The keys are derived from the item unless WithExplicitKeysOnly was called.
+func (config *settings) keysOf(item any, key []string) []string {
+	if config.explicitKeysOnly {
+		return key
+	}
+	if config.keyNamespaces {
+		return namespacedKeysOf(item, key)
+	}
+	return keysOf(item, key)
+}
+
+// keysOf gets the keys of the given item, after the given keys
+//
+// Empty IDs and names are skipped.
+func keysOf(item any, key []string) []string {
+	if cacheable, ok := item.(Cacheable); ok {
+		for _, k := range cacheable.CacheKeys() {
+			if len(k) > 0 {
+				key = append(key, k)
+			}
+		}
+		return key
+	}
+	if identifiable, ok := item.(core.Identifiable); ok {
+		key = append(key, identifiable.GetID().String())
+	}
+	if identifiable, ok := item.(core.StringIdentifiable); ok && len(identifiable.GetID()) > 0 {
+		key = append(key, identifiable.GetID())
+	}
+	if named, ok := item.(core.Named); ok && len(named.GetName()) > 0 {
+		key = append(key, named.GetName())
+	}
+	return key
+}
+
+// namespacedKeysOf gets the keys of the given item, after the given keys, prefixed by their source
+//
+// The IDs are prefixed with "id:" and the names with "name:", the keys of a Cacheable are not prefixed.
+func namespacedKeysOf(item any, key []string) []string {
+	if _, ok := item.(Cacheable); ok {
+		return keysOf(item, key)
+	}
+	for _, source := range []string{IDKeySource, NameKeySource} {
+		if k := keyFrom(item, source); len(k) > 0 {
+			key = append(key, source+":"+k)
+		}
+	}
+	return key
+}
+
+// IDKeySource is the source of the keys derived from core.Identifiable and core.StringIdentifiable
+const IDKeySource = "id"
+
+// NameKeySource is the source of the keys derived from core.Named
+const NameKeySource = "name"
+
+// keyFrom gets the key derived from the given source of an item, empty if the item has none
+func keyFrom(item any, source string) string {
+	switch source {
+	case IDKeySource:
+		if identifiable, ok := item.(core.Identifiable); ok {
+			return identifiable.GetID().String()
+		}
+		if identifiable, ok := item.(core.StringIdentifiable); ok {
+			return identifiable.GetID()
+		}
+	case NameKeySource:
+		if named, ok := item.(core.Named); ok {
+			return named.GetName()
+		}
+	}
+	return ""
+}
+
+// keySource gets the source a key was derived from for the given item, empty if it was not derived
+func keySource(item any, key string) string {
+	if _, ok := item.(Cacheable); ok {
+		return ""
+	}
+	for _, source := range []string{IDKeySource, NameKeySource} {
+		if keyFrom(item, source) == key {
+			return source
+		}
+	}
+	return ""
+}
+
+// checkCollisions checks that no key of the item is held by another item that derived it from another source
+func (cache *Cache[T]) checkCollisions(config *settings, item T, key []string) error {
+	if !config.detectCollisions {
+		return nil
+	}
+	for _, k := range key {
+		source := keySource(item, k)
+		if len(source) == 0 {
+			continue
+		}
+		existing, found, _ := cache.lookup(config, k)
+		if !found || existing.expired() {
+			continue
+		}
+		if other := keySource(existing.Item, k); len(other) > 0 && other != source {
+			return ErrKeyCollision.With(k, other)
+		}
+	}
+	return nil
+}
+
+// checkKeys checks the keys and the ID of an item before it is stored
+//
+// Empty keys are rejected with ErrEmptyKey. With WithNilIDRejection,
+// items whose ID is the nil UUID are rejected with ErrNilID.
+func (config *settings) checkKeys(item any, key []string) error {
+	if len(key) == 0 {
+		return errors.ArgumentMissing.With("key")
+	}
+	for _, k := range key {
+		if len(k) == 0 {
+			return emptyKey()
+		}
+	}
+	if config.rejectNilID {
+		if identifiable, ok := item.(core.Identifiable); ok && identifiable.GetID() == uuid.Nil {
+			return ErrNilID.WithStack()
+		}
+	}
+	return nil
+}
+
+// WithNilIDRejection rejects the items whose ID is the nil UUID
+//
+// Such items are usually not initialized yet, Set returns an ErrNilID instead of storing them under the nil UUID.
+func (cache *Cache[T]) WithNilIDRejection() *Cache[T] {
+	return cache.configure(func(config *settings) {
+		config.rejectNilID = true
+	})
+}
+
+// WithKeyNamespaces prefixes the derived keys with their source
+//
+// The IDs are stored under "id:<id>" and the names under "name:<name>",
+// so the name of an item can no longer overwrite the item whose ID has the same string.
+func (cache *Cache[T]) WithKeyNamespaces() *Cache[T] {
+	return cache.configure(func(config *settings) {
+		config.keyNamespaces = true
+	})
+}
+
+// WithKeyCollisionDetection rejects the items whose derived keys collide with keys derived from another source
+//
+// For example, when an item's name equals the ID of a cached item, Set returns an ErrKeyCollision instead of overwriting it.
+func (cache *Cache[T]) WithKeyCollisionDetection() *Cache[T] {
+	return cache.configure(func(config *settings) {
+		config.detectCollisions = true
+	})
+}
+
+// WithExplicitKeysOnly stores the items only under the keys given to Set
+//
+// The keys are no longer derived from Cacheable, core.Identifiable, core.StringIdentifiable, or core.Named.
+func (cache *Cache[T]) WithExplicitKeysOnly() *Cache[T] {
+	return cache.configure(func(config *settings) {
+		config.explicitKeysOnly = true
+	})
+}
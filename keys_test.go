@@ -0,0 +1,121 @@
+package cache_test
+
+import (
+	"github.com/google/uuid"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+type Account struct {
+	ID    uuid.UUID `json:"id"`
+	Name  string    `json:"name"`
+	Email string    `json:"email"`
+}
+
+func (account Account) GetID() uuid.UUID {
+	return account.ID
+}
+
+func (account Account) GetName() string {
+	return account.Name
+}
+
+func (account Account) CacheKeys() []string {
+	return []string{account.ID.String(), account.Email}
+}
+
+func (suite *CacheSuite) TestCanSetWithCacheableKeys() {
+	accounts := cache.New[Account]("test")
+	joe := Account{ID: uuid.New(), Name: "Joe", Email: "joe@acme.com"}
+
+	err := accounts.Set(joe, "me")
+	suite.Require().NoError(err, "Failed to set account: %+v", err)
+	for _, key := range []string{"me", joe.ID.String(), "joe@acme.com"} {
+		cached, err := accounts.Get(key)
+		suite.Require().NoError(err, "Failed to get account with key %s: %+v", key, err)
+		suite.Assert().Equal(joe, *cached)
+	}
+	_, err = accounts.Get("Joe")
+	suite.Assert().ErrorIs(err, errors.NotFound, "The name should not be a key when the item is Cacheable")
+}
+
+func (suite *CacheSuite) TestCanSetWithExplicitKeysOnly() {
+	users := cache.New[User]("test").WithExplicitKeysOnly()
+	joe := User{ID: uuid.New(), Name: "Joe"}
+
+	err := users.Set(joe)
+	suite.Assert().ErrorIs(err, errors.ArgumentMissing, "Set without keys should fail")
+	err = users.Set(joe, "me")
+	suite.Require().NoError(err, "Failed to set user: %+v", err)
+	_, err = users.Get("me")
+	suite.Assert().NoError(err, "Failed to get user: %+v", err)
+	_, err = users.Get("Joe")
+	suite.Assert().ErrorIs(err, errors.NotFound, "The name should not be a key")
+	_, err = users.Get(joe.ID.String())
+	suite.Assert().ErrorIs(err, errors.NotFound, "The ID should not be a key")
+}
+
+func (suite *CacheSuite) TestShouldRejectEmptyKeys() {
+	users := cache.New[User]("test")
+	err := users.Set(User{ID: uuid.New(), Name: "Joe"}, "")
+	suite.Assert().ErrorIs(err, cache.ErrEmptyKey)
+	suite.Assert().ErrorIs(err, errors.ArgumentMissing)
+
+	err = users.Add("", User{ID: uuid.New(), Name: "Joe"})
+	suite.Assert().ErrorIs(err, cache.ErrEmptyKey)
+}
+
+func (suite *CacheSuite) TestShouldSkipEmptyDerivedKeys() {
+	users := cache.New[StringIDUser]("test")
+	err := users.Set(StringIDUser{}, "key")
+	suite.Require().NoError(err, "An item without an ID should be stored under its explicit key: %+v", err)
+	_, err = users.Get("")
+	suite.Assert().ErrorIs(err, errors.NotFound, "The item should not be stored under an empty key")
+}
+
+func (suite *CacheSuite) TestCanRejectNilID() {
+	users := cache.New[User]("test")
+	err := users.Set(User{Name: "Nobody"})
+	suite.Assert().NoError(err, "Nil IDs are accepted by default")
+
+	users = cache.New[User]("test").WithNilIDRejection()
+	err = users.Set(User{Name: "Nobody"})
+	suite.Assert().ErrorIs(err, cache.ErrNilID)
+	_, err = users.Get("Nobody")
+	suite.Assert().ErrorIs(err, errors.NotFound, "The rejected item should not be stored")
+}
+
+func (suite *CacheSuite) TestCanDetectKeyCollisions() {
+	users := cache.New[User]("test").WithKeyCollisionDetection()
+	joe := User{ID: uuid.New(), Name: "Joe"}
+	impostor := User{ID: uuid.New(), Name: joe.ID.String()}
+
+	err := users.Set(joe)
+	suite.Require().NoError(err, "Failed to set user: %+v", err)
+	err = users.Set(impostor)
+	suite.Assert().ErrorIs(err, cache.ErrKeyCollision)
+	cached, err := users.Get(joe.ID.String())
+	suite.Require().NoError(err, "Failed to get user: %+v", err)
+	suite.Assert().Equal(joe, *cached, "The colliding item should not overwrite the cached item")
+
+	err = users.Set(User{ID: joe.ID, Name: "Joseph"})
+	suite.Assert().NoError(err, "Updating an item under the same source should not collide")
+}
+
+func (suite *CacheSuite) TestCanSetWithKeyNamespaces() {
+	users := cache.New[User]("test").WithKeyNamespaces()
+	joe := User{ID: uuid.New(), Name: "Joe"}
+	impostor := User{ID: uuid.New(), Name: joe.ID.String()}
+
+	suite.Require().NoError(users.Set(joe))
+	suite.Require().NoError(users.Set(impostor))
+	cached, err := users.Get("id:" + joe.ID.String())
+	suite.Require().NoError(err, "Failed to get user by ID: %+v", err)
+	suite.Assert().Equal(joe, *cached)
+	cached, err = users.Get("name:" + joe.ID.String())
+	suite.Require().NoError(err, "Failed to get user by name: %+v", err)
+	suite.Assert().Equal(impostor, *cached)
+	_, err = users.Get("Joe")
+	suite.Assert().ErrorIs(err, errors.NotFound, "The derived keys should be namespaced")
+}
@@ -0,0 +1,246 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gildas/go-errors"
+	"github.com/google/uuid"
+)
+
+// leasesFolder is the subfolder of the cache folder where the leases are stored
+const leasesFolder = "leases"
+
+// leasePollInterval is the interval at which WaitLease tries to acquire a lease
+const leasePollInterval = 10 * time.Millisecond
+
+// leaseLockTimeout is the age after which the lock of a lease file is removed, see lockLease
+const leaseLockTimeout = 10 * time.Second
+
+// Lease is the exclusive right to rebuild the item of a key, see AcquireLease
+type Lease struct {
+	Key       string    `json:"key"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	release   func() error
+}
+
+// Expired tells if the lease has expired, another caller can then acquire it
+func (lease Lease) Expired() bool {
+	return time.Now().After(lease.ExpiresAt)
+}
+
+// Release releases the lease, so another caller can acquire it
+//
+// Releasing a lease that expired and was acquired by another caller does nothing.
+func (lease Lease) Release() error {
+	if lease.release == nil {
+		return nil
+	}
+	return lease.release()
+}
+
+// leaseTable contains the leases of a non-persistent cache
+type leaseTable struct {
+	mutex  sync.Mutex
+	leases map[string]Lease
+}
+
+// AcquireLease grants the exclusive right to rebuild the item of a key for ttl
+//
+// If another caller holds a lease on the key that has not expired, an ErrLeaseHeld is returned,
+// the caller can then use the stale item (see GetStale) or wait for the lease (see WaitLease).
+//
+// The leases of a persistent cache are stored in its folder, so they are exclusive across processes too.
+func (cache *Cache[T]) AcquireLease(key string, ttl time.Duration) (lease Lease, err error) {
+	if len(key) == 0 {
+		return Lease{}, emptyKey()
+	}
+	if ttl <= 0 {
+		return Lease{}, errors.ArgumentInvalid.With("ttl", ttl)
+	}
+	config := cache.settings()
+	lease = Lease{Key: key, Token: uuid.NewString(), ExpiresAt: time.Now().Add(ttl)}
+	if config.persistent {
+		return config.acquireLease(lease)
+	}
+	return cache.leases.acquire(lease)
+}
+
+// WaitLease waits until the lease of a key can be acquired, see AcquireLease
+//
+// It returns the error of the context if it is done before.
+func (cache *Cache[T]) WaitLease(ctx context.Context, key string, ttl time.Duration) (Lease, error) {
+	ticker := time.NewTicker(leasePollInterval)
+	defer ticker.Stop()
+	for {
+		lease, err := cache.AcquireLease(key, ttl)
+		if !errors.Is(err, ErrLeaseHeld) {
+			return lease, err
+		}
+		select {
+		case <-ctx.Done():
+			return Lease{}, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetStale gets an item from the cache, even if it has expired
+//
+// expired tells if the item has expired. Expired items are found only until they are removed,
+// see WithServeStaleOnError and WithExpirationMode.
+func (cache *Cache[T]) GetStale(key string) (item *T, expired bool, err error) {
+	config := cache.settings()
+	entry, found, err := cache.lookup(config, key)
+	if err != nil && !errors.Is(err, ErrCorrupted) {
+		return nil, false, err
+	}
+	if !found {
+		return nil, false, notFound(key, err)
+	}
+	return &entry.Item, entry.expired(), nil
+}
+
+// acquire acquires a lease unless the key holds a lease that has not expired
+func (table *leaseTable) acquire(lease Lease) (Lease, error) {
+	table.mutex.Lock()
+	defer table.mutex.Unlock()
+	if current, found := table.leases[lease.Key]; found && !current.Expired() {
+		return Lease{}, ErrLeaseHeld.With(lease.Key)
+	}
+	if table.leases == nil {
+		table.leases = map[string]Lease{}
+	}
+	table.leases[lease.Key] = lease
+	lease.release = func() error {
+		table.mutex.Lock()
+		defer table.mutex.Unlock()
+		if current, found := table.leases[lease.Key]; found && current.Token == lease.Token {
+			delete(table.leases, lease.Key)
+		}
+		return nil
+	}
+	return lease, nil
+}
+
+// acquireLease acquires a lease stored in a file unless the file holds a lease that has not expired
+//
+// The file is written aside and linked, so it is created with its content, or not at all.
+// An expired lease is removed under the lock of the file, so only one caller can take it over, see lockLease.
+func (config *settings) acquireLease(lease Lease) (Lease, error) {
+	folder := filepath.Join(config.folder, leasesFolder)
+	filename := filepath.Join(folder, config.filename(lease.Key))
+	if err := os.MkdirAll(folder, 0700); err != nil {
+		return Lease{}, err
+	}
+	data, err := json.Marshal(lease)
+	if err != nil {
+		return Lease{}, err
+	}
+	temp, err := os.CreateTemp(folder, ".tmp-*")
+	if err != nil {
+		return Lease{}, err
+	}
+	defer os.Remove(temp.Name())
+	_, err = temp.Write(data)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return Lease{}, err
+	}
+	for attempt := 0; attempt < 2; attempt++ {
+		if err = os.Link(temp.Name(), filename); err == nil {
+			lease.release = func() error { return releaseLease(filename, lease.Token) }
+			return lease, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return Lease{}, err
+		}
+		if removed, err := removeExpiredLease(filename); err != nil {
+			return Lease{}, err
+		} else if !removed {
+			break
+		}
+	}
+	return Lease{}, ErrLeaseHeld.With(lease.Key)
+}
+
+// readLease reads the lease stored in a file
+func readLease(filename string) (lease Lease, err error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(data, &lease)
+	return
+}
+
+// removeExpiredLease removes the file of a lease if it has expired or is gone
+//
+// It does not wait for the lock of the file, another caller is then taking the lease over or releasing it.
+func removeExpiredLease(filename string) (removed bool, err error) {
+	unlock, err := lockLease(filename)
+	if errors.Is(err, os.ErrExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+	if current, err := readLease(filename); err == nil && !current.Expired() {
+		return false, nil
+	}
+	if err := os.Remove(filename); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+	return true, nil
+}
+
+// releaseLease removes the file of a lease if it still holds the lease with the given token
+//
+// The lease is read and removed under the lock of the file, so a lease that took it over is not removed.
+func releaseLease(filename, token string) error {
+	unlock, err := lockLease(filename)
+	for errors.Is(err, os.ErrExist) {
+		time.Sleep(leasePollInterval)
+		unlock, err = lockLease(filename)
+	}
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	current, err := readLease(filename)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && current.Token != token) {
+		return nil
+	}
+	if err := os.Remove(filename); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// lockLease takes the lock of the file of a lease, an os.ErrExist is returned if another caller holds it
+//
+// The lock is a file created exclusively next to the lease. It is held only while the lease is checked and removed,
+// a lock older than leaseLockTimeout was left by a process that crashed and is removed.
+func lockLease(filename string) (unlock func(), err error) {
+	lockname := filename + ".lock"
+	file, err := os.OpenFile(lockname, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if errors.Is(err, os.ErrExist) {
+		if info, statErr := os.Stat(lockname); statErr == nil && time.Since(info.ModTime()) > leaseLockTimeout {
+			_ = os.Remove(lockname)
+		}
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	_ = file.Close()
+	return func() { _ = os.Remove(lockname) }, nil
+}
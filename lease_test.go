@@ -0,0 +1,100 @@
+package cache_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gildas/go-cache"
+)
+
+func (suite *CacheSuite) TestCanAcquireLease() {
+	reports := cache.New[string]("test")
+	lease, err := reports.AcquireLease("report", time.Minute)
+	suite.Require().NoError(err, "Failed to acquire lease: %+v", err)
+	suite.Assert().Equal("report", lease.Key)
+	suite.Assert().False(lease.Expired())
+
+	_, err = reports.AcquireLease("report", time.Minute)
+	suite.Assert().ErrorIs(err, cache.ErrLeaseHeld, "The lease should be exclusive")
+	other, err := reports.AcquireLease("other", time.Minute)
+	suite.Assert().NoError(err, "The leases of other keys should be free: %+v", err)
+	_ = other.Release()
+
+	suite.Require().NoError(lease.Release())
+	lease, err = reports.AcquireLease("report", time.Minute)
+	suite.Require().NoError(err, "A released lease should be free: %+v", err)
+	_ = lease.Release()
+}
+
+func (suite *CacheSuite) TestCanAcquireExpiredLease() {
+	reports := cache.New[string]("test")
+	lease, err := reports.AcquireLease("report", 10*time.Millisecond)
+	suite.Require().NoError(err, "Failed to acquire lease: %+v", err)
+	time.Sleep(20 * time.Millisecond)
+
+	taken, err := reports.AcquireLease("report", time.Minute)
+	suite.Require().NoError(err, "An expired lease should be free: %+v", err)
+	suite.Require().NoError(lease.Release(), "Releasing an expired lease should not fail")
+	_, err = reports.AcquireLease("report", time.Minute)
+	suite.Assert().ErrorIs(err, cache.ErrLeaseHeld, "Releasing an expired lease should not release the lease that took it over")
+	_ = taken.Release()
+}
+
+func (suite *CacheSuite) TestCanAcquireLeaseAcrossProcesses() {
+	first := cache.New[string]("test", cache.CacheOptionPersistent)
+	defer func() { _ = first.Clear() }()
+	second := cache.New[string]("test", cache.CacheOptionPersistent)
+
+	lease, err := first.AcquireLease("report", time.Minute)
+	suite.Require().NoError(err, "Failed to acquire lease: %+v", err)
+	_, err = second.AcquireLease("report", time.Minute)
+	suite.Assert().ErrorIs(err, cache.ErrLeaseHeld, "The lease should be exclusive across caches of the same folder")
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = lease.Release()
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	waited, err := second.WaitLease(ctx, "report", time.Minute)
+	suite.Require().NoError(err, "Failed to wait for the lease: %+v", err)
+	_ = waited.Release()
+}
+
+func (suite *CacheSuite) TestCanTakeOverExpiredLeaseOnlyOnceAcrossProcesses() {
+	first := cache.New[string]("test", cache.CacheOptionPersistent)
+	defer func() { _ = first.Clear() }()
+	expired, err := first.AcquireLease("report", 10*time.Millisecond)
+	suite.Require().NoError(err, "Failed to acquire lease: %+v", err)
+	time.Sleep(20 * time.Millisecond)
+
+	var acquired atomic.Int32
+	var group sync.WaitGroup
+	for range 8 {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			if _, err := cache.New[string]("test", cache.CacheOptionPersistent).AcquireLease("report", time.Minute); err == nil {
+				acquired.Add(1)
+			}
+		}()
+	}
+	group.Wait()
+	suite.Assert().Equal(int32(1), acquired.Load(), "Only one caller should take over the expired lease")
+	suite.Require().NoError(expired.Release(), "Releasing an expired lease should not fail")
+	_, err = first.AcquireLease("report", time.Minute)
+	suite.Assert().ErrorIs(err, cache.ErrLeaseHeld, "Releasing an expired lease should not release the lease that took it over")
+}
+
+func (suite *CacheSuite) TestCanGetStaleItems() {
+	reports := cache.New[string]("test")
+	_ = reports.SetWithExpiration("monthly", 10*time.Millisecond, "report")
+	time.Sleep(20 * time.Millisecond)
+
+	value, expired, err := reports.GetStale("report")
+	suite.Require().NoError(err, "Failed to get stale item: %+v", err)
+	suite.Assert().True(expired)
+	suite.Assert().Equal("monthly", *value)
+}
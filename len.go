@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"time"
+)
+
+// Len gets the number of items in memory that have not expired
+//
+// The items of the previous generations are not counted, see BumpGeneration.
+// The items persisted but not loaded in memory are counted by LenWithPersisted.
+func (cache *Cache[T]) Len() (count int) {
+	generation, now := cache.generation(cache.settings()), time.Now().UnixNano()
+	cache.storage().eachMetadata(func(key string, metadata frameMetadata) {
+		if metadata.live(generation, now) {
+			count++
+		}
+	})
+	return
+}
+
+// LenWithPersisted gets the number of items that have not expired, in memory or persisted on the disk
+//
+// Like Has, it reads only the header of the files that are not loaded in memory.
+// The items of the multi-value entries (see Add) are counted one by one, their files are read in full.
+func (cache *Cache[T]) LenWithPersisted() (count int, err error) {
+	config := cache.settings()
+	generation := cache.generation(config)
+	count = cache.Len()
+	loaded := internalFiles(config)
+	cache.sets.Range(func(key, value interface{}) bool {
+		entry := value.(*set[T])
+		entry.mutex.Lock()
+		defer entry.mutex.Unlock()
+		if entry.loaded {
+			count += len(entry.purge(generation))
+			loaded[setFilekey(key.(string))] = true
+		}
+		return true
+	})
+	if !config.persistent {
+		return
+	}
+	cache.storage().eachMetadata(func(key string, metadata frameMetadata) {
+		loaded[filepath.Base(config.filekey(key))] = true
+	})
+	err = config.walkPersisted(func(filekey string) error {
+		if !loaded[filepath.Base(filekey)] {
+			count += cache.persistedCount(config, filekey)
+		}
+		return nil
+	})
+	return
+}
+
+// persistedCount gets the number of items of the current generation that have not expired in the file named filekey
+//
+// The file of a multi-value entry can hold several items, the other files hold one at most.
+func (cache *Cache[T]) persistedCount(config *settings, filekey string) int {
+	metadata, known, err := config.peek(filekey)
+	if err != nil {
+		return 0
+	}
+	if !known || metadata.set {
+		var data json.RawMessage
+		var entry record[T]
+
+		if err = config.restore(filekey, &data); err != nil {
+			return 0
+		}
+		if len(data) > 0 && data[0] == '[' {
+			var records setRecords[T]
+
+			if err = json.Unmarshal(data, &records); err != nil {
+				return 0
+			}
+			return len((&set[T]{records: records}).purge(cache.generation(config)))
+		}
+		if err = json.Unmarshal(data, &entry); err != nil {
+			return 0
+		}
+		metadata = entry.metadata()
+	}
+	if cache.current(config, metadata) {
+		return 1
+	}
+	return 0
+}
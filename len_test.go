@@ -0,0 +1,65 @@
+package cache_test
+
+import (
+	"time"
+
+	"github.com/gildas/go-cache"
+)
+
+func (suite *CacheSuite) TestCanCountItems() {
+	names := cache.New[string]("test")
+	suite.Assert().Equal(0, names.Len())
+	suite.Require().NoError(names.Set("value", "key1"))
+	suite.Require().NoError(names.Set("value", "key2"))
+	suite.Require().NoError(names.SetWithExpiration("value", time.Millisecond, "short"))
+	suite.Assert().Equal(3, names.Len())
+	time.Sleep(5 * time.Millisecond)
+	suite.Assert().Equal(2, names.Len(), "Expired items should not be counted")
+
+	count, err := names.LenWithPersisted()
+	suite.Require().NoError(err, "Failed to count the items: %+v", err)
+	suite.Assert().Equal(2, count)
+}
+
+func (suite *CacheSuite) TestCanCountPersistedItems() {
+	writer := cache.New[string]("test", cache.CacheOptionPersistent).WithEncryptionKey([]byte("0123456789abcdef0123456789abcdef"))
+	defer func() { _ = writer.Clear() }()
+	suite.Require().NoError(writer.Set("value", "key1"))
+	suite.Require().NoError(writer.Set("value", "key2"))
+	suite.Require().NoError(writer.Set("value", "key3"))
+	suite.Require().NoError(writer.SetWithExpiration("value", time.Millisecond, "short"))
+	suite.Require().NoError(writer.ForTenant("acme").Set("value", "key4"))
+	time.Sleep(5 * time.Millisecond)
+
+	reader := cache.New[string]("test", cache.CacheOptionPersistent).WithEncryptionKey([]byte("0123456789abcdef0123456789abcdef"))
+	_, err := reader.Get("key1")
+	suite.Require().NoError(err, "Failed to get key1: %+v", err)
+	suite.Assert().Equal(1, reader.Len())
+	count, err := reader.LenWithPersisted()
+	suite.Require().NoError(err, "Failed to count the items: %+v", err)
+	suite.Assert().Equal(3, count, "The items on the disk should be counted once, without the expired item and the tenants")
+}
+
+func (suite *CacheSuite) TestCanCountMultiValueItemsAfterRestart() {
+	writer := cache.New[string]("test", cache.CacheOptionPersistent)
+	defer func() { _ = writer.Clear() }()
+	suite.Require().NoError(writer.Add("tags", "a"))
+	suite.Require().NoError(writer.Add("tags", "b"))
+	suite.Require().NoError(writer.AddWithExpiration("expired", "c", time.Millisecond))
+	suite.Require().NoError(writer.Set("x", "x"))
+	time.Sleep(5 * time.Millisecond)
+	count, err := writer.LenWithPersisted()
+	suite.Require().NoError(err, "Failed to count the items: %+v", err)
+	suite.Assert().Equal(3, count, "The items of the multi-value entries should be counted in memory")
+
+	reader := cache.New[string]("test", cache.CacheOptionPersistent)
+	count, err = reader.LenWithPersisted()
+	suite.Require().NoError(err, "Failed to count the items: %+v", err)
+	suite.Assert().Equal(3, count, "The items of the multi-value entries should be counted on the disk")
+
+	_, err = reader.GetAll("tags")
+	suite.Require().NoError(err, "Failed to get the items: %+v", err)
+	count, err = reader.LenWithPersisted()
+	suite.Require().NoError(err, "Failed to count the items: %+v", err)
+	suite.Assert().Equal(3, count, "The loaded multi-value entries should be counted once")
+}
@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/gildas/go-errors"
+)
+
+// WithLoader sets the function that loads the items missing from the cache
+//
+// The loader is used by Load and to prefetch the keys given by the prefetcher (see WithPrefetcher).
+func (cache *Cache[T]) WithLoader(loader func(ctx context.Context, key string) (T, error)) *Cache[T] {
+	return cache.configure(func(config *settings) {
+		config.loader = loader
+	})
+}
+
+// WithPrefetcher sets the function that tells which keys to load in the background when a key is missed
+//
+// For example, a miss on a page of a paginated resource can prefetch the next pages.
+// The keys that are already in the cache are not loaded again.
+// The keys are loaded with the loader given to WithLoader.
+func (cache *Cache[T]) WithPrefetcher(prefetcher func(missedKey string) []string) *Cache[T] {
+	return cache.configure(func(config *settings) {
+		config.prefetcher = prefetcher
+	})
+}
+
+// Load gets an item from the cache or loads it with the loader given to WithLoader
+//
+// Load works like GetOrCompute with the loader as the computation.
+// If there is no loader, an errors.ArgumentMissing is returned.
+func (cache *Cache[T]) Load(ctx context.Context, key string, options ...ComputeOption) (*T, error) {
+	loader, ok := cache.settings().loader.(func(context.Context, string) (T, error))
+	if !ok {
+		return nil, errors.ArgumentMissing.With("loader")
+	}
+	return cache.GetOrCompute(ctx, key, func(ctx context.Context) (T, error) {
+		return loader(ctx, key)
+	}, options...)
+}
+
+// prefetch loads in the background the keys the prefetcher relates to the missed key
+func (cache *Cache[T]) prefetch(config *settings, missedKey string) {
+	if config.prefetcher == nil {
+		return
+	}
+	loader, ok := config.loader.(func(context.Context, string) (T, error))
+	if !ok {
+		return
+	}
+	go func() {
+		for _, key := range config.prefetcher(missedKey) {
+			if len(key) == 0 || key == missedKey {
+				continue
+			}
+			if entry, found, _ := cache.lookup(config, key); found && !entry.expired() {
+				continue
+			}
+			go func() {
+				if _, err := cache.join(context.Background(), config, key, func(ctx context.Context) (T, error) {
+					return loader(ctx, key)
+				}, computeOptions{}); err != nil {
+					config.reportError(OperationPrefetch, key, err)
+				}
+			}()
+		}
+	}()
+}
@@ -0,0 +1,62 @@
+package cache_test
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+func (suite *CacheSuite) TestCanLoadMissingItems() {
+	var loads atomic.Int32
+	pages := cache.New[string]("test").WithLoader(func(ctx context.Context, key string) (string, error) {
+		loads.Add(1)
+		return "content of " + key, nil
+	})
+
+	page, err := pages.Load(context.Background(), "page-1")
+	suite.Require().NoError(err, "Failed to load page: %+v", err)
+	suite.Assert().Equal("content of page-1", *page)
+	_, err = pages.Load(context.Background(), "page-1")
+	suite.Require().NoError(err, "Failed to load page: %+v", err)
+	suite.Assert().Equal(int32(1), loads.Load(), "The page should be loaded only once")
+
+	_, err = cache.New[string]("test").Load(context.Background(), "page-1")
+	suite.Assert().ErrorIs(err, errors.ArgumentMissing, "Load without a loader should fail")
+}
+
+func (suite *CacheSuite) TestCanPrefetchRelatedKeys() {
+	var loads atomic.Int32
+	pages := cache.New[string]("test").
+		WithLoader(func(ctx context.Context, key string) (string, error) {
+			loads.Add(1)
+			return "content of " + key, nil
+		}).
+		WithPrefetcher(func(missedKey string) []string {
+			page, _ := strconv.Atoi(strings.TrimPrefix(missedKey, "page-"))
+			return []string{fmt.Sprintf("page-%d", page+1), fmt.Sprintf("page-%d", page+2)}
+		})
+
+	_, err := pages.Load(context.Background(), "page-1")
+	suite.Require().NoError(err, "Failed to load page: %+v", err)
+	suite.Require().Eventually(func() bool { return loads.Load() == 3 }, time.Second, 10*time.Millisecond, "The next pages should have been prefetched")
+	time.Sleep(10 * time.Millisecond) // let the prefetched pages be stored
+	_, err = pages.Get("page-2")
+	suite.Assert().NoError(err, "page-2 should have been prefetched")
+	_, err = pages.Get("page-3")
+	suite.Assert().NoError(err, "page-3 should have been prefetched")
+	suite.Assert().Equal(int32(3), loads.Load())
+
+	// A miss on Get prefetches too
+	_, err = pages.Get("page-10")
+	suite.Assert().ErrorIs(err, errors.NotFound)
+	suite.Require().Eventually(func() bool {
+		_, err := pages.Get("page-11")
+		return err == nil
+	}, time.Second, 10*time.Millisecond, "The next pages should have been prefetched")
+}
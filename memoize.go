@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// memoizeNamespace is used to compute the keys of memoized results
+var memoizeNamespace = uuid.NewSHA1(uuid.Nil, []byte("go-cache:memoize"))
+
+// contextType is the reflect.Type of context.Context
+var contextType = reflect.TypeFor[context.Context]()
+
+// Memoize wraps a function so its results are cached
+//
+// The argument of the function is hashed into the key of the result,
+// and the result expires with the cache's expiration.
+//
+// The result is stored only under that key, no key is derived from it.
+// Errors are not cached, and failing to store a result in the cache does not fail the call,
+// the error is given to the error handler with OperationMemoize, see WithErrorHandler.
+//
+// Example:
+//
+//	getUser := cache.Memoize(users, func(id uuid.UUID) (User, error) {
+//		return db.FindUser(id)
+//	})
+//	user, err := getUser(id)
+func Memoize[K comparable, V any](cache *Cache[V], fn func(K) (V, error)) func(K) (V, error) {
+	return memoize(cache, fn, func(argument K) (string, error) {
+		return memoizeKey(argument), nil
+	})
+}
+
+// MemoizeArguments wraps a function whose arguments are gathered in a struct so its results are cached
+//
+// The exported fields of the arguments are marshaled to JSON and hashed into the key of the result.
+// Fields of type context.Context, fields tagged with `cache:"-"`, and fields listed in excludedFields
+// are not used to compute the key.
+//
+// If the arguments cannot be marshaled, the function is called and its result is not cached.
+// The results are stored like with Memoize.
+//
+// Example:
+//
+//	type SearchArgs struct {
+//		Context context.Context
+//		Logger  *logger.Logger `cache:"-"`
+//		Query   string
+//		Page    int
+//	}
+//	search := cache.MemoizeArguments(results, func(args SearchArgs) (Results, error) {
+//		return db.Search(args.Context, args.Query, args.Page)
+//	})
+func MemoizeArguments[A any, V any](cache *Cache[V], fn func(A) (V, error), excludedFields ...string) func(A) (V, error) {
+	return memoize(cache, fn, func(arguments A) (string, error) {
+		return argumentsKey(arguments, excludedFields)
+	})
+}
+
+// memoize wraps a function so its results are cached under the key computed by keyOf
+func memoize[A any, V any](cache *Cache[V], fn func(A) (V, error), keyOf func(A) (string, error)) func(A) (V, error) {
+	return func(argument A) (V, error) {
+		key, err := keyOf(argument)
+		if err != nil {
+			return fn(argument)
+		}
+		if cached, err := cache.Get(key); err == nil {
+			return *cached, nil
+		}
+		value, err := fn(argument)
+		if err != nil {
+			return value, err
+		}
+		config := cache.settings()
+		config.reportError(OperationMemoize, key, cache.put(context.Background(), config, value, expirationOf(value, config.expiration), key))
+		return value, nil
+	}
+}
+
+// memoizeKey computes the cache key of the given argument
+func memoizeKey(argument any) string {
+	return uuid.NewSHA1(memoizeNamespace, []byte(fmt.Sprintf("%T:%#v", argument, argument))).String()
+}
+
+// argumentsKey computes the cache key of the given arguments
+//
+// If arguments is not a struct, it is marshaled as a whole.
+func argumentsKey(arguments any, excludedFields []string) (string, error) {
+	value := reflect.ValueOf(arguments)
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+	var payload any = arguments
+	if value.Kind() == reflect.Struct {
+		fields := map[string]any{}
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if !field.IsExported() || field.Tag.Get("cache") == "-" || slices.Contains(excludedFields, field.Name) {
+				continue
+			}
+			if field.Type == contextType || field.Type.Implements(contextType) {
+				continue
+			}
+			fields[field.Name] = value.Field(i).Interface()
+		}
+		payload = fields
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	return uuid.NewSHA1(memoizeNamespace, append([]byte(fmt.Sprintf("%T:", arguments)), data...)).String(), nil
+}
@@ -0,0 +1,110 @@
+package cache_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+)
+
+func (suite *CacheSuite) TestCanMemoize() {
+	users := cache.New[User]("test")
+	defer func() { _ = users.Clear() }()
+	calls := 0
+	getUser := cache.Memoize(users, func(name string) (User, error) {
+		calls++
+		if name == "nobody" {
+			return User{}, errors.NotFound.With("name", name)
+		}
+		return User{ID: uuid.New(), Name: name}, nil
+	})
+
+	joe, err := getUser("Joe")
+	suite.Require().NoError(err, "Failed to get user: %+v", err)
+	cached, err := getUser("Joe")
+	suite.Require().NoError(err, "Failed to get user: %+v", err)
+	suite.Assert().Equal(joe, cached, "User and Cached User are different")
+	suite.Assert().Equal(1, calls, "The function should have been called once")
+	_, err = users.Get(joe.ID.String())
+	suite.Assert().ErrorIs(err, errors.NotFound, "The result should not be stored under the keys derived from it")
+
+	jane, err := getUser("Jane")
+	suite.Require().NoError(err, "Failed to get user: %+v", err)
+	suite.Assert().NotEqual(joe, jane)
+	suite.Assert().Equal(2, calls, "The function should have been called for a new argument")
+
+	for i := 0; i < 2; i++ {
+		_, err = getUser("nobody")
+		suite.Assert().ErrorIs(err, errors.NotFound, fmt.Sprintf("Call %d should have failed", i))
+	}
+	suite.Assert().Equal(4, calls, "Errors should not be cached")
+}
+
+func (suite *CacheSuite) TestCanReportMemoizedResultsThatCannotBeStored() {
+	var operations []string
+	users := cache.New[User]("test").WithValidator(func(user User) error {
+		return errors.ArgumentInvalid.With("name", user.Name)
+	}).WithErrorHandler(func(operation, key string, err error) {
+		operations = append(operations, operation)
+	})
+	getUser := cache.Memoize(users, func(name string) (User, error) {
+		return User{ID: uuid.New(), Name: name}, nil
+	})
+
+	joe, err := getUser("Joe")
+	suite.Require().NoError(err, "Failing to store the result should not fail the call: %+v", err)
+	suite.Assert().Equal("Joe", joe.Name)
+	suite.Assert().Equal([]string{cache.OperationMemoize}, operations)
+}
+
+type SearchArguments struct {
+	Context context.Context
+	Logger  *logger.Logger `cache:"-"`
+	Tracer  func()
+	Query   string
+	Page    int
+}
+
+func (suite *CacheSuite) TestCanMemoizeArguments() {
+	users := cache.New[User]("test")
+	defer func() { _ = users.Clear() }()
+	calls := 0
+	search := cache.MemoizeArguments(users, func(args SearchArguments) (User, error) {
+		calls++
+		return User{ID: uuid.New(), Name: fmt.Sprintf("%s-%d", args.Query, args.Page)}, nil
+	}, "Tracer")
+
+	first, err := search(SearchArguments{Context: context.Background(), Logger: suite.Logger, Tracer: func() {}, Query: "joe", Page: 1})
+	suite.Require().NoError(err, "Failed to search: %+v", err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cached, err := search(SearchArguments{Context: ctx, Query: "joe", Page: 1})
+	suite.Require().NoError(err, "Failed to search: %+v", err)
+	suite.Assert().Equal(first, cached, "Excluded fields should not change the key")
+	suite.Assert().Equal(1, calls, "The function should have been called once")
+
+	second, err := search(SearchArguments{Query: "joe", Page: 2})
+	suite.Require().NoError(err, "Failed to search: %+v", err)
+	suite.Assert().NotEqual(first, second)
+	suite.Assert().Equal(2, calls, "The function should have been called for new arguments")
+}
+
+func (suite *CacheSuite) TestShouldNotMemoizeUnmarshalableArguments() {
+	users := cache.New[User]("test")
+	defer func() { _ = users.Clear() }()
+	calls := 0
+	search := cache.MemoizeArguments(users, func(args SearchArguments) (User, error) {
+		calls++
+		return User{ID: uuid.New(), Name: args.Query}, nil
+	})
+
+	for i := 0; i < 2; i++ {
+		_, err := search(SearchArguments{Tracer: func() {}, Query: "joe"})
+		suite.Require().NoError(err, "Failed to search: %+v", err)
+	}
+	suite.Assert().Equal(2, calls, "Arguments that cannot be marshaled should not be cached")
+}
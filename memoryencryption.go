@@ -0,0 +1,121 @@
+package cache
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// sealer encrypts the items kept in memory, see WithMemoryEncryption
+//
+// The cipher is created from the key for each item, so the key can be destroyed by Close.
+type sealer struct {
+	key    *secret
+	random io.Reader
+}
+
+// WithMemoryEncryption keeps the items encrypted in memory, they are decrypted when they are read
+//
+// This is meant for processes that handle secrets: the items do not appear in clear in heap dumps
+// and core files. The items are encrypted with AES-GCM and a random key that lives only in memory,
+// so this reduces the exposure, it does not remove it. Get returns a decrypted copy of the item,
+// and the snapshots of ReadSnapshot hold decrypted copies too.
+//
+// Each read decrypts and unmarshals the item, the items must be marshalable in JSON.
+// Items that cannot be marshaled are kept in clear.
+//
+// Close destroys the key, the items kept in memory cannot be read anymore.
+//
+// If the key cannot be read from the random source, see WithRandom, the items are not kept in memory
+// and Set returns the error.
+func (cache *Cache[T]) WithMemoryEncryption() *Cache[T] {
+	cache.configure(func(config *settings) {
+		if config.sealer == nil {
+			config.sealer, config.sealerError = newSealer(config.randomReader())
+		}
+	})
+	cache.rebuild()
+	return cache
+}
+
+// newSealer creates a new sealer with a key read from random
+//
+// It fails if random fails, rather than sealing the items with a predictable key.
+func newSealer(random io.Reader) (*sealer, error) {
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(random, key); err != nil {
+		return nil, ErrNoMemoryKey.Wrap(err)
+	}
+	defer clear(key)
+	return &sealer{key: newSecret(key), random: random}, nil
+}
+
+// seal encrypts data, the nonce is prepended to the result
+func (sealer *sealer) seal(data []byte) ([]byte, error) {
+	gcm, err := sealer.key.gcm()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize(), gcm.NonceSize()+len(data)+gcm.Overhead())
+	if _, err = io.ReadFull(sealer.random, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, data, nil), nil
+}
+
+// open decrypts data sealed by seal
+func (sealer *sealer) open(data []byte) ([]byte, error) {
+	gcm, err := sealer.key.gcm()
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errCorruptedFile
+	}
+	return gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+}
+
+// sealRecord encrypts the item of a record, if the store has a sealer
+//
+// It fails if the key is destroyed or no nonce can be read, the item must not be kept in clear then.
+func sealRecord[T interface{}](sealer *sealer, entry record[T]) (record[T], error) {
+	if sealer == nil || entry.sealed != nil {
+		return entry, nil
+	}
+	var data []byte
+	var err error
+
+	if raw, ok := any(entry.Item).([]byte); ok {
+		data = raw
+	} else if data, err = json.Marshal(entry.Item); err != nil {
+		return entry, nil
+	} else {
+		defer clear(data)
+	}
+	var zero T
+	if entry.sealed, err = sealer.seal(data); err != nil {
+		return entry, err
+	}
+	entry.Item = zero
+	return entry, nil
+}
+
+// unsealRecord decrypts the item of a record sealed by sealRecord
+func unsealRecord[T interface{}](sealer *sealer, entry record[T]) (record[T], bool) {
+	if entry.sealed == nil {
+		return entry, true
+	}
+	data, err := sealer.open(entry.sealed)
+	if err != nil {
+		return entry, false
+	}
+	entry.sealed = nil
+	if raw, ok := any(&entry.Item).(*[]byte); ok {
+		*raw = data
+		return entry, true
+	}
+	defer clear(data)
+	if err = json.Unmarshal(data, &entry.Item); err != nil {
+		return entry, false
+	}
+	return entry, true
+}
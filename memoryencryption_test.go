@@ -0,0 +1,64 @@
+package cache_test
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+type Credentials struct {
+	Username string
+	Password string
+}
+
+func (suite *CacheSuite) TestCanEncryptItemsInMemory() {
+	secrets := cache.New[Credentials]("test").WithMemoryEncryption()
+	credentials := Credentials{Username: "joe", Password: "p@ssw0rd"}
+	err := secrets.Set(credentials, "joe")
+	suite.Require().NoError(err, "Failed to set item: %+v", err)
+	value, err := secrets.Get("joe")
+	suite.Require().NoError(err, "Failed to get item: %+v", err)
+	suite.Assert().Equal(credentials, *value)
+
+	value.Password = "changed"
+	value, _ = secrets.Get("joe")
+	suite.Assert().Equal("p@ssw0rd", value.Password, "Get should return a copy of the item")
+
+	tokens := cache.NewBytes("test").WithCapacity(10).WithMemoryEncryption()
+	_ = tokens.SetWithExpiration([]byte("token"), time.Hour, "token")
+	token, err := tokens.Get("token")
+	suite.Require().NoError(err, "Failed to get item: %+v", err)
+	suite.Assert().Equal([]byte("token"), *token)
+}
+
+func (suite *CacheSuite) TestCannotUseEncryptionKeyAfterClose() {
+	secrets := cache.New[Credentials]("test-closed").WithEncryptionKey([]byte("@v3ry#S3cr3tK3y!"))
+	suite.Require().NoError(secrets.Set(Credentials{Username: "joe", Password: "p@ssw0rd"}, "joe"))
+	suite.Require().NoError(secrets.Close(), "Failed to close the cache")
+	err := secrets.Set(Credentials{Username: "jane", Password: "s3cr3t"}, "jane")
+	suite.Require().ErrorIs(err, cache.ErrKeyDestroyed)
+
+	secrets.WithEncryptionKey([]byte("@v3ry#S3cr3tK3y!"))
+	suite.Require().NoError(secrets.Set(Credentials{Username: "jane", Password: "s3cr3t"}, "jane"), "The cache should work with a new key")
+	_ = secrets.Clear()
+}
+
+func (suite *CacheSuite) TestCannotReadItemsEncryptedInMemoryAfterClose() {
+	secrets := cache.New[Credentials]("test").WithMemoryEncryption()
+	suite.Require().NoError(secrets.Set(Credentials{Username: "joe", Password: "p@ssw0rd"}, "joe"))
+	suite.Require().NoError(secrets.Close(), "Failed to close the cache")
+	_, err := secrets.Get("joe")
+	suite.Require().Error(err, "The item should not be readable after Close")
+	err = secrets.Set(Credentials{Username: "jane", Password: "s3cr3t"}, "jane")
+	suite.Require().ErrorIs(err, cache.ErrKeyDestroyed, "The item should not be stored when it cannot be sealed")
+}
+
+func (suite *CacheSuite) TestCannotEncryptItemsInMemoryWithoutRandom() {
+	secrets := cache.New[Credentials]("test").WithRandom(bytes.NewReader(nil)).WithMemoryEncryption()
+	err := secrets.Set(Credentials{Username: "joe", Password: "p@ssw0rd"}, "joe")
+	suite.Require().ErrorIs(err, cache.ErrNoMemoryKey, "The items should not be sealed with a predictable key")
+	_, err = secrets.Get("joe")
+	suite.Require().ErrorIs(err, errors.NotFound)
+}
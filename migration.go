@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+
+	"github.com/gildas/go-errors"
+)
+
+// WithMigration upgrades the items persisted with the given schema version to the next version
+//
+// The version of the schema of the items is the highest fromVersion given to WithMigration plus one,
+// the items persisted before the first migration was added have the version 0.
+// When an item with an older version is read, the migrations from its version are applied in order
+// to the JSON of the item before it is unmarshaled.
+//
+//	cache := cache.New[User]("users").
+//	  WithMigration(0, renameFullNameToName). // version 0 -> 1
+//	  WithMigration(1, splitAddress)          // version 1 -> 2
+func (cache *Cache[T]) WithMigration(fromVersion int, migrate func(data []byte) ([]byte, error)) *Cache[T] {
+	return cache.configure(func(config *settings) {
+		config.migrations = maps.Clone(config.migrations)
+		if config.migrations == nil {
+			config.migrations = map[int]func([]byte) ([]byte, error){}
+		}
+		config.migrations[max(fromVersion, 0)] = migrate
+		config.schemaVersion = max(config.schemaVersion, max(fromVersion, 0)+1)
+	})
+}
+
+// versionedItem is the part of a persisted record that migrations change
+type versionedItem struct {
+	Item    json.RawMessage
+	Version int
+}
+
+// upgrade applies the migrations to the records of the given JSON, a record or a list of records
+//
+// Records that are already at the schema version are not changed.
+func (config *settings) upgrade(data []byte) ([]byte, error) {
+	if len(config.migrations) == 0 {
+		return data, nil
+	}
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var records []json.RawMessage
+		if err := json.Unmarshal(trimmed, &records); err != nil {
+			return data, nil // let the caller report the error
+		}
+		for i, record := range records {
+			upgraded, err := config.upgradeRecord(record)
+			if err != nil {
+				return nil, err
+			}
+			records[i] = upgraded
+		}
+		return json.Marshal(records)
+	}
+	return config.upgradeRecord(data)
+}
+
+// upgradeRecord applies the migrations to the item of a record
+func (config *settings) upgradeRecord(data []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	var versioned versionedItem
+
+	if err := json.Unmarshal(data, &fields); err != nil || fields["Item"] == nil {
+		return data, nil // not a record, let the caller unmarshal it
+	}
+	if err := json.Unmarshal(data, &versioned); err != nil || versioned.Version >= config.schemaVersion {
+		return data, nil
+	}
+	item := []byte(versioned.Item)
+	for version := versioned.Version; version < config.schemaVersion; version++ {
+		migrate, found := config.migrations[version]
+		if !found {
+			return nil, ErrMigrationFailed.With("version", version)
+		}
+		upgraded, err := migrate(item)
+		if err != nil {
+			failure := ErrMigrationFailed.With("version", version).(errors.Error)
+			return nil, failure.Wrap(err)
+		}
+		item = upgraded
+	}
+	version, _ := json.Marshal(config.schemaVersion)
+	fields["Item"] = item
+	fields["Version"] = version
+	return json.Marshal(fields)
+}
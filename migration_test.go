@@ -0,0 +1,73 @@
+package cache_test
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+type ProfileV0 struct {
+	FullName string
+}
+
+type Profile struct {
+	First string
+	Last  string
+	Email string
+}
+
+// splitFullName migrates a ProfileV0 to the version 1, with First and Last
+func splitFullName(data []byte) ([]byte, error) {
+	var old ProfileV0
+	if err := json.Unmarshal(data, &old); err != nil {
+		return nil, err
+	}
+	first, last, _ := strings.Cut(old.FullName, " ")
+	return json.Marshal(map[string]string{"First": first, "Last": last})
+}
+
+// addEmail migrates a Profile of version 1 to the version 2, with a default Email
+func addEmail(data []byte) ([]byte, error) {
+	var profile Profile
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return nil, err
+	}
+	profile.Email = strings.ToLower(profile.First) + "@acme.com"
+	return json.Marshal(profile)
+}
+
+func (suite *CacheSuite) TestCanMigratePersistedItems() {
+	old := cache.New[ProfileV0]("test", cache.CacheOptionPersistent)
+	defer func() { _ = old.Clear() }()
+	suite.Require().NoError(old.Set(ProfileV0{FullName: "Joe Doe"}, "joe"))
+	suite.Require().NoError(old.Add("team", ProfileV0{FullName: "Ann Smith"}))
+
+	profiles := cache.New[Profile]("test", cache.CacheOptionPersistent).WithMigration(0, splitFullName).WithMigration(1, addEmail)
+	profile, err := profiles.Get("joe")
+	suite.Require().NoError(err, "Failed to get migrated item: %+v", err)
+	suite.Assert().Equal(Profile{First: "Joe", Last: "Doe", Email: "joe@acme.com"}, *profile)
+	team, err := profiles.GetAll("team")
+	suite.Require().NoError(err, "Failed to get migrated multi-value entry: %+v", err)
+	suite.Assert().Equal([]Profile{{First: "Ann", Last: "Smith", Email: "ann@acme.com"}}, team)
+
+	suite.Require().NoError(profiles.Set(Profile{First: "Max", Last: "Payne", Email: "max@payne.com"}, "max"))
+	reopened := cache.New[Profile]("test", cache.CacheOptionPersistent).WithMigration(0, splitFullName).WithMigration(1, addEmail)
+	profile, err = reopened.Get("max")
+	suite.Require().NoError(err, "Failed to get item: %+v", err)
+	suite.Assert().Equal("max@payne.com", profile.Email, "The items at the current version should not be migrated")
+}
+
+func (suite *CacheSuite) TestShouldFailWithMissingMigration() {
+	old := cache.New[ProfileV0]("test", cache.CacheOptionPersistent)
+	defer func() { _ = old.Clear() }()
+	suite.Require().NoError(old.Set(ProfileV0{FullName: "Joe Doe"}, "joe"))
+
+	profiles := cache.New[Profile]("test", cache.CacheOptionPersistent).WithMigration(1, addEmail)
+	_, err := profiles.Get("joe")
+	suite.Assert().ErrorIs(err, cache.ErrMigrationFailed)
+	var details errors.Error
+	suite.Require().True(errors.As(err, &details))
+	suite.Assert().Equal(0, details.Value, "The error should tell the version that could not be migrated")
+}
@@ -0,0 +1,25 @@
+//go:build !unix
+
+package cache
+
+import (
+	"os"
+
+	"github.com/gildas/go-errors"
+)
+
+// mmapFile cannot map files on this platform, the blob is read from its file instead
+func mmapFile(file *os.File, offset, size int64) (BlobReaderAt, error) {
+	return nil, errors.NotImplemented.WithStack()
+}
+
+// mapArena cannot map anonymous regions on this platform, the region is allocated in the Go heap instead
+//
+// The region holds no pointers, so the garbage collector does not scan it.
+func mapArena(size int) ([]byte, error) {
+	return make([]byte, size), nil
+}
+
+// unmapArena releases a region allocated by mapArena, the garbage collector frees it
+func unmapArena(region []byte) {
+}
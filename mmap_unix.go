@@ -0,0 +1,60 @@
+//go:build unix
+
+package cache
+
+import (
+	"io"
+	"os"
+	"syscall"
+)
+
+// mmapReaderAt reads a blob mapped in memory
+type mmapReaderAt struct {
+	data   []byte
+	offset int64
+}
+
+// mmapFile maps the size bytes of the file starting at offset in memory
+func mmapFile(file *os.File, offset, size int64) (BlobReaderAt, error) {
+	data, err := syscall.Mmap(int(file.Fd()), 0, int(offset+size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, err
+	}
+	return &mmapReaderAt{data: data, offset: offset}, nil
+}
+
+// ReadAt reads the mapped blob at the given offset
+//
+// implements io.ReaderAt
+func (reader *mmapReaderAt) ReadAt(data []byte, offset int64) (int, error) {
+	if offset < 0 || offset >= reader.Size() {
+		return 0, io.EOF
+	}
+	count := copy(data, reader.data[reader.offset+offset:])
+	if count < len(data) {
+		return count, io.EOF
+	}
+	return count, nil
+}
+
+// Size gets the size of the blob
+func (reader *mmapReaderAt) Size() int64 {
+	return int64(len(reader.data)) - reader.offset
+}
+
+// Close unmaps the blob
+//
+// implements io.Closer
+func (reader *mmapReaderAt) Close() error {
+	return syscall.Munmap(reader.data)
+}
+
+// mapArena maps an anonymous region of size bytes outside of the Go heap
+func mapArena(size int) ([]byte, error) {
+	return syscall.Mmap(-1, 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+}
+
+// unmapArena releases a region mapped by mapArena
+func unmapArena(region []byte) {
+	_ = syscall.Munmap(region)
+}
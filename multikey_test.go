@@ -0,0 +1,52 @@
+package cache_test
+
+import (
+	"bytes"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+func (suite *CacheSuite) TestShouldPersistAllKeysWhenOneFails() {
+	items := cache.New[string]("test", cache.CacheOptionPersistent).WithStoreTransform(func(data []byte) ([]byte, error) {
+		if bytes.Contains(data, []byte(`"Key":"bad`)) {
+			return nil, errors.NotImplemented.WithStack()
+		}
+		return data, nil
+	})
+	defer func() { _ = items.Clear() }()
+
+	err := items.Set("value", "bad-1", "good-1", "bad-2", "good-2")
+	suite.Require().Error(err)
+	suite.Assert().ErrorIs(err, cache.ErrKeyNotPersisted)
+	var failures *errors.MultiError
+	suite.Require().ErrorAs(err, &failures)
+	suite.Require().Len(failures.Errors, 2)
+	for i, failure := range failures.Errors {
+		var details errors.Error
+		suite.Require().ErrorAs(failure, &details)
+		suite.Assert().Equal([]string{"bad-1", "bad-2"}[i], details.What)
+		suite.Assert().ErrorIs(failure, errors.NotImplemented)
+	}
+
+	reader := cache.New[string]("test", cache.CacheOptionPersistent)
+	for _, key := range []string{"good-1", "good-2"} {
+		_, err := reader.Get(key)
+		suite.Assert().NoError(err, "%s should have been persisted", key)
+	}
+	_, err = items.Get("bad-1")
+	suite.Assert().NoError(err, "The item should be in memory under all the keys")
+}
+
+func (suite *CacheSuite) TestShouldReportSingleKeyFailure() {
+	items := cache.New[string]("test", cache.CacheOptionPersistent).WithStoreTransform(func(data []byte) ([]byte, error) {
+		return nil, errors.NotImplemented.WithStack()
+	})
+	defer func() { _ = items.Clear() }()
+
+	err := items.Set("value", "key")
+	suite.Require().ErrorIs(err, cache.ErrKeyNotPersisted)
+	var details errors.Error
+	suite.Require().ErrorAs(err, &details)
+	suite.Assert().Equal("key", details.What)
+}
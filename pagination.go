@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"cmp"
+	"container/heap"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/gildas/go-errors"
+)
+
+// KeysPage gets up to limit keys of the cache after the given cursor, and the cursor of the next page
+//
+// Start with an empty cursor, the last page gives an empty next cursor. The keys come in a stable
+// order, the order of the names of their files. Keys set or deleted while paging may or may not be listed.
+//
+// Each page walks all the keys, in memory and on the disk, but holds only limit of them, so huge caches
+// can be listed by admin APIs and tools. The files of the keys of the page that are not in memory are read
+// to get their key: a page may have fewer keys than limit when some of them have expired meanwhile.
+func (cache *Cache[T]) KeysPage(cursor string, limit int) (keys []string, next string, err error) {
+	if limit <= 0 {
+		return nil, "", errors.ArgumentInvalid.With("limit", limit)
+	}
+	config := cache.settings()
+	page := &keysPage{limit: limit, positions: map[string]bool{}}
+	generation, now := cache.generation(config), time.Now().UnixNano()
+	cache.storage().eachMetadata(func(key string, metadata frameMetadata) {
+		if metadata.live(generation, now) {
+			page.offer(cursor, config.filekey(key), key)
+		}
+	})
+	if config.persistent {
+		if err = config.walkPersisted(func(filekey string) error {
+			page.offer(cursor, filekey, "")
+			return nil
+		}); err != nil {
+			return nil, "", err
+		}
+	}
+	candidates := page.sorted()
+	for _, candidate := range candidates {
+		if len(candidate.key) == 0 {
+			var entry record[json.RawMessage]
+
+			if err := config.restore(candidate.position, &entry); err != nil || len(entry.Key) == 0 || entry.expired() || entry.Generation != generation {
+				continue
+			}
+			candidate.key = entry.Key
+		}
+		keys = append(keys, candidate.key)
+	}
+	if len(candidates) == limit {
+		next = candidates[len(candidates)-1].position
+	}
+	return
+}
+
+// pageCandidate is a key that may be in a page, at the position of its file name
+//
+// The key of the files that are not in memory is not known until the file is read.
+type pageCandidate struct {
+	position string
+	key      string
+}
+
+// keysPage keeps the limit first candidates after a cursor, in a max-heap of their positions
+//
+// implements heap.Interface
+type keysPage struct {
+	limit      int
+	candidates []pageCandidate
+	positions  map[string]bool
+}
+
+// offer adds a candidate to the page if it comes after the cursor and before the last candidate of a full page
+//
+// The candidates of a position that is already in the page are ignored.
+func (page *keysPage) offer(cursor, position, key string) {
+	if position <= cursor || page.positions[position] {
+		return
+	}
+	if len(page.candidates) == page.limit {
+		if position >= page.candidates[0].position {
+			return
+		}
+		delete(page.positions, heap.Pop(page).(pageCandidate).position)
+	}
+	heap.Push(page, pageCandidate{position: position, key: key})
+	page.positions[position] = true
+}
+
+// sorted gets the candidates of the page in the order of their positions
+func (page *keysPage) sorted() []pageCandidate {
+	return slices.SortedFunc(slices.Values(page.candidates), func(a, b pageCandidate) int {
+		return cmp.Compare(a.position, b.position)
+	})
+}
+
+// Len implements heap.Interface
+func (page *keysPage) Len() int { return len(page.candidates) }
+
+// Less implements heap.Interface, the last position comes first
+func (page *keysPage) Less(i, j int) bool {
+	return page.candidates[i].position > page.candidates[j].position
+}
+
+// Swap implements heap.Interface
+func (page *keysPage) Swap(i, j int) {
+	page.candidates[i], page.candidates[j] = page.candidates[j], page.candidates[i]
+}
+
+// Push implements heap.Interface
+func (page *keysPage) Push(candidate any) {
+	page.candidates = append(page.candidates, candidate.(pageCandidate))
+}
+
+// Pop implements heap.Interface
+func (page *keysPage) Pop() any {
+	last := page.candidates[len(page.candidates)-1]
+	page.candidates = page.candidates[:len(page.candidates)-1]
+	return last
+}
@@ -0,0 +1,64 @@
+package cache_test
+
+import (
+	"fmt"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+// allKeysByPage pages through the keys of the cache
+func (suite *CacheSuite) allKeysByPage(items *cache.Cache[int], limit int) (all []string, pages int) {
+	cursor := ""
+	for {
+		keys, next, err := items.KeysPage(cursor, limit)
+		suite.Require().NoError(err, "Failed to get page: %+v", err)
+		suite.Require().LessOrEqual(len(keys), limit)
+		all = append(all, keys...)
+		pages++
+		if len(next) == 0 {
+			return
+		}
+		cursor = next
+	}
+}
+
+func (suite *CacheSuite) TestCanPageThroughKeys() {
+	items := cache.New[int]("test")
+	for i := range 25 {
+		_ = items.Set(i, fmt.Sprintf("key-%02d", i))
+	}
+	keys, pages := suite.allKeysByPage(items, 10)
+	suite.Assert().Len(keys, 25)
+	suite.Assert().Equal(3, pages)
+	suite.Assert().ElementsMatch(keys, uniqueKeys(keys), "The pages should not overlap")
+
+	_, _, err := items.KeysPage("", 0)
+	suite.Assert().ErrorIs(err, errors.ArgumentInvalid)
+}
+
+func (suite *CacheSuite) TestCanPageThroughPersistedKeys() {
+	items := cache.New[int]("test", cache.CacheOptionPersistent)
+	defer func() { _ = items.Clear() }()
+	for i := range 25 {
+		_ = items.Set(i, fmt.Sprintf("key-%02d", i))
+	}
+	reopened := cache.New[int]("test", cache.CacheOptionPersistent)
+	_, _ = reopened.Get("key-03") // some keys are in memory too
+	_, _ = reopened.Get("key-17")
+	keys, _ := suite.allKeysByPage(reopened, 7)
+	suite.Assert().Len(keys, 25)
+	suite.Assert().ElementsMatch(keys, uniqueKeys(keys), "The pages should not overlap")
+	suite.Assert().Contains(keys, "key-24")
+}
+
+func uniqueKeys(keys []string) (unique []string) {
+	seen := map[string]bool{}
+	for _, key := range keys {
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, key)
+		}
+	}
+	return
+}
@@ -0,0 +1,169 @@
+// Package peers loads the items of a Cache from the peer that owns their key, like groupcache
+//
+// Each key is owned by one peer, chosen with consistent hashing. On a miss, a peer asks the owner
+// of the key over HTTP, and only the owner calls the loader, so the origin is asked once per key
+// for the whole cluster. The keys fetched often from their owner are also kept by the peers that ask for them.
+//
+//	users := peers.NewGroup(cache.New[User]("users"), "http://10.0.0.1:8080/_users/", loadUser)
+//	users.SetPeers("http://10.0.0.1:8080/_users/", "http://10.0.0.2:8080/_users/")
+//	http.Handle("/_users/", users)
+//	user, err := users.Get(ctx, id)
+package peers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+// Replicas is the number of times each peer is placed on the ring, see NewRing
+const Replicas = 50
+
+// Group loads the items of a Cache from the peer that owns their key
+//
+// The peers are given by their base URL, where their Group is served.
+// The items are sent between the peers in JSON.
+type Group[T any] struct {
+	Cache  *cache.Cache[T]
+	Self   string
+	Client *http.Client
+
+	// HotThreshold is the number of times a key is fetched from its owner before it is kept by this peer
+	HotThreshold int
+	// HotExpiration is the time the hot keys are kept by this peer, 0 means they are never kept
+	HotExpiration time.Duration
+
+	loader  func(ctx context.Context, key string) (T, error)
+	ring    atomic.Pointer[Ring]
+	fetches *cache.Cache[int]
+	mutex   sync.Mutex
+}
+
+// NewGroup creates a new Group for the given cache
+//
+// self is the base URL of this peer, loader loads the items this peer owns.
+// The hot keys are kept for a minute after they were fetched 10 times.
+func NewGroup[T any](items *cache.Cache[T], self string, loader func(ctx context.Context, key string) (T, error)) *Group[T] {
+	return &Group[T]{
+		Cache:         items,
+		Self:          self,
+		HotThreshold:  10,
+		HotExpiration: time.Minute,
+		loader:        loader,
+		fetches:       cache.New[int](items.Name + "-fetches"),
+	}
+}
+
+// SetPeers sets the base URLs of the peers of the group, this peer included
+//
+// It is safe to call this method while the group is in use.
+func (group *Group[T]) SetPeers(peers ...string) {
+	group.ring.Store(NewRing(Replicas, peers...))
+}
+
+// Get gets an item from the cache, from the peer that owns its key, or from the loader
+//
+// If the owner of the key cannot be reached, the item is loaded by this peer.
+// If the owner does not find the item, an errors.NotFound is returned.
+func (group *Group[T]) Get(ctx context.Context, key string) (*T, error) {
+	if item, err := group.Cache.Get(key); err == nil {
+		return item, nil
+	}
+	if ring := group.ring.Load(); ring != nil {
+		if owner := ring.Owner(key); len(owner) > 0 && owner != group.Self {
+			item, err := group.fetch(ctx, owner, key)
+			if err == nil {
+				group.replicate(key, *item)
+				return item, nil
+			}
+			if errors.Is(err, errors.NotFound) {
+				return nil, err
+			}
+		}
+	}
+	return group.load(ctx, key)
+}
+
+// ServeHTTP sends the items this peer owns to the other peers
+//
+// The key is the last segment of the path of the request.
+//
+// implements http.Handler
+func (group *Group[T]) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	path := request.URL.EscapedPath()
+	key, err := url.PathUnescape(path[strings.LastIndex(path, "/")+1:])
+	if err != nil || len(key) == 0 {
+		http.Error(writer, "Missing key", http.StatusBadRequest)
+		return
+	}
+	item, err := group.load(request.Context(), key)
+	if errors.Is(err, errors.NotFound) {
+		http.Error(writer, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(writer).Encode(item)
+}
+
+// load gets an item from the cache or loads it with the loader
+func (group *Group[T]) load(ctx context.Context, key string) (*T, error) {
+	return group.Cache.GetOrCompute(ctx, key, func(ctx context.Context) (T, error) {
+		return group.loader(ctx, key)
+	})
+}
+
+// fetch gets an item from the peer that owns its key
+func (group *Group[T]) fetch(ctx context.Context, owner, key string) (*T, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(owner, "/")+"/"+url.PathEscape(key), nil)
+	if err != nil {
+		return nil, errors.InvalidURL.With(owner)
+	}
+	client := group.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.FromHTTPStatusCode(response.StatusCode)
+	}
+	var item T
+
+	if err = json.NewDecoder(response.Body).Decode(&item); err != nil {
+		return nil, errors.JSONUnmarshalError.Wrap(err)
+	}
+	return &item, nil
+}
+
+// replicate keeps an item fetched from its owner when its key is hot
+func (group *Group[T]) replicate(key string, item T) {
+	if group.HotExpiration <= 0 {
+		return
+	}
+	group.mutex.Lock()
+	count, _ := group.fetches.GetValue(key)
+	hot := count+1 >= group.HotThreshold
+	if hot {
+		_ = group.fetches.Delete(key)
+	} else {
+		_ = group.fetches.SetWithExpiration(count+1, group.HotExpiration, key)
+	}
+	group.mutex.Unlock()
+	if hot {
+		_ = group.Cache.SetWithExpiration(item, group.HotExpiration, key)
+	}
+}
@@ -0,0 +1,103 @@
+package peers_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-cache/peers"
+	"github.com/gildas/go-errors"
+)
+
+type PeersSuite struct {
+	suite.Suite
+}
+
+func TestPeersSuite(t *testing.T) {
+	suite.Run(t, new(PeersSuite))
+}
+
+type peer struct {
+	group  *peers.Group[string]
+	server *httptest.Server
+	loads  atomic.Int32
+}
+
+func (suite *PeersSuite) start(name string) *peer {
+	peer := &peer{}
+	mux := http.NewServeMux()
+	peer.server = httptest.NewServer(mux)
+	peer.group = peers.NewGroup(cache.New[string](name), peer.server.URL+"/_items/", func(ctx context.Context, key string) (string, error) {
+		peer.loads.Add(1)
+		if key == "missing" {
+			return "", errors.NotFound.With("key", key)
+		}
+		return "value of " + key, nil
+	})
+	mux.Handle("/_items/", peer.group)
+	return peer
+}
+
+func (suite *PeersSuite) TestCanLoadFromOwner() {
+	first, second := suite.start("test-peers-1"), suite.start("test-peers-2")
+	defer first.server.Close()
+	defer second.server.Close()
+	ring := peers.NewRing(peers.Replicas, first.group.Self, second.group.Self)
+	first.group.SetPeers(first.group.Self, second.group.Self)
+	second.group.SetPeers(first.group.Self, second.group.Self)
+
+	owned := 0
+	for i := range 20 {
+		key := fmt.Sprintf("key/%d", i)
+		if ring.Owner(key) == second.group.Self {
+			owned++
+		}
+		value, err := first.group.Get(context.Background(), key)
+		suite.Require().NoError(err, "Failed to get %s: %+v", key, err)
+		suite.Assert().Equal("value of "+key, *value)
+		value, err = second.group.Get(context.Background(), key)
+		suite.Require().NoError(err, "Failed to get %s: %+v", key, err)
+		suite.Assert().Equal("value of "+key, *value)
+	}
+	suite.Require().NotZero(owned, "The second peer should own some keys")
+	suite.Assert().Equal(int32(owned), second.loads.Load(), "The second peer should load only the keys it owns")
+	suite.Assert().Equal(int32(20-owned), first.loads.Load(), "The first peer should load only the keys it owns")
+
+	_, err := first.group.Get(context.Background(), "missing")
+	suite.Assert().ErrorIs(err, errors.NotFound)
+}
+
+func (suite *PeersSuite) TestCanReplicateHotKeys() {
+	first, second := suite.start("test-peers-1"), suite.start("test-peers-2")
+	defer first.server.Close()
+	defer second.server.Close()
+	first.group.SetPeers(second.group.Self)
+	first.group.HotThreshold = 2
+
+	_, err := first.group.Get(context.Background(), "hot")
+	suite.Require().NoError(err, "Failed to get item: %+v", err)
+	_, found := first.group.Cache.GetValue("hot")
+	suite.Assert().False(found, "The key should not be kept before it is hot")
+	_, err = first.group.Get(context.Background(), "hot")
+	suite.Require().NoError(err, "Failed to get item: %+v", err)
+	_, found = first.group.Cache.GetValue("hot")
+	suite.Assert().True(found, "The hot key should be kept")
+	suite.Assert().Zero(first.loads.Load())
+}
+
+func (suite *PeersSuite) TestShouldLoadWhenOwnerIsUnreachable() {
+	first := suite.start("test-peers-1")
+	defer first.server.Close()
+	first.group.SetPeers("http://127.0.0.1:1/_items/")
+
+	value, err := first.group.Get(context.Background(), "key")
+	suite.Require().NoError(err, "Failed to get item: %+v", err)
+	suite.Assert().Equal("value of key", *value)
+	suite.Assert().Equal(int32(1), first.loads.Load())
+}
@@ -0,0 +1,43 @@
+package peers
+
+import (
+	"hash/crc32"
+	"slices"
+	"strconv"
+)
+
+// Ring chooses the peer that owns a key with consistent hashing
+//
+// Each peer is placed on the ring several times, so the keys are spread evenly
+// and adding or removing a peer only moves the keys of that peer.
+type Ring struct {
+	hashes []uint32
+	owners map[uint32]string
+}
+
+// NewRing creates a new Ring with the given peers, each placed replicas times on the ring
+func NewRing(replicas int, peers ...string) *Ring {
+	ring := &Ring{owners: map[uint32]string{}}
+	for _, peer := range peers {
+		for replica := range max(replicas, 1) {
+			hash := crc32.ChecksumIEEE([]byte(strconv.Itoa(replica) + peer))
+			ring.hashes = append(ring.hashes, hash)
+			ring.owners[hash] = peer
+		}
+	}
+	slices.Sort(ring.hashes)
+	return ring
+}
+
+// Owner gets the peer that owns the given key, or an empty string if the ring has no peer
+func (ring *Ring) Owner(key string) string {
+	if len(ring.hashes) == 0 {
+		return ""
+	}
+	hash := crc32.ChecksumIEEE([]byte(key))
+	index, _ := slices.BinarySearch(ring.hashes, hash)
+	if index == len(ring.hashes) {
+		index = 0
+	}
+	return ring.owners[ring.hashes[index]]
+}
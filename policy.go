@@ -0,0 +1,63 @@
+package cache
+
+// Policy is an eviction policy that decides which key to evict when a cache reaches its capacity
+//
+// Each shard of the cache has its own Policy, the methods of a Policy can be called concurrently.
+type Policy interface {
+	// OnSet tells the policy a key was stored with the given item
+	OnSet(key string, item any)
+	// OnGet tells the policy a key was read
+	OnGet(key string)
+	// OnDelete tells the policy a key was removed, deleted or expired
+	OnDelete(key string)
+	// Victim gets the key to evict next and forgets it
+	Victim() (key string, ok bool)
+	// Len gets the number of keys known to the policy
+	Len() int
+}
+
+// itemPolicy is a policy that is told the items that are stored
+type itemPolicy interface {
+	addItem(key string, item any)
+}
+
+// customPolicy adapts a Policy to the policies of the shards
+type customPolicy struct {
+	Policy
+}
+
+func (policy customPolicy) add(key string) {
+	policy.OnSet(key, nil)
+}
+
+func (policy customPolicy) addItem(key string, item any) {
+	policy.OnSet(key, item)
+}
+
+func (policy customPolicy) touch(key string) {
+	policy.OnGet(key)
+}
+
+func (policy customPolicy) remove(key string) {
+	policy.OnDelete(key)
+}
+
+func (policy customPolicy) victim() (string, bool) {
+	return policy.Victim()
+}
+
+func (policy customPolicy) len() int {
+	return policy.Len()
+}
+
+// WithEvictionPolicy sets the maximum number of keys the cache keeps in memory
+// and evicts them with the policies created by newPolicy
+//
+// newPolicy is called for each shard with the capacity of the shard.
+// This allows policies that are not provided by the package, for example a policy that evicts
+// the items that are the cheapest to compute again first.
+func (cache *Cache[T]) WithEvictionPolicy(capacity int, newPolicy func(capacity int) Policy) *Cache[T] {
+	return cache.withPolicy(capacity, func(capacity int) policy {
+		return customPolicy{newPolicy(capacity)}
+	})
+}
@@ -0,0 +1,68 @@
+package cache_test
+
+import (
+	"sync"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+type Report struct {
+	Name string
+	Cost int
+}
+
+// costPolicy evicts the reports that are the cheapest to compute again first
+type costPolicy struct {
+	mutex sync.Mutex
+	costs map[string]int
+}
+
+func (policy *costPolicy) OnSet(key string, item any) {
+	policy.mutex.Lock()
+	defer policy.mutex.Unlock()
+	policy.costs[key] = item.(Report).Cost
+}
+
+func (policy *costPolicy) OnGet(key string) {}
+
+func (policy *costPolicy) OnDelete(key string) {
+	policy.mutex.Lock()
+	defer policy.mutex.Unlock()
+	delete(policy.costs, key)
+}
+
+func (policy *costPolicy) Victim() (victim string, ok bool) {
+	policy.mutex.Lock()
+	defer policy.mutex.Unlock()
+	for key, cost := range policy.costs {
+		if !ok || cost < policy.costs[victim] {
+			victim, ok = key, true
+		}
+	}
+	delete(policy.costs, victim)
+	return
+}
+
+func (policy *costPolicy) Len() int {
+	policy.mutex.Lock()
+	defer policy.mutex.Unlock()
+	return len(policy.costs)
+}
+
+func (suite *CacheSuite) TestCanEvictWithCustomPolicy() {
+	reports := cache.New[Report]("test").WithShards(1).WithEvictionPolicy(2, func(capacity int) cache.Policy {
+		return &costPolicy{costs: map[string]int{}}
+	})
+	defer func() { _ = reports.Clear() }()
+	_ = reports.Set(Report{Name: "expensive", Cost: 100}, "expensive")
+	_ = reports.Set(Report{Name: "cheap", Cost: 1}, "cheap")
+	_ = reports.Set(Report{Name: "average", Cost: 10}, "average")
+
+	_, err := reports.Get("cheap")
+	suite.Assert().ErrorIs(err, errors.NotFound, "The cheapest report should have been evicted")
+	for _, key := range []string{"expensive", "average"} {
+		_, err = reports.Get(key)
+		suite.Assert().NoError(err, "Failed to get report %s: %+v", key, err)
+	}
+}
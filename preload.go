@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// Preload loads the persisted items in memory with a pool of workers
+//
+// The files are read, decrypted, and decoded by up to workers goroutines, GOMAXPROCS if workers is 0 or less.
+// Expired items and the keys already in memory are skipped. With a capacity, the items that do not fit are evicted.
+// The items with the most hits are loaded first, see WithPreloadPriority.
+//
+// When the context is cancelled, the items loaded so far stay in memory and the context error is returned.
+//
+// If progress is not nil, it is called after each file is read with the number of read files and the total.
+// It is not called concurrently.
+func (cache *Cache[T]) Preload(ctx context.Context, workers int, progress func(done, total int)) error {
+	config := cache.settings()
+	if !config.persistent {
+		return ErrNotPersistent.With(cache.Name)
+	}
+	var files []string
+	err := config.walkPersisted(func(filekey string) error {
+		files = append(files, filekey)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	config.prioritize(files)
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	var done int
+	queue := make(chan string)
+	for range min(workers, max(len(files), 1)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for file := range queue {
+				cache.preload(config, file)
+				if progress != nil {
+					mutex.Lock()
+					done++
+					progress(done, len(files))
+					mutex.Unlock()
+				}
+			}
+		}()
+	}
+	defer wg.Wait()
+	defer close(queue)
+	for _, file := range files {
+		select {
+		case queue <- file:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// preload loads the item persisted in the file named filekey in memory
+//
+// Files that are not items (e.g. multi-value entries) and items kept on the disk only (see TierDiskPreferred) are skipped.
+func (cache *Cache[T]) preload(config *settings, filekey string) {
+	var entry record[T]
+
+	if err := config.restore(filekey, &entry); err != nil || len(entry.Key) == 0 || entry.expired() || entry.Generation != cache.generation(config) || entry.Tier == TierDiskPreferred {
+		return
+	}
+	cache.write(func(storage *store[T]) { _ = storage.storeIfAbsent(entry.Key, entry) })
+}
@@ -0,0 +1,68 @@
+package cache_test
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/gildas/go-cache"
+)
+
+func (suite *CacheSuite) TestCanPreload() {
+	items := cache.New[string]("test", cache.CacheOptionPersistent)
+	defer func() { _ = items.Clear() }()
+	for i := 0; i < 20; i++ {
+		_ = items.Set(fmt.Sprintf("value-%d", i), fmt.Sprintf("key-%d", i))
+	}
+	_ = items.SetWithExpiration("expired", time.Nanosecond, "expired")
+
+	var loads atomic.Int32
+	reader := cache.New[string]("test", cache.CacheOptionPersistent).WithLoadTransform(func(data []byte) ([]byte, error) {
+		loads.Add(1)
+		return data, nil
+	})
+	_ = reader.Set("fresh", "key-0")
+	var last, total int
+	err := reader.Preload(context.Background(), 4, func(done, count int) {
+		suite.Assert().Equal(last+1, done, "The progress should be reported in order")
+		last, total = done, count
+	})
+	suite.Require().NoError(err, "Failed to preload: %+v", err)
+	suite.Assert().Equal(21, total)
+	suite.Assert().Equal(21, last)
+
+	loaded := loads.Load()
+	for i := 1; i < 20; i++ {
+		value, err := reader.Get(fmt.Sprintf("key-%d", i))
+		suite.Require().NoError(err, "Failed to get key-%d: %+v", i, err)
+		suite.Assert().Equal(fmt.Sprintf("value-%d", i), *value)
+	}
+	suite.Assert().Equal(loaded, loads.Load(), "The items should be read from memory")
+	value, _ := reader.Get("key-0")
+	suite.Assert().Equal("fresh", *value, "Preload should not replace the items in memory")
+}
+
+func (suite *CacheSuite) TestCanCancelPreload() {
+	items := cache.New[string]("test", cache.CacheOptionPersistent)
+	defer func() { _ = items.Clear() }()
+	for i := 0; i < 20; i++ {
+		_ = items.Set("value", fmt.Sprintf("key-%d", i))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var last int
+	err := cache.New[string]("test", cache.CacheOptionPersistent).Preload(ctx, 1, func(done, total int) {
+		last = done
+		if done == 5 {
+			cancel()
+		}
+	})
+	suite.Require().ErrorIs(err, context.Canceled)
+	suite.Assert().Less(last, 20)
+}
+
+func (suite *CacheSuite) TestShouldNotPreloadWithoutPersistence() {
+	err := cache.New[string]("test").Preload(context.Background(), 0, nil)
+	suite.Assert().ErrorIs(err, cache.ErrNotPersistent)
+}
@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"cmp"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// prioritiesKey is the key of the priorities of the items of a persistent cache
+//
+// It starts with a NUL so it does not collide with the keys of the items.
+const prioritiesKey = "\x00priorities"
+
+// priority is the hit count and the last access of an item, see WithPreloadPriority
+type priority struct {
+	Hits     uint64 `json:"hits"`
+	Accessed int64  `json:"accessed"`
+}
+
+// priorities counts the hits of the items of a cache
+type priorities struct {
+	mutex sync.Mutex
+	keys  map[string]priority
+}
+
+// persistedPriorities is the content of the file that persists the priorities of a cache
+//
+// The priorities are indexed by the name of the file of their item.
+type persistedPriorities struct {
+	Files map[string]priority
+}
+
+// WithPreloadPriority counts the hits and the last access of the items, so Preload loads the most valuable items first
+//
+// The counts are persisted with the items by Flush and Close. Preload then loads the items with the most hits first,
+// the most recently accessed first among the items with the same hits, and the items without counts last.
+// Running Preload in a goroutine makes the most valuable items available first while the rest stream in.
+func (cache *Cache[T]) WithPreloadPriority() *Cache[T] {
+	return cache.configure(func(config *settings) {
+		if config.priorities == nil {
+			config.priorities = &priorities{keys: map[string]priority{}}
+		}
+	})
+}
+
+// hit counts a hit of a key
+func (priorities *priorities) hit(key string) {
+	priorities.mutex.Lock()
+	defer priorities.mutex.Unlock()
+	current := priorities.keys[key]
+	priorities.keys[key] = priority{Hits: current.Hits + 1, Accessed: time.Now().UnixNano()}
+}
+
+// persistPriorities writes the priorities of the items that are still in the cache
+//
+// The priorities persisted by a previous instance are kept for the items that were not read since.
+func (cache *Cache[T]) persistPriorities(config *settings) error {
+	if config.priorities == nil || !config.persistent {
+		return nil
+	}
+	persisted := config.loadPriorities()
+	storage := cache.storage()
+	config.priorities.mutex.Lock()
+	for key, current := range config.priorities.keys {
+		if _, found := storage.load(key); !found {
+			delete(config.priorities.keys, key)
+			continue
+		}
+		filename := filepath.Base(config.filekey(key))
+		previous := persisted.Files[filename]
+		persisted.Files[filename] = priority{Hits: previous.Hits + current.Hits, Accessed: max(previous.Accessed, current.Accessed)}
+		config.priorities.keys[key] = priority{Accessed: current.Accessed}
+	}
+	config.priorities.mutex.Unlock()
+	return config.persist(config.filekey(prioritiesKey), persisted)
+}
+
+// loadPriorities reads the persisted priorities of a cache
+func (config *settings) loadPriorities() persistedPriorities {
+	persisted := persistedPriorities{}
+	quiet := *config // loading the priorities is not an operation of the cache
+	quiet.recorder = nil
+	_ = quiet.restore(config.filekey(prioritiesKey), &persisted)
+	if persisted.Files == nil {
+		persisted.Files = map[string]priority{}
+	}
+	return persisted
+}
+
+// prioritize sorts the files to preload, the most valuable first
+func (config *settings) prioritize(files []string) {
+	persisted := config.loadPriorities()
+	if len(persisted.Files) == 0 {
+		return
+	}
+	slices.SortStableFunc(files, func(a, b string) int {
+		first, second := persisted.Files[filepath.Base(a)], persisted.Files[filepath.Base(b)]
+		if first.Hits != second.Hits {
+			return cmp.Compare(second.Hits, first.Hits)
+		}
+		return cmp.Compare(second.Accessed, first.Accessed)
+	})
+}
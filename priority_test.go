@@ -0,0 +1,37 @@
+package cache_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/gildas/go-cache"
+)
+
+func (suite *CacheSuite) TestCanPreloadMostValuableItemsFirst() {
+	items := cache.New[string]("test", cache.CacheOptionPersistent).WithPreloadPriority()
+	defer func() { _ = items.Clear() }()
+	for i := 0; i < 10; i++ {
+		_ = items.Set(fmt.Sprintf("value-%d", i), fmt.Sprintf("key-%d", i))
+	}
+	for range 3 {
+		_, _ = items.Get("key-7")
+	}
+	_, _ = items.Get("key-2")
+	_, _ = items.Get("key-2")
+	_, _ = items.Get("key-5")
+	suite.Require().NoError(items.Close(), "Failed to close the cache")
+
+	var order []string
+	reader := cache.New[string]("test", cache.CacheOptionPersistent).WithLoadTransform(func(data []byte) ([]byte, error) {
+		var entry struct{ Key string }
+		if json.Unmarshal(data, &entry) == nil && len(entry.Key) > 0 {
+			order = append(order, entry.Key)
+		}
+		return data, nil
+	})
+	err := reader.Preload(context.Background(), 1, nil)
+	suite.Require().NoError(err, "Failed to preload: %+v", err)
+	suite.Require().Len(order, 10)
+	suite.Assert().Equal([]string{"key-7", "key-2", "key-5"}, order[:3], "The items with the most hits should be loaded first")
+}
@@ -0,0 +1,36 @@
+package cache
+
+import (
+	"context"
+	"runtime/pprof"
+)
+
+// WithProfilerLabels labels the goroutines running the operations of the cache with the cache name and the operation
+//
+// CPU profiles then attribute the time spent in the cache to each cache and operation (get, set, compute, delete).
+// The goroutines started by the operations, like computations and prefetches, inherit the labels,
+// and the context given to the computations carries them (see pprof.Label).
+//
+// When an operation ends, the labels of the goroutine are set to the labels of its context,
+// so the operations without a context (Get, Set, Delete) remove the labels set by the caller.
+// Use the operations with a context to keep them.
+func (cache *Cache[T]) WithProfilerLabels(enabled bool) *Cache[T] {
+	return cache.configure(func(config *settings) {
+		config.profilerLabels = enabled
+	})
+}
+
+// unlabel does nothing, it is returned by label when the labels are disabled
+func unlabel() {}
+
+// label labels the current goroutine with the cache name and the operation
+//
+// It returns the labeled context and the function that restores the labels of ctx.
+func (config *settings) label(ctx context.Context, name, operation string) (context.Context, func()) {
+	if !config.profilerLabels {
+		return ctx, unlabel
+	}
+	labeled := pprof.WithLabels(ctx, pprof.Labels("cache", name, "operation", operation))
+	pprof.SetGoroutineLabels(labeled)
+	return labeled, func() { pprof.SetGoroutineLabels(ctx) }
+}
@@ -0,0 +1,29 @@
+package cache_test
+
+import (
+	"context"
+	"runtime/pprof"
+
+	"github.com/gildas/go-cache"
+)
+
+func (suite *CacheSuite) TestCanLabelComputations() {
+	items := cache.New[string]("test").WithProfilerLabels(true)
+	var name, operation string
+	_, err := items.GetOrCompute(context.Background(), "key", func(ctx context.Context) (string, error) {
+		name, _ = pprof.Label(ctx, "cache")
+		operation, _ = pprof.Label(ctx, "operation")
+		return "value", nil
+	})
+	suite.Require().NoError(err, "Failed to compute: %+v", err)
+	suite.Assert().Equal("test", name)
+	suite.Assert().Equal("compute", operation)
+
+	items.WithProfilerLabels(false)
+	_, err = items.GetOrCompute(context.Background(), "other", func(ctx context.Context) (string, error) {
+		name, _ = pprof.Label(ctx, "cache")
+		return "value", nil
+	})
+	suite.Require().NoError(err, "Failed to compute: %+v", err)
+	suite.Assert().Empty(name, "The labels should be disabled")
+}
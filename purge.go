@@ -0,0 +1,221 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gildas/go-errors"
+)
+
+// Purge removes the items that match the given predicate, in memory and on the disk
+//
+// The predicate is called with the key and the item of each entry, including the items of
+// multi-value entries and of the tenant views. It returns the number of items removed.
+//
+// Items persisted before their keys were recorded are given an empty key.
+func (cache *Cache[T]) Purge(match func(key string, item T) bool) (count int, err error) {
+	return cache.purge(match, nil)
+}
+
+// PurgeDryRun reports what Purge would remove with the given predicate, without removing anything
+func (cache *Cache[T]) PurgeDryRun(match func(key string, item T) bool) (report DryRun, err error) {
+	dryRun := newDryRun()
+	_, err = cache.purge(match, dryRun)
+	return *dryRun, err
+}
+
+// purge removes the items that match the given predicate, or adds them to the report if it is not nil
+func (cache *Cache[T]) purge(match func(key string, item T) bool, report *DryRun) (count int, err error) {
+	config := cache.settings()
+
+	cache.storage().each(func(key string, entry record[T]) bool {
+		if !match(key, entry.Item) {
+			return true
+		}
+		count++
+		if report != nil {
+			report.add(key)
+			if config.persistent {
+				report.addFile(filepath.Join(config.folder, config.filekey(key)))
+			}
+			return true
+		}
+		cache.write(func(storage *store[T]) { storage.delete(key) })
+		if config.auditor != nil {
+			config.audit(context.Background(), cache.Name, AuditDelete, key, 0)
+		}
+		if config.onChange != nil {
+			cache.changed(context.Background(), config, Change[T]{Operation: AuditDelete, Key: key})
+		}
+		if config.persistent {
+			if err = config.erase(config.filekey(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return false
+			}
+			err = nil
+		}
+		return true
+	})
+	if err != nil {
+		return
+	}
+	cache.sets.Range(func(key, value interface{}) bool {
+		var purged int
+
+		entry := value.(*set[T])
+		entry.mutex.Lock()
+		defer entry.mutex.Unlock()
+		if report != nil {
+			if purged = dryRunRecords(report, entry.records, match); purged > 0 && config.persistent {
+				report.addFile(filepath.Join(config.folder, setFilekey(key.(string))))
+			}
+			count += purged
+		} else if entry.records, purged = purgeRecords(entry.records, match); purged > 0 {
+			count += purged
+			if err = rewrite(config, setFilekey(key.(string)), entry.records); err == nil && len(entry.records) == 0 {
+				cache.forget(key.(string), entry)
+			}
+		}
+		return err == nil
+	})
+	if err != nil {
+		return
+	}
+	cache.tenants.Range(func(id, view interface{}) bool {
+		var purged int
+
+		purged, err = view.(*Cache[T]).purge(match, report)
+		count += purged
+		return err == nil
+	})
+	if err != nil || !config.persistent {
+		return
+	}
+	purged, err := purgeFolder(config, match, report)
+	count += purged
+	// The tenants that are loaded were purged with their view
+	for _, name := range cache.unloadedTenants(config) {
+		if err != nil {
+			return
+		}
+		purged, err = purgeFolder(config.tenantSettings(name), match, report)
+		count += purged
+	}
+	return
+}
+
+// purgeFolder removes the persisted items that match the given predicate from the folder of the settings and its subfolders
+//
+// If report is not nil, the items are added to it instead.
+func purgeFolder[T interface{}](config *settings, match func(key string, item T) bool, report *DryRun) (count int, err error) {
+	var entries []os.DirEntry
+
+	internal := internalFiles(config)
+	if entries, err = os.ReadDir(config.folder); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return
+	}
+	for _, entry := range entries {
+		var purged int
+
+		if entry.IsDir() && (entry.Name() == blobsFolder || entry.Name() == dependentsFolder || entry.Name() == leasesFolder || entry.Name() == quarantineFolder || entry.Name() == tenantsFolder) {
+			continue
+		} else if entry.IsDir() {
+			subfolder := *config
+			subfolder.folder = filepath.Join(config.folder, entry.Name())
+			subfolder.bloom = nil
+			purged, err = purgeFolder(&subfolder, match, report)
+		} else if !internal[entry.Name()] && !strings.HasPrefix(entry.Name(), ".tmp-") {
+			purged, err = purgeFile(config, entry.Name(), match, report)
+		}
+		count += purged
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// purgeFile removes the persisted items that match the given predicate from the file named filekey
+//
+// The file contains either a single record or the records of a multi-value entry.
+// If report is not nil, the items are added to it instead.
+func purgeFile[T interface{}](config *settings, filekey string, match func(key string, item T) bool, report *DryRun) (count int, err error) {
+	var data json.RawMessage
+
+	path := filepath.Join(config.folder, filekey)
+	if report != nil && report.seen(path) {
+		return 0, nil
+	}
+	if err = config.restore(filekey, &data); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return
+	}
+	if len(data) > 0 && data[0] == '[' {
+		var records []record[T]
+
+		if err = json.Unmarshal(data, &records); err == nil && report != nil {
+			if count = dryRunRecords(report, records, match); count > 0 {
+				report.addFile(path)
+			}
+		} else if err == nil {
+			if records, count = purgeRecords(records, match); count > 0 {
+				err = rewrite(config, filekey, records)
+			}
+		}
+		return
+	}
+	var entry record[T]
+
+	if err = json.Unmarshal(data, &entry); err != nil || !match(entry.Key, entry.Item) {
+		return
+	}
+	if report != nil {
+		report.add(entry.Key)
+		report.addFile(path)
+		return 1, nil
+	}
+	if err = config.erase(filekey); err == nil {
+		count = 1
+	}
+	return
+}
+
+// purgeRecords gets the records that do not match the given predicate and how many were removed
+func purgeRecords[T interface{}](records []record[T], match func(key string, item T) bool) ([]record[T], int) {
+	kept := make([]record[T], 0, len(records))
+	for _, record := range records {
+		if !match(record.Key, record.Item) {
+			kept = append(kept, record)
+		}
+	}
+	return kept, len(records) - len(kept)
+}
+
+// dryRunRecords adds the records that match the given predicate to the report and gets how many there are
+func dryRunRecords[T interface{}](report *DryRun, records []record[T], match func(key string, item T) bool) (count int) {
+	for _, record := range records {
+		if match(record.Key, record.Item) {
+			report.add(record.Key)
+			count++
+		}
+	}
+	return
+}
+
+// rewrite persists the records of a multi-value entry, or erases its file if there are none left
+func rewrite[T interface{}](config *settings, filekey string, records []record[T]) error {
+	if !config.persistent {
+		return nil
+	}
+	if len(records) == 0 {
+		return config.erase(filekey)
+	}
+	return config.persist(filekey, setRecords[T](records))
+}
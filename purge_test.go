@@ -0,0 +1,109 @@
+package cache_test
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+func (suite *CacheSuite) TestCanPurgeMatchingItems() {
+	users := cache.New[User]("test", cache.CacheOptionPersistent)
+	defer func() { _ = users.Clear() }()
+	joe := User{ID: uuid.New(), Name: "Joe"}
+	jane := User{ID: uuid.New(), Name: "Jane"}
+	_ = users.Set(joe)
+	_ = users.Set(jane)
+	_ = users.Add("friends", joe)
+	_ = users.Add("friends", jane)
+	_ = users.ForTenant("acme").Set(joe, "me")
+
+	count, err := users.Purge(func(key string, item User) bool { return item.ID == joe.ID })
+	suite.Require().NoError(err, "Failed to purge: %+v", err)
+	suite.Assert().Equal(6, count, "Joe's ID and Name keys, the friend, and the 3 keys of the tenant item should be purged")
+
+	_, err = users.Get(joe.ID.String())
+	suite.Assert().ErrorIs(err, errors.NotFound)
+	_, err = users.Get("Joe")
+	suite.Assert().ErrorIs(err, errors.NotFound)
+	_, err = users.ForTenant("acme").Get("me")
+	suite.Assert().ErrorIs(err, errors.NotFound)
+	friends, err := users.GetAll("friends")
+	suite.Require().NoError(err, "Failed to get friends: %+v", err)
+	suite.Assert().Equal([]User{jane}, friends)
+	_, err = users.Get("Jane")
+	suite.Assert().NoError(err, "Jane should not be purged")
+}
+
+func (suite *CacheSuite) TestCanPurgePersistedItems() {
+	users := cache.New[User]("test", cache.CacheOptionPersistent)
+	defer func() { _ = users.Clear() }()
+	joe := User{ID: uuid.New(), Name: "Joe"}
+	_ = users.Set(joe)
+	_ = users.Add("friends", joe)
+	_ = users.ForTenant("acme").Set(joe, "me")
+
+	// Another process that never read these items
+	other := cache.New[User]("test", cache.CacheOptionPersistent)
+	count, err := other.Purge(func(key string, item User) bool { return key == "Joe" || key == "friends" || key == "me" })
+	suite.Require().NoError(err, "Failed to purge: %+v", err)
+	suite.Assert().Equal(4, count, "Joe's Name key, the friends, and the tenant's me and Name keys should be purged")
+
+	fresh := cache.New[User]("test", cache.CacheOptionPersistent)
+	_, err = fresh.Get("Joe")
+	suite.Assert().ErrorIs(err, errors.NotFound)
+	_, err = fresh.GetAll("friends")
+	suite.Assert().ErrorIs(err, errors.NotFound)
+	_, err = fresh.ForTenant("acme").Get("me")
+	suite.Assert().ErrorIs(err, errors.NotFound)
+	_, err = fresh.Get(joe.ID.String())
+	suite.Assert().NoError(err, "Items under other keys should not be purged")
+}
+
+func (suite *CacheSuite) TestPurgeKeepsLeases() {
+	for _, key := range [][]byte{nil, []byte("0123456789abcdef0123456789abcdef")} {
+		items := cache.New[string]("test", cache.CacheOptionPersistent)
+		if key != nil {
+			items = items.WithEncryptionKey(key)
+		}
+		lease, err := items.AcquireLease("a", time.Minute)
+		suite.Require().NoError(err, "Failed to acquire the lease: %+v", err)
+		suite.Require().NoError(items.Set("value", "a"))
+
+		count, err := items.Purge(func(key string, item string) bool { return key == "a" })
+		suite.Require().NoError(err, "Failed to purge with an active lease (encrypted: %v): %+v", key != nil, err)
+		suite.Assert().Equal(1, count, "Only the item should be purged")
+
+		_, err = cache.New[string]("test", cache.CacheOptionPersistent).AcquireLease("a", time.Minute)
+		suite.Assert().ErrorIs(err, cache.ErrLeaseHeld, "The lease should still be held")
+		suite.Require().NoError(lease.Release())
+		suite.Require().NoError(items.Clear())
+	}
+}
+
+func (suite *CacheSuite) TestCanPurgeEncryptedTenantItems() {
+	encryptionKey := []byte("@v3ry#S3cr3tK3y!")
+	users := cache.New[User]("test").WithEncryptionKey(encryptionKey)
+	defer func() { _ = users.Clear() }()
+	joe := User{ID: uuid.New(), Name: "Joe"}
+	jane := User{ID: uuid.New(), Name: "Jane"}
+	suite.Require().NoError(users.ForTenant("acme").Set(joe, "me"))
+	suite.Require().NoError(users.ForTenant("globex").Set(jane, "me"))
+
+	count, err := users.Purge(func(key string, item User) bool { return item.ID == joe.ID })
+	suite.Require().NoError(err, "Failed to purge: %+v", err)
+	suite.Assert().Equal(3, count, "The 3 keys of the tenant item should be purged")
+
+	// After a restart, the tenants are not loaded
+	restarted := cache.New[User]("test").WithEncryptionKey(encryptionKey)
+	report, err := restarted.PurgeDryRun(func(key string, item User) bool { return item.ID == jane.ID })
+	suite.Require().NoError(err, "Failed to dry run the purge: %+v", err)
+	suite.Assert().Equal(3, report.Files, "The 3 files of the tenant item should be reported")
+	count, err = restarted.Purge(func(key string, item User) bool { return item.ID == jane.ID })
+	suite.Require().NoError(err, "Failed to purge: %+v", err)
+	suite.Assert().Equal(3, count, "The 3 keys of the tenant item should be purged")
+	_, err = cache.New[User]("test").WithEncryptionKey(encryptionKey).ForTenant("globex").Get("me")
+	suite.Assert().ErrorIs(err, errors.NotFound)
+}
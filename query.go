@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// QueryCache caches the results of SQL queries
+//
+// The results are stored under a key made of the normalized SQL text and the hash of its arguments,
+// and depend on the tables the query reads, so they can be invalidated when a table changes.
+//
+// When the cache is persistent, the results are stored as JSON: values of a map[string]any
+// come back as JSON types (e.g. numbers are float64), use structs to keep the types.
+type QueryCache[T any] struct {
+	Cache *Cache[[]T]
+	TTL   time.Duration
+}
+
+// NewQueryCache creates a new QueryCache whose results expire after ttl
+func NewQueryCache[T any](name string, ttl time.Duration, option ...CacheOption) *QueryCache[T] {
+	return &QueryCache[T]{
+		Cache: New[[]T](name, option...).WithExplicitKeysOnly(),
+		TTL:   ttl,
+	}
+}
+
+// Query gets the cached results of the query, or runs it and caches its results
+//
+// tables are the tables the query reads, they are given to Invalidate when they change.
+func (queries *QueryCache[T]) Query(ctx context.Context, tables []string, query string, args []any, run func(context.Context) ([]T, error)) ([]T, error) {
+	key := queryKey(query, args)
+	dependencies := make([]string, 0, len(tables))
+	for _, table := range tables {
+		dependencies = append(dependencies, tableKey(table))
+	}
+	results, err := queries.Cache.GetOrCompute(ctx, key, run, ComputeExpiration(queries.TTL), ComputeDependencies(dependencies...))
+	if err != nil {
+		return nil, err
+	}
+	return *results, nil
+}
+
+// Invalidate removes the cached results of the queries that read the given tables
+func (queries *QueryCache[T]) Invalidate(tables ...string) error {
+	for _, table := range tables {
+		if err := queries.Cache.Delete(tableKey(table)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ScanRows reads all the rows in maps of column names to values, and closes the rows
+func ScanRows(rows *sql.Rows) (results []map[string]any, err error) {
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	results = []map[string]any{}
+	for rows.Next() {
+		values := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err = rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		result := make(map[string]any, len(columns))
+		for i, column := range columns {
+			if bytes, ok := values[i].([]byte); ok {
+				result[column] = string(bytes)
+			} else {
+				result[column] = values[i]
+			}
+		}
+		results = append(results, result)
+	}
+	return results, rows.Err()
+}
+
+// queryKey gets the key of a query from its normalized text and its arguments
+func queryKey(query string, args []any) string {
+	hash := sha256.New()
+	_, _ = hash.Write([]byte(normalizeQuery(query)))
+	_, _ = hash.Write([]byte{0})
+	if data, err := json.Marshal(args); err == nil {
+		_, _ = hash.Write(data)
+	} else {
+		_, _ = fmt.Fprintf(hash, "%#v", args)
+	}
+	return "sql:" + hex.EncodeToString(hash.Sum(nil))
+}
+
+// normalizeQuery collapses the whitespace of a query and removes its final semicolon
+//
+// The case is kept, as it matters in string literals.
+func normalizeQuery(query string) string {
+	return strings.TrimSpace(strings.TrimSuffix(strings.Join(strings.Fields(query), " "), ";"))
+}
+
+// tableKey gets the key the results of the queries reading a table depend on
+func tableKey(table string) string {
+	return "sql-table:" + strings.ToLower(table)
+}
@@ -0,0 +1,65 @@
+package cache_test
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/gildas/go-cache"
+)
+
+type Order struct {
+	ID     int    `json:"id"`
+	Status string `json:"status"`
+}
+
+func (suite *CacheSuite) TestCanCacheQueryResults() {
+	var runs atomic.Int32
+	queries := cache.NewQueryCache[Order]("test", time.Minute)
+	run := func(ctx context.Context) ([]Order, error) {
+		runs.Add(1)
+		return []Order{{ID: 1, Status: "open"}}, nil
+	}
+
+	orders, err := queries.Query(context.Background(), []string{"orders"}, "SELECT * FROM orders WHERE status = ?", []any{"open"}, run)
+	suite.Require().NoError(err, "Failed to query: %+v", err)
+	suite.Assert().Equal([]Order{{ID: 1, Status: "open"}}, orders)
+	_, err = queries.Query(context.Background(), []string{"orders"}, "  SELECT *\n FROM orders\tWHERE status = ?;", []any{"open"}, run)
+	suite.Require().NoError(err, "Failed to query: %+v", err)
+	suite.Assert().Equal(int32(1), runs.Load(), "The normalized query should be cached")
+
+	_, err = queries.Query(context.Background(), []string{"orders"}, "SELECT * FROM orders WHERE status = ?", []any{"closed"}, run)
+	suite.Require().NoError(err, "Failed to query: %+v", err)
+	suite.Assert().Equal(int32(2), runs.Load(), "Other arguments should not be cached")
+
+	err = queries.Invalidate("Orders")
+	suite.Require().NoError(err, "Failed to invalidate: %+v", err)
+	_, err = queries.Query(context.Background(), []string{"orders"}, "SELECT * FROM orders WHERE status = ?", []any{"open"}, run)
+	suite.Require().NoError(err, "Failed to query: %+v", err)
+	suite.Assert().Equal(int32(3), runs.Load(), "The query should run again after the table was invalidated")
+}
+
+func (suite *CacheSuite) TestCanInvalidateQueryResultsComputedAfterTheCallerLeft() {
+	var runs atomic.Int32
+	release := make(chan struct{})
+	queries := cache.NewQueryCache[Order]("test", time.Minute)
+	run := func(ctx context.Context) ([]Order, error) {
+		if runs.Add(1) == 1 {
+			<-release
+		}
+		return []Order{{ID: 1, Status: "open"}}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := queries.Query(ctx, []string{"orders"}, "SELECT * FROM orders", nil, run)
+	suite.Require().ErrorIs(err, context.DeadlineExceeded)
+	close(release)
+	suite.Require().Eventually(func() bool { return queries.Cache.Len() > 0 }, time.Second, time.Millisecond, "The results should be cached")
+
+	err = queries.Invalidate("orders")
+	suite.Require().NoError(err, "Failed to invalidate: %+v", err)
+	_, err = queries.Query(context.Background(), []string{"orders"}, "SELECT * FROM orders", nil, run)
+	suite.Require().NoError(err, "Failed to query: %+v", err)
+	suite.Assert().Equal(int32(2), runs.Load(), "The query should run again after the table was invalidated")
+}
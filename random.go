@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	mathrand "math/rand/v2"
+	"sync"
+)
+
+// lockedReader serializes the reads of a source of random bytes
+type lockedReader struct {
+	mutex  sync.Mutex
+	source io.Reader
+}
+
+// Read reads random bytes from the source
+//
+// implements io.Reader
+func (reader *lockedReader) Read(buffer []byte) (int, error) {
+	reader.mutex.Lock()
+	defer reader.mutex.Unlock()
+	return reader.source.Read(buffer)
+}
+
+// WithRandom sets the source of the random bytes of the cache, for reproducible tests
+//
+// The nonces, the salts, and the key of WithMemoryEncryption are read from the source,
+// and Samples picks the items in the order of their keys. With a seeded source, like SeededRandom,
+// the persisted files are the same from one run to the next, so they can be compared with golden files.
+//
+// This is meant for tests only: a predictable source makes the encryption predictable too.
+// WithRandom must be called before WithMemoryEncryption. A nil source restores crypto/rand.
+func (cache *Cache[T]) WithRandom(source io.Reader) *Cache[T] {
+	return cache.configure(func(config *settings) {
+		config.random = nil
+		if source != nil {
+			config.random = &lockedReader{source: source}
+		}
+	})
+}
+
+// SeededRandom gets a source of random bytes that always gives the same bytes for the same seed, see WithRandom
+func SeededRandom(seed uint64) io.Reader {
+	var key [32]byte
+	binary.LittleEndian.PutUint64(key[:], seed)
+	return mathrand.NewChaCha8(key)
+}
+
+// randomReader gets the source of the random bytes of the cache
+func (config *settings) randomReader() io.Reader {
+	if config.random != nil {
+		return config.random
+	}
+	return rand.Reader
+}
+
+// deterministic tells if the cache must behave the same from one run to the next, see WithRandom
+func (config *settings) deterministic() bool {
+	return config.random != nil
+}
@@ -0,0 +1,39 @@
+package cache_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gildas/go-cache"
+	"github.com/google/uuid"
+)
+
+func (suite *CacheSuite) TestCanPersistReproducibleFilesWithSeededRandom() {
+	persist := func(seed uint64) []byte {
+		users := cache.New[User]("test").WithRandom(cache.SeededRandom(seed)).WithEncryptionKey([]byte("@v3ry#S3cr3tK3y!")).WithExpiration(0)
+		defer func() { _ = users.Clear() }()
+		suite.Require().NoError(users.Set(User{ID: uuid.Nil, Name: "Joe"}, "joe"))
+		folder, _ := os.UserCacheDir()
+		data, err := os.ReadFile(filepath.Join(folder, "test", uuid.NewSHA1(uuid.Nil, []byte("joe")).String()))
+		suite.Require().NoError(err, "Failed to read persisted file: %+v", err)
+		return data
+	}
+	suite.Assert().Equal(persist(42), persist(42), "The same seed should persist the same file")
+	suite.Assert().NotEqual(persist(42), persist(43), "Another seed should persist another file")
+}
+
+func (suite *CacheSuite) TestCanSampleDeterministicallyWithSeededRandom() {
+	items := cache.New[string]("test").WithRandom(cache.SeededRandom(42)).WithMemoryEncryption()
+	for i := 9; i >= 0; i-- {
+		_ = items.Set(fmt.Sprintf("value-%d", i), fmt.Sprintf("key-%d", i))
+	}
+	samples := items.Samples(3)
+	suite.Require().Len(samples, 3)
+	for i, sample := range samples {
+		suite.Assert().Contains(string(sample), fmt.Sprintf(`"Key":"key-%d"`, i))
+	}
+	value, err := items.Get("key-5")
+	suite.Require().NoError(err, "Failed to get item: %+v", err)
+	suite.Assert().Equal("value-5", *value)
+}
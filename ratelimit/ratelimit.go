@@ -0,0 +1,153 @@
+// Package ratelimit limits the rate of events per key, with its state stored in a Cache
+//
+// With a persistent cache, the limits survive restarts of the application:
+//
+//	buckets := cache.New[ratelimit.Bucket]("ratelimit", cache.CacheOptionPersistent)
+//	limiter := ratelimit.NewTokenBucket(buckets, 10, 20) // 10 requests per second, bursts of 20
+//	if allowed, _ := limiter.Allow(clientIP); !allowed {
+//	  http.Error(writer, "Too Many Requests", http.StatusTooManyRequests)
+//	}
+//
+// The limiters serialize their calls, so the limits are exact between the callers
+// of the same limiter. Several processes sharing a cache folder only get approximate limits.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+// Limiter tells if an event is allowed for a key
+type Limiter interface {
+	Allow(key string) (bool, error)
+	AllowN(key string, n int) (bool, error)
+}
+
+// Bucket is the state of a key in a TokenBucket
+type Bucket struct {
+	Tokens  float64   `json:"tokens"`
+	Updated time.Time `json:"updated"`
+}
+
+// TokenBucket allows bursts of events, and a steady rate of events after that
+//
+// Each key has a bucket of Burst tokens, refilled at Rate tokens per second.
+// An event takes a token from the bucket of its key, and is denied when the bucket is empty.
+// Full buckets are not kept in the cache.
+type TokenBucket struct {
+	Cache *cache.Cache[Bucket]
+	Rate  float64
+	Burst int
+	mutex sync.Mutex
+}
+
+// NewTokenBucket creates a new TokenBucket that stores its buckets in the given cache
+func NewTokenBucket(cache *cache.Cache[Bucket], rate float64, burst int) *TokenBucket {
+	return &TokenBucket{Cache: cache, Rate: rate, Burst: burst}
+}
+
+// Allow tells if an event is allowed for the given key, and counts it if it is
+//
+// implements Limiter
+func (limiter *TokenBucket) Allow(key string) (bool, error) {
+	return limiter.AllowN(key, 1)
+}
+
+// AllowN tells if n events are allowed for the given key, and counts them if they are
+//
+// n must be positive, otherwise an errors.ArgumentInvalid is returned.
+//
+// implements Limiter
+func (limiter *TokenBucket) AllowN(key string, n int) (bool, error) {
+	if n <= 0 {
+		return false, errors.ArgumentInvalid.With("n", n)
+	}
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	now := time.Now()
+	bucket, found := limiter.Cache.GetValue(key)
+	if !found {
+		bucket = Bucket{Tokens: float64(limiter.Burst), Updated: now}
+	}
+	bucket.Tokens = math.Min(float64(limiter.Burst), bucket.Tokens+now.Sub(bucket.Updated).Seconds()*limiter.Rate)
+	bucket.Updated = now
+	if bucket.Tokens < float64(n) {
+		return false, nil
+	}
+	bucket.Tokens -= float64(n)
+	return true, limiter.Cache.SetWithExpiration(bucket, limiter.refill(bucket), key)
+}
+
+// refill gets the time the bucket takes to be full again
+func (limiter *TokenBucket) refill(bucket Bucket) time.Duration {
+	if limiter.Rate <= 0 {
+		return 0
+	}
+	return time.Duration((float64(limiter.Burst) - bucket.Tokens) / limiter.Rate * float64(time.Second))
+}
+
+// Window is the state of a key in a SlidingWindow
+type Window struct {
+	Start    time.Time `json:"start"`
+	Previous int       `json:"previous"`
+	Current  int       `json:"current"`
+}
+
+// SlidingWindow allows Limit events per Window for each key
+//
+// The events of a key are counted per window, the count of the sliding window is
+// the count of the current window plus the part of the count of the previous window
+// that is still in the sliding window.
+type SlidingWindow struct {
+	Cache  *cache.Cache[Window]
+	Limit  int
+	Window time.Duration
+	mutex  sync.Mutex
+}
+
+// NewSlidingWindow creates a new SlidingWindow that stores its counters in the given cache
+func NewSlidingWindow(cache *cache.Cache[Window], limit int, window time.Duration) *SlidingWindow {
+	return &SlidingWindow{Cache: cache, Limit: limit, Window: window}
+}
+
+// Allow tells if an event is allowed for the given key, and counts it if it is
+//
+// implements Limiter
+func (limiter *SlidingWindow) Allow(key string) (bool, error) {
+	return limiter.AllowN(key, 1)
+}
+
+// AllowN tells if n events are allowed for the given key, and counts them if they are
+//
+// n must be positive, otherwise an errors.ArgumentInvalid is returned.
+//
+// implements Limiter
+func (limiter *SlidingWindow) AllowN(key string, n int) (bool, error) {
+	if n <= 0 {
+		return false, errors.ArgumentInvalid.With("n", n)
+	}
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	now := time.Now()
+	start := now.Truncate(limiter.Window)
+	window, _ := limiter.Cache.GetValue(key)
+	switch {
+	case window.Start.Equal(start):
+	case window.Start.Add(limiter.Window).Equal(start):
+		window = Window{Start: start, Previous: window.Current}
+	default:
+		window = Window{Start: start}
+	}
+	weight := 1 - float64(now.Sub(start))/float64(limiter.Window)
+	if float64(window.Previous)*weight+float64(window.Current+n) > float64(limiter.Limit) {
+		return false, nil
+	}
+	window.Current += n
+	return true, limiter.Cache.SetWithExpiration(window, start.Add(2*limiter.Window).Sub(now), key)
+}
@@ -0,0 +1,76 @@
+package ratelimit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-cache/ratelimit"
+	"github.com/gildas/go-errors"
+)
+
+type RateLimitSuite struct {
+	suite.Suite
+}
+
+func TestRateLimitSuite(t *testing.T) {
+	suite.Run(t, new(RateLimitSuite))
+}
+
+func (suite *RateLimitSuite) TestCanLimitWithTokenBucket() {
+	buckets := cache.New[ratelimit.Bucket]("test-ratelimit", cache.CacheOptionPersistent)
+	defer func() { _ = buckets.Clear() }()
+	limiter := ratelimit.NewTokenBucket(buckets, 10, 3)
+
+	for i := range 3 {
+		allowed, err := limiter.Allow("client")
+		suite.Require().NoError(err, "Failed to check event %d: %+v", i, err)
+		suite.Assert().True(allowed, "Event %d should be allowed by the burst", i)
+	}
+	allowed, _ := limiter.Allow("client")
+	suite.Assert().False(allowed, "The bucket should be empty")
+	allowed, _ = limiter.Allow("other")
+	suite.Assert().True(allowed, "Each key should have its own bucket")
+
+	restarted := ratelimit.NewTokenBucket(cache.New[ratelimit.Bucket]("test-ratelimit", cache.CacheOptionPersistent), 10, 3)
+	allowed, _ = restarted.Allow("client")
+	suite.Assert().False(allowed, "The bucket should survive a restart")
+
+	time.Sleep(110 * time.Millisecond)
+	allowed, _ = restarted.Allow("client")
+	suite.Assert().True(allowed, "The bucket should be refilled")
+}
+
+func (suite *RateLimitSuite) TestCanLimitWithSlidingWindow() {
+	windows := cache.New[ratelimit.Window]("test-ratelimit", cache.CacheOptionPersistent)
+	defer func() { _ = windows.Clear() }()
+	limiter := ratelimit.NewSlidingWindow(windows, 2, time.Second)
+
+	allowed, err := limiter.AllowN("client", 2)
+	suite.Require().NoError(err, "Failed to check events: %+v", err)
+	suite.Assert().True(allowed)
+	allowed, _ = limiter.Allow("client")
+	suite.Assert().False(allowed, "The limit should be reached")
+
+	restarted := ratelimit.NewSlidingWindow(cache.New[ratelimit.Window]("test-ratelimit", cache.CacheOptionPersistent), 2, time.Second)
+	allowed, _ = restarted.Allow("client")
+	suite.Assert().False(allowed, "The counters should survive a restart")
+	allowed, _ = restarted.Allow("other")
+	suite.Assert().True(allowed, "Each key should have its own counters")
+}
+
+func (suite *RateLimitSuite) TestShouldRejectNonPositiveEventCounts() {
+	limiters := []ratelimit.Limiter{
+		ratelimit.NewTokenBucket(cache.New[ratelimit.Bucket]("test-ratelimit"), 10, 3),
+		ratelimit.NewSlidingWindow(cache.New[ratelimit.Window]("test-ratelimit"), 3, time.Second),
+	}
+	for _, limiter := range limiters {
+		for _, n := range []int{0, -1} {
+			allowed, err := limiter.AllowN("client", n)
+			suite.Assert().ErrorIs(err, errors.ArgumentInvalid, "%T should reject %d events", limiter, n)
+			suite.Assert().False(allowed)
+		}
+	}
+}
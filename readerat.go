@@ -0,0 +1,240 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gildas/go-errors"
+)
+
+// BlobReaderAt gives random access to a blob
+type BlobReaderAt interface {
+	io.ReaderAt
+	io.Closer
+	// Size gets the size of the blob in bytes
+	Size() int64
+}
+
+// WithMemoryMap maps the blobs of at least threshold bytes in memory when they are read with GetReaderAt
+//
+// Mapped blobs are read by the operating system as they are accessed instead of being copied in the heap.
+// Only blobs that are not encrypted are mapped, and only on the platforms that support it.
+//
+// A threshold of 0 never maps the blobs.
+func (cache *Cache[T]) WithMemoryMap(threshold int64) *Cache[T] {
+	return cache.configure(func(config *settings) {
+		config.mmapThreshold = max(threshold, 0)
+	})
+}
+
+// GetReaderAt gets a random access reader on the blob stored under the given key with SetReader
+//
+// The caller must close the reader. If the blob is not found, is incomplete, or has expired,
+// an errors.NotFound is returned.
+func (cache *Cache[T]) GetReaderAt(key string) (BlobReaderAt, error) {
+	config := cache.settings()
+	if !config.persistent {
+		return nil, ErrNotPersistent.With(cache.Name)
+	}
+	folder := filepath.Join(config.folder, blobsFolder)
+	reader, manifest, err := config.openBlobAt(filepath.Join(folder, config.filename(key)), config.blobData(config.filename(key), 0))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, errors.NotFound.With("key", key)
+	} else if err != nil {
+		return nil, err
+	}
+	if manifest == nil {
+		return reader, nil
+	}
+	if !manifest.Complete {
+		return nil, errors.NotFound.With("key", key)
+	}
+	chunks := &chunksReaderAt{chunkSize: manifest.ChunkSize, size: manifest.Size}
+	for index := 0; index < manifest.Chunks; index++ {
+		chunk, _, err := config.openBlobAt(chunkFilename(chunksFolder(folder, config.filename(key)), index), config.blobData(config.filename(key), index))
+		if err != nil {
+			_ = chunks.Close()
+			return nil, err
+		}
+		chunks.chunks = append(chunks.chunks, chunk)
+	}
+	return chunks, nil
+}
+
+// openBlobAt opens the blob file with the given name for random access, its segments are bound to additional if it is encrypted
+//
+// If the file is the manifest of a chunked blob, the manifest is returned instead of a reader.
+// Expired blobs are removed and os.ErrNotExist is returned.
+func (config *settings) openBlobAt(filename string, additional []byte) (reader BlobReaderAt, manifest *blobManifest, err error) {
+	var file *os.File
+	var info os.FileInfo
+
+	if file, err = os.Open(filename); err != nil {
+		return
+	}
+	header := make([]byte, len(blobMagic)+8)
+	if _, err = io.ReadFull(file, header); err == nil && bytes.Equal(header[:len(manifestMagic)], manifestMagic) {
+		_ = file.Close()
+		_, manifest, err = config.openBlob(filename, additional)
+		return nil, manifest, err
+	}
+	if err != nil || !isBlob(header[:len(blobMagic)]) {
+		_ = file.Close()
+		return nil, nil, errors.ArgumentInvalid.With("blob", filepath.Base(filename))
+	}
+	if expiration := binary.BigEndian.Uint64(header[len(blobMagic):]); expiration > 0 && time.Now().UnixNano() > int64(expiration) {
+		_ = file.Close()
+		_ = os.Remove(filename)
+		return nil, nil, os.ErrNotExist
+	}
+	if info, err = file.Stat(); err != nil {
+		_ = file.Close()
+		return
+	}
+	offset := int64(len(header))
+	if config.encryptionKey == nil {
+		size := info.Size() - offset
+		if config.mmapThreshold > 0 && size >= config.mmapThreshold {
+			if reader, err = mmapFile(file, offset, size); err == nil {
+				_ = file.Close()
+				return
+			}
+		}
+		return &fileReaderAt{SectionReader: io.NewSectionReader(file, offset, size), file: file}, nil, nil
+	}
+	gcm, prefix, bound, size, err := config.openSegments(file, header[:len(blobMagic)], filepath.Base(filename), additional)
+	if err != nil {
+		_ = file.Close()
+		return nil, nil, err
+	}
+	offset += int64(size)
+	sealedSegmentSize := int64(blobSegmentSize + gcm.Overhead())
+	sealed := info.Size() - offset
+	segments := (sealed + sealedSegmentSize - 1) / sealedSegmentSize
+	return &segmentReaderAt{
+		file:       file,
+		gcm:        gcm,
+		prefix:     prefix,
+		additional: bound,
+		offset:     offset,
+		segments:   segments,
+		size:       sealed - segments*int64(gcm.Overhead()),
+	}, nil, nil
+}
+
+// fileReaderAt reads a blob that is not encrypted from its file
+type fileReaderAt struct {
+	*io.SectionReader
+	file *os.File
+}
+
+// Close closes the blob file
+//
+// implements io.Closer
+func (reader *fileReaderAt) Close() error {
+	return reader.file.Close()
+}
+
+// segmentReaderAt reads an encrypted blob, decrypting only the segments that are read
+type segmentReaderAt struct {
+	file       *os.File
+	gcm        cipher.AEAD
+	prefix     []byte
+	additional []byte
+	offset     int64
+	segments   int64
+	size       int64
+}
+
+// ReadAt reads the decrypted content of the blob at the given offset
+//
+// implements io.ReaderAt
+func (reader *segmentReaderAt) ReadAt(data []byte, offset int64) (count int, err error) {
+	if offset < 0 {
+		return 0, errors.ArgumentInvalid.With("offset", offset)
+	}
+	sealedSegmentSize := int64(blobSegmentSize + reader.gcm.Overhead())
+	sealed := make([]byte, sealedSegmentSize)
+	for count < len(data) {
+		if offset >= reader.size {
+			return count, io.EOF
+		}
+		index := offset / blobSegmentSize
+		size, err := reader.file.ReadAt(sealed, reader.offset+index*sealedSegmentSize)
+		if err != nil && err != io.EOF {
+			return count, err
+		}
+		plain, err := reader.gcm.Open(sealed[:0], segmentNonce(reader.prefix, uint32(index)), sealed[:size], segmentData(reader.additional, index == reader.segments-1))
+		if err != nil {
+			return count, err
+		}
+		copied := copy(data[count:], plain[offset%blobSegmentSize:])
+		count += copied
+		offset += int64(copied)
+	}
+	return count, nil
+}
+
+// Size gets the size of the decrypted blob
+func (reader *segmentReaderAt) Size() int64 {
+	return reader.size
+}
+
+// Close closes the blob file
+//
+// implements io.Closer
+func (reader *segmentReaderAt) Close() error {
+	return reader.file.Close()
+}
+
+// chunksReaderAt reads a chunked blob, each chunk with its own reader
+type chunksReaderAt struct {
+	chunks    []BlobReaderAt
+	chunkSize int64
+	size      int64
+}
+
+// ReadAt reads the chunks at the given offset
+//
+// implements io.ReaderAt
+func (reader *chunksReaderAt) ReadAt(data []byte, offset int64) (count int, err error) {
+	if offset < 0 {
+		return 0, errors.ArgumentInvalid.With("offset", offset)
+	}
+	for count < len(data) {
+		if offset >= reader.size {
+			return count, io.EOF
+		}
+		chunk := reader.chunks[offset/reader.chunkSize]
+		want := min(int64(len(data)-count), reader.chunkSize-offset%reader.chunkSize)
+		size, err := chunk.ReadAt(data[count:int64(count)+want], offset%reader.chunkSize)
+		count += size
+		offset += int64(size)
+		if err != nil && (err != io.EOF || size == 0) {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// Size gets the size of the blob
+func (reader *chunksReaderAt) Size() int64 {
+	return reader.size
+}
+
+// Close closes the chunks
+//
+// implements io.Closer
+func (reader *chunksReaderAt) Close() (err error) {
+	for _, chunk := range reader.chunks {
+		if closeErr := chunk.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return
+}
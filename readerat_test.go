@@ -0,0 +1,47 @@
+package cache_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+func (suite *CacheSuite) TestCanReadBlobsAt() {
+	payload := make([]byte, 150*1024+17)
+	_, _ = rand.Read(payload)
+	blobs := []*cache.Cache[string]{
+		cache.New[string]("test", cache.CacheOptionPersistent),
+		cache.New[string]("test", cache.CacheOptionPersistent).WithMemoryMap(1024),
+		cache.New[string]("test").WithEncryptionKey([]byte("@v3ry#S3cr3tK3y!")),
+		cache.New[string]("test", cache.CacheOptionPersistent).WithChunkSize(50 * 1024).WithMemoryMap(1024),
+		cache.New[string]("test").WithEncryptionKey([]byte("@v3ry#S3cr3tK3y!")).WithChunkSize(50 * 1024),
+	}
+	for index, blob := range blobs {
+		err := blob.SetReader("model", bytes.NewReader(payload), 0)
+		suite.Require().NoError(err, "Failed to set blob with cache #%d: %+v", index, err)
+		reader, err := blob.GetReaderAt("model")
+		suite.Require().NoError(err, "Failed to get blob with cache #%d: %+v", index, err)
+		suite.Assert().Equal(int64(len(payload)), reader.Size(), "Wrong size with cache #%d", index)
+
+		// Across segments and chunks
+		data := make([]byte, 70*1024)
+		count, err := reader.ReadAt(data, 40*1024)
+		suite.Require().NoError(err, "Failed to read blob with cache #%d: %+v", index, err)
+		suite.Assert().Equal(len(data), count)
+		suite.Assert().Equal(payload[40*1024:110*1024], data, "Wrong data with cache #%d", index)
+
+		// At the end
+		count, err = reader.ReadAt(data, int64(len(payload)-10))
+		suite.Assert().ErrorIs(err, io.EOF)
+		suite.Assert().Equal(10, count)
+		suite.Assert().Equal(payload[len(payload)-10:], data[:10], "Wrong data with cache #%d", index)
+		suite.Require().NoError(reader.Close())
+		_ = blob.Clear()
+	}
+
+	_, err := blobs[0].GetReaderAt("unknown")
+	suite.Assert().ErrorIs(err, errors.NotFound)
+}
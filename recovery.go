@@ -0,0 +1,192 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+
+	"github.com/gildas/go-errors"
+)
+
+// frameMagic starts every persisted item, followed by the format version
+//
+// The header is the magic, the length of the payload (4 bytes, big endian), its CRC-32 (4 bytes, big endian),
+// and the metadata of the item in clear (17 bytes, see frameMetadata). The CRC-32 covers the metadata and the payload.
+var frameMagic = []byte("GCIT\x03")
+
+// flaglessFrameMagic starts the items persisted before the flags were added to the metadata
+var flaglessFrameMagic = []byte("GCIT\x02")
+
+// legacyFrameMagic starts the items persisted before the metadata was added to the header
+var legacyFrameMagic = []byte("GCIT\x01")
+
+const (
+	// frameHeaderSize is the size of the header of a persisted item
+	frameHeaderSize = 30
+	// flaglessFrameHeaderSize is the size of the header of the items persisted without flags
+	flaglessFrameHeaderSize = 29
+	// legacyFrameHeaderSize is the size of the header of the items persisted without metadata
+	legacyFrameHeaderSize = 13
+)
+
+const (
+	// frameImmutable is the flag of the immutable items, see SetImmutable
+	frameImmutable byte = 1 << 0
+	// frameSet is the flag of the multi-value entries, see Add
+	frameSet byte = 1 << 1
+)
+
+// frameMetadata is the metadata of a persisted item kept in clear in its header, see Has
+//
+// It is not authenticated by WithSigningKey, it is only used to tell if an item exists without reading it.
+type frameMetadata struct {
+	expiration uint64
+	generation uint64
+	immutable  bool
+	set        bool // the file holds the records of a multi-value entry, its expiration and generation are zero
+}
+
+// live tells if the item is of the given generation and has not expired at now
+func (metadata frameMetadata) live(generation uint64, now int64) bool {
+	return metadata.generation == generation && (metadata.expiration == 0 || now <= int64(metadata.expiration))
+}
+
+// metadataOf gets the frame metadata of a persisted value, the other values than records have none
+func metadataOf(value any) frameMetadata {
+	if described, ok := value.(interface{ metadata() frameMetadata }); ok {
+		return described.metadata()
+	}
+	return frameMetadata{}
+}
+
+// quarantineFolder is the subfolder of the cache folder where the corrupted files are moved, see WithQuarantine
+const quarantineFolder = "quarantine"
+
+// errCorruptedFile tells a persisted file is truncated, was partially written, or does not match its checksum
+var errCorruptedFile = errors.New("persisted file is corrupted")
+
+// corruptedFile is the error of restore when a persisted file is corrupted
+//
+// It is an ErrCorrupted, and an os.ErrNotExist so the item is treated as missing and computed or fetched again.
+type corruptedFile struct {
+	error
+}
+
+// Is tells if the target is os.ErrNotExist
+func (err corruptedFile) Is(target error) bool {
+	return target == os.ErrNotExist
+}
+
+// Unwrap gets the ErrCorrupted
+func (err corruptedFile) Unwrap() error {
+	return err.error
+}
+
+// WithQuarantine moves the corrupted files to the quarantine subfolder of the cache folder instead of removing them
+//
+// A persisted file is corrupted when it is truncated, was partially written, e.g. after a crash, or does not match its checksum.
+// Corrupted files are found when they are read, they are then treated as missing,
+// counted in Stats.CorruptedFiles, and reported to the error handler (see WithErrorHandler).
+func (cache *Cache[T]) WithQuarantine(enabled bool) *Cache[T] {
+	return cache.configure(func(config *settings) {
+		config.quarantine = enabled
+	})
+}
+
+// frame adds the header of a persisted item to data
+func frame(data []byte, metadata frameMetadata) []byte {
+	framed := make([]byte, 0, frameHeaderSize+len(data))
+	framed = append(framed, frameMagic...)
+	framed = binary.BigEndian.AppendUint32(framed, uint32(len(data)))
+	framed = binary.BigEndian.AppendUint32(framed, 0)
+	framed = binary.BigEndian.AppendUint64(framed, metadata.expiration)
+	framed = binary.BigEndian.AppendUint64(framed, metadata.generation)
+	var flags byte
+	if metadata.immutable {
+		flags |= frameImmutable
+	}
+	if metadata.set {
+		flags |= frameSet
+	}
+	framed = append(framed, flags)
+	framed = append(framed, data...)
+	binary.BigEndian.PutUint32(framed[9:13], crc32.ChecksumIEEE(framed[legacyFrameHeaderSize:]))
+	return framed
+}
+
+// unframe checks the header of a persisted item and removes it
+//
+// Files persisted before the header was introduced are returned as is.
+func unframe(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, frameMagic[:4]) {
+		return data, nil
+	}
+	size := frameHeaderSize
+	if bytes.HasPrefix(data, legacyFrameMagic) {
+		size = legacyFrameHeaderSize
+	} else if bytes.HasPrefix(data, flaglessFrameMagic) {
+		size = flaglessFrameHeaderSize
+	} else if !bytes.HasPrefix(data, frameMagic) {
+		return nil, errCorruptedFile
+	}
+	if len(data) < size {
+		return nil, errCorruptedFile
+	}
+	length := binary.BigEndian.Uint32(data[5:9])
+	payload := data[size:]
+	if uint64(len(payload)) != uint64(length) || crc32.ChecksumIEEE(data[legacyFrameHeaderSize:]) != binary.BigEndian.Uint32(data[9:13]) {
+		return nil, errCorruptedFile
+	}
+	return payload, nil
+}
+
+// unframeMetadata gets the metadata from the header of a persisted item
+//
+// It returns false if the item was persisted without metadata, or without flags.
+func unframeMetadata(header []byte) (metadata frameMetadata, ok bool) {
+	if len(header) < frameHeaderSize || !bytes.HasPrefix(header, frameMagic) {
+		return metadata, false
+	}
+	metadata.expiration = binary.BigEndian.Uint64(header[13:21])
+	metadata.generation = binary.BigEndian.Uint64(header[21:29])
+	metadata.immutable = header[29]&frameImmutable != 0
+	metadata.set = header[29]&frameSet != 0
+	return metadata, true
+}
+
+// corrupted tells if err means a persisted file is corrupted
+//
+// Besides a wrong header, files persisted without a header are corrupted when they are not valid JSON.
+func corrupted(err error) bool {
+	var syntaxError *json.SyntaxError
+	return errors.Is(err, errCorruptedFile) || errors.As(err, &syntaxError)
+}
+
+// recoverFile removes or quarantines the corrupted file named filekey
+//
+// It returns an ErrCorrupted that is also an os.ErrNotExist, so the item is treated as missing.
+func (config *settings) recoverFile(filekey string, cause error) error {
+	var err error
+
+	failure := ErrCorrupted.With(filekey).(errors.Error)
+	cause = failure.Wrap(cause)
+	path := filepath.Join(config.folder, filekey)
+	if config.quarantine {
+		if err = os.MkdirAll(filepath.Join(config.folder, quarantineFolder), 0700); err == nil {
+			err = os.Rename(path, filepath.Join(config.folder, quarantineFolder, filepath.Base(filekey)))
+		}
+	} else {
+		err = os.Remove(path)
+	}
+	if config.corrupted != nil {
+		config.corrupted.Add(1)
+	}
+	if err != nil {
+		cause = errors.Join(cause, err)
+	}
+	config.reportError(OperationRestore, filekey, cause)
+	return corruptedFile{cause}
+}
@@ -0,0 +1,99 @@
+package cache_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+func (suite *CacheSuite) truncate(key string) (filename string) {
+	folder, _ := os.UserCacheDir()
+	filename = filepath.Join(folder, "test", uuid.NewSHA1(uuid.Nil, []byte(key)).String())
+	data, err := os.ReadFile(filename)
+	suite.Require().NoError(err, "Failed to read persisted file: %+v", err)
+	err = os.WriteFile(filename, data[:len(data)-5], 0600)
+	suite.Require().NoError(err, "Failed to truncate persisted file: %+v", err)
+	return
+}
+
+func (suite *CacheSuite) TestCanRecoverFromTruncatedFile() {
+	var reported string
+	items := cache.New[string]("test", cache.CacheOptionPersistent)
+	defer func() { _ = items.Clear() }()
+	_ = items.Set("value", "key")
+	filename := suite.truncate("key")
+
+	reader := cache.New[string]("test", cache.CacheOptionPersistent).WithErrorHandler(func(operation, key string, err error) {
+		reported = operation
+	})
+	_, err := reader.Get("key")
+	suite.Require().ErrorIs(err, errors.NotFound, "A truncated file should be a miss")
+	suite.Assert().Equal(uint64(1), reader.Stats().CorruptedFiles)
+	suite.Assert().Equal(cache.OperationRestore, reported)
+	_, err = os.Stat(filename)
+	suite.Assert().ErrorIs(err, os.ErrNotExist, "The truncated file should be removed")
+
+	err = reader.Set("other", "key")
+	suite.Require().NoError(err, "Failed to set item: %+v", err)
+	value, err := cache.New[string]("test", cache.CacheOptionPersistent).Get("key")
+	suite.Require().NoError(err, "Failed to get item: %+v", err)
+	suite.Assert().Equal("other", *value)
+}
+
+func (suite *CacheSuite) TestCanQuarantineTruncatedFile() {
+	items := cache.New[string]("test", cache.CacheOptionPersistent)
+	defer func() { _ = items.Clear() }()
+	_ = items.Set("value", "key")
+	filename := suite.truncate("key")
+
+	_, err := cache.New[string]("test", cache.CacheOptionPersistent).WithQuarantine(true).Get("key")
+	suite.Require().ErrorIs(err, errors.NotFound, "A truncated file should be a miss")
+	_, err = os.Stat(filepath.Join(filepath.Dir(filename), "quarantine", filepath.Base(filename)))
+	suite.Assert().NoError(err, "The truncated file should be quarantined")
+}
+
+func (suite *CacheSuite) TestCanReadFilesWithoutHeader() {
+	items := cache.New[string]("test", cache.CacheOptionPersistent)
+	defer func() { _ = items.Clear() }()
+	folder, _ := os.UserCacheDir()
+	_ = os.MkdirAll(filepath.Join(folder, "test"), 0700)
+	err := os.WriteFile(filepath.Join(folder, "test", uuid.NewSHA1(uuid.Nil, []byte("key")).String()), []byte(`{"Key":"key","Item":"legacy","Expiration":0}`), 0600)
+	suite.Require().NoError(err, "Failed to write legacy file: %+v", err)
+
+	value, err := items.Get("key")
+	suite.Require().NoError(err, "Failed to get item: %+v", err)
+	suite.Assert().Equal("legacy", *value)
+}
+
+func (suite *CacheSuite) TestShouldDetectBitRot() {
+	var reported error
+	items := cache.New[User]("test", cache.CacheOptionPersistent).WithEncryptionKey([]byte("@v3ry#S3cr3tK3y!"))
+	defer func() { _ = items.Clear() }()
+	user := User{ID: uuid.New(), Name: "Joe"}
+	_ = items.Set(user, "key")
+	folder, _ := os.UserCacheDir()
+	filename := filepath.Join(folder, "test", uuid.NewSHA1(uuid.Nil, []byte("key")).String())
+	data, err := os.ReadFile(filename)
+	suite.Require().NoError(err, "Failed to read persisted file: %+v", err)
+	data[len(data)/2] ^= 0x01
+	suite.Require().NoError(os.WriteFile(filename, data, 0600))
+
+	reader := cache.New[User]("test", cache.CacheOptionPersistent).WithEncryptionKey([]byte("@v3ry#S3cr3tK3y!")).WithErrorHandler(func(operation, key string, err error) {
+		reported = err
+	})
+	_, err = reader.Get("key")
+	suite.Assert().ErrorIs(err, errors.NotFound, "A corrupted file should be a miss")
+	suite.Assert().ErrorIs(err, cache.ErrCorrupted, "A corrupted file should be reported as such")
+	suite.Assert().ErrorIs(reported, cache.ErrCorrupted, "The error handler should get an ErrCorrupted")
+
+	computed, err := reader.GetOrCompute(context.Background(), "key", func(ctx context.Context) (User, error) {
+		return user, nil
+	})
+	suite.Require().NoError(err, "A corrupted item should be computed again: %+v", err)
+	suite.Assert().Equal(user, *computed)
+}
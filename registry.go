@@ -0,0 +1,38 @@
+package cache
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+// defaultExpirations contains the default expiration per cached type
+var defaultExpirations sync.Map
+
+// RegisterExpiration registers the default expiration for caches of type T
+//
+// Caches created with New[T] after this call will use this expiration
+// unless WithExpiration is called on them.
+//
+// Example:
+//
+//	cache.RegisterExpiration[User](10 * time.Minute)
+//	users := cache.New[User]("users") // expires entries after 10 minutes
+func RegisterExpiration[T any](expiration time.Duration) {
+	defaultExpirations.Store(reflect.TypeFor[T](), expiration)
+}
+
+// UnregisterExpiration removes the default expiration for caches of type T
+func UnregisterExpiration[T any]() {
+	defaultExpirations.Delete(reflect.TypeFor[T]())
+}
+
+// RegisteredExpiration gets the default expiration registered for caches of type T
+//
+// If no expiration was registered, it returns 0 and false.
+func RegisteredExpiration[T any]() (time.Duration, bool) {
+	if value, found := defaultExpirations.Load(reflect.TypeFor[T]()); found {
+		return value.(time.Duration), true
+	}
+	return 0, false
+}
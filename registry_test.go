@@ -0,0 +1,59 @@
+package cache_test
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gildas/go-cache"
+)
+
+type Session struct {
+	ID uuid.UUID `json:"id"`
+}
+
+// GetID gets the ID of the Session
+//
+// implements core.Identifiable
+func (session Session) GetID() uuid.UUID {
+	return session.ID
+}
+
+func (suite *CacheSuite) TestCanRegisterDefaultExpiration() {
+	cache.RegisterExpiration[Session](250 * time.Millisecond)
+	defer cache.UnregisterExpiration[Session]()
+
+	expiration, found := cache.RegisteredExpiration[Session]()
+	suite.Require().True(found, "The default expiration should be registered")
+	suite.Assert().Equal(250*time.Millisecond, expiration)
+
+	sessions := cache.New[Session]("test")
+	defer func() { _ = sessions.Clear() }()
+	session := Session{ID: uuid.New()}
+	err := sessions.Set(session)
+	suite.Require().NoError(err, "Failed to set cached session: %+v", err)
+
+	time.Sleep(500 * time.Millisecond)
+	cached, err := sessions.Get(session.GetID().String())
+	suite.Require().Error(err, "Getting a cached session that should have expired did not fail")
+	suite.Require().Nil(cached, "Cached Session is not nil")
+}
+
+func (suite *CacheSuite) TestCanOverrideRegisteredExpiration() {
+	cache.RegisterExpiration[Session](250 * time.Millisecond)
+	defer cache.UnregisterExpiration[Session]()
+
+	sessions := cache.New[Session]("test").WithExpiration(0)
+	defer func() { _ = sessions.Clear() }()
+	session := Session{ID: uuid.New()}
+	err := sessions.Set(session)
+	suite.Require().NoError(err, "Failed to set cached session: %+v", err)
+
+	time.Sleep(500 * time.Millisecond)
+	cached, err := sessions.Get(session.GetID().String())
+	suite.Require().NoError(err, "Failed to get cached session: %+v", err)
+	suite.Assert().Equal(session, *cached, "Session and Cached Session are different")
+
+	_, found := cache.RegisteredExpiration[User]()
+	suite.Assert().False(found, "No default expiration should be registered for User")
+}
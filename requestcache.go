@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"context"
+)
+
+type contextCacheKey[T any] struct{}
+
+// WithContextCache gets a context that carries the given cache
+//
+// The cache is retrieved with FromContext, one cache per item type.
+func WithContextCache[T any](ctx context.Context, cache *Cache[T]) context.Context {
+	return context.WithValue(ctx, contextCacheKey[T]{}, cache)
+}
+
+// FromContext gets the cache of items of type T stored in the context by WithContextCache or NewRequestCache
+func FromContext[T any](ctx context.Context) (*Cache[T], bool) {
+	cache, ok := ctx.Value(contextCacheKey[T]{}).(*Cache[T])
+	return cache, ok && cache != nil
+}
+
+// NewRequestCache creates a non-persistent cache that lives as long as the given context
+//
+// The returned context carries the cache (see FromContext). When the context is done,
+// the cache is closed and its items are released.
+//
+// It is meant for memoization within a request:
+//
+//	ctx, users := cache.NewRequestCache[User](r.Context(), "users")
+func NewRequestCache[T any](ctx context.Context, name string) (context.Context, *Cache[T]) {
+	cache := New[T](name)
+	context.AfterFunc(ctx, cache.dispose)
+	return WithContextCache(ctx, cache), cache
+}
+
+// dispose closes the cache and releases its items in memory
+func (cache *Cache[T]) dispose() {
+	_ = cache.Close()
+	cache.write(func(storage *store[T]) { storage.clear() })
+}
@@ -0,0 +1,44 @@
+package cache_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+func (suite *CacheSuite) TestCanStoreCacheInContext() {
+	users := cache.New[User]("test")
+	ctx := cache.WithContextCache(context.Background(), users)
+
+	found, ok := cache.FromContext[User](ctx)
+	suite.Require().True(ok, "The cache should be in the context")
+	suite.Assert().Same(users, found)
+
+	_, ok = cache.FromContext[StringIDUser](ctx)
+	suite.Assert().False(ok, "There should be no cache of another type in the context")
+	_, ok = cache.FromContext[User](context.Background())
+	suite.Assert().False(ok, "There should be no cache in an empty context")
+}
+
+func (suite *CacheSuite) TestShouldDisposeRequestCacheWhenContextIsDone() {
+	parent, cancel := context.WithCancel(context.Background())
+	ctx, users := cache.NewRequestCache[User](parent, "test-request")
+	user := User{ID: uuid.New(), Name: "Joe"}
+	suite.Require().NoError(users.Set(user))
+
+	found, ok := cache.FromContext[User](ctx)
+	suite.Require().True(ok, "The request cache should be in the context")
+	cached, err := found.Get(user.ID.String())
+	suite.Require().NoError(err, "Failed to get user: %+v", err)
+	suite.Assert().Equal(user, *cached)
+
+	cancel()
+	suite.Assert().Eventually(func() bool {
+		_, err := users.Get(user.ID.String())
+		return errors.Is(err, errors.NotFound)
+	}, time.Second, 10*time.Millisecond, "The request cache should be emptied when the context is done")
+}
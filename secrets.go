@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"sync"
+)
+
+// secret is key material that is zeroed when the cache is closed
+//
+// The key is only lent to the functions given to use, so it is not copied
+// into derived values that outlive the call.
+type secret struct {
+	mutex sync.RWMutex
+	key   []byte
+}
+
+// newSecret creates a secret from a copy of the given key
+func newSecret(key []byte) *secret {
+	return &secret{key: append(make([]byte, 0, len(key)), key...)}
+}
+
+// use calls fn with the key, it fails with ErrKeyDestroyed once the secret is destroyed
+//
+// fn must not keep the key after it returns.
+func (material *secret) use(fn func(key []byte) error) error {
+	material.mutex.RLock()
+	defer material.mutex.RUnlock()
+	if material.key == nil {
+		return ErrKeyDestroyed
+	}
+	return fn(material.key)
+}
+
+// gcm creates an AES-GCM cipher with the key
+func (material *secret) gcm() (gcm cipher.AEAD, err error) {
+	err = material.use(func(key []byte) (err error) {
+		gcm, err = newGCM(key)
+		return
+	})
+	return
+}
+
+// derive creates a new secret from an HMAC of label under the key
+func (material *secret) derive(label string) (derived *secret, err error) {
+	err = material.use(func(key []byte) error {
+		mac := hmac.New(sha256.New, key)
+		_, _ = mac.Write([]byte(label))
+		derived = &secret{key: mac.Sum(nil)}
+		return nil
+	})
+	return
+}
+
+// destroy zeroes the key, the secret cannot be used anymore
+func (material *secret) destroy() {
+	if material == nil {
+		return
+	}
+	material.mutex.Lock()
+	defer material.mutex.Unlock()
+	clear(material.key)
+	material.key = nil
+}
+
+// destroySecrets zeroes the keys of the cache, see Close
+func (config *settings) destroySecrets() {
+	config.encryptionKey.destroy()
+	config.filenameKey.destroy()
+	config.signingKey.destroy()
+	if config.sealer != nil {
+		config.sealer.key.destroy()
+	}
+}
@@ -0,0 +1,144 @@
+// Package sessionstore provides a gorilla/sessions Store backed by a Cache
+//
+// With an encrypted persistent cache, small web applications get encrypted,
+// expiring, disk-backed sessions:
+//
+//	sessions := cache.New[sessionstore.Record]("sessions", cache.CacheOptionPersistent).WithEncryptionKey(key)
+//	store := sessionstore.New(sessions, hashKey)
+package sessionstore
+
+import (
+	"bytes"
+	"encoding/base32"
+	"encoding/gob"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+	"github.com/gorilla/securecookie"
+	"github.com/gorilla/sessions"
+)
+
+// Record is a session stored in the cache
+//
+// Its values are encoded with encoding/gob, like gorilla/sessions does,
+// so their types must be registered with gob.Register.
+type Record struct {
+	ID     string `json:"id"`
+	Values []byte `json:"values"`
+}
+
+// Store is a gorilla/sessions Store that keeps the sessions in a Cache
+//
+// The cookie only contains the session ID, signed (and encrypted if asked) with securecookie.
+// The session expires in the cache when its cookie expires.
+// Sessions whose MaxAge is 0 (browser sessions) do not expire in the cache.
+//
+// Use MaxAge to change Options.MaxAge, so the codecs reject the cookies older than MaxAge too.
+type Store struct {
+	Cache   *cache.Cache[Record]
+	Codecs  []securecookie.Codec
+	Options *sessions.Options
+}
+
+// New creates a new Store with the given cache
+//
+// The keyPairs are given to securecookie.CodecsFromPairs to sign and encrypt the session ID in the cookie.
+func New(cache *cache.Cache[Record], keyPairs ...[]byte) *Store {
+	store := &Store{
+		Cache:  cache,
+		Codecs: securecookie.CodecsFromPairs(keyPairs...),
+		Options: &sessions.Options{
+			Path:     "/",
+			HttpOnly: true,
+		},
+	}
+	store.MaxAge(86400 * 30)
+	return store
+}
+
+// MaxAge sets the maximum age of the sessions, in seconds, in the options and in the codecs of the store
+//
+// The codecs then reject the cookies older than age. An age of 0 does not limit the age of the cookies,
+// a negative age deletes the sessions when they are saved.
+func (store *Store) MaxAge(age int) {
+	store.Options.MaxAge = age
+	for _, codec := range store.Codecs {
+		if secure, ok := codec.(*securecookie.SecureCookie); ok {
+			secure.MaxAge(age)
+		}
+	}
+}
+
+// Get gets a session from the request's registry
+//
+// implements sessions.Store
+func (store *Store) Get(request *http.Request, name string) (*sessions.Session, error) {
+	return sessions.GetRegistry(request).Get(store, name)
+}
+
+// New creates a session, loading its values from the cache if the request has its cookie
+//
+// implements sessions.Store
+func (store *Store) New(request *http.Request, name string) (*sessions.Session, error) {
+	session := sessions.NewSession(store, name)
+	options := *store.Options
+	session.Options = &options
+	session.IsNew = true
+
+	cookie, err := request.Cookie(name)
+	if err != nil {
+		return session, nil
+	}
+	if err = securecookie.DecodeMulti(name, cookie.Value, &session.ID, store.Codecs...); err != nil {
+		return session, err
+	}
+	record, err := store.Cache.Get(session.ID)
+	if errors.Is(err, errors.NotFound) {
+		return session, nil
+	} else if err != nil {
+		return session, err
+	}
+	if err = gob.NewDecoder(bytes.NewReader(record.Values)).Decode(&session.Values); err != nil {
+		return session, err
+	}
+	session.IsNew = false
+	return session, nil
+}
+
+// Save stores the session in the cache and writes its cookie
+//
+// A session with a negative MaxAge is deleted from the cache and its cookie is removed.
+//
+// implements sessions.Store
+func (store *Store) Save(request *http.Request, writer http.ResponseWriter, session *sessions.Session) error {
+	if session.Options.MaxAge < 0 {
+		if len(session.ID) > 0 {
+			if err := store.Cache.Delete(session.ID); err != nil {
+				return err
+			}
+		}
+		http.SetCookie(writer, sessions.NewCookie(session.Name(), "", session.Options))
+		return nil
+	}
+	if len(session.ID) == 0 {
+		session.ID = strings.TrimRight(base32.StdEncoding.EncodeToString(securecookie.GenerateRandomKey(32)), "=")
+	}
+	var values bytes.Buffer
+
+	if err := gob.NewEncoder(&values).Encode(session.Values); err != nil {
+		return err
+	}
+	record := Record{ID: session.ID, Values: values.Bytes()}
+	if err := store.Cache.SetWithExpiration(record, time.Duration(session.Options.MaxAge)*time.Second, session.ID); err != nil {
+		return err
+	}
+	encoded, err := securecookie.EncodeMulti(session.Name(), session.ID, store.Codecs...)
+	if err != nil {
+		return err
+	}
+	http.SetCookie(writer, sessions.NewCookie(session.Name(), encoded, session.Options))
+	return nil
+}
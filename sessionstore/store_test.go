@@ -0,0 +1,82 @@
+package sessionstore_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-cache/sessionstore"
+)
+
+type StoreSuite struct {
+	suite.Suite
+}
+
+func TestStoreSuite(t *testing.T) {
+	suite.Run(t, new(StoreSuite))
+}
+
+func (suite *StoreSuite) TestCanSaveAndLoadSession() {
+	store := sessionstore.New(cache.New[sessionstore.Record]("test-sessions"), []byte("0123456789abcdef0123456789abcdef"))
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	session, err := store.Get(request, "session")
+	suite.Require().NoError(err, "Failed to get session: %+v", err)
+	suite.Assert().True(session.IsNew)
+	session.Values["user"] = "joe"
+	recorder := httptest.NewRecorder()
+	err = session.Save(request, recorder)
+	suite.Require().NoError(err, "Failed to save session: %+v", err)
+	cookies := recorder.Result().Cookies()
+	suite.Require().Len(cookies, 1)
+
+	request = httptest.NewRequest(http.MethodGet, "/", nil)
+	request.AddCookie(cookies[0])
+	session, err = store.Get(request, "session")
+	suite.Require().NoError(err, "Failed to get session: %+v", err)
+	suite.Assert().False(session.IsNew)
+	suite.Assert().Equal("joe", session.Values["user"])
+
+	// Deleting the session
+	session.Options.MaxAge = -1
+	err = session.Save(request, httptest.NewRecorder())
+	suite.Require().NoError(err, "Failed to delete session: %+v", err)
+	request = httptest.NewRequest(http.MethodGet, "/", nil)
+	request.AddCookie(cookies[0])
+	session, err = store.Get(request, "session")
+	suite.Require().NoError(err, "Failed to get session: %+v", err)
+	suite.Assert().True(session.IsNew, "The session should have been deleted")
+}
+
+func (suite *StoreSuite) TestShouldRejectForgedCookie() {
+	store := sessionstore.New(cache.New[sessionstore.Record]("test-sessions"), []byte("0123456789abcdef0123456789abcdef"))
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.AddCookie(&http.Cookie{Name: "session", Value: "forged"})
+	session, err := store.Get(request, "session")
+	suite.Assert().Error(err, "A forged cookie should be rejected")
+	suite.Assert().True(session.IsNew)
+}
+
+func (suite *StoreSuite) TestShouldRejectCookieOlderThanMaxAge() {
+	store := sessionstore.New(cache.New[sessionstore.Record]("test-sessions"), []byte("0123456789abcdef0123456789abcdef"))
+	store.MaxAge(1)
+	suite.Assert().Equal(1, store.Options.MaxAge)
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	session, err := store.Get(request, "session")
+	suite.Require().NoError(err, "Failed to get session: %+v", err)
+	recorder := httptest.NewRecorder()
+	suite.Require().NoError(session.Save(request, recorder))
+	cookies := recorder.Result().Cookies()
+	suite.Require().Len(cookies, 1)
+
+	time.Sleep(2100 * time.Millisecond)
+	request = httptest.NewRequest(http.MethodGet, "/", nil)
+	request.AddCookie(cookies[0])
+	_, err = store.Get(request, "session")
+	suite.Assert().Error(err, "A cookie older than MaxAge should be rejected")
+}
@@ -0,0 +1,169 @@
+package cache
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gildas/go-errors"
+	"github.com/google/uuid"
+)
+
+// set contains the items of a multi-value entry
+type set[T interface{}] struct {
+	mutex   sync.Mutex
+	loaded  bool
+	removed bool // the entry was emptied and forgotten, see forget
+	records []record[T]
+}
+
+// setRecords are the records of a multi-value entry as they are persisted
+type setRecords[T interface{}] []record[T]
+
+// metadata gets the metadata of the records kept in clear in the header of their file
+func (records setRecords[T]) metadata() frameMetadata {
+	return frameMetadata{set: true}
+}
+
+// setNamespace is used to compute the filekeys of multi-value entries
+//
+// so they never collide with the filekeys of single-value entries.
+var setNamespace = uuid.NewSHA1(uuid.Nil, []byte("go-cache:set"))
+
+// setFilekey gets the name of the file that persists the multi-value entry of the given key
+func setFilekey(key string) string {
+	return uuid.NewSHA1(setNamespace, []byte(key)).String()
+}
+
+// Add adds an item to the multi-value entry stored under the given key
+//
+// Multi-value entries are independent of the entries stored with Set.
+//
+// Each item of the entry has its own expiration,
+// taken from the item if it implements Expirable or Expiring.
+func (cache *Cache[T]) Add(key string, item T) error {
+	return cache.AddWithExpiration(key, item, expirationOf(item, cache.settings().expiration))
+}
+
+// AddWithExpiration adds an item to the multi-value entry stored under the given key with a custom expiration
+func (cache *Cache[T]) AddWithExpiration(key string, item T, expiration time.Duration) (err error) {
+	var r record[T]
+
+	if len(key) == 0 {
+		return emptyKey()
+	}
+	if expiration == 0 {
+		r = record[T]{Key: key, Item: item} // The Record does not expire
+	} else {
+		r = record[T]{Key: key, Item: item, Expiration: uint64(time.Now().Add(expiration).UnixNano())}
+	}
+
+	config := cache.settings()
+	if err = config.validate(item); err != nil {
+		return
+	}
+	r.Version = config.schemaVersion
+	r.Generation = cache.generation(config)
+	entry := cache.lockSetOf(key)
+	defer entry.mutex.Unlock()
+	if err = entry.load(config, key); err != nil {
+		return
+	}
+	entry.records = append(entry.purge(r.Generation), r)
+	if config.persistent {
+		return config.persist(setFilekey(key), setRecords[T](entry.records))
+	}
+	return
+}
+
+// GetAll gets all the items of the multi-value entry stored under the given key
+//
+// Expired items are not returned. If there are no items left, an errors.NotFound is returned.
+func (cache *Cache[T]) GetAll(key string) (items []T, err error) {
+	config := cache.settings()
+	if _, found := cache.sets.Load(key); !found && !config.persisted(setFilekey(key)) {
+		return nil, errors.NotFound.With("key", key)
+	}
+	entry := cache.lockSetOf(key)
+	defer entry.mutex.Unlock()
+	if err = entry.load(config, key); err != nil {
+		return nil, err
+	}
+	records := entry.purge(cache.generation(config))
+	if len(records) != len(entry.records) {
+		entry.records = records
+		if config.persistent {
+			if len(records) == 0 {
+				_ = config.erase(setFilekey(key))
+			} else if err = config.persist(setFilekey(key), setRecords[T](records)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(records) == 0 {
+		cache.forget(key, entry)
+		return nil, errors.NotFound.With("key", key)
+	}
+	items = make([]T, 0, len(records))
+	for _, record := range records {
+		items = append(items, record.Item)
+	}
+	return items, nil
+}
+
+// lockSetOf gets the multi-value entry stored under the given key with its mutex held, creating it if needed
+//
+// The entries that were forgotten while waiting for their mutex are skipped, see forget.
+func (cache *Cache[T]) lockSetOf(key string) *set[T] {
+	for {
+		value, _ := cache.sets.LoadOrStore(key, &set[T]{})
+		entry := value.(*set[T])
+		entry.mutex.Lock()
+		if !entry.removed {
+			return entry
+		}
+		entry.mutex.Unlock()
+	}
+}
+
+// forget removes an empty multi-value entry, so the keys without items do not accumulate entries
+//
+// The caller must hold the entry's mutex, and have removed its file if the cache is persistent.
+func (cache *Cache[T]) forget(key string, entry *set[T]) {
+	entry.removed = true
+	cache.sets.CompareAndDelete(key, entry)
+}
+
+// load loads the records of the entry from the disk the first time it is used
+//
+// The caller must hold the entry's mutex.
+func (entry *set[T]) load(config *settings, key string) error {
+	if entry.loaded {
+		return nil
+	}
+	if config.persistent {
+		var records []record[T]
+
+		if err := config.restore(setFilekey(key), &records); err == nil {
+			entry.records = append(records, entry.records...)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	entry.loaded = true
+	return nil
+}
+
+// purge gets the records of the entry that are not expired and belong to the given generation
+//
+// The caller must hold the entry's mutex.
+func (entry *set[T]) purge(generation uint64) []record[T] {
+	records := make([]record[T], 0, len(entry.records))
+	now := time.Now().UnixNano()
+	for _, record := range entry.records {
+		if record.metadata().live(generation, now) {
+			records = append(records, record)
+		}
+	}
+	return records
+}
@@ -0,0 +1,73 @@
+package cache_test
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+func (suite *CacheSuite) TestCanAddMultipleItemsToKey() {
+	cache := cache.New[Session]("test")
+	defer func() { _ = cache.Clear() }()
+	sessions := []Session{{ID: uuid.New()}, {ID: uuid.New()}, {ID: uuid.New()}}
+	for _, session := range sessions {
+		err := cache.Add("joe", session)
+		suite.Require().NoError(err, "Failed to add cached session: %+v", err)
+	}
+
+	cached, err := cache.GetAll("joe")
+	suite.Require().NoError(err, "Failed to get cached sessions: %+v", err)
+	suite.Assert().Equal(sessions, cached, "Sessions and Cached Sessions are different")
+
+	_, err = cache.Get("joe")
+	suite.Assert().ErrorIs(err, errors.NotFound, "Multi-value entries should not be visible with Get")
+}
+
+func (suite *CacheSuite) TestCanAddMultipleItemsWithExpiration() {
+	cache := cache.New[Session]("test")
+	defer func() { _ = cache.Clear() }()
+	short := Session{ID: uuid.New()}
+	long := Session{ID: uuid.New()}
+	_ = cache.AddWithExpiration("joe", short, 250*time.Millisecond)
+	_ = cache.AddWithExpiration("joe", long, 5*time.Second)
+
+	cached, err := cache.GetAll("joe")
+	suite.Require().NoError(err, "Failed to get cached sessions: %+v", err)
+	suite.Assert().Len(cached, 2)
+
+	time.Sleep(500 * time.Millisecond)
+	cached, err = cache.GetAll("joe")
+	suite.Require().NoError(err, "Failed to get cached sessions: %+v", err)
+	suite.Assert().Equal([]Session{long}, cached)
+}
+
+func (suite *CacheSuite) TestCanAddMultipleItemsWithPersistence() {
+	firstCache := cache.New[Session]("test", cache.CacheOptionPersistent)
+	defer func() { _ = firstCache.Clear() }()
+	sessions := []Session{{ID: uuid.New()}, {ID: uuid.New()}}
+	for _, session := range sessions {
+		err := firstCache.Add("joe", session)
+		suite.Require().NoError(err, "Failed to add cached session: %+v", err)
+	}
+
+	secondCache := cache.New[Session]("test", cache.CacheOptionPersistent)
+	cached, err := secondCache.GetAll("joe")
+	suite.Require().NoError(err, "Failed to get cached sessions: %+v", err)
+	suite.Assert().Equal(sessions, cached, "Sessions and Cached Sessions are different")
+}
+
+func (suite *CacheSuite) TestShouldFailToGetAllUnknownKey() {
+	cache := cache.New[Session]("test")
+	defer func() { _ = cache.Clear() }()
+
+	cached, err := cache.GetAll("unknown")
+	suite.Require().Error(err, "Getting all items of an unknown key should fail")
+	suite.Assert().Nil(cached)
+	suite.Assert().ErrorIs(err, errors.NotFound)
+
+	err = cache.Add("", Session{ID: uuid.New()})
+	suite.Assert().ErrorIs(err, errors.ArgumentMissing)
+}
@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"path/filepath"
+)
+
+// signedMagic starts the records signed by sign
+var signedMagic = []byte("GCSG\x01")
+
+// WithSigningKey signs the persisted items with an HMAC-SHA256 under the given key
+//
+// This is meant for deployments that need tamper-evidence but not confidentiality, the items are persisted in clear.
+// The signature also covers the cache name and the key of the item, so a file copied to another cache or key is rejected too.
+// The files that are modified, or that are not signed, are rejected with an ErrTampered and are left on the disk.
+// The files that do not match their checksum are still treated as corrupted, see WithQuarantine to keep them.
+// When the cache is also encrypted, the encrypted item is signed.
+//
+// Close destroys the signing key, see Close.
+func (cache *Cache[T]) WithSigningKey(key []byte) *Cache[T] {
+	return cache.configure(func(config *settings) {
+		config.signingKey = newSecret(key)
+	})
+}
+
+// sign prepends the signature of the record of filekey to data
+func (config *settings) sign(filekey string, data []byte) (signed []byte, err error) {
+	err = config.signingKey.use(func(key []byte) error {
+		signature := config.signature(key, filekey, data)
+		signed = make([]byte, 0, len(signedMagic)+len(signature)+len(data))
+		signed = append(append(append(signed, signedMagic...), signature...), data...)
+		return nil
+	})
+	return
+}
+
+// verify checks the signature of the record of filekey and gets its data
+func (config *settings) verify(filekey string, signed []byte) (data []byte, err error) {
+	err = config.signingKey.use(func(key []byte) error {
+		if !bytes.HasPrefix(signed, signedMagic) || len(signed) < len(signedMagic)+sha256.Size {
+			return ErrTampered.With(filepath.Base(filekey))
+		}
+		signature, payload := signed[len(signedMagic):len(signedMagic)+sha256.Size], signed[len(signedMagic)+sha256.Size:]
+		if !hmac.Equal(signature, config.signature(key, filekey, payload)) {
+			return ErrTampered.With(filepath.Base(filekey))
+		}
+		data = payload
+		return nil
+	})
+	return
+}
+
+// signature computes the HMAC of the record of filekey
+func (config *settings) signature(key []byte, filekey string, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write(config.additionalData(filekey))
+	_, _ = mac.Write(data)
+	return mac.Sum(nil)
+}
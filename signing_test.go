@@ -0,0 +1,59 @@
+package cache_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+
+	"github.com/gildas/go-cache"
+	"github.com/google/uuid"
+)
+
+func (suite *CacheSuite) TestCanSignPersistedItems() {
+	signingKey := []byte("@v3ry#S1gn1ngK3y")
+	users := cache.New[User]("test", cache.CacheOptionPersistent).WithSigningKey(signingKey)
+	defer func() { _ = users.Clear() }()
+	suite.Require().NoError(users.Set(User{ID: uuid.New(), Name: "Joe"}, "joe"))
+
+	folder, _ := os.UserCacheDir()
+	data, err := os.ReadFile(filepath.Join(folder, "test", uuid.NewSHA1(uuid.Nil, []byte("joe")).String()))
+	suite.Require().NoError(err, "Failed to read persisted file: %+v", err)
+	suite.Assert().Contains(string(data), "Joe", "Signed items should not be encrypted")
+
+	value, err := cache.New[User]("test", cache.CacheOptionPersistent).WithSigningKey(signingKey).Get("joe")
+	suite.Require().NoError(err, "Failed to get item: %+v", err)
+	suite.Assert().Equal("Joe", value.Name)
+
+	_, err = cache.New[User]("test", cache.CacheOptionPersistent).WithSigningKey([]byte("another key")).Get("joe")
+	suite.Assert().ErrorIs(err, cache.ErrTampered, "Items signed with another key should be rejected")
+}
+
+func (suite *CacheSuite) TestShouldRejectTamperedItems() {
+	signingKey := []byte("@v3ry#S1gn1ngK3y")
+	users := cache.New[User]("test", cache.CacheOptionPersistent).WithSigningKey(signingKey)
+	defer func() { _ = users.Clear() }()
+	suite.Require().NoError(users.Set(User{ID: uuid.New(), Name: "Joe"}, "joe"))
+	suite.Require().NoError(users.Set(User{ID: uuid.New(), Name: "Admin"}, "admin"))
+	folder, _ := os.UserCacheDir()
+	filename := filepath.Join(folder, "test", uuid.NewSHA1(uuid.Nil, []byte("joe")).String())
+
+	data, err := os.ReadFile(filename)
+	suite.Require().NoError(err, "Failed to read persisted file: %+v", err)
+	data = bytes.Replace(data, []byte("Joe"), []byte("Bob"), 1)
+	binary.BigEndian.PutUint32(data[9:13], crc32.ChecksumIEEE(data[13:]))
+	suite.Require().NoError(os.WriteFile(filename, data, 0600))
+	_, err = cache.New[User]("test", cache.CacheOptionPersistent).WithSigningKey(signingKey).Get("joe")
+	suite.Assert().ErrorIs(err, cache.ErrTampered, "Modified items should be rejected")
+
+	admin, err := os.ReadFile(filepath.Join(folder, "test", uuid.NewSHA1(uuid.Nil, []byte("admin")).String()))
+	suite.Require().NoError(err, "Failed to read persisted file: %+v", err)
+	suite.Require().NoError(os.WriteFile(filename, admin, 0600))
+	_, err = cache.New[User]("test", cache.CacheOptionPersistent).WithSigningKey(signingKey).Get("joe")
+	suite.Assert().ErrorIs(err, cache.ErrTampered, "Items copied from another key should be rejected")
+
+	suite.Require().NoError(os.WriteFile(filename, []byte(`{"Key":"joe","Item":{"name":"Bob"},"Expiration":0}`), 0600))
+	_, err = cache.New[User]("test", cache.CacheOptionPersistent).WithSigningKey(signingKey).Get("joe")
+	suite.Assert().ErrorIs(err, cache.ErrTampered, "Unsigned items should be rejected")
+}
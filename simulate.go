@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gildas/go-errors"
+)
+
+// SimulationResult is the outcome of replaying a trace against an eviction policy and a capacity
+type SimulationResult struct {
+	Policy   string  `json:"policy"`
+	Capacity int     `json:"capacity"`
+	Hits     uint64  `json:"hits"`
+	Misses   uint64  `json:"misses"`
+	HitRatio float64 `json:"hitRatio"`
+}
+
+// Simulate replays a trace recorded with WithAccessTrace against eviction policies and capacities
+//
+// Policies are given by name: "lru", "clock", "slru" (with a protected segment of 80%), or "slru:<ratio>" (e.g. "slru:0.5").
+// Each miss on a read is considered to be loaded in the cache, like GetOrCompute would.
+//
+// The results are returned per policy, then per capacity.
+func Simulate(trace io.Reader, capacities []int, policies ...string) ([]SimulationResult, error) {
+	events := []TraceEvent{}
+	reader := NewTraceReader(trace)
+	for {
+		event, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	results := make([]SimulationResult, 0, len(policies)*len(capacities))
+	for _, name := range policies {
+		for _, capacity := range capacities {
+			policy, err := simulationPolicy(name, capacity)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, simulate(events, name, capacity, policy))
+		}
+	}
+	return results, nil
+}
+
+// simulationPolicy creates the eviction policy with the given name
+func simulationPolicy(name string, capacity int) (policy, error) {
+	kind, parameter, _ := strings.Cut(strings.ToLower(name), ":")
+	switch kind {
+	case "lru":
+		return newLRU(), nil
+	case "clock":
+		return newClock(), nil
+	case "slru":
+		ratio := 0.8
+		if len(parameter) > 0 {
+			var err error
+			if ratio, err = strconv.ParseFloat(parameter, 64); err != nil || ratio < 0 || ratio > 1 {
+				return nil, errors.ArgumentInvalid.With("policy", name)
+			}
+		}
+		return newSLRU(int(float64(capacity) * ratio)), nil
+	default:
+		return nil, errors.ArgumentInvalid.With("policy", name)
+	}
+}
+
+// simulate replays the events against the policy
+func simulate(events []TraceEvent, name string, capacity int, policy policy) SimulationResult {
+	result := SimulationResult{Policy: name, Capacity: capacity}
+	cached := map[uint64]bool{}
+	for _, event := range events {
+		key := strconv.FormatUint(event.KeyHash, 16)
+		if event.Operation == TraceGet {
+			if cached[event.KeyHash] {
+				result.Hits++
+				policy.touch(key)
+				continue
+			}
+			result.Misses++
+		}
+		cached[event.KeyHash] = true
+		policy.add(key)
+		for policy.len() > capacity {
+			victim, ok := policy.victim()
+			if !ok {
+				break
+			}
+			evicted, _ := strconv.ParseUint(victim, 16, 64)
+			delete(cached, evicted)
+		}
+	}
+	if total := result.Hits + result.Misses; total > 0 {
+		result.HitRatio = float64(result.Hits) / float64(total)
+	}
+	return result
+}
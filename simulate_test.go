@@ -0,0 +1,42 @@
+package cache_test
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+func (suite *CacheSuite) TestCanSimulatePolicies() {
+	var trace bytes.Buffer
+	users := cache.New[User]("test").WithAccessTrace(&trace, 1)
+	defer func() { _ = users.Clear() }()
+
+	// 4 hot keys read twice per round, and a scan of keys read only once
+	for round := 0; round < 20; round++ {
+		for i := 0; i < 8; i++ {
+			_, _ = users.Get(fmt.Sprintf("hot-%d", i%4))
+		}
+		for i := 0; i < 8; i++ {
+			_, _ = users.Get(fmt.Sprintf("scan-%d-%d", round, i))
+		}
+	}
+
+	results, err := cache.Simulate(&trace, []int{8, 1000}, "lru", "slru:0.5", "clock")
+	suite.Require().NoError(err, "Failed to simulate: %+v", err)
+	suite.Require().Len(results, 6)
+	lru, slru, clock := results[0], results[2], results[4]
+	suite.Assert().Equal("lru", lru.Policy)
+	suite.Assert().Equal(8, lru.Capacity)
+	suite.Assert().Equal(uint64(320), lru.Hits+lru.Misses)
+	suite.Assert().Greater(slru.HitRatio, lru.HitRatio, "SLRU should resist the scan better than LRU")
+	suite.Assert().Equal("clock", clock.Policy)
+	suite.Assert().GreaterOrEqual(clock.HitRatio, lru.HitRatio, "CLOCK should give the hot keys a second chance")
+	suite.Assert().Equal(uint64(156), results[1].Hits, "An unbounded cache only misses the first access of each key")
+}
+
+func (suite *CacheSuite) TestShouldFailToSimulateUnknownPolicy() {
+	_, err := cache.Simulate(&bytes.Buffer{}, []int{10}, "fifo")
+	suite.Assert().ErrorIs(err, errors.ArgumentInvalid)
+}
@@ -0,0 +1,90 @@
+package cache
+
+import "reflect"
+
+// Sizer is implemented by items that know how much memory they use
+//
+// Without it, the size of an item is estimated by walking it with reflection.
+type Sizer interface {
+	CacheSize() int64
+}
+
+// sizeOf gets the size of the given item in bytes
+func sizeOf(item any) int64 {
+	switch sized := item.(type) {
+	case Sizer:
+		return sized.CacheSize()
+	case []byte:
+		return int64(reflect.TypeFor[[]byte]().Size()) + int64(cap(sized))
+	}
+	value := reflect.ValueOf(item)
+	if !value.IsValid() {
+		return 0
+	}
+	return int64(value.Type().Size()) + indirectSize(value, map[uintptr]bool{})
+}
+
+// indirectSize estimates the memory used by the given value outside of its own type size
+//
+// Pointers are followed only once, so shared and cyclic values are counted once.
+func indirectSize(value reflect.Value, visited map[uintptr]bool) (size int64) {
+	switch value.Kind() {
+	case reflect.Pointer:
+		if value.IsNil() || visited[value.Pointer()] {
+			return 0
+		}
+		visited[value.Pointer()] = true
+		return int64(value.Type().Elem().Size()) + indirectSize(value.Elem(), visited)
+	case reflect.Interface:
+		if value.IsNil() {
+			return 0
+		}
+		return int64(value.Elem().Type().Size()) + indirectSize(value.Elem(), visited)
+	case reflect.String:
+		return int64(value.Len())
+	case reflect.Slice:
+		if value.IsNil() || visited[value.Pointer()] {
+			return 0
+		}
+		visited[value.Pointer()] = true
+		size = int64(value.Cap()) * int64(value.Type().Elem().Size())
+		for i := 0; i < value.Len(); i++ {
+			size += indirectSize(value.Index(i), visited)
+		}
+	case reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			size += indirectSize(value.Index(i), visited)
+		}
+	case reflect.Map:
+		if value.IsNil() || visited[value.Pointer()] {
+			return 0
+		}
+		visited[value.Pointer()] = true
+		entrySize := int64(value.Type().Key().Size() + value.Type().Elem().Size())
+		iterator := value.MapRange()
+		for iterator.Next() {
+			size += entrySize + indirectSize(iterator.Key(), visited) + indirectSize(iterator.Value(), visited)
+		}
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			size += indirectSize(value.Field(i), visited)
+		}
+	}
+	return
+}
+
+// WithMaxMemory sets the maximum memory, in bytes, used by the items the cache keeps in memory
+//
+// The size of an item is given by its CacheSize method if it implements Sizer,
+// otherwise it is estimated. When the budget is exceeded, the Least Recently Used keys
+// are evicted, or as decided by the policy given to WithSegmentedLRU.
+// Each shard gets an equal part of the budget.
+//
+// A maxMemory of 0 means the memory is not bounded.
+func (cache *Cache[T]) WithMaxMemory(maxMemory int64) *Cache[T] {
+	cache.configure(func(config *settings) {
+		config.maxMemory = max(maxMemory, 0)
+	})
+	cache.rebuild()
+	return cache
+}
@@ -0,0 +1,38 @@
+package cache_test
+
+import (
+	"fmt"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+type Payload struct {
+	Data []byte `json:"data"`
+}
+
+func (payload Payload) CacheSize() int64 {
+	return int64(len(payload.Data))
+}
+
+func (suite *CacheSuite) TestCanBoundMemory() {
+	blobs := cache.New[Payload]("test").WithShards(1).WithMaxMemory(1000)
+	for i := 0; i < 5; i++ {
+		_ = blobs.Set(Payload{Data: make([]byte, 300)}, fmt.Sprintf("blob-%d", i))
+	}
+	for i := 0; i < 2; i++ {
+		_, err := blobs.Get(fmt.Sprintf("blob-%d", i))
+		suite.Assert().ErrorIs(err, errors.NotFound, "blob-%d should have been evicted", i)
+	}
+	for i := 2; i < 5; i++ {
+		_, err := blobs.Get(fmt.Sprintf("blob-%d", i))
+		suite.Assert().NoError(err, "blob-%d should still be in memory", i)
+	}
+
+	// Replacing an item accounts for its new size
+	_ = blobs.Set(Payload{Data: make([]byte, 700)}, "blob-4")
+	_, err := blobs.Get("blob-2")
+	suite.Assert().ErrorIs(err, errors.NotFound, "blob-2 should have been evicted")
+	_, err = blobs.Get("blob-4")
+	suite.Assert().NoError(err, "blob-4 should still be in memory")
+}
@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"sort"
+)
+
+// Snapshot is an immutable copy of the items of a cache in memory, see ReadSnapshot
+//
+// Reading a Snapshot takes no lock, it can be shared by many goroutines.
+type Snapshot[T interface{}] struct {
+	storage *store[T]
+	version uint64
+	records map[string]record[T]
+}
+
+// ReadSnapshot gets an immutable snapshot of the items of the cache in memory
+//
+// The snapshot is built the first time it is asked after a change of the cache, then it is shared
+// until the next change. This is meant for caches that change rarely but are read very often,
+// like feature flags or configuration: reading a snapshot takes no lock.
+//
+// The snapshot does not see the items that are only on the disk, and the changes made after it was taken.
+func (cache *Cache[T]) ReadSnapshot() *Snapshot[T] {
+	storage := cache.storage()
+	if snapshot := cache.snapshot.Load(); snapshot != nil && snapshot.current(storage) {
+		return snapshot
+	}
+	snapshot := &Snapshot[T]{storage: storage, version: storage.version.Load(), records: map[string]record[T]{}}
+	storage.each(func(key string, entry record[T]) bool {
+		snapshot.records[key] = entry
+		return true
+	})
+	cache.snapshot.Store(snapshot)
+	return snapshot
+}
+
+// current tells if the snapshot has the records of the given store
+func (snapshot *Snapshot[T]) current(storage *store[T]) bool {
+	return snapshot.storage == storage && snapshot.version == storage.version.Load()
+}
+
+// Get gets an item of the snapshot
+//
+// Items that have expired since the snapshot was taken are not found.
+func (snapshot *Snapshot[T]) Get(key string) (item T, found bool) {
+	entry, found := snapshot.records[key]
+	if !found || entry.expired() {
+		return item, false
+	}
+	return entry.Item, true
+}
+
+// Len gets the number of keys in the snapshot, including the keys that have expired since it was taken
+func (snapshot *Snapshot[T]) Len() int {
+	return len(snapshot.records)
+}
+
+// Keys gets the sorted keys of the items of the snapshot that have not expired
+func (snapshot *Snapshot[T]) Keys() []string {
+	keys := make([]string, 0, len(snapshot.records))
+	for key, entry := range snapshot.records {
+		if !entry.expired() {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,40 @@
+package cache_test
+
+import (
+	"time"
+
+	"github.com/gildas/go-cache"
+)
+
+func (suite *CacheSuite) TestCanReadSnapshot() {
+	flags := cache.New[string]("test")
+	_ = flags.Set("on", "feature1")
+	_ = flags.Set("off", "feature2")
+
+	snapshot := flags.ReadSnapshot()
+	value, found := snapshot.Get("feature1")
+	suite.Require().True(found, "feature1 should be in the snapshot")
+	suite.Assert().Equal("on", value)
+	suite.Assert().Equal([]string{"feature1", "feature2"}, snapshot.Keys())
+	suite.Assert().Same(snapshot, flags.ReadSnapshot(), "The snapshot should be shared until the cache changes")
+
+	_ = flags.Set("on", "feature3")
+	_ = flags.Delete("feature1")
+	latest := flags.ReadSnapshot()
+	suite.Assert().NotSame(snapshot, latest, "The snapshot should be rebuilt after the cache changes")
+	suite.Assert().Equal([]string{"feature2", "feature3"}, latest.Keys())
+	_, found = snapshot.Get("feature3")
+	suite.Assert().False(found, "An older snapshot should not see later changes")
+	_, found = snapshot.Get("feature1")
+	suite.Assert().True(found, "An older snapshot should keep deleted items")
+}
+
+func (suite *CacheSuite) TestShouldNotReadExpiredItemsFromSnapshot() {
+	flags := cache.New[string]("test")
+	_ = flags.SetWithExpiration("on", 10*time.Millisecond, "feature")
+	snapshot := flags.ReadSnapshot()
+	time.Sleep(20 * time.Millisecond)
+	_, found := snapshot.Get("feature")
+	suite.Assert().False(found, "An expired item should not be found in the snapshot")
+	suite.Assert().Empty(snapshot.Keys())
+}
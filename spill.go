@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"math"
+	"runtime/metrics"
+	"sync/atomic"
+	"time"
+)
+
+// defaultSpillInterval is the interval at which the memory is checked by WithSpillToDisk
+const defaultSpillInterval = time.Second
+
+// spiller drops the cold items of a persistent cache from memory when the live heap is above a threshold
+type spiller struct {
+	threshold uint64
+	janitor   *janitor
+	cycle     atomic.Uint64
+	measure   func() (live, cycles uint64)
+}
+
+// WithSpillToDisk drops the coldest items from memory when the live heap of the process is above threshold bytes
+//
+// The live heap is read from runtime/metrics every interval, every second if interval is 0 or less.
+// When it is above the threshold, each shard drops the same share of its items as the share of the heap
+// above the threshold, the least recently used first. The items stay on the disk and are loaded again when they are read,
+// so the cache is bounded in memory without losing items. The dropped items are counted in Stats.Spilled.
+//
+// The live heap is measured by the garbage collector, the items are dropped at most once per collection.
+// Only persistent caches drop items. A threshold of 0 stops checking the memory.
+// Call Close to stop checking the memory when the cache is not needed anymore.
+func (cache *Cache[T]) WithSpillToDisk(threshold uint64, interval time.Duration) *Cache[T] {
+	var previous *spiller
+
+	if interval <= 0 {
+		interval = defaultSpillInterval
+	}
+	cache.configure(func(config *settings) {
+		previous = config.spiller
+		config.spiller = nil
+		if threshold > 0 {
+			spiller := &spiller{threshold: threshold, measure: liveHeap}
+			spiller.janitor = newJanitor(interval, func() { cache.spill(spiller) })
+			config.spiller = spiller
+		}
+	})
+	if previous != nil {
+		previous.janitor.close()
+	}
+	cache.rebuild()
+	return cache
+}
+
+// liveHeap gets the live heap measured by the last garbage collection and the number of collections
+func liveHeap() (live, cycles uint64) {
+	samples := []metrics.Sample{{Name: "/gc/heap/live:bytes"}, {Name: "/gc/cycles/total:gc-cycles"}}
+	metrics.Read(samples)
+	if samples[0].Value.Kind() == metrics.KindUint64 {
+		live = samples[0].Value.Uint64()
+	}
+	if samples[1].Value.Kind() == metrics.KindUint64 {
+		cycles = samples[1].Value.Uint64()
+	}
+	return
+}
+
+// spill drops the coldest items from memory if the live heap is above the threshold of the spiller
+func (cache *Cache[T]) spill(spiller *spiller) {
+	config := cache.settings()
+	if !config.persistent {
+		return
+	}
+	live, cycles := spiller.measure()
+	if live <= spiller.threshold || spiller.cycle.Swap(cycles) == cycles {
+		return
+	}
+	share := float64(live-spiller.threshold) / float64(live)
+	storage := cache.storage()
+	var spilled int
+	for _, shard := range storage.shards {
+		spilled += shard.spill(share)
+	}
+	if spilled > 0 {
+		cache.stats.spilled.Add(uint64(spilled))
+	}
+}
+
+// spill drops the given share of the items of the shard, the least recently used first, and returns how many were dropped
+func (shard *shard[T]) spill(share float64) int {
+	shard.mutex.Lock()
+	defer shard.mutex.Unlock()
+	eviction := shard.eviction.Load()
+	if eviction == nil || len(shard.items) == 0 {
+		return 0
+	}
+	before := len(shard.items)
+	target := before - int(math.Ceil(share*float64(before)))
+	shard.evictWhile(eviction, func(count int, size int64) bool { return count > target })
+	return before - len(shard.items)
+}
@@ -0,0 +1,28 @@
+package cache_test
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/gildas/go-cache"
+)
+
+func (suite *CacheSuite) TestCanSpillItemsToDisk() {
+	users := cache.New[Credentials]("test", cache.CacheOptionPersistent).WithSpillToDisk(1, 10*time.Millisecond)
+	defer users.Close()
+	for i := 0; i < 50; i++ {
+		suite.Require().NoError(users.Set(Credentials{Username: fmt.Sprintf("user-%d", i)}, fmt.Sprintf("key-%d", i)))
+	}
+	suite.Require().Eventually(func() bool {
+		runtime.GC()
+		return users.Stats().Spilled > 0
+	}, 2*time.Second, 20*time.Millisecond, "Items should have been dropped from memory")
+	suite.Assert().Less(users.ReadSnapshot().Len(), 50)
+
+	for i := 0; i < 50; i++ {
+		value, err := users.Get(fmt.Sprintf("key-%d", i))
+		suite.Require().NoError(err, "Failed to get key-%d: %+v", i, err)
+		suite.Assert().Equal(fmt.Sprintf("user-%d", i), value.Username)
+	}
+}
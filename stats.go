@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"sync/atomic"
+)
+
+// Stats contains the statistics of a Cache
+type Stats struct {
+	// Hits is the number of reads that found a fresh item
+	Hits uint64 `json:"hits"`
+	// Misses is the number of reads that did not find a fresh item
+	Misses uint64 `json:"misses"`
+	// StaleHits is the number of reads that got an expired item because its computation failed
+	StaleHits uint64 `json:"staleHits"`
+	// DroppedWrites is the number of persisted writes replaced by a later write of the same key (see WithWriteCoalescing and WithWriteBehind)
+	DroppedWrites uint64 `json:"droppedWrites"`
+	// Expired is the number of expired items removed by the janitor (see WithJanitor)
+	Expired uint64 `json:"expired"`
+	// OverflowedWrites is the number of persisted writes dropped because the write-behind queue was full (see WithWriteBehind)
+	OverflowedWrites uint64 `json:"overflowedWrites"`
+	// WriteQueueDepth is the number of writes in the write-behind queue
+	WriteQueueDepth uint64 `json:"writeQueueDepth"`
+	// CorruptedFiles is the number of persisted files found truncated or partially written (see WithQuarantine)
+	CorruptedFiles uint64 `json:"corruptedFiles"`
+	// Spilled is the number of items dropped from memory under memory pressure, they stay on the disk (see WithSpillToDisk)
+	Spilled uint64 `json:"spilled"`
+}
+
+// statistics collects the statistics of a Cache
+type statistics struct {
+	hits             atomic.Uint64
+	misses           atomic.Uint64
+	staleHits        atomic.Uint64
+	droppedWrites    atomic.Uint64
+	expired          atomic.Uint64
+	overflowedWrites atomic.Uint64
+	corrupted        atomic.Uint64
+	spilled          atomic.Uint64
+}
+
+// Stats gets the current statistics of the cache
+func (cache *Cache[T]) Stats() Stats {
+	var depth int
+
+	if queue := cache.settings().writeBehind; queue != nil {
+		depth = queue.depth()
+	}
+	return Stats{
+		Hits:             cache.stats.hits.Load(),
+		Misses:           cache.stats.misses.Load(),
+		StaleHits:        cache.stats.staleHits.Load(),
+		DroppedWrites:    cache.stats.droppedWrites.Load(),
+		Expired:          cache.stats.expired.Load(),
+		OverflowedWrites: cache.stats.overflowedWrites.Load(),
+		WriteQueueDepth:  uint64(depth),
+		CorruptedFiles:   cache.stats.corrupted.Load(),
+		Spilled:          cache.stats.spilled.Load(),
+	}
+}
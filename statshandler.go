@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// StatsReport is the report served by the StatsHandler
+type StatsReport struct {
+	Name    string      `json:"name"`
+	Stats   Stats       `json:"stats"`
+	Config  StatsConfig `json:"config"`
+	TopKeys []KeyHits   `json:"topKeys"`
+}
+
+// StatsConfig is the configuration of a cache in a StatsReport
+//
+// The secrets are redacted: the encryption key is reported only as Encrypted.
+type StatsConfig struct {
+	Expiration       time.Duration `json:"expiration"`
+	Persistent       bool          `json:"persistent"`
+	Folder           string        `json:"folder,omitempty"`
+	Encrypted        bool          `json:"encrypted"`
+	Capacity         int           `json:"capacity,omitempty"`
+	MaxMemory        int64         `json:"maxMemory,omitempty"`
+	Shards           int           `json:"shards"`
+	Durability       string        `json:"durability"`
+	Consistency      string        `json:"consistency"`
+	ExpirationMode   string        `json:"expirationMode"`
+	ExpirationEngine string        `json:"expirationEngine"`
+	MaxStale         time.Duration `json:"maxStale,omitempty"`
+}
+
+// StatsHandler gets an http.Handler that serves the statistics, the configuration, and the top keys of the cache in JSON
+//
+// The top keys are counted only if WithTopKeys was called. The handler is meant to be mounted
+// in an internal route of a service, e.g. /internal/cache/{name}.
+func (cache *Cache[T]) StatsHandler() http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodGet && request.Method != http.MethodHead {
+			writer.Header().Set("Allow", "GET, HEAD")
+			http.Error(writer, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		writer.Header().Set("Content-Type", "application/json")
+		writer.Header().Set("Cache-Control", "no-store")
+		_ = json.NewEncoder(writer).Encode(cache.report())
+	})
+}
+
+// report gets the current StatsReport of the cache
+func (cache *Cache[T]) report() StatsReport {
+	config := cache.settings()
+	return StatsReport{
+		Name:  cache.Name,
+		Stats: cache.Stats(),
+		Config: StatsConfig{
+			Expiration:       config.expiration,
+			Persistent:       config.persistent,
+			Folder:           config.folder,
+			Encrypted:        config.encryptionKey != nil,
+			Capacity:         config.capacity,
+			MaxMemory:        config.maxMemory,
+			Shards:           len(cache.storage().shards),
+			Durability:       config.durability.String(),
+			Consistency:      config.consistency().String(),
+			ExpirationMode:   config.expirationMode.String(),
+			ExpirationEngine: config.expirationEngine.String(),
+			MaxStale:         config.maxStale,
+		},
+		TopKeys: cache.TopKeys(),
+	}
+}
@@ -0,0 +1,44 @@
+package cache_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gildas/go-cache"
+)
+
+func (suite *CacheSuite) TestCanServeStats() {
+	items := cache.New[string]("test").WithEncryptionKey([]byte("@v3ry#S3cr3tK3y!")).WithTopKeys(2).WithShards(4)
+	defer func() { _ = items.Clear() }()
+	for i := 0; i < 3; i++ {
+		_ = items.Set("value", fmt.Sprintf("key-%d", i))
+		for range i + 1 {
+			_, _ = items.Get(fmt.Sprintf("key-%d", i))
+		}
+	}
+	_, _ = items.Get("missing")
+
+	recorder := httptest.NewRecorder()
+	items.StatsHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/internal/cache/test", nil))
+	suite.Require().Equal(http.StatusOK, recorder.Code)
+	suite.Assert().Equal("application/json", recorder.Header().Get("Content-Type"))
+	suite.Assert().NotContains(recorder.Body.String(), "@v3ry#S3cr3tK3y!", "The encryption key should be redacted")
+
+	var report cache.StatsReport
+	err := json.Unmarshal(recorder.Body.Bytes(), &report)
+	suite.Require().NoError(err, "Failed to decode the report: %+v", err)
+	suite.Assert().Equal("test", report.Name)
+	suite.Assert().Equal(uint64(6), report.Stats.Hits)
+	suite.Assert().Equal(uint64(1), report.Stats.Misses)
+	suite.Assert().True(report.Config.Encrypted)
+	suite.Assert().True(report.Config.Persistent)
+	suite.Assert().Equal(4, report.Config.Shards)
+	suite.Assert().Equal("lazy", report.Config.ExpirationMode)
+	suite.Assert().Equal([]cache.KeyHits{{Key: "key-2", Hits: 3}, {Key: "key-1", Hits: 2}}, report.TopKeys)
+
+	recorder = httptest.NewRecorder()
+	items.StatsHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/internal/cache/test", nil))
+	suite.Assert().Equal(http.StatusMethodNotAllowed, recorder.Code)
+}
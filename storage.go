@@ -0,0 +1,169 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gildas/go-errors"
+	"github.com/google/uuid"
+)
+
+// filekey gets the name of the file that persists the given key
+func filekey(key string) string {
+	return uuid.NewSHA1(uuid.Nil, []byte(key)).String()
+}
+
+// persist marshals, transforms and encrypts if needed, see encryptRecord, and writes the given value in the file named filekey
+func (config *settings) persist(filekey string, value any) (err error) {
+	var data []byte
+
+	if config.folderError != nil {
+		return config.folderError
+	}
+	start := time.Now()
+	if entry, ok := value.(record[[]byte]); ok {
+		buffer := rawBuffers.Get().(*bytes.Buffer)
+		defer releaseBuffer(buffer)
+		err = encodeRaw(buffer, entry)
+		data = buffer.Bytes()
+	} else {
+		data, err = json.Marshal(value)
+	}
+	if err == nil && config.storeTransform != nil {
+		data, err = config.storeTransform(data)
+	}
+	if err == nil && config.compressor != nil {
+		data, err = config.compressor.compress(data)
+	}
+	config.recordPhase("persist", PhaseSerialize, start, err)
+	if err != nil {
+		return
+	}
+	if err = os.MkdirAll(filepath.Dir(filepath.Join(config.folder, filekey)), 0700); err != nil {
+		return
+	}
+	if config.encryptionKey != nil {
+		start = time.Now()
+		data, err = config.encryptRecord(filekey, data)
+		config.recordPhase("persist", PhaseEncrypt, start, err)
+		if err != nil {
+			return
+		}
+	}
+	if config.signingKey != nil {
+		if data, err = config.sign(filekey, data); err != nil {
+			return
+		}
+	}
+	start = time.Now()
+	err = config.writeFile(filepath.Join(config.folder, filekey), frame(data, metadataOf(value)))
+	config.recordPhase("persist", PhaseDisk, start, err)
+	if err == nil && config.bloom != nil {
+		config.bloom.add(filepath.Base(filekey))
+	}
+	return
+}
+
+// restore reads, decrypts and transforms if needed, and unmarshals the file named filekey into the given value
+//
+// Corrupted files are removed or quarantined and os.ErrNotExist is returned, see WithQuarantine.
+func (config *settings) restore(filekey string, value any) (err error) {
+	var data []byte
+
+	if config.folderError != nil {
+		return config.folderError
+	}
+	if config.bloom != nil && !config.bloom.mayContain(config.folder, filepath.Base(filekey)) {
+		return os.ErrNotExist
+	}
+	start := time.Now()
+	release := config.acquireIO()
+	data, err = os.ReadFile(filepath.Join(config.folder, filekey))
+	if errors.Is(err, os.ErrNotExist) && config.fanOut && config.unflatten(filekey) {
+		data, err = os.ReadFile(filepath.Join(config.folder, filekey))
+	}
+	release()
+	config.recordPhase("restore", PhaseDisk, start, err)
+	if err != nil {
+		return
+	}
+	if data, err = unframe(data); err != nil {
+		return config.recoverFile(filekey, err)
+	}
+	if config.signingKey != nil {
+		if data, err = config.verify(filekey, data); err != nil {
+			return
+		}
+	}
+	if config.encryptionKey != nil {
+		start = time.Now()
+		data, err = config.decryptRecord(filekey, data)
+		config.recordPhase("restore", PhaseEncrypt, start, err)
+		if err != nil {
+			return
+		}
+	}
+	start = time.Now()
+	if config.compressor != nil {
+		data, err = config.compressor.decompress(data)
+	}
+	if err == nil && config.loadTransform != nil {
+		data, err = config.loadTransform(data)
+	}
+	if err == nil && isRaw(data) {
+		data, err = config.restoreRaw(data, value)
+	}
+	if err == nil && data != nil {
+		data, err = config.upgrade(data)
+	}
+	if err == nil && data != nil {
+		err = json.Unmarshal(data, value)
+	}
+	config.recordPhase("restore", PhaseSerialize, start, err)
+	if corrupted(err) {
+		return config.recoverFile(filekey, err)
+	}
+	return
+}
+
+// erase removes the file named filekey
+//
+// A write of the file waiting for the end of its coalescing window or in the write-behind queue is cancelled.
+func (config *settings) erase(filekey string) error {
+	if config.coalescer != nil {
+		config.coalescer.cancel(config, filekey)
+	}
+	if config.writeBehind != nil {
+		config.writeBehind.cancel(config, filekey)
+	}
+	defer config.acquireIO()()
+	err := os.Remove(filepath.Join(config.folder, filekey))
+	if name := filepath.Base(filekey); name != filekey {
+		// The file may still be in the cache folder, from before WithDirectoryFanOut
+		if flatErr := os.Remove(filepath.Join(config.folder, name)); flatErr == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// WithStoreTransform transforms the persisted data of the items after they are marshaled
+//
+// The transform is applied before the encryption, use WithLoadTransform to revert it.
+func (cache *Cache[T]) WithStoreTransform(transform func(data []byte) ([]byte, error)) *Cache[T] {
+	return cache.configure(func(config *settings) {
+		config.storeTransform = transform
+	})
+}
+
+// WithLoadTransform transforms the persisted data of the items before they are unmarshaled
+//
+// The transform is applied after the decryption, it reverts the transform given to WithStoreTransform.
+func (cache *Cache[T]) WithLoadTransform(transform func(data []byte) ([]byte, error)) *Cache[T] {
+	return cache.configure(func(config *settings) {
+		config.loadTransform = transform
+	})
+}
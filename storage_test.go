@@ -0,0 +1,43 @@
+package cache_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+var legacyHeader = []byte("LEGACY:")
+
+func addLegacyHeader(data []byte) ([]byte, error) {
+	return append(append([]byte{}, legacyHeader...), data...), nil
+}
+
+func removeLegacyHeader(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, legacyHeader) {
+		return nil, errors.ArgumentInvalid.With("data", string(data))
+	}
+	return bytes.TrimPrefix(data, legacyHeader), nil
+}
+
+func (suite *CacheSuite) TestCanTransformPersistedData() {
+	users := cache.New[User]("test", cache.CacheOptionPersistent).WithStoreTransform(addLegacyHeader).WithLoadTransform(removeLegacyHeader)
+	defer func() { _ = users.Clear() }()
+	joe := User{ID: uuid.New(), Name: "Joe"}
+
+	err := users.Set(joe, "me")
+	suite.Require().NoError(err, "Failed to set user: %+v", err)
+	folder, _ := os.UserCacheDir()
+	data, err := os.ReadFile(filepath.Join(folder, "test", uuid.NewSHA1(uuid.Nil, []byte("me")).String()))
+	suite.Require().NoError(err, "Failed to read persisted file: %+v", err)
+	suite.Assert().True(bytes.Contains(data, legacyHeader), "The persisted data should be transformed")
+
+	other := cache.New[User]("test", cache.CacheOptionPersistent).WithLoadTransform(removeLegacyHeader)
+	cached, err := other.Get("me")
+	suite.Require().NoError(err, "Failed to get persisted user: %+v", err)
+	suite.Assert().Equal(joe, *cached)
+}
@@ -0,0 +1,469 @@
+package cache
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"unique"
+)
+
+// minShardCapacity is the minimum capacity of a shard when the shard count is chosen automatically
+//
+// Each shard evicts its own keys, the smaller the shards the less accurate the eviction is.
+const minShardCapacity = 64
+
+// store contains the items of a Cache in memory, split in shards to reduce contention
+//
+// Its version changes after each change of its records, see ReadSnapshot.
+//
+// With WithMemoryEncryption, the items are sealed by the shards and unsealed when they are read.
+// With WithOffHeapStorage, the shards move the items to their arenas and get them back when they are read.
+type store[T interface{}] struct {
+	shards  []*shard[T]
+	version atomic.Uint64
+	sealer  *sealer
+}
+
+// shard contains a part of the items of a Cache
+//
+// The records are stored by value in a typed map, so storing and loading them
+// does not box them in interfaces.
+//
+// When the cache is bounded, each shard has its own eviction policy, capacity, and memory budget.
+//
+// The expirations of the records are kept in an expirationQueue, so the expired records are found without a scan.
+type shard[T interface{}] struct {
+	mutex       sync.RWMutex
+	items       map[string]record[T]
+	size        int64
+	expirations expirationQueue
+	eviction    atomic.Pointer[eviction]
+	trimming    atomic.Bool
+	sealer      *sealer
+	compactKeys bool
+	arenas      *arenas
+	version     *atomic.Uint64 // the version of the store, changed by the evictions, see evictWhile
+}
+
+// eviction is the eviction policy of a shard, its capacity, its memory budget, and its watermarks
+type eviction struct {
+	policy   policy
+	capacity int
+	maxSize  int64
+	high     float64
+	low      float64
+}
+
+// full tells if a shard with the given number of keys and size must evict keys
+func (eviction *eviction) full(count int, size int64) bool {
+	return (eviction.capacity > 0 && count > eviction.capacity) || (eviction.maxSize > 0 && size > eviction.maxSize)
+}
+
+// above tells if a shard with the given number of keys and size is above the given fraction of its capacity or budget
+func (eviction *eviction) above(count int, size int64, fraction float64) bool {
+	return (eviction.capacity > 0 && float64(count) > fraction*float64(eviction.capacity)) ||
+		(eviction.maxSize > 0 && float64(size) > fraction*float64(eviction.maxSize))
+}
+
+// newStore creates a new store as configured in the given settings
+func newStore[T interface{}](config *settings) *store[T] {
+	count := config.shardCount()
+	storage := &store[T]{shards: make([]*shard[T], count), sealer: config.sealer}
+	for i := range storage.shards {
+		storage.shards[i] = &shard[T]{items: map[string]record[T]{}, expirations: newExpirationQueue(config.expirationEngine), sealer: config.sealer, compactKeys: config.compactKeys, version: &storage.version}
+		if config.arenaSize > 0 {
+			storage.shards[i].arenas = newArenas(config.arenaSize)
+		}
+		if config.capacity > 0 || config.maxMemory > 0 || config.spiller != nil {
+			var capacity int
+			var newPolicy = config.newPolicy
+
+			if config.capacity > 0 {
+				capacity = (config.capacity + count - 1) / count
+			}
+			if newPolicy == nil {
+				newPolicy = func(int) policy { return newLRU() }
+			}
+			storage.shards[i].eviction.Store(&eviction{
+				policy:   newPolicy(capacity),
+				capacity: capacity,
+				maxSize:  (config.maxMemory + int64(count) - 1) / int64(count),
+				high:     config.highWatermark,
+				low:      config.lowWatermark,
+			})
+		}
+	}
+	return storage
+}
+
+// shardCount gets the number of shards to use
+//
+// Unless set with WithShards, it is GOMAXPROCS, reduced for small capacities
+// so each shard can hold at least minShardCapacity keys.
+func (config *settings) shardCount() int {
+	if config.shards > 0 {
+		return config.shards
+	}
+	count := runtime.GOMAXPROCS(0)
+	if config.capacity > 0 {
+		count = min(count, max(1, config.capacity/minShardCapacity))
+	}
+	return max(count, 1)
+}
+
+// shard gets the shard that holds the given key
+func (storage *store[T]) shard(key string) *shard[T] {
+	if len(storage.shards) == 1 {
+		return storage.shards[0]
+	}
+	// FNV-1a, inlined to avoid allocations
+	hash := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		hash ^= uint32(key[i])
+		hash *= 16777619
+	}
+	return storage.shards[hash%uint32(len(storage.shards))]
+}
+
+// load gets the record of a key
+func (storage *store[T]) load(key string) (record[T], bool) {
+	shard := storage.shard(key)
+	shard.mutex.RLock()
+	entry, found := shard.items[key]
+	if found && shard.arenas != nil {
+		entry, found = shard.unload(entry)
+	}
+	shard.mutex.RUnlock()
+	if found {
+		return unsealRecord(storage.sealer, entry)
+	}
+	return entry, found
+}
+
+// peek gets the metadata of the record of a key, without unsealing or unloading its item
+func (storage *store[T]) peek(key string) (frameMetadata, bool) {
+	shard := storage.shard(key)
+	shard.mutex.RLock()
+	defer shard.mutex.RUnlock()
+	entry, found := shard.items[key]
+	return entry.metadata(), found
+}
+
+// store stores the record of a key, evicting other keys if the shard is full
+func (storage *store[T]) store(key string, entry record[T]) error {
+	shard := storage.shard(key)
+	shard.mutex.Lock()
+	defer shard.mutex.Unlock()
+	if err := shard.store(key, entry); err != nil {
+		return err
+	}
+	storage.version.Add(1)
+	return nil
+}
+
+// storeIfAbsent stores the record of a key unless the key is already stored
+func (storage *store[T]) storeIfAbsent(key string, entry record[T]) error {
+	shard := storage.shard(key)
+	shard.mutex.Lock()
+	defer shard.mutex.Unlock()
+	if _, found := shard.items[key]; found {
+		return nil
+	}
+	if err := shard.store(key, entry); err != nil {
+		return err
+	}
+	storage.version.Add(1)
+	return nil
+}
+
+// extend changes the expiration of a key, unless its record was replaced since it was read
+func (storage *store[T]) extend(key string, from, to uint64) {
+	shard := storage.shard(key)
+	shard.mutex.Lock()
+	defer shard.mutex.Unlock()
+	if entry, found := shard.items[key]; found && entry.Expiration == from {
+		entry.Expiration = to
+		shard.items[key] = entry
+		shard.expirations.set(key, int64(to))
+		storage.version.Add(1)
+	}
+}
+
+// store stores the record of a key, evicting other keys if the shard is full
+//
+// Nothing is stored if the record cannot be sealed, see WithMemoryEncryption.
+// The caller must hold the mutex.
+func (shard *shard[T]) store(key string, entry record[T]) (err error) {
+	item := entry.Item
+	if entry, err = sealRecord(shard.sealer, entry); err != nil {
+		return
+	}
+	if shard.compactKeys {
+		key = shard.intern(key)
+		entry.Key = key
+	}
+	if shard.arenas != nil {
+		entry = shard.offload(entry)
+	}
+	eviction := shard.eviction.Load()
+	if eviction != nil && eviction.maxSize > 0 && entry.size == 0 {
+		if entry.span.region != 0 {
+			entry.size = int64(entry.span.length)
+		} else if entry.sealed != nil {
+			entry.size = int64(cap(entry.sealed))
+		} else {
+			entry.size = sizeOf(entry.Item)
+		}
+	}
+	if current, found := shard.items[key]; found {
+		shard.size -= current.size
+		shard.release(current)
+	}
+	shard.items[key] = entry
+	shard.size += entry.size
+	shard.expirations.set(key, int64(entry.Expiration))
+	if eviction != nil {
+		if aware, ok := eviction.policy.(itemPolicy); ok {
+			aware.addItem(key, item)
+		} else if aware, ok := eviction.policy.(costPolicy); ok {
+			aware.addCost(key, entry.Cost, entry.size)
+		} else {
+			eviction.policy.add(key)
+		}
+		shard.evictWhile(eviction, eviction.full)
+		if eviction.high > 0 && eviction.above(eviction.policy.len(), shard.size, eviction.high) && shard.trimming.CompareAndSwap(false, true) {
+			go shard.trim(eviction)
+		}
+	}
+	return nil
+}
+
+// release frees the place of a record in the arenas of the shard, if any
+//
+// The caller must hold the mutex.
+func (shard *shard[T]) release(entry record[T]) {
+	if shard.arenas != nil {
+		shard.arenas.free(entry.span)
+	}
+}
+
+// intern gets the copy of a key the shard keeps, see WithCompactKeys
+//
+// The caller must hold the mutex.
+func (shard *shard[T]) intern(key string) string {
+	if current, found := shard.items[key]; found && current.Key == key {
+		return current.Key
+	}
+	return unique.Make(key).Value()
+}
+
+// evictWhile evicts the keys chosen by the policy while the shard is over the given limit
+//
+// The version of the store changes with each key evicted, so the snapshots do not keep the evicted keys,
+// even when they are evicted in the background, see trim and spill.
+// The caller must hold the mutex.
+func (shard *shard[T]) evictWhile(eviction *eviction, over func(count int, size int64) bool) {
+	for over(eviction.policy.len(), shard.size) {
+		victim, ok := eviction.policy.victim()
+		if !ok {
+			break
+		}
+		shard.size -= shard.items[victim].size
+		shard.release(shard.items[victim])
+		delete(shard.items, victim)
+		shard.expirations.remove(victim)
+		shard.version.Add(1)
+	}
+}
+
+// trim evicts keys in a batch until the shard is below its low watermark, see WithWatermarks
+func (shard *shard[T]) trim(eviction *eviction) {
+	defer shard.trimming.Store(false)
+	shard.mutex.Lock()
+	defer shard.mutex.Unlock()
+	shard.evictWhile(eviction, func(count int, size int64) bool {
+		return eviction.above(count, size, eviction.low)
+	})
+}
+
+// touch tells the eviction policy that a key was read
+func (storage *store[T]) touch(key string) {
+	if eviction := storage.shard(key).eviction.Load(); eviction != nil {
+		eviction.policy.touch(key)
+	}
+}
+
+// delete removes the record of a key
+func (storage *store[T]) delete(key string) {
+	shard := storage.shard(key)
+	shard.mutex.Lock()
+	defer shard.mutex.Unlock()
+	shard.size -= shard.items[key].size
+	shard.release(shard.items[key])
+	delete(shard.items, key)
+	shard.expirations.remove(key)
+	if eviction := shard.eviction.Load(); eviction != nil {
+		eviction.policy.remove(key)
+	}
+	storage.version.Add(1)
+}
+
+// clear removes all the records
+func (storage *store[T]) clear() {
+	for _, shard := range storage.shards {
+		shard.mutex.Lock()
+		if eviction := shard.eviction.Load(); eviction != nil {
+			for key := range shard.items {
+				eviction.policy.remove(key)
+			}
+		}
+		shard.items = map[string]record[T]{}
+		shard.size = 0
+		if shard.arenas != nil {
+			shard.arenas.reset()
+		}
+		shard.expirations.clear()
+		shard.mutex.Unlock()
+	}
+	storage.version.Add(1)
+}
+
+// expire removes the records that expired at or before deadline and returns them
+func (storage *store[T]) expire(deadline int64) (expired []record[T]) {
+	for _, shard := range storage.shards {
+		shard.mutex.Lock()
+		for _, next := range shard.expirations.expire(deadline) {
+			entry := shard.items[next.key]
+			shard.size -= entry.size
+			entry.Key = next.key
+			if entry, ok := shard.unload(entry); ok {
+				if entry, ok := unsealRecord(storage.sealer, entry); ok {
+					expired = append(expired, entry)
+				}
+			}
+			shard.release(entry)
+			delete(shard.items, next.key)
+			if eviction := shard.eviction.Load(); eviction != nil {
+				eviction.policy.remove(next.key)
+			}
+		}
+		shard.mutex.Unlock()
+	}
+	if len(expired) > 0 {
+		storage.version.Add(1)
+	}
+	return
+}
+
+// expiring gets the expirations at or before deadline
+func (storage *store[T]) expiring(deadline int64) (expirations []expiry) {
+	for _, shard := range storage.shards {
+		shard.mutex.RLock()
+		expirations = append(expirations, shard.expirations.within(deadline)...)
+		shard.mutex.RUnlock()
+	}
+	return
+}
+
+// each calls fn for each key and record until fn returns false
+//
+// fn is called on a copy of each shard, so it can modify the store.
+func (storage *store[T]) each(fn func(key string, entry record[T]) bool) {
+	for _, shard := range storage.shards {
+		shard.mutex.RLock()
+		items := make(map[string]record[T], len(shard.items))
+		for key, entry := range shard.items {
+			if entry, ok := shard.unload(entry); ok {
+				items[key] = entry
+			}
+		}
+		shard.mutex.RUnlock()
+		for key, entry := range items {
+			entry, ok := unsealRecord(storage.sealer, entry)
+			if ok && !fn(key, entry) {
+				return
+			}
+		}
+	}
+}
+
+// eachMetadata calls fn with the key and the metadata of each record, without unsealing or unloading the items
+//
+// fn is called under the mutex of the shards, it must not use the store.
+func (storage *store[T]) eachMetadata(fn func(key string, metadata frameMetadata)) {
+	for _, shard := range storage.shards {
+		shard.mutex.RLock()
+		for key, entry := range shard.items {
+			fn(key, entry.metadata())
+		}
+		shard.mutex.RUnlock()
+	}
+}
+
+// storage gets the store of the cache, creating it if needed
+func (cache *Cache[T]) storage() *store[T] {
+	if storage := cache.items.Load(); storage != nil {
+		return storage
+	}
+	cache.items.CompareAndSwap(nil, newStore[T](cache.settings()))
+	return cache.items.Load()
+}
+
+// write runs fn with the store of the cache, which is not replaced until fn returns, see rebuild
+//
+// The changes of the store go through write, so they are not lost when the store is rebuilt.
+func (cache *Cache[T]) write(fn func(storage *store[T])) {
+	cache.swapMutex.RLock()
+	defer cache.swapMutex.RUnlock()
+	fn(cache.storage())
+}
+
+// rebuild replaces the store of the cache after its shards or its capacity changed
+//
+// The records of the current store are moved to the new one.
+// The changes made by other goroutines wait until the records are moved, see write.
+func (cache *Cache[T]) rebuild() {
+	cache.configMutex.Lock()
+	defer cache.configMutex.Unlock()
+	cache.swapMutex.Lock()
+	defer cache.swapMutex.Unlock()
+	storage := newStore[T](cache.settings())
+	if current := cache.items.Swap(storage); current != nil {
+		current.each(func(key string, entry record[T]) bool {
+			_ = storage.store(key, entry)
+			return true
+		})
+	}
+}
+
+// WithCompactKeys keeps one compact copy of each key in memory
+//
+// A key is interned with unique.Make when it is first stored, and the later writes of the same key reuse that copy,
+// so the map, the records, the expirations, the eviction policy, and the other caches share one string per key.
+// The keys given to Set can then be collected right away, even when they are slices of larger strings,
+// like the lines of a log or the bodies of requests. The keys are matched in full, so two keys never collide.
+//
+// This trades allocations for memory: storing a key that is not in memory yet costs a few more allocations,
+// while the heap kept by the keys shrinks, see BenchmarkCompactKeys.
+func (cache *Cache[T]) WithCompactKeys(enabled bool) *Cache[T] {
+	cache.configure(func(config *settings) {
+		config.compactKeys = enabled
+	})
+	cache.rebuild()
+	return cache
+}
+
+// WithShards sets the number of shards of the cache
+//
+// Items are split between shards by key so goroutines working on different keys do not contend.
+// By default, the number of shards is GOMAXPROCS, reduced for small capacities.
+//
+// When the cache has a capacity, each shard evicts its own keys with capacity/shards keys each,
+// so the more shards, the less accurate the eviction is.
+func (cache *Cache[T]) WithShards(shards int) *Cache[T] {
+	cache.configure(func(config *settings) {
+		config.shards = max(shards, 0)
+	})
+	cache.rebuild()
+	return cache
+}
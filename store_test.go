@@ -0,0 +1,160 @@
+package cache_test
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/gildas/go-cache"
+)
+
+func (suite *CacheSuite) TestCanCacheStuffWithShards() {
+	users := cache.New[User]("test").WithShards(8)
+	defer func() { _ = users.Clear() }()
+	stored := []User{}
+	for i := 0; i < 100; i++ {
+		user := User{ID: uuid.New(), Name: fmt.Sprintf("user-%d", i)}
+		err := users.Set(user)
+		suite.Require().NoError(err, "Failed to set cached user: %+v", err)
+		stored = append(stored, user)
+	}
+	for _, user := range stored {
+		cached, err := users.Get(user.GetID().String())
+		suite.Require().NoError(err, "Failed to get cached user: %+v", err)
+		suite.Assert().Equal(user, *cached, "User and Cached User are different")
+	}
+}
+
+func (suite *CacheSuite) TestCanChangeShardsAfterUse() {
+	users := cache.New[User]("test").WithCapacity(1000)
+	defer func() { _ = users.Clear() }()
+	user := User{ID: uuid.New(), Name: "Joe"}
+	_ = users.Set(user)
+
+	users.WithShards(4)
+	cached, err := users.Get(user.GetID().String())
+	suite.Require().NoError(err, "Items should be kept when the shards change: %+v", err)
+	suite.Assert().Equal(user, *cached, "User and Cached User are different")
+}
+
+func (suite *CacheSuite) TestCanChangeShardsWhileSetting() {
+	reports := cache.New[string]("test")
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			_ = reports.Set(strconv.Itoa(i), strconv.Itoa(i))
+		}
+	}()
+	for shards := 0; ; shards++ {
+		select {
+		case <-done:
+			for i := 0; i < 1000; i++ {
+				_, err := reports.Get(strconv.Itoa(i))
+				suite.Require().NoError(err, "Items set while the shards change should be kept: %+v", err)
+			}
+			return
+		default:
+			reports.WithShards(shards%16 + 1)
+		}
+	}
+}
+
+func (suite *CacheSuite) TestCanBoundShardedCache() {
+	names := cache.New[string]("test").WithShards(4).WithCapacity(40)
+	defer func() { _ = names.Clear() }()
+	for i := 0; i < 200; i++ {
+		_ = names.Set(fmt.Sprintf("name-%d", i), strconv.Itoa(i))
+	}
+	found := 0
+	for i := 0; i < 200; i++ {
+		if _, err := names.Get(strconv.Itoa(i)); err == nil {
+			found++
+		}
+	}
+	// Each shard holds 10 keys, the keys are not evenly spread between shards
+	suite.Assert().LessOrEqual(found, 40, "The cache should not hold more than its capacity")
+	suite.Assert().Greater(found, 20, "The cache should hold most of its capacity")
+}
+
+// BenchmarkShards measures how the number of shards affects parallel Set and Get
+//
+// Run with: go test -run=^$ -bench=Shards -cpu=1,4,16
+func BenchmarkShards(b *testing.B) {
+	for _, shards := range []int{1, 4, 16, 64} {
+		b.Run(fmt.Sprintf("shards=%d", shards), func(b *testing.B) {
+			names := cache.New[string]("bench").WithShards(shards).WithCapacity(100000)
+			var counter atomic.Int64
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					key := strconv.FormatInt(counter.Add(1)%10000, 10)
+					if _, err := names.Get(key); err != nil {
+						_ = names.Set(key, key)
+					}
+				}
+			})
+		})
+	}
+}
+
+func (suite *CacheSuite) TestCanCacheStuffWithCompactKeys() {
+	names := cache.New[string]("test").WithCompactKeys(true).WithShards(1).WithCapacity(10)
+	for i := 0; i < 20; i++ {
+		line := fmt.Sprintf("key-%d %s", i%15, strings.Repeat("x", 100))
+		suite.Require().NoError(names.Set(strconv.Itoa(i), line[:strings.IndexByte(line, ' ')]))
+	}
+	suite.Assert().Equal(10, names.ReadSnapshot().Len(), "The cache should evict keys beyond its capacity")
+	value, err := names.Get("key-4")
+	suite.Require().NoError(err, "Failed to get item: %+v", err)
+	suite.Assert().Equal("19", *value)
+}
+
+// BenchmarkCompactKeys measures the memory kept by keys that are slices of larger strings
+//
+// Run with: go test -run=^$ -bench=CompactKeys -benchmem
+func BenchmarkCompactKeys(b *testing.B) {
+	padding := strings.Repeat("x", 256)
+	for _, compact := range []bool{false, true} {
+		b.Run(fmt.Sprintf("compact=%t", compact), func(b *testing.B) {
+			names := cache.New[int]("bench").WithCompactKeys(compact).WithCapacity(100000)
+			var before, after runtime.MemStats
+			runtime.GC()
+			runtime.ReadMemStats(&before)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				line := fmt.Sprintf("key-%d %s", i%100000, padding)
+				_ = names.Set(i, line[:strings.IndexByte(line, ' ')])
+			}
+			b.StopTimer()
+			runtime.GC()
+			runtime.ReadMemStats(&after)
+			b.ReportMetric(float64(after.HeapInuse)-float64(before.HeapInuse), "heap-bytes")
+			runtime.KeepAlive(names)
+		})
+	}
+}
+
+func (suite *CacheSuite) TestDeprecatedItemsMapReadsTheShards() {
+	names := cache.New[string]("test").WithShards(4)
+	suite.Require().NoError(names.Set("value1", "key1"))
+	suite.Require().NoError(names.Set("value2", "key2"))
+
+	value, found := names.Items.Load("key1") //nolint:staticcheck // the deprecated field is still supported
+	suite.Require().True(found)
+	suite.Assert().Equal("value1", value)
+	count := 0
+	names.Items.Range(func(key, value any) bool { //nolint:staticcheck // the deprecated field is still supported
+		count++
+		return true
+	})
+	suite.Assert().Equal(2, count)
+	names.Items.Delete("key1") //nolint:staticcheck // the deprecated field is still supported
+	_, err := names.Get("key1")
+	suite.Assert().Error(err, "The item should have been deleted")
+}
@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+)
+
+// SyncMap exposes a Cache with the methods of sync.Map
+//
+// Code that uses a sync.Map can switch to a SyncMap to get expiration and persistence,
+// and move to the typed methods of the Cache later.
+//
+// The keys are converted to strings with fmt.Sprint, so Range gives string keys.
+// Storing a value that is not a T panics, like a failed type assertion would.
+//
+// LoadOrStore is atomic only between the callers of the same SyncMap.
+type SyncMap[T any] struct {
+	Cache *Cache[T]
+	mutex sync.Mutex
+}
+
+// AsSyncMap gets a SyncMap that works on the cache
+func (cache *Cache[T]) AsSyncMap() *SyncMap[T] {
+	return &SyncMap[T]{Cache: cache}
+}
+
+// Load gets the value stored under the key, or nil and false
+func (m *SyncMap[T]) Load(key any) (value any, ok bool) {
+	item, err := m.Cache.Get(fmt.Sprint(key))
+	if err != nil {
+		return nil, false
+	}
+	return *item, true
+}
+
+// Store stores the value under the key
+//
+// The value is stored only under the key, no key is derived from it.
+func (m *SyncMap[T]) Store(key, value any) {
+	config := m.Cache.settings()
+	_ = m.Cache.put(context.Background(), config, value.(T), expirationOf(value, config.expiration), fmt.Sprint(key))
+}
+
+// LoadOrStore gets the value stored under the key if there is one, otherwise it stores the given value
+//
+// loaded is true if the value was loaded.
+func (m *SyncMap[T]) LoadOrStore(key, value any) (actual any, loaded bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if actual, loaded = m.Load(key); loaded {
+		return
+	}
+	m.Store(key, value)
+	return value, false
+}
+
+// LoadAndDelete deletes the value stored under the key and returns it
+//
+// loaded is true if there was a value.
+func (m *SyncMap[T]) LoadAndDelete(key any) (value any, loaded bool) {
+	if value, loaded = m.Load(key); loaded {
+		m.Delete(key)
+	}
+	return
+}
+
+// Delete deletes the value stored under the key
+func (m *SyncMap[T]) Delete(key any) {
+	_ = m.Cache.Delete(fmt.Sprint(key))
+}
+
+// Range calls fn for each key and value until fn returns false
+//
+// Expired values are skipped. The values persisted by other processes are included.
+func (m *SyncMap[T]) Range(fn func(key, value any) bool) {
+	m.Cache.each(func(key string, item T) bool {
+		return fn(key, item)
+	})
+}
+
+// each calls fn for each key and item that has not expired, in memory and on the disk, until fn returns false
+//
+// Items persisted before their keys were recorded are skipped.
+func (cache *Cache[T]) each(fn func(key string, item T) bool) {
+	config := cache.settings()
+	seen := map[string]bool{}
+	generation := cache.generation(config)
+	stopped := false
+
+	cache.storage().each(func(key string, entry record[T]) bool {
+		seen[key] = true
+		if entry.expired() || entry.Generation != generation {
+			return true
+		}
+		stopped = !fn(key, entry.Item)
+		return !stopped
+	})
+	if stopped || !config.persistent {
+		return
+	}
+	_ = config.walkPersisted(func(filekey string) error {
+		var entry record[T]
+
+		if err := config.restore(filekey, &entry); err != nil || len(entry.Key) == 0 || seen[entry.Key] || entry.expired() || entry.Generation != generation {
+			return nil
+		}
+		seen[entry.Key] = true
+		if !fn(entry.Key, entry.Item) {
+			return filepath.SkipAll
+		}
+		return nil
+	})
+}
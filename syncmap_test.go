@@ -0,0 +1,43 @@
+package cache_test
+
+import (
+	"github.com/gildas/go-cache"
+)
+
+func (suite *CacheSuite) TestCanUseCacheAsSyncMap() {
+	m := cache.New[string]("test", cache.CacheOptionPersistent).AsSyncMap()
+	defer func() { _ = m.Cache.Clear() }()
+
+	m.Store("key1", "value1")
+	m.Store(2, "value2")
+	value, ok := m.Load("key1")
+	suite.Require().True(ok)
+	suite.Assert().Equal("value1", value)
+	value, ok = m.Load(2)
+	suite.Require().True(ok, "Keys should be converted to strings")
+	suite.Assert().Equal("value2", value)
+
+	actual, loaded := m.LoadOrStore("key1", "other")
+	suite.Assert().True(loaded)
+	suite.Assert().Equal("value1", actual)
+	actual, loaded = m.LoadOrStore("key3", "value3")
+	suite.Assert().False(loaded)
+	suite.Assert().Equal("value3", actual)
+
+	value, loaded = m.LoadAndDelete("key3")
+	suite.Assert().True(loaded)
+	suite.Assert().Equal("value3", value)
+	_, ok = m.Load("key3")
+	suite.Assert().False(ok)
+
+	// Range sees the items persisted by other processes
+	other := cache.New[string]("test", cache.CacheOptionPersistent).AsSyncMap()
+	other.Store("key4", "value4")
+	values := map[any]any{}
+	m.Range(func(key, value any) bool {
+		values[key] = value
+		return true
+	})
+	suite.Assert().Equal(map[any]any{"key1": "value1", "2": "value2", "key4": "value4"}, values)
+	suite.Assert().Panics(func() { m.Store("key5", 5) }, "Storing a value of the wrong type should panic")
+}
@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+)
+
+// tenantsFolder is the subfolder of the cache folder where the tenants persist their items, see ForTenant
+const tenantsFolder = "tenants"
+
+// ForTenant gets a view of the cache dedicated to the given tenant
+//
+// The view has its own items in memory, and persists them in a subfolder of the cache folder,
+// so tenants never see each other's items and a tenant's items can be purged with ClearTenant.
+//
+// The view starts with the configuration of the cache when it is first created,
+// calling ForTenant again with the same id returns the same view.
+// The view has its own janitor, statistics, hit counts, and circuit breaker, Close stops its janitor too.
+func (cache *Cache[T]) ForTenant(id string) *Cache[T] {
+	if view, found := cache.tenants.Load(id); found {
+		return view.(*Cache[T])
+	}
+	config := *cache.settings()
+	config.folder = tenantFolder(config.folder, id)
+	config.tenant, config.tenantKey = id, filepath.Base(config.folder)
+	view := &Cache[T]{Name: cache.Name, Expiration: config.expiration}
+	view.Items = ItemsMap[T]{cache: view}
+	view.own(&config)
+	view.config.Store(&config)
+	actual, loaded := cache.tenants.LoadOrStore(id, view)
+	if loaded {
+		config.stopJanitors()
+	}
+	return actual.(*Cache[T])
+}
+
+// own replaces the state the settings of a tenant view share with its cache by new state of the view
+//
+// The janitors are started again for the view, so its own items are expired and spilled.
+func (cache *Cache[T]) own(config *settings) {
+	config.corrupted = &cache.stats.corrupted
+	if config.bloom != nil {
+		config.bloom = config.bloom.empty()
+	}
+	if config.keyIndex != nil {
+		config.keyIndex = newKeyIndex()
+	}
+	if config.janitor != nil {
+		config.janitor = newJanitor(config.janitor.interval, cache.expire)
+	}
+	if config.spiller != nil {
+		spiller := &spiller{threshold: config.spiller.threshold, measure: config.spiller.measure}
+		spiller.janitor = newJanitor(config.spiller.janitor.interval, func() { cache.spill(spiller) })
+		config.spiller = spiller
+	}
+	if config.priorities != nil {
+		config.priorities = &priorities{keys: map[string]priority{}}
+	}
+	if config.topKeys != nil {
+		config.topKeys = &topKeys{size: config.topKeys.size, counters: make(map[string]uint64, config.topKeys.size*topKeysFactor)}
+	}
+	if config.breaker != nil {
+		config.breaker = &circuitBreaker{threshold: config.breaker.threshold, coolDown: config.breaker.coolDown}
+	}
+}
+
+// ClearTenant clears the items of the given tenant, in memory and on the disk
+func (cache *Cache[T]) ClearTenant(id string) error {
+	if view, found := cache.tenants.Load(id); found {
+		return view.(*Cache[T]).Clear()
+	}
+	if config := cache.settings(); config.persistent {
+		return os.RemoveAll(tenantFolder(config.folder, id))
+	}
+	return nil
+}
+
+// unloadedTenants gets the names of the folders of the tenants that have no view in this process
+func (cache *Cache[T]) unloadedTenants(config *settings) (names []string) {
+	loaded := map[string]bool{}
+	cache.tenants.Range(func(id, view interface{}) bool {
+		loaded[filepath.Base(view.(*Cache[T]).settings().folder)] = true
+		return true
+	})
+	entries, _ := os.ReadDir(filepath.Join(config.folder, tenantsFolder))
+	for _, entry := range entries {
+		if entry.IsDir() && !loaded[entry.Name()] {
+			names = append(names, entry.Name())
+		}
+	}
+	return
+}
+
+// tenantSettings gets the settings of the folder of a tenant that is not loaded, named by the hash of its id
+//
+// The settings are bound to the tenant, see additionalData, so its encrypted files can be read without its id.
+func (config *settings) tenantSettings(name string) *settings {
+	tenant := *config
+	tenant.folder = filepath.Join(config.folder, tenantsFolder, name)
+	tenant.tenant, tenant.tenantKey = "", name
+	tenant.bloom, tenant.keyIndex = nil, nil
+	return &tenant
+}
+
+// tenantFolder gets the folder where the items of a tenant are persisted
+//
+// The tenant id is hashed so it is always a valid folder name.
+func tenantFolder(folder, id string) string {
+	return filepath.Join(folder, tenantsFolder, uuid.NewSHA1(uuid.Nil, []byte(id)).String())
+}
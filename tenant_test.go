@@ -0,0 +1,74 @@
+package cache_test
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+func (suite *CacheSuite) TestCanIsolateTenants() {
+	users := cache.New[User]("test", cache.CacheOptionPersistent)
+	defer func() { _ = users.Clear() }()
+	joe := User{ID: uuid.New(), Name: "Joe"}
+	jane := User{ID: uuid.New(), Name: "Jane"}
+	acme := users.ForTenant("acme")
+	globex := users.ForTenant("globex")
+	suite.Assert().Same(acme, users.ForTenant("acme"), "ForTenant should return the same view")
+
+	_ = acme.Set(joe, "me")
+	_ = globex.Set(jane, "me")
+
+	cached, err := acme.Get("me")
+	suite.Require().NoError(err, "Failed to get cached user: %+v", err)
+	suite.Assert().Equal(joe, *cached)
+	cached, err = globex.Get("me")
+	suite.Require().NoError(err, "Failed to get cached user: %+v", err)
+	suite.Assert().Equal(jane, *cached)
+	_, err = users.Get("me")
+	suite.Assert().ErrorIs(err, errors.NotFound, "Tenant items should not be visible in the cache")
+
+	// Another process sees the persisted items of the tenant
+	other := cache.New[User]("test", cache.CacheOptionPersistent).ForTenant("acme")
+	cached, err = other.Get("me")
+	suite.Require().NoError(err, "Failed to get persisted user: %+v", err)
+	suite.Assert().Equal(joe, *cached)
+}
+
+func (suite *CacheSuite) TestCanClearTenant() {
+	users := cache.New[User]("test", cache.CacheOptionPersistent)
+	defer func() { _ = users.Clear() }()
+	joe := User{ID: uuid.New(), Name: "Joe"}
+	jane := User{ID: uuid.New(), Name: "Jane"}
+	_ = users.ForTenant("acme").Set(joe, "me")
+	_ = users.ForTenant("globex").Set(jane, "me")
+
+	err := users.ClearTenant("acme")
+	suite.Require().NoError(err, "Failed to clear tenant: %+v", err)
+	_, err = users.ForTenant("acme").Get("me")
+	suite.Assert().ErrorIs(err, errors.NotFound, "Tenant items should have been cleared")
+	_, err = cache.New[User]("test", cache.CacheOptionPersistent).ForTenant("acme").Get("me")
+	suite.Assert().ErrorIs(err, errors.NotFound, "Tenant items should have been cleared from the disk")
+	_, err = users.ForTenant("globex").Get("me")
+	suite.Assert().NoError(err, "Other tenants should not be cleared")
+
+	// Clearing a tenant that was not used by this process
+	err = cache.New[User]("test", cache.CacheOptionPersistent).ClearTenant("globex")
+	suite.Require().NoError(err, "Failed to clear tenant: %+v", err)
+	_, err = cache.New[User]("test", cache.CacheOptionPersistent).ForTenant("globex").Get("me")
+	suite.Assert().ErrorIs(err, errors.NotFound, "Tenant items should have been cleared from the disk")
+}
+
+func (suite *CacheSuite) TestShouldReapExpiredTenantItems() {
+	users := cache.New[User]("test").WithExpiration(20 * time.Millisecond).WithJanitor(10 * time.Millisecond)
+	defer func() { _ = users.Close(); _ = users.Clear() }()
+	acme := users.ForTenant("acme")
+	suite.Require().NoError(acme.Set(User{ID: uuid.New(), Name: "Joe"}, "me"))
+
+	suite.Assert().Eventually(func() bool {
+		return acme.Stats().Expired > 0
+	}, time.Second, 10*time.Millisecond, "The janitor of the tenant should have reaped the expired item")
+	suite.Assert().Zero(users.Stats().Expired, "The item of the tenant should not be counted by the cache")
+}
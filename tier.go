@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"context"
+	"os"
+
+	"github.com/gildas/go-errors"
+)
+
+// StorageTier tells where an item is kept, see SetWithTier
+type StorageTier int
+
+const (
+	// TierDefault keeps the item in memory, and on the disk if the cache is persistent
+	TierDefault StorageTier = iota
+	// TierMemoryOnly keeps the item in memory only, it is never persisted, e.g. for secrets
+	//
+	// The item is lost when it is evicted or when the application stops.
+	TierMemoryOnly
+	// TierDiskPreferred keeps the item on the disk only, e.g. for items too big to be kept in memory
+	//
+	// The item is read from the disk on each Get. When the cache is not persistent, the item is kept in memory.
+	TierDiskPreferred
+)
+
+// String gets the name of the StorageTier
+//
+// implements fmt.Stringer
+func (tier StorageTier) String() string {
+	switch tier {
+	case TierMemoryOnly:
+		return "memory-only"
+	case TierDiskPreferred:
+		return "disk-preferred"
+	default:
+		return "default"
+	}
+}
+
+// SetWithTier sets an item in the cache, kept in memory only or on the disk only as told by tier
+//
+// The tier is kept with the item until it is set again: ExpireAt and Touch keep the item where it is.
+// A memory-only item replaces the file of its keys, so an older persisted item does not come back.
+func (cache *Cache[T]) SetWithTier(item T, tier StorageTier, key ...string) error {
+	config := cache.settings()
+	return cache.putWith(context.Background(), config, setDefault, item, expirationOf(item, config.expiration), 0, tier, nil, config.keysOf(item, key)...)
+}
+
+// SetVolatile sets an item in the cache that is kept in memory only, even when the cache is persistent
+//
+// This is for items that are cheap to compute again or that must never touch the disk, see TierMemoryOnly.
+func (cache *Cache[T]) SetVolatile(item T, key ...string) error {
+	return cache.SetWithTier(item, TierMemoryOnly, key...)
+}
+
+// storageTier gets the tier of the record
+func (r record[T]) storageTier() StorageTier {
+	return r.Tier
+}
+
+// tierOf gets the tier of a persisted value, the other values than records have the default tier
+func tierOf(value any) StorageTier {
+	if tiered, ok := value.(interface{ storageTier() StorageTier }); ok {
+		return tiered.storageTier()
+	}
+	return TierDefault
+}
+
+// keep stores the record of a key in memory, unless it is kept on the disk only
+func (cache *Cache[T]) keep(config *settings, key string, entry record[T]) (err error) {
+	if config.sealerError != nil {
+		return config.sealerError
+	}
+	cache.write(func(storage *store[T]) {
+		if entry.Tier == TierDiskPreferred && config.persistent {
+			storage.delete(key)
+			return
+		}
+		err = storage.store(key, entry)
+	})
+	return
+}
+
+// forget removes the file of a memory-only item, it is not an error if there is none
+func (config *settings) forget(key string) error {
+	if err := config.erase(config.filekey(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
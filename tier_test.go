@@ -0,0 +1,58 @@
+package cache_test
+
+import (
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+func (suite *CacheSuite) TestCanKeepItemsInMemoryOnly() {
+	secrets := cache.New[string]("test", cache.CacheOptionPersistent)
+	defer func() { _ = secrets.Clear() }()
+	suite.Require().NoError(secrets.Set("old secret", "token"))
+	suite.Require().NoError(secrets.SetWithTier("secret", cache.TierMemoryOnly, "token"))
+	value, err := secrets.Get("token")
+	suite.Require().NoError(err, "Failed to get the item: %+v", err)
+	suite.Assert().Equal("secret", *value)
+
+	reloaded := cache.New[string]("test", cache.CacheOptionPersistent)
+	_, err = reloaded.Get("token")
+	suite.Assert().ErrorIs(err, errors.NotFound, "The item should not be persisted, nor the previous one")
+}
+
+func (suite *CacheSuite) TestCanKeepItemsOnDiskOnly() {
+	payloads := cache.New[string]("test", cache.CacheOptionPersistent)
+	defer func() { _ = payloads.Clear() }()
+	suite.Require().NoError(payloads.Set("small", "payload"))
+	suite.Require().NoError(payloads.SetWithTier("large", cache.TierDiskPreferred, "payload"))
+	suite.Assert().Equal(0, payloads.Len(), "The item should not be kept in memory")
+
+	value, err := payloads.Get("payload")
+	suite.Require().NoError(err, "Failed to get the item: %+v", err)
+	suite.Assert().Equal("large", *value)
+	suite.Assert().Equal(0, payloads.Len(), "The item should not be loaded in memory when it is read")
+
+	count, err := payloads.LenWithPersisted()
+	suite.Require().NoError(err, "Failed to count the items: %+v", err)
+	suite.Assert().Equal(1, count)
+}
+
+func (suite *CacheSuite) TestDiskPreferredItemsStayInMemoryWithoutPersistence() {
+	payloads := cache.New[string]("test")
+	suite.Require().NoError(payloads.SetWithTier("large", cache.TierDiskPreferred, "payload"))
+	value, err := payloads.Get("payload")
+	suite.Require().NoError(err, "Failed to get the item: %+v", err)
+	suite.Assert().Equal("large", *value)
+}
+
+func (suite *CacheSuite) TestCanSetVolatileItems() {
+	items := cache.New[string]("test", cache.CacheOptionPersistent)
+	defer func() { _ = items.Clear() }()
+	suite.Require().NoError(items.SetVolatile("volatile", "key"))
+	suite.Assert().True(items.Has("key"))
+
+	count, err := items.LenWithPersisted()
+	suite.Require().NoError(err, "Failed to count the items: %+v", err)
+	suite.Assert().Equal(1, count, "The item should be counted once, from memory")
+	_, err = cache.New[string]("test", cache.CacheOptionPersistent).Get("key")
+	suite.Assert().ErrorIs(err, errors.NotFound, "The item should not be persisted")
+}
@@ -0,0 +1,98 @@
+package cache
+
+import "time"
+
+const (
+	// timingWheelResolution is the duration covered by a slot of a timing wheel
+	timingWheelResolution = 100 * time.Millisecond
+	// timingWheelSlots is the number of slots of a timing wheel
+	timingWheelSlots = 1024
+)
+
+// timingWheel is a hashed timing wheel of the expirations of the keys of a shard
+//
+// Each slot holds the keys that expire during a resolution, the wheel wraps around
+// after all its slots, so a slot can hold keys of later rounds.
+// Keys are expired once their whole slot has elapsed, so up to one resolution late.
+type timingWheel struct {
+	resolution int64
+	slots      []map[string]int64
+	index      map[string]int
+	cursor     int64
+}
+
+// newTimingWheel creates a new timingWheel that starts at the given time
+func newTimingWheel(resolution time.Duration, slots int, start time.Time) *timingWheel {
+	return &timingWheel{
+		resolution: resolution.Nanoseconds(),
+		slots:      make([]map[string]int64, slots),
+		index:      map[string]int{},
+		cursor:     start.UnixNano() / resolution.Nanoseconds(),
+	}
+}
+
+// set sets the expiration of a key, at 0 removes it
+func (wheel *timingWheel) set(key string, at int64) {
+	wheel.remove(key)
+	if at == 0 {
+		return
+	}
+	// The slot b holds the expirations in ((b-1)*resolution, b*resolution]
+	bucket := max((at+wheel.resolution-1)/wheel.resolution, wheel.cursor)
+	slot := int(bucket % int64(len(wheel.slots)))
+	if wheel.slots[slot] == nil {
+		wheel.slots[slot] = map[string]int64{}
+	}
+	wheel.slots[slot][key] = at
+	wheel.index[key] = slot
+}
+
+// remove removes the expiration of a key
+func (wheel *timingWheel) remove(key string) {
+	if slot, found := wheel.index[key]; found {
+		delete(wheel.slots[slot], key)
+		delete(wheel.index, key)
+	}
+}
+
+// expire removes and returns the expirations of the slots that elapsed at deadline
+func (wheel *timingWheel) expire(deadline int64) (expired []expiry) {
+	last := deadline / wheel.resolution
+	if last < wheel.cursor {
+		return
+	}
+	expired = wheel.visit(wheel.cursor, last, deadline, true)
+	wheel.cursor = last + 1
+	return
+}
+
+// within gets the expirations at or before deadline
+func (wheel *timingWheel) within(deadline int64) []expiry {
+	return wheel.visit(wheel.cursor, (deadline+wheel.resolution-1)/wheel.resolution, deadline, false)
+}
+
+// visit gets the expirations at or before deadline in the slots from first to last
+//
+// When remove is true, the expirations are removed from the wheel.
+func (wheel *timingWheel) visit(first, last, deadline int64, remove bool) (found []expiry) {
+	count := min(last-first+1, int64(len(wheel.slots)))
+	for i := int64(0); i < count; i++ {
+		slot := int((first + i) % int64(len(wheel.slots)))
+		for key, at := range wheel.slots[slot] {
+			if at <= deadline {
+				found = append(found, expiry{key: key, at: at})
+				if remove {
+					delete(wheel.slots[slot], key)
+					delete(wheel.index, key)
+				}
+			}
+		}
+	}
+	return
+}
+
+// clear removes all the expirations
+func (wheel *timingWheel) clear() {
+	wheel.slots = make([]map[string]int64, len(wheel.slots))
+	wheel.index = map[string]int{}
+}
@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+)
+
+// KeyHits is the number of hits of a key
+type KeyHits struct {
+	Key  string `json:"key"`
+	Hits uint64 `json:"hits"`
+}
+
+// topKeys counts the hits of the most read keys with the Space-Saving algorithm
+//
+// It keeps a bounded number of counters. When a key that is not counted is read and the counters are full,
+// the key replaces the key with the fewest hits and inherits its count, so counts are over-estimated
+// by at most the count of the replaced key.
+type topKeys struct {
+	mutex    sync.Mutex
+	size     int
+	counters map[string]uint64
+}
+
+// topKeysFactor is the number of counters kept for each top key
+//
+// The more counters, the more accurate the top keys are.
+const topKeysFactor = 10
+
+// WithTopKeys counts the hits of the most read keys, so the StatsHandler can show the top count keys
+//
+// A count of 0 stops counting.
+func (cache *Cache[T]) WithTopKeys(count int) *Cache[T] {
+	return cache.configure(func(config *settings) {
+		config.topKeys = nil
+		if count > 0 {
+			config.topKeys = &topKeys{size: count, counters: make(map[string]uint64, count*topKeysFactor)}
+		}
+	})
+}
+
+// TopKeys gets the most read keys, the most read first
+//
+// It is empty unless the hits are counted with WithTopKeys.
+func (cache *Cache[T]) TopKeys() []KeyHits {
+	if top := cache.settings().topKeys; top != nil {
+		return top.top()
+	}
+	return []KeyHits{}
+}
+
+// hit counts a hit of a key
+func (top *topKeys) hit(key string) {
+	top.mutex.Lock()
+	defer top.mutex.Unlock()
+	if count, found := top.counters[key]; found || len(top.counters) < top.size*topKeysFactor {
+		top.counters[key] = count + 1
+		return
+	}
+	var victim string
+	var fewest uint64
+	for key, count := range top.counters {
+		if len(victim) == 0 || count < fewest {
+			victim, fewest = key, count
+		}
+	}
+	delete(top.counters, victim)
+	top.counters[key] = fewest + 1
+}
+
+// top gets the keys with the most hits
+func (top *topKeys) top() []KeyHits {
+	top.mutex.Lock()
+	keys := make([]KeyHits, 0, len(top.counters))
+	for key, count := range top.counters {
+		keys = append(keys, KeyHits{Key: key, Hits: count})
+	}
+	top.mutex.Unlock()
+	slices.SortFunc(keys, func(a, b KeyHits) int {
+		if a.Hits != b.Hits {
+			return cmp.Compare(b.Hits, a.Hits)
+		}
+		return cmp.Compare(a.Key, b.Key)
+	})
+	return keys[:min(len(keys), top.size)]
+}
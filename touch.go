@@ -0,0 +1,46 @@
+package cache
+
+import "time"
+
+// WithTouchOnGet extends the expiration of the items that are read
+//
+// After a successful Get or GetOrCompute, the item lives at least extension more, unless its expiration is already later.
+// Unlike a sliding expiration, the whole expiration of the cache does not restart on each read,
+// but an item read more often than extension keeps being extended and does not expire, see WithMaxLifetime.
+//
+// Items that do not expire are not changed. The extended expiration is kept in memory only,
+// an item loaded again from the disk gets its persisted expiration.
+//
+// An extension of 0 stops extending the items.
+func (cache *Cache[T]) WithTouchOnGet(extension time.Duration) *Cache[T] {
+	return cache.configure(func(config *settings) {
+		config.touchOnGet = max(extension, 0)
+	})
+}
+
+// WithMaxLifetime caps the expiration extended by WithTouchOnGet to lifetime after the item was set
+//
+// An item read often then expires at most lifetime after its Set, even if it is still read.
+// The expiration given to Set or ExpireAt is not capped.
+// The creation time is recorded only while a lifetime is set, items set before are not capped.
+//
+// A lifetime of 0 removes the cap.
+func (cache *Cache[T]) WithMaxLifetime(lifetime time.Duration) *Cache[T] {
+	return cache.configure(func(config *settings) {
+		config.maxLifetime = max(lifetime, 0)
+	})
+}
+
+// extend extends the expiration of the record of a key that was read, see WithTouchOnGet
+func (cache *Cache[T]) extend(config *settings, key string, entry record[T]) {
+	if config.touchOnGet == 0 || entry.Expiration == 0 {
+		return
+	}
+	extended := uint64(time.Now().Add(config.touchOnGet).UnixNano())
+	if config.maxLifetime > 0 && entry.Created > 0 {
+		extended = min(extended, entry.Created+uint64(config.maxLifetime))
+	}
+	if extended > entry.Expiration {
+		cache.write(func(storage *store[T]) { storage.extend(key, entry.Expiration, extended) })
+	}
+}
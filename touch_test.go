@@ -0,0 +1,46 @@
+package cache_test
+
+import (
+	"time"
+
+	"github.com/gildas/go-cache"
+)
+
+func (suite *CacheSuite) TestCanExtendExpirationOnGet() {
+	items := cache.New[string]("test").WithExpiration(50 * time.Millisecond).WithTouchOnGet(100 * time.Millisecond)
+	_ = items.Set("value", "key")
+	_ = items.Set("other", "other")
+
+	time.Sleep(30 * time.Millisecond)
+	_, err := items.Get("key")
+	suite.Require().NoError(err, "Failed to get item: %+v", err)
+	time.Sleep(40 * time.Millisecond)
+	_, err = items.Get("key")
+	suite.Assert().NoError(err, "The item should have been extended")
+	_, err = items.Get("other")
+	suite.Assert().Error(err, "The item that was not read should be expired")
+
+	keys := items.ExpiringWithin(110 * time.Millisecond)
+	suite.Assert().Equal([]string{"key"}, keys)
+	suite.Assert().Empty(items.ExpiringWithin(50*time.Millisecond), "The extension should be capped to 100ms after the last read")
+}
+
+func (suite *CacheSuite) TestShouldNotShortenExpirationOnGet() {
+	items := cache.New[string]("test").WithExpiration(time.Hour).WithTouchOnGet(time.Minute)
+	_ = items.Set("value", "key")
+	_, _ = items.Get("key")
+	suite.Assert().Empty(items.ExpiringWithin(30*time.Minute), "The item should keep its longer expiration")
+}
+
+func (suite *CacheSuite) TestCanCapExtensionsWithMaxLifetime() {
+	items := cache.New[string]("test").WithExpiration(150 * time.Millisecond).WithTouchOnGet(150 * time.Millisecond).WithMaxLifetime(450 * time.Millisecond)
+	_ = items.Set("value", "key")
+	for range 4 {
+		time.Sleep(90 * time.Millisecond)
+		_, err := items.Get("key")
+		suite.Require().NoError(err, "The item should have been extended: %+v", err)
+	}
+	time.Sleep(120 * time.Millisecond)
+	_, err := items.Get("key")
+	suite.Assert().Error(err, "The item should have expired at its maximum lifetime, even though it was read")
+}
@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"bufio"
+	"encoding/binary"
+	"hash/fnv"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/gildas/go-errors"
+)
+
+// TraceOperation is the operation of a TraceEvent
+type TraceOperation byte
+
+const (
+	// TraceGet is a read of the cache
+	TraceGet TraceOperation = iota
+	// TraceSet is a write in the cache
+	TraceSet
+)
+
+// TraceEvent is an access to the cache recorded by WithAccessTrace
+type TraceEvent struct {
+	Time      time.Time
+	KeyHash   uint64
+	Operation TraceOperation
+	Hit       bool
+	Latency   time.Duration
+}
+
+// traceMagic starts every trace, followed by the format version
+var traceMagic = []byte("GCTR\x01")
+
+// accessTracer writes sampled TraceEvents to an io.Writer
+//
+// The format is compact: the trace starts with a magic header and the time of the first event (8 bytes, big endian, in nanoseconds),
+// then each event is the time since the previous event (uvarint, in nanoseconds),
+// the key hash (8 bytes, big endian), the operation and hit flag (1 byte), and the latency (uvarint, in nanoseconds).
+type accessTracer struct {
+	mutex     sync.Mutex
+	writer    io.Writer
+	threshold uint64
+	last      int64
+	buffer    []byte
+}
+
+// newAccessTracer creates a new accessTracer that keeps sampleRate of the keys
+func newAccessTracer(writer io.Writer, sampleRate float64) *accessTracer {
+	sampleRate = min(max(sampleRate, 0), 1)
+	return &accessTracer{
+		writer:    writer,
+		threshold: uint64(sampleRate * 10000),
+		buffer:    make([]byte, 0, 32),
+	}
+}
+
+// trace records an access to a key if the key is sampled
+func (tracer *accessTracer) trace(key string, operation TraceOperation, hit bool, start time.Time) {
+	hash := traceKeyHash(key)
+	if hash%10000 >= tracer.threshold {
+		return
+	}
+	latency := time.Since(start)
+	tracer.mutex.Lock()
+	defer tracer.mutex.Unlock()
+	buffer := tracer.buffer[:0]
+	if tracer.last == 0 {
+		buffer = append(buffer, traceMagic...)
+		buffer = binary.BigEndian.AppendUint64(buffer, uint64(start.UnixNano()))
+		tracer.last = start.UnixNano()
+	}
+	buffer = binary.AppendUvarint(buffer, uint64(max(start.UnixNano()-tracer.last, 0)))
+	buffer = binary.BigEndian.AppendUint64(buffer, hash)
+	flags := byte(operation) << 1
+	if hit {
+		flags |= 1
+	}
+	buffer = append(buffer, flags)
+	buffer = binary.AppendUvarint(buffer, uint64(latency.Nanoseconds()))
+	tracer.last = max(start.UnixNano(), tracer.last)
+	tracer.buffer = buffer
+	_, _ = tracer.writer.Write(buffer)
+}
+
+// traceKeyHash computes the hash of a key as stored in a trace
+func traceKeyHash(key string) uint64 {
+	hasher := fnv.New64a()
+	_, _ = hasher.Write([]byte(key))
+	return hasher.Sum64()
+}
+
+// TraceReader reads the TraceEvents written by WithAccessTrace
+type TraceReader struct {
+	reader *bufio.Reader
+	last   int64
+	header bool
+}
+
+// NewTraceReader creates a new TraceReader
+func NewTraceReader(reader io.Reader) *TraceReader {
+	return &TraceReader{reader: bufio.NewReader(reader)}
+}
+
+// Next reads the next TraceEvent
+//
+// It returns io.EOF when there are no more events.
+func (reader *TraceReader) Next() (event TraceEvent, err error) {
+	if !reader.header {
+		header := make([]byte, len(traceMagic))
+		if _, err = io.ReadFull(reader.reader, header); err != nil {
+			return event, err
+		}
+		if string(header) != string(traceMagic) {
+			return event, errors.ArgumentInvalid.With("trace", string(header))
+		}
+		var start [8]byte
+		if _, err = io.ReadFull(reader.reader, start[:]); err != nil {
+			return event, io.ErrUnexpectedEOF
+		}
+		reader.last = int64(binary.BigEndian.Uint64(start[:]))
+		reader.header = true
+	}
+	delta, err := binary.ReadUvarint(reader.reader)
+	if err != nil {
+		return event, err
+	}
+	var hash [8]byte
+	if _, err = io.ReadFull(reader.reader, hash[:]); err != nil {
+		return event, io.ErrUnexpectedEOF
+	}
+	flags, err := reader.reader.ReadByte()
+	if err != nil {
+		return event, io.ErrUnexpectedEOF
+	}
+	latency, err := binary.ReadUvarint(reader.reader)
+	if err != nil {
+		return event, io.ErrUnexpectedEOF
+	}
+	reader.last += int64(delta)
+	return TraceEvent{
+		Time:      time.Unix(0, reader.last),
+		KeyHash:   binary.BigEndian.Uint64(hash[:]),
+		Operation: TraceOperation(flags >> 1),
+		Hit:       flags&1 == 1,
+		Latency:   time.Duration(latency),
+	}, nil
+}
+
+// WithAccessTrace records the accesses to the cache in the given writer
+//
+// Only sampleRate (between 0 and 1) of the keys are recorded, the keys are sampled
+// by their hash so all the accesses to a sampled key are recorded.
+// Keys are recorded as hashes, the trace can be read with a TraceReader.
+//
+// A nil writer stops the recording.
+func (cache *Cache[T]) WithAccessTrace(writer io.Writer, sampleRate float64) *Cache[T] {
+	return cache.configure(func(config *settings) {
+		if writer == nil {
+			config.tracer = nil
+			return
+		}
+		config.tracer = newAccessTracer(writer, sampleRate)
+	})
+}
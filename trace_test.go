@@ -0,0 +1,49 @@
+package cache_test
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/google/uuid"
+
+	"github.com/gildas/go-cache"
+)
+
+func (suite *CacheSuite) TestCanRecordAccessTrace() {
+	var trace bytes.Buffer
+	users := cache.New[User]("test").WithAccessTrace(&trace, 1)
+	defer func() { _ = users.Clear() }()
+	user := User{ID: uuid.New(), Name: "Joe"}
+	_ = users.Set(user, "joe")
+	_, _ = users.Get("joe")
+	_, _ = users.Get("jane")
+
+	reader := cache.NewTraceReader(&trace)
+	events := []cache.TraceEvent{}
+	for {
+		event, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		suite.Require().NoError(err, "Failed to read trace: %+v", err)
+		events = append(events, event)
+	}
+	// Set stores the user under "joe", its ID, and its name
+	suite.Require().Len(events, 5)
+	suite.Assert().Equal(cache.TraceSet, events[0].Operation)
+	suite.Assert().Equal(cache.TraceGet, events[3].Operation)
+	suite.Assert().True(events[3].Hit, "The first Get should be a hit")
+	suite.Assert().Equal(events[0].KeyHash, events[3].KeyHash, "The same key should have the same hash")
+	suite.Assert().False(events[4].Hit, "The second Get should be a miss")
+	suite.Assert().NotEqual(events[3].KeyHash, events[4].KeyHash)
+	suite.Assert().False(events[4].Time.Before(events[0].Time), "Events should be in order")
+}
+
+func (suite *CacheSuite) TestCanSampleAccessTrace() {
+	var trace bytes.Buffer
+	users := cache.New[User]("test").WithAccessTrace(&trace, 0)
+	defer func() { _ = users.Clear() }()
+	_ = users.Set(User{ID: uuid.New(), Name: "Joe"}, "joe")
+	_, _ = users.Get("joe")
+	suite.Assert().Equal(0, trace.Len(), "No key should have been sampled")
+}
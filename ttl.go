@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/gildas/go-errors"
+)
+
+// DefaultTTL gets the time to live of the items set without their own expiration
+//
+// 0 means the items do not expire.
+func (cache *Cache[T]) DefaultTTL() time.Duration {
+	return cache.settings().expiration
+}
+
+// TTL gets the time the item of the given key has left to live
+//
+// 0 means the item does not expire. If the key is not in the cache or has expired,
+// an errors.NotFound is returned.
+func (cache *Cache[T]) TTL(key string) (time.Duration, error) {
+	entry, err := cache.live(cache.settings(), key)
+	if err != nil {
+		return 0, err
+	}
+	if entry.Expiration == 0 {
+		return 0, nil
+	}
+	return time.Until(expirationTime(entry.Expiration)), nil
+}
+
+// ExpireAt changes the expiration of the item of the given key, without setting it again
+//
+// The zero time makes the item never expire, a time in the past deletes it.
+// The new expiration is persisted, and given to the auditor and the OnChange callback as a set.
+//
+// If the key is not in the cache or has expired, an errors.NotFound is returned.
+// If the item is immutable, an ErrImmutable is returned, see SetImmutable.
+func (cache *Cache[T]) ExpireAt(key string, at time.Time) (err error) {
+	config := cache.settings()
+	entry, err := cache.live(config, key)
+	if err != nil {
+		return err
+	}
+	if entry.Immutable {
+		return ErrImmutable.With(key)
+	}
+	if !at.IsZero() && !at.After(time.Now()) {
+		return cache.Delete(key)
+	}
+	entry.Key = key
+	entry.Expiration = 0
+	if !at.IsZero() {
+		entry.Expiration = uint64(at.UnixNano())
+	}
+	if err = cache.keep(config, key, entry); err != nil {
+		return
+	}
+	if err = config.save(key, entry); err != nil {
+		return keyNotPersisted(key, err)
+	}
+	if config.auditor != nil {
+		config.audit(context.Background(), cache.Name, AuditSet, key, auditSize(entry.Item))
+	}
+	if config.onChange != nil {
+		cache.changed(context.Background(), config, Change[T]{Operation: AuditSet, Key: key, Item: entry.Item, Expiration: at, Tier: entry.Tier})
+	}
+	return nil
+}
+
+// live gets the record of a key that has not expired
+func (cache *Cache[T]) live(config *settings, key string) (entry record[T], err error) {
+	entry, found, err := cache.lookup(config, key)
+	if err != nil && !errors.Is(err, ErrCorrupted) {
+		return entry, err
+	}
+	if !found || entry.expired() {
+		return entry, notFound(key, err)
+	}
+	return entry, nil
+}
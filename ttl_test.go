@@ -0,0 +1,54 @@
+package cache_test
+
+import (
+	"time"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+func (suite *CacheSuite) TestCanGetTTL() {
+	users := cache.New[string]("test").WithExpiration(time.Hour)
+	suite.Assert().Equal(time.Hour, users.DefaultTTL())
+	suite.Assert().Zero(cache.New[string]("test").DefaultTTL())
+
+	_ = users.Set("Joe", "joe")
+	ttl, err := users.TTL("joe")
+	suite.Require().NoError(err, "Failed to get TTL: %+v", err)
+	suite.Assert().InDelta(time.Hour, ttl, float64(time.Second))
+
+	_ = users.SetWithExpiration("Ann", 0, "ann")
+	ttl, err = users.TTL("ann")
+	suite.Require().NoError(err, "Failed to get TTL: %+v", err)
+	suite.Assert().Zero(ttl, "An item that does not expire should have a TTL of 0")
+
+	_, err = users.TTL("unknown")
+	suite.Assert().ErrorIs(err, errors.NotFound)
+}
+
+func (suite *CacheSuite) TestCanExpireAt() {
+	users := cache.New[string]("test", cache.CacheOptionPersistent).WithExpiration(time.Hour)
+	defer func() { _ = users.Clear() }()
+	_ = users.Set("Joe", "joe")
+
+	err := users.ExpireAt("joe", time.Now().Add(24*time.Hour))
+	suite.Require().NoError(err, "Failed to change expiration: %+v", err)
+	reopened := cache.New[string]("test", cache.CacheOptionPersistent)
+	ttl, err := reopened.TTL("joe")
+	suite.Require().NoError(err, "Failed to get TTL: %+v", err)
+	suite.Assert().InDelta(24*time.Hour, ttl, float64(time.Second), "The new expiration should be persisted")
+
+	suite.Require().NoError(users.ExpireAt("joe", time.Time{}))
+	ttl, _ = users.TTL("joe")
+	suite.Assert().Zero(ttl, "The item should not expire anymore")
+
+	suite.Require().NoError(users.ExpireAt("joe", time.Now().Add(-time.Second)))
+	_, err = users.Get("joe")
+	suite.Assert().ErrorIs(err, errors.NotFound, "An expiration in the past should delete the item")
+
+	err = users.ExpireAt("unknown", time.Now().Add(time.Hour))
+	suite.Assert().ErrorIs(err, errors.NotFound)
+	_ = users.SetImmutable("Ann", "ann")
+	err = users.ExpireAt("ann", time.Now().Add(time.Hour))
+	suite.Assert().ErrorIs(err, cache.ErrImmutable)
+}
@@ -0,0 +1,29 @@
+package cache
+
+// WithValidator validates the items before they are cached
+//
+// Set and Add return an ErrInvalidItem that wraps the validator's error
+// when an item is rejected, and the item is not cached.
+//
+// A nil validator stops the validation.
+func (cache *Cache[T]) WithValidator(validator func(item T) error) *Cache[T] {
+	return cache.configure(func(config *settings) {
+		if validator == nil {
+			config.validator = nil
+			return
+		}
+		config.validator = func(item any) error {
+			return validator(item.(T))
+		}
+	})
+}
+
+// validate validates the given item with the validator of the settings, if any
+func (config *settings) validate(item any) error {
+	if config.validator != nil {
+		if err := config.validator(item); err != nil {
+			return ErrInvalidItem.Wrap(err)
+		}
+	}
+	return nil
+}
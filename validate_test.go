@@ -0,0 +1,30 @@
+package cache_test
+
+import (
+	"github.com/google/uuid"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+func (suite *CacheSuite) TestCanRejectInvalidItems() {
+	users := cache.New[User]("test").WithValidator(func(user User) error {
+		if user.ID == uuid.Nil {
+			return errors.ArgumentMissing.With("ID")
+		}
+		return nil
+	})
+
+	err := users.Set(User{Name: "Joe"}, "joe")
+	suite.Require().Error(err, "Set should reject an invalid item")
+	suite.Assert().ErrorIs(err, cache.ErrInvalidItem)
+	suite.Assert().ErrorIs(err, errors.ArgumentMissing, "The error should wrap the validator's error")
+	_, err = users.Get("joe")
+	suite.Assert().ErrorIs(err, errors.NotFound, "An invalid item should not be cached")
+
+	err = users.Add("friends", User{Name: "Jane"})
+	suite.Assert().ErrorIs(err, cache.ErrInvalidItem, "Add should reject an invalid item")
+
+	err = users.Set(User{ID: uuid.New(), Name: "Joe"}, "joe")
+	suite.Assert().NoError(err, "Set should accept a valid item")
+}
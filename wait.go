@@ -0,0 +1,100 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gildas/go-errors"
+)
+
+// waiters contains the channels closed when keys are set, see Wait
+type waiters struct {
+	mutex   sync.Mutex
+	keys    map[string]*waitingKey
+	waiting atomic.Int64
+}
+
+// waitingKey is the channel closed when a key is set and the number of waiters on it
+type waitingKey struct {
+	set     chan struct{}
+	waiting int
+}
+
+// Wait waits until an item is set under the given key and gets it
+//
+// If the key already holds an item that has not expired, it is returned immediately.
+// Otherwise Wait blocks until another goroutine sets the key, or returns the error of ctx when it is done.
+//
+// This lets consumers wait for the items produced asynchronously instead of polling Get.
+func (cache *Cache[T]) Wait(ctx context.Context, key string) (*T, error) {
+	if len(key) == 0 {
+		return nil, emptyKey()
+	}
+	config := cache.settings()
+	for {
+		// The key is watched before the lookup, so an item set in between is not missed
+		watched := cache.waiters.watch(key)
+		entry, found, err := cache.lookup(config, key)
+		if errors.Is(err, ErrCorrupted) {
+			err = nil
+		}
+		if err != nil || (found && !entry.expired()) {
+			cache.waiters.leave(key, watched)
+			if err != nil {
+				return nil, err
+			}
+			return &entry.Item, nil
+		}
+		select {
+		case <-ctx.Done():
+			cache.waiters.leave(key, watched)
+			return nil, ctx.Err()
+		case <-watched.set:
+			cache.waiters.leave(key, watched)
+		}
+	}
+}
+
+// watch gets the channel closed when the key is set
+func (waiters *waiters) watch(key string) *waitingKey {
+	waiters.mutex.Lock()
+	defer waiters.mutex.Unlock()
+	if waiters.keys == nil {
+		waiters.keys = map[string]*waitingKey{}
+	}
+	watched, found := waiters.keys[key]
+	if !found {
+		watched = &waitingKey{set: make(chan struct{})}
+		waiters.keys[key] = watched
+	}
+	watched.waiting++
+	waiters.waiting.Add(1)
+	return watched
+}
+
+// leave tells a waiter does not wait for the key anymore
+func (waiters *waiters) leave(key string, watched *waitingKey) {
+	waiters.mutex.Lock()
+	defer waiters.mutex.Unlock()
+	watched.waiting--
+	waiters.waiting.Add(-1)
+	if watched.waiting == 0 && waiters.keys[key] == watched {
+		delete(waiters.keys, key)
+	}
+}
+
+// notify wakes up the waiters of the given key
+//
+// It costs an atomic load when no one waits.
+func (waiters *waiters) notify(key string) {
+	if waiters.waiting.Load() == 0 {
+		return
+	}
+	waiters.mutex.Lock()
+	defer waiters.mutex.Unlock()
+	if watched, found := waiters.keys[key]; found {
+		close(watched.set)
+		delete(waiters.keys, key)
+	}
+}
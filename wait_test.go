@@ -0,0 +1,34 @@
+package cache_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/gildas/go-cache"
+)
+
+func (suite *CacheSuite) TestCanWaitForKey() {
+	results := cache.New[string]("test")
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		_ = results.Set("done", "job")
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	value, err := results.Wait(ctx, "job")
+	suite.Require().NoError(err, "Failed to wait for key: %+v", err)
+	suite.Assert().Equal("done", *value)
+
+	value, err = results.Wait(ctx, "job")
+	suite.Require().NoError(err, "Waiting for a key that is set should not block: %+v", err)
+	suite.Assert().Equal("done", *value)
+}
+
+func (suite *CacheSuite) TestShouldStopWaitingForKeyWhenContextIsDone() {
+	results := cache.New[string]("test")
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := results.Wait(ctx, "job")
+	suite.Assert().ErrorIs(err, context.DeadlineExceeded)
+}
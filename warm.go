@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gildas/go-errors"
+)
+
+// HotKeySet is the hot-key set a draining instance hands over to its replacement
+//
+// See HotKeysHandler and WarmFrom.
+type HotKeySet struct {
+	Name string    `json:"name"`
+	Keys []KeyHits `json:"keys"`
+}
+
+// HotKeys gets the hot-key set of the cache, the most read keys first
+//
+// It is empty unless the hits are counted with WithTopKeys.
+func (cache *Cache[T]) HotKeys() HotKeySet {
+	return HotKeySet{Name: cache.Name, Keys: cache.TopKeys()}
+}
+
+// HotKeysHandler gets an http.Handler that serves the hot-key set of the cache in JSON
+//
+// During a rolling deploy, the replacement instance calls WarmFrom with the URL of this handler
+// on the draining instance, so it loads the hot keys before it takes traffic.
+func (cache *Cache[T]) HotKeysHandler() http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodGet && request.Method != http.MethodHead {
+			writer.Header().Set("Allow", "GET, HEAD")
+			http.Error(writer, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		writer.Header().Set("Content-Type", "application/json")
+		writer.Header().Set("Cache-Control", "no-store")
+		_ = json.NewEncoder(writer).Encode(cache.HotKeys())
+	})
+}
+
+// Warm loads the given keys with the loader given to WithLoader, with a pool of workers
+//
+// The keys that are already in the cache are not loaded again, and the loads of a key are shared
+// with the concurrent calls to Load. Up to workers keys are loaded at a time, GOMAXPROCS if workers is 0 or less.
+// The errors of the loader are given to the error handler with OperationWarm, see WithErrorHandler.
+//
+// Warm returns the number of loaded keys. When the context is cancelled, the context error is returned.
+// If there is no loader, an errors.ArgumentMissing is returned.
+func (cache *Cache[T]) Warm(ctx context.Context, keys []string, workers int) (loaded int, err error) {
+	config := cache.settings()
+	loader, ok := config.loader.(func(context.Context, string) (T, error))
+	if !ok {
+		return 0, errors.ArgumentMissing.With("loader")
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	var wg sync.WaitGroup
+	var count atomic.Int64
+	queue := make(chan string)
+	for range min(workers, max(len(keys), 1)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range queue {
+				if entry, found, _ := cache.lookup(config, key); found && !entry.expired() {
+					continue
+				}
+				if _, err := cache.join(ctx, config, key, func(ctx context.Context) (T, error) {
+					return loader(ctx, key)
+				}, computeOptions{}); err != nil {
+					config.reportError(OperationWarm, key, err)
+					continue
+				}
+				count.Add(1)
+			}
+		}()
+	}
+	func() {
+		defer close(queue)
+		for _, key := range keys {
+			if len(key) == 0 {
+				continue
+			}
+			select {
+			case queue <- key:
+			case <-ctx.Done():
+				err = ctx.Err()
+				return
+			}
+		}
+	}()
+	wg.Wait()
+	return int(count.Load()), err
+}
+
+// WarmFrom loads the hot-key set served by the HotKeysHandler at the given URL, see Warm
+//
+// The hot-key set is fetched with http.DefaultClient. To use another client, fetch the HotKeySet and give its keys to Warm.
+func (cache *Cache[T]) WarmFrom(ctx context.Context, url string, workers int) (loaded int, err error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, errors.InvalidURL.With(url)
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return 0, errors.FromHTTPStatusCode(response.StatusCode)
+	}
+	var set HotKeySet
+	if err = json.NewDecoder(response.Body).Decode(&set); err != nil {
+		return 0, errors.JSONUnmarshalError.Wrap(err)
+	}
+	keys := make([]string, 0, len(set.Keys))
+	for _, key := range set.Keys {
+		keys = append(keys, key.Key)
+	}
+	return cache.Warm(ctx, keys, workers)
+}
@@ -0,0 +1,51 @@
+package cache_test
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+func (suite *CacheSuite) TestCanWarmFromDrainingInstance() {
+	draining := cache.New[string]("test").WithTopKeys(2)
+	for i := 0; i < 3; i++ {
+		_ = draining.Set(fmt.Sprintf("value-%d", i), fmt.Sprintf("key-%d", i))
+		for range i + 1 {
+			_, _ = draining.Get(fmt.Sprintf("key-%d", i))
+		}
+	}
+	server := httptest.NewServer(draining.HotKeysHandler())
+	defer server.Close()
+
+	var mutex sync.Mutex
+	var loaded []string
+	replacement := cache.New[string]("test").WithLoader(func(ctx context.Context, key string) (string, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		loaded = append(loaded, key)
+		return "loaded " + key, nil
+	})
+	count, err := replacement.WarmFrom(context.Background(), server.URL, 2)
+	suite.Require().NoError(err, "Failed to warm the cache: %+v", err)
+	suite.Assert().Equal(2, count)
+	suite.Assert().ElementsMatch([]string{"key-2", "key-1"}, loaded, "Only the hot keys should be loaded")
+
+	value, err := replacement.Get("key-2")
+	suite.Require().NoError(err, "Failed to get item: %+v", err)
+	suite.Assert().Equal("loaded key-2", *value)
+	_, err = replacement.Get("key-0")
+	suite.Assert().ErrorIs(err, errors.NotFound, "The cold keys should not be loaded")
+
+	count, err = replacement.Warm(context.Background(), []string{"key-1", "key-2"}, 0)
+	suite.Require().NoError(err, "Failed to warm the cache: %+v", err)
+	suite.Assert().Equal(0, count, "The keys already in the cache should not be loaded again")
+}
+
+func (suite *CacheSuite) TestCannotWarmWithoutLoader() {
+	_, err := cache.New[string]("test").Warm(context.Background(), []string{"key"}, 1)
+	suite.Assert().ErrorIs(err, errors.ArgumentMissing)
+}
@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// Windowed caches items per period of time, like per-minute or per-hour aggregates
+//
+// The keys given to a Windowed are suffixed with the start of the current window,
+// e.g. "metric:2024-06-01T10:00", and the items expire with their window.
+//
+// Update is atomic only between the callers of the same Windowed.
+type Windowed[T any] struct {
+	Cache  *Cache[T]
+	Window time.Duration
+	Keep   int
+	mutex  sync.Mutex
+}
+
+// AsWindowed gets a Windowed that works on the cache
+//
+// The items of a window expire keep windows after the start of their window, so the items of
+// the current window and of the keep-1 previous windows can be read.
+// A window of 0 or less is set to one minute, a keep less than 1 is set to 1.
+func (cache *Cache[T]) AsWindowed(window time.Duration, keep int) *Windowed[T] {
+	if window <= 0 {
+		window = time.Minute
+	}
+	return &Windowed[T]{Cache: cache, Window: window, Keep: max(keep, 1)}
+}
+
+// Key gets the key of the current window for the given key
+func (windowed *Windowed[T]) Key(key string) string {
+	return windowed.KeyAt(key, time.Now())
+}
+
+// KeyAt gets the key of the window that contains at for the given key
+func (windowed *Windowed[T]) KeyAt(key string, at time.Time) string {
+	layout := "2006-01-02T15:04:05.999999999"
+	switch {
+	case windowed.Window%time.Minute == 0:
+		layout = "2006-01-02T15:04"
+	case windowed.Window%time.Second == 0:
+		layout = "2006-01-02T15:04:05"
+	}
+	return key + ":" + at.UTC().Truncate(windowed.Window).Format(layout)
+}
+
+// Set stores the item in the current window under the given key
+func (windowed *Windowed[T]) Set(item T, key string) error {
+	now := time.Now()
+	return windowed.Cache.SetWithExpiration(item, windowed.expiration(now), windowed.KeyAt(key, now))
+}
+
+// Get gets the item stored in the current window under the given key
+func (windowed *Windowed[T]) Get(key string) (*T, error) {
+	return windowed.Cache.Get(windowed.Key(key))
+}
+
+// GetAt gets the item stored under the given key in the window that contains at
+//
+// Only the windows that have not expired can be read, see AsWindowed.
+func (windowed *Windowed[T]) GetAt(key string, at time.Time) (*T, error) {
+	return windowed.Cache.Get(windowed.KeyAt(key, at))
+}
+
+// Update replaces the item of the current window under the given key with the result of update
+//
+// update gets the current item, and found is false if the window has no item for the key yet.
+func (windowed *Windowed[T]) Update(key string, update func(current T, found bool) T) (item T, err error) {
+	windowed.mutex.Lock()
+	defer windowed.mutex.Unlock()
+	now := time.Now()
+	windowKey := windowed.KeyAt(key, now)
+	current, found := windowed.Cache.GetValue(windowKey)
+	item = update(current, found)
+	err = windowed.Cache.SetWithExpiration(item, windowed.expiration(now), windowKey)
+	return
+}
+
+// expiration gets the time to live of the items set at now
+func (windowed *Windowed[T]) expiration(now time.Time) time.Duration {
+	start := now.UTC().Truncate(windowed.Window)
+	return start.Add(windowed.Window * time.Duration(windowed.Keep)).Sub(now)
+}
@@ -0,0 +1,42 @@
+package cache_test
+
+import (
+	"time"
+
+	"github.com/gildas/go-cache"
+	"github.com/gildas/go-errors"
+)
+
+func (suite *CacheSuite) TestCanDeriveWindowedKeys() {
+	at := time.Date(2024, 6, 1, 10, 0, 42, 0, time.UTC)
+	suite.Assert().Equal("metric:2024-06-01T10:00", cache.New[int]("test").AsWindowed(time.Minute, 1).KeyAt("metric", at))
+	suite.Assert().Equal("metric:2024-06-01T10:00", cache.New[int]("test").AsWindowed(time.Hour, 1).KeyAt("metric", at.Add(59*time.Minute)))
+	suite.Assert().Equal("metric:2024-06-01T10:00:40", cache.New[int]("test").AsWindowed(10*time.Second, 1).KeyAt("metric", at))
+}
+
+func (suite *CacheSuite) TestCanCacheWindowedAggregates() {
+	counters := cache.New[int]("test").AsWindowed(100*time.Millisecond, 2)
+	increment := func(current int, found bool) int { return current + 1 }
+	start := time.Now()
+	for range 3 {
+		_, err := counters.Update("requests", increment)
+		suite.Require().NoError(err, "Failed to update counter: %+v", err)
+	}
+	if counters.Key("requests") != counters.KeyAt("requests", start) {
+		suite.T().Skip("The window ended during the updates")
+	}
+	count, err := counters.Get("requests")
+	suite.Require().NoError(err, "Failed to get counter: %+v", err)
+	suite.Assert().Equal(3, *count)
+
+	time.Sleep(time.Until(start.Truncate(100 * time.Millisecond).Add(110 * time.Millisecond)))
+	_, err = counters.Get("requests")
+	suite.Assert().ErrorIs(err, errors.NotFound, "A new window should start empty")
+	count, err = counters.GetAt("requests", start)
+	suite.Require().NoError(err, "The previous window should be kept: %+v", err)
+	suite.Assert().Equal(3, *count)
+
+	time.Sleep(time.Until(start.Truncate(100 * time.Millisecond).Add(210 * time.Millisecond)))
+	_, err = counters.GetAt("requests", start)
+	suite.Assert().ErrorIs(err, errors.NotFound, "The window should have expired")
+}
@@ -0,0 +1,274 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+)
+
+// BackpressurePolicy tells what a write-behind queue does when it is full
+type BackpressurePolicy int
+
+const (
+	// BackpressureBlock makes the write wait until the queue has room
+	BackpressureBlock BackpressurePolicy = iota
+	// BackpressureDropOldest drops the oldest write of the queue to make room
+	BackpressureDropOldest
+	// BackpressureDropNewest drops the new write
+	BackpressureDropNewest
+	// BackpressureSynchronous writes the item immediately, in the goroutine that sets it
+	BackpressureSynchronous
+)
+
+// writeBehind persists the items in a background goroutine
+//
+// The queue holds the files to write in order, the pending writes hold their last value.
+// Setting a key that is already queued replaces its value without taking more room.
+// The write in progress stays readable until it is on the disk, see Cache.Consistency.
+type writeBehind struct {
+	mutex      sync.Mutex
+	changed    *sync.Cond
+	capacity   int
+	policy     BackpressurePolicy
+	queue      []string
+	pending    map[string]*pendingWrite
+	inflight   map[string]*pendingWrite
+	writing    int
+	closed     bool
+	dropped    *atomic.Uint64
+	overflowed *atomic.Uint64
+}
+
+// String gets the name of the policy
+//
+// implements fmt.Stringer
+func (policy BackpressurePolicy) String() string {
+	switch policy {
+	case BackpressureDropOldest:
+		return "drop-oldest"
+	case BackpressureDropNewest:
+		return "drop-newest"
+	case BackpressureSynchronous:
+		return "synchronous"
+	default:
+		return "block"
+	}
+}
+
+// WithWriteBehind persists the items in a background goroutine
+//
+// Set returns once the item is in memory and queued. The queue holds up to capacity writes,
+// when it is full the policy tells what happens to the next write.
+// The writes that were dropped are counted in Stats.OverflowedWrites, those replaced by
+// a later write of the same key in Stats.DroppedWrites. Stats.WriteQueueDepth is the number of queued writes.
+//
+// Call Flush to wait for the queued writes, and Close to stop the goroutine.
+//
+// A capacity of 0 writes the items synchronously.
+func (cache *Cache[T]) WithWriteBehind(capacity int, policy BackpressurePolicy) *Cache[T] {
+	var previous *writeBehind
+
+	cache.configure(func(config *settings) {
+		previous = config.writeBehind
+		config.writeBehind = nil
+		if capacity > 0 {
+			config.writeBehind = newWriteBehind(capacity, policy, &cache.stats.droppedWrites, &cache.stats.overflowedWrites)
+		}
+	})
+	if previous != nil {
+		previous.close()
+	}
+	return cache
+}
+
+// newWriteBehind creates a new writeBehind and starts its goroutine
+func newWriteBehind(capacity int, policy BackpressurePolicy, dropped, overflowed *atomic.Uint64) *writeBehind {
+	queue := &writeBehind{
+		capacity:   capacity,
+		policy:     policy,
+		pending:    map[string]*pendingWrite{},
+		inflight:   map[string]*pendingWrite{},
+		dropped:    dropped,
+		overflowed: overflowed,
+	}
+	queue.changed = sync.NewCond(&queue.mutex)
+	go queue.run()
+	return queue
+}
+
+// write queues the persistence of the value of the given key
+//
+// If the queue is closed, or full with the BackpressureSynchronous policy, the value is persisted immediately.
+func (queue *writeBehind) write(config *settings, key string, value any) error {
+	filekey := config.filekey(key)
+	path := filepath.Join(config.folder, filekey)
+	queue.mutex.Lock()
+	if pending, found := queue.pending[path]; found {
+		pending.config = config
+		pending.value = value
+		queue.dropped.Add(1)
+		queue.mutex.Unlock()
+		return nil
+	}
+	var stale []string
+	defer func() {
+		for _, path := range stale {
+			_ = os.Remove(path)
+		}
+	}()
+	for !queue.closed && len(queue.queue) >= queue.capacity {
+		switch queue.policy {
+		case BackpressureDropOldest:
+			if _, found := queue.pending[queue.queue[0]]; found {
+				delete(queue.pending, queue.queue[0])
+				queue.overflowed.Add(1)
+				if queue.discard(queue.queue[0]) {
+					stale = append(stale, queue.queue[0])
+				}
+			}
+			queue.queue = queue.queue[1:]
+		case BackpressureDropNewest:
+			queue.overflowed.Add(1)
+			if queue.discard(path) {
+				stale = append(stale, path)
+			}
+			queue.mutex.Unlock()
+			return nil
+		case BackpressureSynchronous:
+			queue.mutex.Unlock()
+			return config.persist(filekey, value)
+		default:
+			queue.changed.Wait()
+		}
+	}
+	if queue.closed {
+		queue.mutex.Unlock()
+		return config.persist(filekey, value)
+	}
+	queue.pending[path] = &pendingWrite{config: config, key: key, filekey: filekey, value: value}
+	queue.queue = append(queue.queue, path)
+	queue.changed.Broadcast()
+	queue.mutex.Unlock()
+	return nil
+}
+
+// run persists the queued writes until the queue is closed
+func (queue *writeBehind) run() {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+	for {
+		for !queue.closed && len(queue.queue) == 0 {
+			queue.changed.Wait()
+		}
+		if len(queue.queue) == 0 {
+			return
+		}
+		path := queue.queue[0]
+		queue.queue = queue.queue[1:]
+		pending, found := queue.pending[path]
+		delete(queue.pending, path)
+		if found {
+			queue.writing++
+			queue.inflight[path] = pending
+			queue.mutex.Unlock()
+			if err := pending.config.persist(pending.filekey, pending.value); err != nil {
+				pending.config.reportError(OperationWriteBehind, pending.key, err)
+			}
+			queue.mutex.Lock()
+			queue.writing--
+			if queue.inflight[path] == pending {
+				delete(queue.inflight, path)
+			}
+			if _, requeued := queue.pending[path]; pending.cancelled && !requeued {
+				// The key was deleted, or its next write dropped, while its value was being written
+				_ = os.Remove(path)
+			}
+		}
+		queue.changed.Broadcast()
+	}
+}
+
+// get gets the value waiting to be written in the file named filekey
+func (queue *writeBehind) get(config *settings, filekey string) (any, bool) {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+	path := filepath.Join(config.folder, filekey)
+	if pending, found := queue.pending[path]; found {
+		return pending.value, true
+	}
+	if pending, found := queue.inflight[path]; found {
+		return pending.value, true
+	}
+	return nil, false
+}
+
+// discard forgets the write in progress of the given path, if any, after it was dropped
+//
+// It tells if the file must be removed now, otherwise it is removed when the write in progress is done.
+func (queue *writeBehind) discard(path string) (remove bool) {
+	if pending, writing := queue.inflight[path]; writing {
+		pending.cancelled = true
+		delete(queue.inflight, path)
+		return false
+	}
+	return true
+}
+
+// cancel cancels the write waiting for the file named filekey
+//
+// The path stays in the queue, it is skipped when its turn comes.
+func (queue *writeBehind) cancel(config *settings, filekey string) {
+	path := filepath.Join(config.folder, filekey)
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+	delete(queue.pending, path)
+	if pending, found := queue.inflight[path]; found {
+		pending.cancelled = true
+		delete(queue.inflight, path)
+	}
+}
+
+// cancelAll cancels all the writes waiting in the given folder and its subfolders
+func (queue *writeBehind) cancelAll(folder string) {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+	for path := range queue.pending {
+		if relative, err := filepath.Rel(folder, path); err == nil && filepath.IsLocal(relative) {
+			delete(queue.pending, path)
+		}
+	}
+	for path, pending := range queue.inflight {
+		if relative, err := filepath.Rel(folder, path); err == nil && filepath.IsLocal(relative) {
+			pending.cancelled = true
+			delete(queue.inflight, path)
+		}
+	}
+}
+
+// depth gets the number of queued writes
+func (queue *writeBehind) depth() int {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+	return len(queue.pending)
+}
+
+// flush waits until all the queued writes are persisted
+func (queue *writeBehind) flush() {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+	for len(queue.queue) > 0 || queue.writing > 0 {
+		queue.changed.Wait()
+	}
+}
+
+// close persists the queued writes and stops the goroutine
+//
+// The writes that come after are persisted immediately.
+func (queue *writeBehind) close() {
+	queue.mutex.Lock()
+	queue.closed = true
+	queue.changed.Broadcast()
+	queue.mutex.Unlock()
+	queue.flush()
+}
@@ -0,0 +1,106 @@
+package cache_test
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/gildas/go-cache"
+)
+
+// newGatedWriteBehind creates a cache whose write-behind goroutine is stuck writing "first" until gate is closed
+func (suite *CacheSuite) newGatedWriteBehind(policy cache.BackpressurePolicy) (items *cache.Cache[string], gate chan struct{}) {
+	gate = make(chan struct{})
+	items = cache.New[string]("test", cache.CacheOptionPersistent).WithStoreTransform(func(data []byte) ([]byte, error) {
+		if bytes.Contains(data, []byte(`"first"`)) {
+			<-gate
+		}
+		return data, nil
+	}).WithWriteBehind(1, policy)
+
+	_ = items.Set("first", "first")
+	suite.Require().Eventually(func() bool { return items.Stats().WriteQueueDepth == 0 }, time.Second, time.Millisecond, "The goroutine should be writing first")
+	_ = items.Set("second", "second")
+	suite.Require().Equal(uint64(1), items.Stats().WriteQueueDepth)
+	return
+}
+
+// persisted tells which keys were persisted
+func (suite *CacheSuite) persisted(keys ...string) (found []string) {
+	reader := cache.New[string]("test", cache.CacheOptionPersistent)
+	for _, key := range keys {
+		if _, err := reader.Get(key); err == nil {
+			found = append(found, key)
+		}
+	}
+	return
+}
+
+func (suite *CacheSuite) TestCanWriteBehind() {
+	items := cache.New[string]("test", cache.CacheOptionPersistent).WithWriteBehind(10, cache.BackpressureBlock)
+	defer func() { _ = items.Clear() }()
+	_ = items.Set("value", "key")
+	_ = items.Set("other", "key")
+	value, err := items.Get("key")
+	suite.Require().NoError(err, "Failed to get item: %+v", err)
+	suite.Assert().Equal("other", *value)
+
+	err = items.Close()
+	suite.Require().NoError(err, "Failed to close: %+v", err)
+	suite.Assert().Equal([]string{"key"}, suite.persisted("key"))
+	suite.Assert().Equal(uint64(0), items.Stats().WriteQueueDepth)
+
+	_ = items.Set("value", "after")
+	suite.Assert().Equal([]string{"after"}, suite.persisted("after"), "Items set after Close should be written synchronously")
+}
+
+func (suite *CacheSuite) TestCanDropNewestWrite() {
+	items, gate := suite.newGatedWriteBehind(cache.BackpressureDropNewest)
+	defer func() { _ = items.Clear() }()
+	defer items.Close()
+	_ = items.Set("third", "third")
+	suite.Assert().Equal(uint64(1), items.Stats().OverflowedWrites)
+	close(gate)
+	_ = items.Flush()
+	suite.Assert().Equal([]string{"first", "second"}, suite.persisted("first", "second", "third"))
+}
+
+func (suite *CacheSuite) TestCanDropOldestWrite() {
+	items, gate := suite.newGatedWriteBehind(cache.BackpressureDropOldest)
+	defer func() { _ = items.Clear() }()
+	defer items.Close()
+	_ = items.Set("third", "third")
+	suite.Assert().Equal(uint64(1), items.Stats().OverflowedWrites)
+	close(gate)
+	_ = items.Flush()
+	suite.Assert().Equal([]string{"first", "third"}, suite.persisted("first", "second", "third"))
+}
+
+func (suite *CacheSuite) TestCanWriteSynchronouslyWhenQueueIsFull() {
+	items, gate := suite.newGatedWriteBehind(cache.BackpressureSynchronous)
+	defer func() { _ = items.Clear() }()
+	defer items.Close()
+	_ = items.Set("third", "third")
+	suite.Assert().Equal([]string{"third"}, suite.persisted("first", "second", "third"), "third should be written before returning")
+	close(gate)
+	_ = items.Flush()
+	suite.Assert().Equal([]string{"first", "second", "third"}, suite.persisted("first", "second", "third"))
+}
+
+func (suite *CacheSuite) TestCanBlockWhenQueueIsFull() {
+	items, gate := suite.newGatedWriteBehind(cache.BackpressureBlock)
+	defer func() { _ = items.Clear() }()
+	done := make(chan struct{})
+	go func() {
+		_ = items.Set("third", "third")
+		close(done)
+	}()
+	select {
+	case <-done:
+		suite.Fail("Set should wait for room in the queue")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(gate)
+	<-done
+	_ = items.Close()
+	suite.Assert().Equal([]string{"first", "second", "third"}, suite.persisted("first", "second", "third"))
+}